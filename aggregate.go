@@ -0,0 +1,72 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"math"
+
+	"gopkg.in/redis.v2"
+)
+
+// Aggregate summarizes a set of results
+type Aggregate struct {
+	Count int
+	// Centroid is the geographic mean of the results, nil without results
+	Centroid *Point
+	// Spread is the standard distance of the results from the centroid, the root mean square of their distances,
+	// in meters unless the search used WithUnit
+	Spread float64
+}
+
+// Summarize returns the centroid and spread, in meters, of results
+//
+// The centroid is the mean of the results on the unit sphere so results on both sides of the antimeridian are
+// averaged correctly.
+func Summarize(results []Result) Aggregate {
+	aggregate := Aggregate{Count: len(results)}
+	if len(results) == 0 {
+		return aggregate
+	}
+
+	var x, y, z float64
+	for _, result := range results {
+		lat, lon := radians(result.Lat), radians(result.Lon)
+		x += math.Cos(lat) * math.Cos(lon)
+		y += math.Cos(lat) * math.Sin(lon)
+		z += math.Sin(lat)
+	}
+	count := float64(len(results))
+	x, y, z = x/count, y/count, z/count
+
+	aggregate.Centroid = &Point{
+		Lat: degrees(math.Atan2(z, math.Hypot(x, y))),
+		Lon: degrees(math.Atan2(y, x)),
+	}
+
+	var squares float64
+	for _, result := range results {
+		distance := Haversine(aggregate.Centroid.Lat, aggregate.Centroid.Lon, result.Lat, result.Lon)
+		squares += distance * distance
+	}
+	aggregate.Spread = math.Sqrt(squares / count)
+
+	return aggregate
+}
+
+// SearchAggregate searches like Search and returns the aggregate of the results instead of the results
+func SearchAggregate(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, options ...SearchOption) (Aggregate, error) {
+	results, err := Search(client, bucketName, lat, lon, radius, bitDepth, options...)
+	var partial *PartialResultsError
+	if err != nil && !errors.As(err, &partial) {
+		return Aggregate{}, err
+	}
+
+	aggregate := Summarize(results)
+	aggregate.Spread = newSearchOptions(options).unit.FromMeters(aggregate.Spread)
+
+	return aggregate, err
+}
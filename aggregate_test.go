@@ -0,0 +1,31 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSummarize(t *testing.T) {
+	if aggregate := Summarize(nil); aggregate.Count != 0 || aggregate.Centroid != nil {
+		t.Logf("expected an empty aggregate got %v\n", aggregate)
+		t.Fail()
+	}
+
+	// two points on both sides of the antimeridian are centered on it, not on the prime meridian
+	aggregate := Summarize([]Result{{Lat: 0, Lon: 179}, {Lat: 0, Lon: -179}})
+	if aggregate.Count != 2 || math.Abs(math.Abs(aggregate.Centroid.Lon)-180) > 1e-9 || math.Abs(aggregate.Centroid.Lat) > 1e-9 {
+		t.Logf("unexpected centroid %v\n", aggregate.Centroid)
+		t.Fail()
+	}
+	if expected := Haversine(0, 180, 0, 179); math.Abs(aggregate.Spread-expected) > 1 {
+		t.Logf("expected a spread of %f got %f\n", expected, aggregate.Spread)
+		t.Fail()
+	}
+}
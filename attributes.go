@@ -0,0 +1,140 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/redis.v2"
+)
+
+// setAttributesLuaBody stores the attributes of a member unless it isn't part of the bucket
+//
+// KEYS are the bucket and its attributes, ARGV holds the label and the encoded attributes, empty to delete them.
+// The reply is 0 when the label is not a member.
+const setAttributesLuaBody = `
+if not redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+  return 0
+end
+
+if ARGV[2] == '' then
+  redis.call('HDEL', KEYS[2], ARGV[1])
+else
+  redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+end
+
+return 1
+`
+
+var setAttributesScript = newLuaScript(setAttributesLuaBody)
+
+// WithAttributes returns the attributes stored for each result
+func WithAttributes() SearchOption {
+	return func(o *searchOptions) {
+		o.withAttributes = true
+	}
+}
+
+// SetAttributes replaces the attributes of a member or returns ErrMemberNotFound, empty attributes delete them
+//
+// Attributes are removed together with their member.
+func SetAttributes(client *redis.Client, bucketName, label string, attributes map[string]string) error {
+	encoded := ""
+	if len(attributes) > 0 {
+		value, err := json.Marshal(attributes)
+		if err != nil {
+			return err
+		}
+		encoded = string(value)
+	}
+
+	reply, err := setAttributesScript.run(client, []string{bucketName, attributesKey(bucketName)}, []string{label, encoded})
+	if err != nil {
+		return err
+	}
+	if stored, ok := reply.(int64); !ok || stored == 0 {
+		return ErrMemberNotFound
+	}
+
+	return nil
+}
+
+// GetAttributes returns the attributes of the labels, in the same order, empty if a label has none
+func GetAttributes(client *redis.Client, bucketName string, labels ...string) ([]map[string]string, error) {
+	attributes := make([]map[string]string, len(labels))
+	for idx := range attributes {
+		attributes[idx] = map[string]string{}
+	}
+	if len(labels) == 0 {
+		return attributes, nil
+	}
+
+	values, err := client.HMGet(attributesKey(bucketName), labels...).Result()
+	if err != nil {
+		return attributes, err
+	}
+
+	for idx := range values {
+		value, ok := values[idx].(string)
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal([]byte(value), &attributes[idx]); err != nil {
+			return attributes, fmt.Errorf("invalid attributes of %q: %s", labels[idx], err)
+		}
+	}
+
+	return attributes, nil
+}
+
+// SetAttributes replaces the attributes of a member or returns ErrMemberNotFound
+func (c *GeoClient) SetAttributes(bucketName, label string, attributes map[string]string) error {
+	return c.handle(Operation{Name: "SetAttributes", Bucket: bucketName, Write: true, Args: []any{label, attributes}}, func() error {
+		key := c.key(bucketName)
+		defer c.wrote(key)
+		err := c.do(func() error {
+			return SetAttributes(c.client, key, label, attributes)
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.audit.record(c.client, AuditUpdate, key, []string{label}, "set attributes")
+	})
+}
+
+// GetAttributes returns the attributes of the labels, in the same order
+func (c *GeoClient) GetAttributes(bucketName string, labels ...string) ([]map[string]string, error) {
+	return handleOp(c, Operation{Name: "GetAttributes", Bucket: bucketName, Args: []any{labels}}, func() ([]map[string]string, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() ([]map[string]string, error) {
+			return GetAttributes(c.reader(key), key, labels...)
+		})
+	})
+}
+
+func attributesKey(bucketName string) string {
+	return bucketName + ":attributes"
+}
+
+func attachAttributes(client *redis.Client, bucketName string, results []Result) error {
+	labels := make([]string, len(results))
+	for idx := range results {
+		labels[idx] = results[idx].Label
+	}
+
+	attributes, err := GetAttributes(client, bucketName, labels...)
+	if err != nil {
+		return err
+	}
+
+	for idx := range results {
+		results[idx].Attributes = attributes[idx]
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestAttributes(t *testing.T) {
+	const zSetAttributes = "test:attributes"
+
+	client.Del(zSetAttributes, zSetAttributes+":attributes")
+	AddCoordinates(client, zSetAttributes, bitDepth,
+		GeoKey{Label: "shop", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "bar", Lat: 52.521, Lon: 13.405},
+	)
+
+	if err := SetAttributes(client, zSetAttributes, "shop", map[string]string{"kind": "bakery", "open": "yes"}); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if err := SetAttributes(client, zSetAttributes, "missing", map[string]string{"kind": "none"}); err != ErrMemberNotFound {
+		t.Logf("expected ErrMemberNotFound got %v\n", err)
+		t.Fail()
+	}
+
+	attributes, err := GetAttributes(client, zSetAttributes, "shop", "bar")
+	if err != nil || len(attributes) != 2 || attributes[0]["kind"] != "bakery" || len(attributes[1]) != 0 {
+		t.Logf("unexpected attributes %v, %v\n", attributes, err)
+		t.Fail()
+	}
+
+	results, err := Search(client, zSetAttributes, 52.52, 13.405, 1000, bitDepth, WithAttributes())
+	if err != nil || len(results) != 2 || results[0].Attributes["open"] != "yes" {
+		t.Logf("unexpected results %v, %v\n", results, err)
+		t.Fail()
+	}
+
+	RemoveCoordinatesByKeys(client, zSetAttributes, "shop")
+	AddCoordinates(client, zSetAttributes, bitDepth, GeoKey{Label: "shop", Lat: 52.52, Lon: 13.405})
+	if attributes, err := GetAttributes(client, zSetAttributes, "shop"); err != nil || len(attributes[0]) != 0 {
+		t.Logf("expected the attributes to be removed with the member got %v, %v\n", attributes, err)
+		t.Fail()
+	}
+}
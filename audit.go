@@ -0,0 +1,284 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const auditPageSize = 500
+
+const (
+	// AuditAdd is recorded for members written for the first time
+	AuditAdd AuditAction = "add"
+	// AuditUpdate is recorded for members which were moved
+	AuditUpdate AuditAction = "update"
+	// AuditRemove is recorded for removed members
+	AuditRemove AuditAction = "remove"
+	// AuditAdmin is recorded for fence changes and prunes
+	AuditAdmin AuditAction = "admin"
+)
+
+type (
+	// AuditAction is the kind of an AuditEntry
+	AuditAction string
+
+	// AuditEntry records who modified what and when
+	AuditEntry struct {
+		ID     string
+		Time   time.Time
+		Actor  string
+		Action AuditAction
+		Bucket string
+		Labels []string
+		// Detail describes admin operations, like "delete fence"
+		Detail string
+	}
+
+	// AuditQuery selects audit entries, zero fields match everything
+	AuditQuery struct {
+		From   time.Time
+		To     time.Time
+		Actor  string
+		Action AuditAction
+		Bucket string
+		Label  string
+		// Limit is the maximum number of entries returned, 0 returns all
+		Limit int
+	}
+
+	auditConfig struct {
+		key    string
+		maxLen int64
+		actor  string
+	}
+)
+
+// WithAudit appends an AuditEntry to the stream key for every modification made through the GeoClient, the
+// stream is capped to about maxLen entries unless maxLen is 0
+//
+// Entries are appended after the modification succeeded, see As to set the actor.
+func WithAudit(key string, maxLen int64) ClientOption {
+	return func(c *GeoClient) {
+		c.audit = &auditConfig{key: key, maxLen: maxLen}
+	}
+}
+
+// As returns a copy of the GeoClient recording actor in the audit entries, it shares the connections of c
+func (c *GeoClient) As(actor string) *GeoClient {
+	clone := *c
+	if c.audit != nil {
+		audit := *c.audit
+		audit.actor = actor
+		clone.audit = &audit
+	}
+
+	return &clone
+}
+
+// RecordAudit appends an entry to the audit stream key, the ID and Time of the entry are set by redis
+func RecordAudit(client *redis.Client, key string, maxLen int64, entry AuditEntry) error {
+	args := []string{"XADD", key}
+	if maxLen > 0 {
+		args = append(args, "MAXLEN", "~", strconv.FormatInt(maxLen, 10))
+	}
+	args = append(args, "*",
+		"actor", entry.Actor,
+		"action", string(entry.Action),
+		"bucket", entry.Bucket,
+		"labels", strings.Join(entry.Labels, "\n"),
+	)
+	if entry.Detail != "" {
+		args = append(args, "detail", entry.Detail)
+	}
+
+	cmd := redis.NewCmd(args...)
+	client.Process(cmd)
+
+	return cmd.Err()
+}
+
+// QueryAudit returns the entries of the audit stream key matching query, oldest first
+func QueryAudit(client *redis.Client, key string, query AuditQuery) ([]AuditEntry, error) {
+	start, end := "-", "+"
+	if !query.From.IsZero() {
+		start = formatStreamID(query.From)
+	}
+	if !query.To.IsZero() {
+		end = formatStreamID(query.To)
+	}
+
+	matches := []AuditEntry{}
+	for {
+		cmd := redis.NewCmd("XRANGE", key, start, end, "COUNT", strconv.Itoa(auditPageSize))
+		client.Process(cmd)
+		reply, err := cmd.Result()
+		if err != nil {
+			return []AuditEntry{}, err
+		}
+
+		entries, err := parseAuditEntries(reply)
+		if err != nil {
+			return []AuditEntry{}, err
+		}
+		for _, entry := range entries {
+			if !query.matches(entry) {
+				continue
+			}
+			matches = append(matches, entry)
+			if query.Limit > 0 && len(matches) == query.Limit {
+				return matches, nil
+			}
+		}
+
+		if len(entries) < auditPageSize {
+			return matches, nil
+		}
+		start = "(" + entries[len(entries)-1].ID
+	}
+}
+
+func (q AuditQuery) matches(entry AuditEntry) bool {
+	if q.Actor != "" && entry.Actor != q.Actor {
+		return false
+	}
+	if q.Action != "" && entry.Action != q.Action {
+		return false
+	}
+	if q.Bucket != "" && entry.Bucket != q.Bucket {
+		return false
+	}
+	if q.Label != "" {
+		for _, label := range entry.Labels {
+			if label == q.Label {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// record appends an entry to the audit stream if auditing is enabled
+func (a *auditConfig) record(client *redis.Client, action AuditAction, bucketName string, labels []string, detail string) error {
+	if a == nil || (len(labels) == 0 && action != AuditAdmin) {
+		return nil
+	}
+
+	return RecordAudit(client, a.key, a.maxLen, AuditEntry{
+		Actor:  a.actor,
+		Action: action,
+		Bucket: bucketName,
+		Labels: labels,
+		Detail: detail,
+	})
+}
+
+// recordWrite records the labels which existed before the write, see existingLabels, as updated and the others as
+// added
+func (a *auditConfig) recordWrite(client *redis.Client, bucketName string, labels []string, existed []bool) error {
+	if a == nil {
+		return nil
+	}
+
+	var added, updated []string
+	for idx, label := range labels {
+		if existed[idx] {
+			updated = append(updated, label)
+		} else {
+			added = append(added, label)
+		}
+	}
+
+	if err := a.record(client, AuditAdd, bucketName, added, ""); err != nil {
+		return err
+	}
+
+	return a.record(client, AuditUpdate, bucketName, updated, "")
+}
+
+// existingLabels reports for each label whether it is a member of the bucket
+func existingLabels(client *redis.Client, bucketName string, labels []string) ([]bool, error) {
+	existed := make([]bool, len(labels))
+	if len(labels) == 0 {
+		return existed, nil
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	scores := make([]*redis.FloatCmd, len(labels))
+	_, err := multi.Exec(func() error {
+		for idx, label := range labels {
+			scores[idx] = multi.ZScore(bucketName, label)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return existed, err
+	}
+
+	for idx := range scores {
+		existed[idx] = scores[idx].Err() == nil
+	}
+
+	return existed, nil
+}
+
+func parseAuditEntries(reply interface{}) ([]AuditEntry, error) {
+	entries, ok := reply.([]interface{})
+	if !ok {
+		return []AuditEntry{}, fmt.Errorf("unexpected stream entries %v", reply)
+	}
+
+	audit := make([]AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		values, ok := entry.([]interface{})
+		if !ok || len(values) != 2 {
+			return []AuditEntry{}, fmt.Errorf("unexpected stream entry %v", entry)
+		}
+		id, _ := values[0].(string)
+		fields, ok := values[1].([]interface{})
+		if !ok || len(fields)%2 != 0 {
+			return []AuditEntry{}, fmt.Errorf("unexpected stream entry %v", entry)
+		}
+
+		ms, _, _ := strings.Cut(id, "-")
+		millis, err := strconv.ParseInt(ms, 10, 64)
+		if err != nil {
+			return []AuditEntry{}, fmt.Errorf("unexpected stream entry id %q", id)
+		}
+
+		record := AuditEntry{ID: id, Time: time.UnixMilli(millis), Labels: []string{}}
+		for idx := 0; idx < len(fields); idx += 2 {
+			field, _ := fields[idx].(string)
+			value, _ := fields[idx+1].(string)
+			switch field {
+			case "actor":
+				record.Actor = value
+			case "action":
+				record.Action = AuditAction(value)
+			case "bucket":
+				record.Bucket = value
+			case "labels":
+				if value != "" {
+					record.Labels = strings.Split(value, "\n")
+				}
+			case "detail":
+				record.Detail = value
+			}
+		}
+		audit = append(audit, record)
+	}
+
+	return audit, nil
+}
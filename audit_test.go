@@ -0,0 +1,50 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"slices"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestAudit(t *testing.T) {
+	const zSetAudit = "test:audit"
+	const auditKey = "test:audit:log"
+
+	client.Del(zSetAudit, auditKey)
+
+	geoClient := NewGeoClient(client, bitDepth, WithAudit(auditKey, 0))
+	alice := geoClient.As("alice")
+	alice.AddCoordinates(zSetAudit, GeoKey{Label: "one", Lat: 52.52, Lon: 13.405})
+	geoClient.As("bob").AddCoordinates(zSetAudit, GeoKey{Label: "one", Lat: 52.53, Lon: 13.405}, GeoKey{Label: "two", Lat: 52.52, Lon: 13.4})
+	alice.RemoveCoordinatesByKeys(zSetAudit, "two", "missing")
+
+	entries, err := QueryAudit(client, auditKey, AuditQuery{})
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	actions := []AuditAction{}
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+	if !slices.Equal(actions, []AuditAction{AuditAdd, AuditAdd, AuditUpdate, AuditRemove}) {
+		t.Logf("unexpected actions %v\n", actions)
+		t.FailNow()
+	}
+	if entries[3].Actor != "alice" || !slices.Equal(entries[3].Labels, []string{"two"}) {
+		t.Logf("unexpected remove entry %v\n", entries[3])
+		t.Fail()
+	}
+
+	entries, err = QueryAudit(client, auditKey, AuditQuery{Actor: "bob", Label: "one"})
+	if err != nil || len(entries) != 1 || entries[0].Action != AuditUpdate {
+		t.Logf("expected bob's update of one got %v error %v\n", entries, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,132 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"slices"
+
+	"gopkg.in/redis.v2"
+)
+
+// ErrNoBucketIndex is returned by the reverse index methods of a GeoClient without WithBucketIndex
+var ErrNoBucketIndex = errors.New("client has no bucket index")
+
+// WithBucketIndex maintains a reverse index from labels to the buckets they are members of in the sets
+// <index>:<label>, for WhichBuckets and RemoveEverywhere
+//
+// Only members added and removed through the GeoClient are indexed, IndexBuckets adds existing ones.
+func WithBucketIndex(index string) ClientOption {
+	return func(c *GeoClient) {
+		c.bucketIndex = index
+	}
+}
+
+// IndexBuckets adds bucketName to the buckets of the labels in the reverse index
+func IndexBuckets(client *redis.Client, index, bucketName string, labels ...string) error {
+	return updateBucketIndex(client, index, bucketName, labels, func(multi *redis.Multi, key string) {
+		multi.SAdd(key, bucketName)
+	})
+}
+
+// UnindexBuckets removes bucketName from the buckets of the labels in the reverse index
+func UnindexBuckets(client *redis.Client, index, bucketName string, labels ...string) error {
+	return updateBucketIndex(client, index, bucketName, labels, func(multi *redis.Multi, key string) {
+		multi.SRem(key, bucketName)
+	})
+}
+
+// WhichBuckets returns the buckets a label is a member of according to the reverse index, sorted by name
+func WhichBuckets(client *redis.Client, index, label string) ([]string, error) {
+	buckets, err := client.SMembers(bucketIndexKey(index, label)).Result()
+	if err != nil {
+		return []string{}, err
+	}
+	slices.Sort(buckets)
+
+	return buckets, nil
+}
+
+// RemoveEverywhere removes a label from all buckets of the reverse index like RemoveCoordinatesByKeys and deletes
+// its index entry, it returns the number of buckets it was removed from
+func RemoveEverywhere(client *redis.Client, index, label string) (int64, error) {
+	buckets, err := WhichBuckets(client, index, label)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	for _, bucketName := range buckets {
+		count, err := RemoveCoordinatesByKeys(client, bucketName, label)
+		if err != nil {
+			return removed, err
+		}
+		removed += count
+	}
+
+	return removed, client.Del(bucketIndexKey(index, label)).Err()
+}
+
+// WhichBuckets returns the buckets a label is a member of, see WithBucketIndex
+func (c *GeoClient) WhichBuckets(label string) ([]string, error) {
+	return handleOp(c, Operation{Name: "WhichBuckets", Args: []any{label}}, func() ([]string, error) {
+		if c.bucketIndex == "" {
+			return []string{}, ErrNoBucketIndex
+		}
+
+		buckets, err := withRetry(c, func() ([]string, error) {
+			return WhichBuckets(c.client, c.key(c.bucketIndex), label)
+		})
+		for idx := range buckets {
+			buckets[idx] = c.unkey(buckets[idx])
+		}
+
+		return buckets, err
+	})
+}
+
+// RemoveEverywhere removes a label from all its buckets like RemoveCoordinatesByKeys, see WithBucketIndex
+func (c *GeoClient) RemoveEverywhere(label string) (int64, error) {
+	return handleOp(c, Operation{Name: "RemoveEverywhere", Write: true, Args: []any{label}}, func() (int64, error) {
+		buckets, err := c.WhichBuckets(label)
+		if err != nil {
+			return 0, err
+		}
+
+		var removed int64
+		for _, bucketName := range buckets {
+			count, err := c.RemoveCoordinatesByKeys(bucketName, label)
+			if err != nil {
+				return removed, err
+			}
+			removed += count
+		}
+
+		return removed, nil
+	})
+}
+
+func updateBucketIndex(client *redis.Client, index, bucketName string, labels []string, update func(multi *redis.Multi, key string)) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	_, err := multi.Exec(func() error {
+		for _, label := range labels {
+			update(multi, bucketIndexKey(index, label))
+		}
+		return nil
+	})
+
+	return err
+}
+
+func bucketIndexKey(index, label string) string {
+	return index + ":" + label
+}
@@ -0,0 +1,47 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestBucketIndex(t *testing.T) {
+	const zSetCafes, zSetBars, bucketIndex = "test:index:cafes", "test:index:bars", "test:index:buckets"
+
+	client.Del(zSetCafes, zSetBars, bucketIndex+":corner", bucketIndex+":espresso")
+	geoClient := NewGeoClient(client, bitDepth, WithBucketIndex(bucketIndex))
+
+	geoClient.AddCoordinates(zSetCafes, GeoKey{Label: "corner", Lat: 52.52, Lon: 13.405}, GeoKey{Label: "espresso", Lat: 52.521, Lon: 13.405})
+	geoClient.AddCoordinates(zSetBars, GeoKey{Label: "corner", Lat: 52.52, Lon: 13.405})
+
+	if buckets, err := geoClient.WhichBuckets("corner"); err != nil || len(buckets) != 2 || buckets[0] != zSetBars || buckets[1] != zSetCafes {
+		t.Logf("expected both buckets got %v, %v\n", buckets, err)
+		t.Fail()
+	}
+
+	if removed, err := geoClient.RemoveEverywhere("corner"); err != nil || removed != 2 {
+		t.Logf("expected 2 removals got %d, %v\n", removed, err)
+		t.Fail()
+	}
+
+	if buckets, err := geoClient.WhichBuckets("corner"); err != nil || len(buckets) != 0 {
+		t.Logf("expected no buckets left got %v, %v\n", buckets, err)
+		t.Fail()
+	}
+
+	if count := client.ZCard(zSetCafes).Val(); count != 1 {
+		t.Logf("expected only espresso left got %d members\n", count)
+		t.Fail()
+	}
+
+	if _, err := NewGeoClient(client, bitDepth).WhichBuckets("espresso"); err != ErrNoBucketIndex {
+		t.Logf("expected ErrNoBucketIndex got %v\n", err)
+		t.Fail()
+	}
+}
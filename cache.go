@@ -0,0 +1,89 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+// QueryCache stores search results in redis for a short time so popular queries are computed only once per TTL
+//
+// Queries are keyed by the cell of the search center at the cache precision, the radius and the search options.
+// All queries falling into the same cell are answered as if they were centered on the middle of that cell,
+// so the precision should be chosen to be small compared to the searched radii.
+type QueryCache struct {
+	client    *redis.Client
+	ttl       time.Duration
+	precision uint8
+	metrics   Metrics
+}
+
+// QueryCacheOption configures a QueryCache
+type QueryCacheOption func(*QueryCache)
+
+// WithCacheMetrics reports the hits and misses of a QueryCache to metrics
+func WithCacheMetrics(metrics Metrics) QueryCacheOption {
+	return func(c *QueryCache) {
+		c.metrics = metrics
+	}
+}
+
+// NewQueryCache returns a QueryCache keeping results for ttl and rounding search centers to cells of the given bit
+// depth, which has to be valid
+func NewQueryCache(client *redis.Client, ttl time.Duration, precision uint8, options ...QueryCacheOption) (*QueryCache, error) {
+	if err := ValidateBitDepth(precision); err != nil {
+		return nil, err
+	}
+
+	c := &QueryCache{client: client, ttl: ttl, precision: precision}
+	for _, option := range options {
+		option(c)
+	}
+
+	return c, nil
+}
+
+// Search works like the package level Search but serves results from the cache when possible
+func (c *QueryCache) Search(bucketName string, lat, lon, radius float64, bitDepth uint8, options ...SearchOption) ([]Result, error) {
+	optionsKey, ok := newSearchOptions(options).cacheKey(radius)
+	if !ok {
+		return Search(c.client, bucketName, lat, lon, radius, bitDepth, options...)
+	}
+	cell := geohashEncoder{bitDepth: c.precision}.EncodeInt(lat, lon)
+	key := fmt.Sprintf("%s:cache:%d:%d:%g:%s", bucketName, c.precision, cell, radius, optionsKey)
+
+	if cached, err := c.client.Get(key).Result(); err == nil {
+		results := []Result{}
+		if err := json.Unmarshal([]byte(cached), &results); err == nil {
+			c.observe(bucketName, true)
+			return results, nil
+		}
+	}
+	c.observe(bucketName, false)
+
+	cellLat, cellLon := geohashEncoder{bitDepth: c.precision}.DecodeInt(cell)
+	results, err := Search(c.client, bucketName, cellLat, cellLon, radius, bitDepth, options...)
+	if err != nil {
+		return results, err
+	}
+
+	// caching is best effort, a failed write only means the next query is computed again
+	if encoded, err := json.Marshal(results); err == nil {
+		c.client.SetEx(key, c.ttl, string(encoded))
+	}
+
+	return results, nil
+}
+
+func (c *QueryCache) observe(bucketName string, hit bool) {
+	if c.metrics != nil {
+		c.metrics.ObserveCache(bucketName, hit)
+	}
+}
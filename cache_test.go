@@ -0,0 +1,54 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestQueryCache(t *testing.T) {
+	const zSetCached = "test:search:cached"
+
+	client.Del(zSetCached)
+	AddCoordinates(client, zSetCached, bitDepth, GeoKey{Lat: 39.9523, Lon: -75.1638, Label: "Philadelphia"})
+
+	cache, err := NewQueryCache(client, time.Minute, 40)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	first, err := cache.Search(zSetCached, 39.9523, -75.1638, 1000, bitDepth)
+	if err != nil || len(first) != 1 {
+		t.Logf("unexpected results %v error %v\n", first, err)
+		t.FailNow()
+	}
+
+	AddCoordinates(client, zSetCached, bitDepth, GeoKey{Lat: 39.9524, Lon: -75.1638, Label: "Nearby"})
+
+	cached, err := cache.Search(zSetCached, 39.9523, -75.1638, 1000, bitDepth)
+	if err != nil || len(cached) != 1 || cached[0].Label != "Philadelphia" {
+		t.Logf("expected cached results got %v error %v\n", cached, err)
+		t.Fail()
+	}
+
+	limited, err := cache.Search(zSetCached, 39.9523, -75.1638, 1000, bitDepth, WithLimit(5))
+	if err != nil || len(limited) != 2 {
+		t.Logf("expected different options to bypass the cache got %v error %v\n", limited, err)
+		t.Fail()
+	}
+}
+
+func TestQueryCacheInvalidPrecision(t *testing.T) {
+	for _, precision := range []uint8{0, 3, 54} {
+		if _, err := NewQueryCache(client, time.Minute, precision); err != ErrInvalidBitDepth {
+			t.Logf("expected ErrInvalidBitDepth for precision %d got %v\n", precision, err)
+			t.Fail()
+		}
+	}
+}
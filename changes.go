@@ -0,0 +1,448 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	// ChangeAdd is recorded when a member is added
+	ChangeAdd ChangeType = "add"
+	// ChangeUpdate is recorded when an existing member is written again
+	ChangeUpdate ChangeType = "update"
+	// ChangeRemove is recorded when a member is removed
+	ChangeRemove ChangeType = "remove"
+)
+
+// changeLuaBody writes or removes members and appends every change to the change stream of the bucket
+//
+// KEYS are the bucket, its payloads, the stream, the last seen times, the motions, the attributes and the quota, adds
+// exceeding the quota fail without writing anything. ARGV holds the bit depth, the approximate
+// maximum stream length (0 to keep everything, -1 to record nothing) and "add" followed by score, label, payload,
+// lat, lon tuples (payloads are prefixed with "=" and empty when not set), "rem" followed by labels or "stale"
+// followed by a unix time and a count to remove up to count members last seen before the time. The reply is the
+// number of added or removed members, for "stale" the labels of the removed members.
+const changeLuaBody = quotaLuaFunction + `
+local depth = tonumber(ARGV[1])
+local maxLen = tonumber(ARGV[2])
+local count = 0
+
+local function decode(score)
+  score = tonumber(score)
+  local bits = {}
+  for i = depth, 1, -1 do
+    bits[i] = score % 2
+    score = math.floor(score / 2)
+  end
+
+  local minLat, maxLat, minLon, maxLon = -90, 90, -180, 180
+  for i = 1, depth do
+    if i % 2 == 1 then
+      local mid = (minLon + maxLon) / 2
+      if bits[i] == 1 then minLon = mid else maxLon = mid end
+    else
+      local mid = (minLat + maxLat) / 2
+      if bits[i] == 1 then minLat = mid else maxLat = mid end
+    end
+  end
+
+  return string.format('%.17g', (minLat + maxLat) / 2), string.format('%.17g', (minLon + maxLon) / 2)
+end
+
+local function record(fields)
+  if maxLen < 0 then return end
+  if maxLen > 0 then
+    redis.call('XADD', KEYS[3], 'MAXLEN', '~', maxLen, '*', unpack(fields))
+  else
+    redis.call('XADD', KEYS[3], '*', unpack(fields))
+  end
+end
+
+if ARGV[3] == 'add' then
+  local exceeded = checkQuota(KEYS[1], KEYS[7], 4, 5)
+  if exceeded then return exceeded end
+
+  for i = 4, #ARGV, 5 do
+    local label = ARGV[i + 1]
+    local before = redis.call('ZSCORE', KEYS[1], label)
+    count = count + redis.call('ZADD', KEYS[1], ARGV[i], label)
+    if ARGV[i + 2] ~= '' then redis.call('HSET', KEYS[2], label, string.sub(ARGV[i + 2], 2)) end
+
+    if before then
+      local lat, lon = decode(before)
+      record({'type', 'update', 'label', label, 'lat', ARGV[i + 3], 'lon', ARGV[i + 4], 'before_lat', lat, 'before_lon', lon})
+    else
+      record({'type', 'add', 'label', label, 'lat', ARGV[i + 3], 'lon', ARGV[i + 4]})
+    end
+  end
+else
+  local labels = ARGV
+  local first = 4
+  if ARGV[3] == 'stale' then
+    labels = redis.call('ZRANGEBYSCORE', KEYS[4], '-inf', '(' .. ARGV[4], 'LIMIT', 0, ARGV[5])
+    first = 1
+  end
+
+  local removed = {}
+  for i = first, #labels do
+    local before = redis.call('ZSCORE', KEYS[1], labels[i])
+    redis.call('ZREM', KEYS[4], labels[i])
+    redis.call('HDEL', KEYS[5], labels[i])
+    if before then
+      count = count + redis.call('ZREM', KEYS[1], labels[i])
+      removed[#removed + 1] = labels[i]
+      redis.call('HDEL', KEYS[2], labels[i])
+      redis.call('HDEL', KEYS[6], labels[i])
+      local lat, lon = decode(before)
+      record({'type', 'remove', 'label', labels[i], 'before_lat', lat, 'before_lon', lon})
+    end
+  end
+  if ARGV[3] == 'stale' then return removed end
+end
+
+return count
+`
+
+var changeScript = newLuaScript(changeLuaBody)
+
+type (
+	// ChangeType is the kind of a Change
+	ChangeType string
+
+	// Change is an entry of the change stream of a bucket
+	//
+	// Before holds the coordinates of updated and removed members before the change, as decoded from the bucket,
+	// After the coordinates written by adds and updates. Time is taken from the stream entry id.
+	Change struct {
+		ID     string
+		Type   ChangeType
+		Label  string
+		Before *Point
+		After  *Point
+		Time   time.Time
+	}
+)
+
+// WithChangeStream appends every add, update and remove of the GeoClient to the change stream of the bucket,
+// trimmed to about maxLen entries when maxLen is positive
+func WithChangeStream(maxLen int64) ClientOption {
+	return func(c *GeoClient) {
+		c.changes, c.changesMaxLen = true, maxLen
+	}
+}
+
+// AddCoordinatesWithChanges adds coordinates to the set and appends the changes to the change stream of the bucket
+// in the same script, it returns a QuotaError instead when the bucket would exceed its quota, see SetQuota
+func AddCoordinatesWithChanges(client *redis.Client, bucketName string, bitDepth uint8, maxLen int64, coordinates ...GeoKey) (int64, error) {
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return 0, err
+	}
+	if err := ValidateCoordinates(coordinates...); err != nil {
+		return 0, err
+	}
+	if len(coordinates) == 0 {
+		return 0, nil
+	}
+
+	args := []string{strconv.Itoa(int(bitDepth)), strconv.FormatInt(max(maxLen, 0), 10), "add"}
+	for _, coordinate := range coordinates {
+		payload := ""
+		if coordinate.Payload != nil {
+			payload = "=" + string(coordinate.Payload)
+		}
+		args = append(args,
+			strconv.FormatUint(geohashEncoder{bitDepth: bitDepth}.EncodeInt(coordinate.Lat, coordinate.Lon), 10),
+			coordinate.Label,
+			payload,
+			strconv.FormatFloat(coordinate.Lat, 'f', -1, 64),
+			strconv.FormatFloat(coordinate.Lon, 'f', -1, 64),
+		)
+	}
+
+	return runChangeScript(client, bucketName, args)
+}
+
+// RemoveCoordinatesByKeysWithChanges removes coordinates, their payloads, attributes, last seen times and motions from the set
+// and appends the removals to the change stream of the bucket in the same script
+func RemoveCoordinatesByKeysWithChanges(client *redis.Client, bucketName string, bitDepth uint8, maxLen int64, coordinatesKeys ...string) (int64, error) {
+	if len(coordinatesKeys) == 0 {
+		return 0, nil
+	}
+
+	args := append([]string{strconv.Itoa(int(bitDepth)), strconv.FormatInt(max(maxLen, 0), 10), "rem"}, coordinatesKeys...)
+	removed, err := runChangeScript(client, bucketName, args)
+	if err != nil {
+		return removed, err
+	}
+
+	if err := clearTags(client, bucketName, coordinatesKeys...); err != nil {
+		return removed, err
+	}
+
+	return removed, clearNames(client, bucketName, coordinatesKeys...)
+}
+
+// CreateChangeGroup creates a consumer group on the change stream of the bucket reading from start, "$" for new
+// changes only or "0" for all, creating the stream when missing
+//
+// Creating a group which already exists is not an error.
+func CreateChangeGroup(client *redis.Client, bucketName, group, start string) error {
+	cmd := redis.NewCmd("XGROUP", "CREATE", ChangeStreamKey(bucketName), group, start, "MKSTREAM")
+	client.Process(cmd)
+	if err := cmd.Err(); err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	return nil
+}
+
+// ReadChanges returns up to count changes delivered to no other consumer of the group, waiting up to block for
+// new changes when block is positive
+//
+// Changes stay pending for the consumer until they are acknowledged with AckChanges.
+func ReadChanges(client *redis.Client, bucketName, group, consumer string, count int64, block time.Duration) ([]Change, error) {
+	args := []string{"XREADGROUP", "GROUP", group, consumer, "COUNT", strconv.FormatInt(count, 10)}
+	if block > 0 {
+		args = append(args, "BLOCK", strconv.FormatInt(block.Milliseconds(), 10))
+	}
+	args = append(args, "STREAMS", ChangeStreamKey(bucketName), ">")
+
+	return readStream(client, args)
+}
+
+// TailChanges returns up to count changes recorded after the change with the id after, waiting up to block for new
+// changes when block is positive
+//
+// Pass the id of the last change seen to the next call, LastChangeID returns where to start from.
+func TailChanges(client *redis.Client, bucketName, after string, count int64, block time.Duration) ([]Change, error) {
+	args := []string{"XREAD", "COUNT", strconv.FormatInt(count, 10)}
+	if block > 0 {
+		args = append(args, "BLOCK", strconv.FormatInt(block.Milliseconds(), 10))
+	}
+
+	return readStream(client, append(args, "STREAMS", ChangeStreamKey(bucketName), after))
+}
+
+// LastChangeID returns the id of the latest change of the bucket, "0-0" when nothing was recorded yet
+func LastChangeID(client *redis.Client, bucketName string) (string, error) {
+	cmd := redis.NewCmd("XREVRANGE", ChangeStreamKey(bucketName), "+", "-", "COUNT", "1")
+	client.Process(cmd)
+	reply, err := cmd.Result()
+	if err != nil {
+		return "", err
+	}
+
+	entries, ok := reply.([]interface{})
+	if !ok {
+		return "", fmt.Errorf("unexpected XREVRANGE reply %v", reply)
+	}
+	if len(entries) == 0 {
+		return "0-0", nil
+	}
+	entry, ok := entries[0].([]interface{})
+	if !ok || len(entry) == 0 {
+		return "", fmt.Errorf("unexpected XREVRANGE reply %v", reply)
+	}
+	id, ok := entry[0].(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected XREVRANGE reply %v", reply)
+	}
+
+	return id, nil
+}
+
+// AckChanges acknowledges processed changes and returns the number of changes which were pending
+func AckChanges(client *redis.Client, bucketName, group string, ids ...string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	cmd := redis.NewCmd(append([]string{"XACK", ChangeStreamKey(bucketName), group}, ids...)...)
+	client.Process(cmd)
+	reply, err := cmd.Result()
+	if err != nil {
+		return 0, err
+	}
+
+	acked, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected XACK reply %v", reply)
+	}
+
+	return acked, nil
+}
+
+// ClaimStaleChanges transfers up to count changes pending for longer than minIdle, for example of crashed consumers,
+// to consumer and returns them, it requires redis 6.2 or newer
+func ClaimStaleChanges(client *redis.Client, bucketName, group, consumer string, minIdle time.Duration, count int64) ([]Change, error) {
+	cmd := redis.NewCmd("XAUTOCLAIM", ChangeStreamKey(bucketName), group, consumer,
+		strconv.FormatInt(minIdle.Milliseconds(), 10), "0-0", "COUNT", strconv.FormatInt(count, 10))
+	client.Process(cmd)
+	reply, err := cmd.Result()
+	if err != nil {
+		return []Change{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) < 2 {
+		return []Change{}, fmt.Errorf("unexpected XAUTOCLAIM reply %v", reply)
+	}
+
+	return parseChanges(values[1])
+}
+
+// ChangeStreamKey returns the key of the change stream of a bucket
+func ChangeStreamKey(bucketName string) string {
+	return bucketName + ":changes"
+}
+
+// readStream runs an XREAD or XREADGROUP command for a single stream and returns its entries
+func readStream(client *redis.Client, args []string) ([]Change, error) {
+	cmd := redis.NewCmd(args...)
+	client.Process(cmd)
+	reply, err := cmd.Result()
+	if err == redis.Nil || (err == nil && reply == nil) {
+		return []Change{}, nil
+	}
+	if err != nil {
+		return []Change{}, err
+	}
+
+	streams, ok := reply.([]interface{})
+	if !ok || len(streams) != 1 {
+		return []Change{}, fmt.Errorf("unexpected %s reply %v", args[0], reply)
+	}
+	stream, ok := streams[0].([]interface{})
+	if !ok || len(stream) != 2 {
+		return []Change{}, fmt.Errorf("unexpected %s reply %v", args[0], reply)
+	}
+
+	return parseChanges(stream[1])
+}
+
+func runChangeScript(client *redis.Client, bucketName string, args []string) (int64, error) {
+	reply, err := changeScript.run(client, changeScriptKeys(bucketName), args)
+	if err != nil {
+		return 0, quotaError(bucketName, err)
+	}
+
+	count, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected change script reply %v", reply)
+	}
+
+	return count, nil
+}
+
+// runStaleScript runs the change script with "stale" arguments and returns the labels of the removed members
+func runStaleScript(client *redis.Client, bucketName string, args []string) ([]string, error) {
+	reply, err := changeScript.run(client, changeScriptKeys(bucketName), args)
+	if err != nil {
+		return []string{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok {
+		return []string{}, fmt.Errorf("unexpected change script reply %v", reply)
+	}
+	labels := make([]string, 0, len(values))
+	for _, value := range values {
+		label, ok := value.(string)
+		if !ok {
+			return []string{}, fmt.Errorf("unexpected change script reply %v", reply)
+		}
+		labels = append(labels, label)
+	}
+
+	return labels, nil
+}
+
+func changeScriptKeys(bucketName string) []string {
+	return []string{bucketName, payloadKey(bucketName), ChangeStreamKey(bucketName), lastSeenKey(bucketName), motionKey(bucketName), attributesKey(bucketName), quotaKey(bucketName)}
+}
+
+// parseChanges decodes a list of stream entries, each an id followed by a flat list of fields and values
+func parseChanges(reply interface{}) ([]Change, error) {
+	entries, ok := reply.([]interface{})
+	if !ok {
+		return []Change{}, fmt.Errorf("unexpected stream entries %v", reply)
+	}
+
+	changes := make([]Change, 0, len(entries))
+	for _, entry := range entries {
+		values, ok := entry.([]interface{})
+		if !ok || len(values) != 2 {
+			return []Change{}, fmt.Errorf("unexpected stream entry %v", entry)
+		}
+		// entries deleted from the stream while pending are claimed without fields
+		if values[1] == nil {
+			continue
+		}
+
+		id, _ := values[0].(string)
+		fields, ok := values[1].([]interface{})
+		if !ok || len(fields)%2 != 0 {
+			return []Change{}, fmt.Errorf("unexpected stream entry %v", entry)
+		}
+
+		change, err := parseChange(id, fields)
+		if err != nil {
+			return []Change{}, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+func parseChange(id string, fields []interface{}) (Change, error) {
+	ms, _, _ := strings.Cut(id, "-")
+	millis, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return Change{}, fmt.Errorf("unexpected stream entry id %q", id)
+	}
+
+	change := Change{ID: id, Time: time.UnixMilli(millis)}
+	values := map[string]string{}
+	for idx := 0; idx < len(fields); idx += 2 {
+		field, _ := fields[idx].(string)
+		values[field], _ = fields[idx+1].(string)
+	}
+	change.Type, change.Label = ChangeType(values["type"]), values["label"]
+
+	if change.After, err = parsePoint(values["lat"], values["lon"]); err != nil {
+		return Change{}, err
+	}
+	if change.Before, err = parsePoint(values["before_lat"], values["before_lon"]); err != nil {
+		return Change{}, err
+	}
+
+	return change, nil
+}
+
+// parsePoint returns nil when both coordinates are empty
+func parsePoint(lat, lon string) (*Point, error) {
+	if lat == "" && lon == "" {
+		return nil, nil
+	}
+
+	point := &Point{}
+	var err error
+	if point.Lat, err = strconv.ParseFloat(lat, 64); err != nil {
+		return nil, err
+	}
+	if point.Lon, err = strconv.ParseFloat(lon, 64); err != nil {
+		return nil, err
+	}
+
+	return point, nil
+}
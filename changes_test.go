@@ -0,0 +1,48 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseChanges(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{"1700000000000-0", []interface{}{"type", "add", "label", "a", "lat", "52.52", "lon", "13.405"}},
+		[]interface{}{"1700000000001-0", []interface{}{"type", "update", "label", "a", "lat", "48.85", "lon", "2.35", "before_lat", "52.52", "before_lon", "13.405"}},
+		[]interface{}{"1700000000001-1", nil},
+		[]interface{}{"1700000000002-0", []interface{}{"type", "remove", "label", "a", "before_lat", "48.85", "before_lon", "2.35"}},
+	}
+
+	changes, err := parseChanges(reply)
+	if err != nil || len(changes) != 3 {
+		t.Logf("expected 3 changes got %v error %v\n", changes, err)
+		t.FailNow()
+	}
+
+	if changes[0].Type != ChangeAdd || changes[0].Before != nil || changes[0].After == nil || changes[0].After.Lat != 52.52 {
+		t.Logf("unexpected add %v\n", changes[0])
+		t.Fail()
+	}
+	if changes[1].Type != ChangeUpdate || changes[1].Before == nil || changes[1].Before.Lon != 13.405 || changes[1].After.Lon != 2.35 {
+		t.Logf("unexpected update %v\n", changes[1])
+		t.Fail()
+	}
+	if changes[2].Type != ChangeRemove || changes[2].After != nil || changes[2].Before == nil || changes[2].ID != "1700000000002-0" {
+		t.Logf("unexpected remove %v\n", changes[2])
+		t.Fail()
+	}
+	if !changes[2].Time.Equal(time.UnixMilli(1700000000002)) {
+		t.Logf("expected the time of the entry id got %v\n", changes[2].Time)
+		t.Fail()
+	}
+
+	if _, err := parseChanges([]interface{}{[]interface{}{"x", []interface{}{}}}); err == nil {
+		t.Logf("expected a malformed id to fail\n")
+		t.Fail()
+	}
+}
@@ -0,0 +1,500 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"log/slog"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+// GeoClient bundles a redis connection with the bit depth used to encode coordinates
+//
+// Its methods mirror the package level functions without the client and bit depth arguments.
+type GeoClient struct {
+	client   *redis.Client
+	bitDepth uint8
+	replicas *replicaSet
+	retry    RetryPolicy
+
+	changes       bool
+	changesMaxLen int64
+	lastSeen      bool
+	history       *HistoryLimits
+	audit         *auditConfig
+	native        map[string]bool
+	metadata      MetadataStore
+	bucketIndex   string
+	metrics       Metrics
+	logger        *slog.Logger
+	slowSearch    time.Duration
+	slowLog       *SlowLog
+	middlewares   []Middleware
+	handler       OpHandler
+	limiter       *rateLimiter
+	quotas        bool
+	namespace     string
+}
+
+// ClientOption configures a GeoClient
+type ClientOption func(*GeoClient)
+
+// NewGeoClient returns a GeoClient using an existing redis client
+func NewGeoClient(client *redis.Client, bitDepth uint8, options ...ClientOption) *GeoClient {
+	c := &GeoClient{
+		client:   client,
+		bitDepth: bitDepth,
+		replicas: &replicaSet{lastWrites: map[string]time.Time{}},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	c.chainMiddlewares()
+
+	return c
+}
+
+// NewFailoverGeoClient returns a GeoClient connected to the master monitored by redis sentinel
+//
+// The sentinels are asked for the current master on every (re)connect, so the client keeps working across failovers.
+func NewFailoverGeoClient(failoverOptions *redis.FailoverOptions, bitDepth uint8, options ...ClientOption) *GeoClient {
+	return NewGeoClient(redis.NewFailoverClient(failoverOptions), bitDepth, options...)
+}
+
+// Client returns the underlying redis client
+func (c *GeoClient) Client() *redis.Client {
+	return c.client
+}
+
+// BitDepth returns the bit depth used to encode coordinates
+func (c *GeoClient) BitDepth() uint8 {
+	return c.bitDepth
+}
+
+// Close closes the underlying redis client and the replica clients
+func (c *GeoClient) Close() error {
+	err := c.client.Close()
+	if replicaErr := c.replicas.close(); err == nil {
+		err = replicaErr
+	}
+
+	return err
+}
+
+// AddCoordinates adds coordinates to the set
+func (c *GeoClient) AddCoordinates(bucketName string, coordinates ...GeoKey) (int64, error) {
+	return handleOp(c, Operation{Name: "AddCoordinates", Bucket: bucketName, Write: true, Args: []any{coordinates}}, func() (int64, error) {
+		coordinates, limited := c.limiter.admit(c, bucketName, coordinates)
+		if c.limiter != nil && len(coordinates) == 0 {
+			return 0, limited
+		}
+
+		added, err := c.addCoordinates(bucketName, coordinates)
+		if err != nil {
+			return added, err
+		}
+
+		return added, limited
+	})
+}
+
+// addCoordinates writes coordinates which passed the rate limit
+func (c *GeoClient) addCoordinates(bucketName string, coordinates []GeoKey) (int64, error) {
+	key := c.key(bucketName)
+	defer c.wrote(key)
+	coordinates, payloads := c.splitPayloads(coordinates)
+
+	var labels []string
+	var existed []bool
+	if c.audit != nil || c.bucketIndex != "" {
+		labels = make([]string, len(coordinates))
+		for idx := range coordinates {
+			labels[idx] = coordinates[idx].Label
+		}
+	}
+	if c.audit != nil {
+		var err error
+		if existed, err = existingLabels(c.client, key, labels); err != nil {
+			return 0, err
+		}
+	}
+
+	now := time.Now()
+	var motions []*Motion
+	if c.lastSeen && c.isNative(key) {
+		motions = make([]*Motion, len(coordinates))
+	} else if c.lastSeen {
+		var err error
+		if motions, err = withRetry(c, func() ([]*Motion, error) {
+			return measureMotion(c.client, key, c.bitDepth, now, coordinates)
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	// the change stream and the history append entries, running them again after a failure which reached redis
+	// would duplicate them, so they are attempted once while the idempotent writes are retried
+	var added int64
+	var err error
+	if c.changes && !c.isNative(key) {
+		err = c.once(func() error {
+			var err error
+			added, err = AddCoordinatesWithChanges(c.client, key, c.bitDepth, c.changesMaxLen, coordinates...)
+			return err
+		})
+	} else {
+		added, err = withRetry(c, func() (int64, error) {
+			if c.isNative(key) {
+				return AddCoordinatesNative(c.client, key, coordinates...)
+			} else if c.quotas {
+				return AddCoordinatesWithinQuota(c.client, key, c.bitDepth, coordinates...)
+			}
+			return AddCoordinates(c.client, key, c.bitDepth, coordinates...)
+		})
+	}
+	if err == nil && c.lastSeen {
+		err = c.do(func() error {
+			return storeMotion(c.client, key, now, coordinates, motions)
+		})
+	}
+	if err == nil && c.history != nil {
+		err = c.once(func() error {
+			return AppendHistory(c.client, key, *c.history, coordinates...)
+		})
+	}
+	if err != nil {
+		return added, err
+	}
+	if c.metadata != nil {
+		if err := c.metadata.SetPayloads(key, payloads); err != nil {
+			return added, err
+		}
+	}
+	if c.bucketIndex != "" {
+		if err := IndexBuckets(c.client, c.key(c.bucketIndex), key, labels...); err != nil {
+			return added, err
+		}
+	}
+
+	if c.namespace != "" {
+		if err := c.client.SAdd(namespaceBucketsKey(c.namespace), bucketName).Err(); err != nil {
+			return added, err
+		}
+	}
+	if err := c.audit.recordWrite(c.client, key, labels, existed); err != nil {
+		return added, err
+	}
+
+	return added, nil
+}
+
+// RemoveCoordinatesByKeys removes coordinates, their payloads, attributes, last seen times and motions from the set
+func (c *GeoClient) RemoveCoordinatesByKeys(bucketName string, coordinatesKeys ...string) (int64, error) {
+	return handleOp(c, Operation{Name: "RemoveCoordinatesByKeys", Bucket: bucketName, Write: true, Args: []any{coordinatesKeys}}, func() (int64, error) {
+		key := c.key(bucketName)
+		defer c.wrote(key)
+
+		var removed []string
+		if c.audit != nil {
+			existed, err := existingLabels(c.client, key, coordinatesKeys)
+			if err != nil {
+				return 0, err
+			}
+			for idx, label := range coordinatesKeys {
+				if existed[idx] {
+					removed = append(removed, label)
+				}
+			}
+		}
+
+		count, err := withRetry(c, func() (int64, error) {
+			if c.changes && !c.isNative(key) {
+				return RemoveCoordinatesByKeysWithChanges(c.client, key, c.bitDepth, c.changesMaxLen, coordinatesKeys...)
+			}
+			return RemoveCoordinatesByKeys(c.client, key, coordinatesKeys...)
+		})
+		if err != nil {
+			return count, err
+		}
+		if c.metadata != nil {
+			if err := c.metadata.DeletePayloads(key, coordinatesKeys...); err != nil {
+				return count, err
+			}
+		}
+		if c.bucketIndex != "" {
+			if err := UnindexBuckets(c.client, c.key(c.bucketIndex), key, coordinatesKeys...); err != nil {
+				return count, err
+			}
+		}
+
+		return count, c.audit.record(c.client, AuditRemove, key, removed, "")
+	})
+}
+
+// GetCoordinates returns the decoded coordinates of a label or ErrMemberNotFound
+func (c *GeoClient) GetCoordinates(bucketName, label string) (GeoKey, error) {
+	return handleOp(c, Operation{Name: "GetCoordinates", Bucket: bucketName, Args: []any{label}}, func() (GeoKey, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() (GeoKey, error) {
+			if c.isNative(key) {
+				return GetCoordinatesNative(c.reader(key), key, label)
+			}
+			return GetCoordinates(c.reader(key), key, c.bitDepth, label)
+		})
+	})
+}
+
+// CountCoordinates returns the number of coordinates in the set
+func (c *GeoClient) CountCoordinates(bucketName string) (int64, error) {
+	return handleOp(c, Operation{Name: "CountCoordinates", Bucket: bucketName}, func() (int64, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() (int64, error) {
+			return CountCoordinates(c.reader(key), key)
+		})
+	})
+}
+
+// SearchByRadius returns all keys which are in a certain range from the provided lat & lon coordinates
+func (c *GeoClient) SearchByRadius(bucketName string, lat, lon, radius float64) ([]string, error) {
+	return handleOp(c, Operation{Name: "SearchByRadius", Bucket: bucketName, Args: []any{lat, lon, radius}}, func() ([]string, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() ([]string, error) {
+			if c.isNative(key) {
+				return nativeLabels(SearchNative(c.reader(key), key, lat, lon, radius))
+			}
+			return SearchByRadius(c.reader(key), key, lat, lon, radius, c.bitDepth)
+		})
+	})
+}
+
+// SearchByRadiusWithLimit returns all keys which are in a certain range from the provided lat & lon coordinates and returns only the nearest "limit" items
+func (c *GeoClient) SearchByRadiusWithLimit(bucketName string, lat, lon, radius float64, limit int) ([]string, error) {
+	return handleOp(c, Operation{Name: "SearchByRadiusWithLimit", Bucket: bucketName, Args: []any{lat, lon, radius, limit}}, func() ([]string, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() ([]string, error) {
+			if c.isNative(key) {
+				return nativeLabels(SearchNative(c.reader(key), key, lat, lon, radius, WithLimit(limit)))
+			}
+			return SearchByRadiusWithLimit(c.reader(key), key, lat, lon, radius, c.bitDepth, limit)
+		})
+	})
+}
+
+// Search returns all members which are in a certain range from the provided lat & lon coordinates, nearest first,
+// together with their coordinates and distance
+func (c *GeoClient) Search(bucketName string, lat, lon, radius float64, options ...SearchOption) ([]Result, error) {
+	return handleOp(c, Operation{Name: "Search", Bucket: bucketName, Args: []any{lat, lon, radius, options}}, func() ([]Result, error) {
+		key := c.key(bucketName)
+		metadata := c.metadata != nil && newSearchOptions(options).withPayloads
+		if metadata {
+			options = append(options[:len(options):len(options)], withoutPayloads)
+		}
+
+		var stats SearchStats
+		if c.observed() {
+			options = append(options[:len(options):len(options)], withStats(&stats))
+		}
+
+		start := time.Now()
+		results, err := withRetry(c, func() ([]Result, error) {
+			if c.isNative(key) {
+				return SearchNative(c.reader(key), key, lat, lon, radius, options...)
+			}
+			return Search(c.reader(key), key, lat, lon, radius, c.bitDepth, options...)
+		})
+		c.observeSearch(key, lat, lon, radius, start, &stats, results, err)
+		if metadata && len(results) > 0 {
+			if err := c.attachMetadata(key, results); err != nil {
+				return []Result{}, err
+			}
+		}
+
+		return results, err
+	})
+}
+
+// SearchAggregate returns the centroid and spread of the results of Search
+func (c *GeoClient) SearchAggregate(bucketName string, lat, lon, radius float64, options ...SearchOption) (Aggregate, error) {
+	return handleOp(c, Operation{Name: "SearchAggregate", Bucket: bucketName, Args: []any{lat, lon, radius, options}}, func() (Aggregate, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() (Aggregate, error) {
+			return SearchAggregate(c.reader(key), key, lat, lon, radius, c.bitDepth, options...)
+		})
+	})
+}
+
+// SearchClusters returns at most maxClusters clusters of the results of Search
+func (c *GeoClient) SearchClusters(bucketName string, lat, lon, radius float64, maxClusters int, options ...SearchOption) ([]Cluster, error) {
+	return handleOp(c, Operation{Name: "SearchClusters", Bucket: bucketName, Args: []any{lat, lon, radius, maxClusters, options}}, func() ([]Cluster, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() ([]Cluster, error) {
+			return SearchClusters(c.reader(key), key, lat, lon, radius, c.bitDepth, maxClusters, options...)
+		})
+	})
+}
+
+// LastSeen returns the last seen time of a label or ErrMemberNotFound, see WithLastSeen
+func (c *GeoClient) LastSeen(bucketName, label string) (time.Time, error) {
+	return handleOp(c, Operation{Name: "LastSeen", Bucket: bucketName, Args: []any{label}}, func() (time.Time, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() (time.Time, error) {
+			return LastSeen(c.reader(key), key, label)
+		})
+	})
+}
+
+// ListStale returns the labels last seen more than olderThan ago, the longest unseen first
+func (c *GeoClient) ListStale(bucketName string, olderThan time.Duration) ([]string, error) {
+	return handleOp(c, Operation{Name: "ListStale", Bucket: bucketName, Args: []any{olderThan}}, func() ([]string, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() ([]string, error) {
+			return ListStale(c.reader(key), key, olderThan)
+		})
+	})
+}
+
+// History returns the locations of a member between from and to, oldest first, see WithHistory
+func (c *GeoClient) History(bucketName, label string, from, to time.Time) ([]HistoryEntry, error) {
+	return handleOp(c, Operation{Name: "History", Bucket: bucketName, Args: []any{label, from, to}}, func() ([]HistoryEntry, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() ([]HistoryEntry, error) {
+			return History(c.reader(key), key, label, from, to)
+		})
+	})
+}
+
+// SearchAsOf searches the snapshot of a bucket taken closest to at, see StartSnapshots
+func (c *GeoClient) SearchAsOf(bucketName string, at time.Time, lat, lon, radius float64, options ...SearchOption) ([]Result, error) {
+	return handleOp(c, Operation{Name: "SearchAsOf", Bucket: bucketName, Args: []any{at, lat, lon, radius, options}}, func() ([]Result, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() ([]Result, error) {
+			return SearchAsOf(c.reader(key), key, at, lat, lon, radius, c.bitDepth, options...)
+		})
+	})
+}
+
+// PruneStale removes the members last seen more than olderThan ago and returns their number
+func (c *GeoClient) PruneStale(bucketName string, olderThan time.Duration) (int64, error) {
+	return handleOp(c, Operation{Name: "PruneStale", Bucket: bucketName, Write: true, Args: []any{olderThan}}, func() (int64, error) {
+		key := c.key(bucketName)
+		defer c.wrote(key)
+		pruned, err := withRetry(c, func() (int64, error) {
+			if c.changes {
+				return PruneStaleWithChanges(c.client, key, c.bitDepth, c.changesMaxLen, olderThan)
+			}
+			return PruneStale(c.client, key, c.bitDepth, olderThan)
+		})
+		if err != nil {
+			return pruned, err
+		}
+
+		return pruned, c.audit.record(c.client, AuditAdmin, key, nil, "prune stale older than "+olderThan.String())
+	})
+}
+
+// FindPairsWithin returns all pairs of members of the bucket closer than distance meters, nearest first
+func (c *GeoClient) FindPairsWithin(bucketName string, distance float64) ([]Pair, error) {
+	return handleOp(c, Operation{Name: "FindPairsWithin", Bucket: bucketName, Args: []any{distance}}, func() ([]Pair, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() ([]Pair, error) {
+			return FindPairsWithin(c.reader(key), key, c.bitDepth, distance)
+		})
+	})
+}
+
+// RegisterLiveQuery starts maintaining the members within radius meters of lat & lon, see WithChangeStream
+func (c *GeoClient) RegisterLiveQuery(bucketName string, lat, lon, radius float64, options ...LiveQueryOption) (*LiveQuery, error) {
+	return handleOp(c, Operation{Name: "RegisterLiveQuery", Bucket: bucketName, Args: []any{lat, lon, radius, options}}, func() (*LiveQuery, error) {
+		key := c.key(bucketName)
+		return RegisterLiveQuery(c.client, key, c.bitDepth, lat, lon, radius, options...)
+	})
+}
+
+// CreateFence stores a circular fence, replacing a fence with the same name
+func (c *GeoClient) CreateFence(fenceSet, name string, lat, lon, radius float64) error {
+	return c.handle(Operation{Name: "CreateFence", Bucket: fenceSet, Write: true, Args: []any{name, lat, lon, radius}}, func() error {
+		fenceKey := c.key(fenceSet)
+		defer c.wrote(fenceKey)
+		err := c.do(func() error {
+			return CreateFence(c.client, fenceKey, name, lat, lon, radius)
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.audit.record(c.client, AuditAdmin, fenceKey, []string{name}, "create fence")
+	})
+}
+
+// CreatePolygonFence stores a Polygon or MultiPolygon fence, replacing a fence with the same name
+func (c *GeoClient) CreatePolygonFence(fenceSet, name string, geometry Geometry) error {
+	return c.handle(Operation{Name: "CreatePolygonFence", Bucket: fenceSet, Write: true, Args: []any{name, geometry}}, func() error {
+		fenceKey := c.key(fenceSet)
+		defer c.wrote(fenceKey)
+		err := c.do(func() error {
+			return CreatePolygonFence(c.client, fenceKey, name, geometry)
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.audit.record(c.client, AuditAdmin, fenceKey, []string{name}, "create fence")
+	})
+}
+
+// CreateRoamingFence stores a circular fence around the member label of bucketName which follows its updates
+func (c *GeoClient) CreateRoamingFence(fenceSet, name, bucketName, label string, radius float64) error {
+	return c.handle(Operation{Name: "CreateRoamingFence", Bucket: bucketName, Write: true, Args: []any{fenceSet, name, label, radius}}, func() error {
+		fenceKey := c.key(fenceSet)
+		bucketKey := c.key(bucketName)
+		defer c.wrote(fenceKey)
+		err := c.do(func() error {
+			return CreateRoamingFence(c.client, fenceKey, name, bucketKey, c.bitDepth, label, radius)
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.audit.record(c.client, AuditAdmin, fenceKey, []string{name}, "create fence")
+	})
+}
+
+// DeleteFence removes a fence or returns ErrFenceNotFound
+func (c *GeoClient) DeleteFence(fenceSet, name string) error {
+	return c.handle(Operation{Name: "DeleteFence", Bucket: fenceSet, Write: true, Args: []any{name}}, func() error {
+		fenceKey := c.key(fenceSet)
+		defer c.wrote(fenceKey)
+		err := c.do(func() error {
+			return DeleteFence(c.client, fenceKey, name)
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.audit.record(c.client, AuditAdmin, fenceKey, []string{name}, "delete fence")
+	})
+}
+
+// ListFences returns all fences of the fence set ordered by name
+func (c *GeoClient) ListFences(fenceSet string) ([]Fence, error) {
+	return handleOp(c, Operation{Name: "ListFences", Bucket: fenceSet}, func() ([]Fence, error) {
+		fenceKey := c.key(fenceSet)
+		return withRetry(c, func() ([]Fence, error) {
+			return ListFences(c.reader(fenceKey), fenceKey)
+		})
+	})
+}
+
+// ContainingFences returns the fences containing lat & lon, nearest center first
+func (c *GeoClient) ContainingFences(fenceSet string, lat, lon float64) ([]Fence, error) {
+	return handleOp(c, Operation{Name: "ContainingFences", Bucket: fenceSet, Args: []any{lat, lon}}, func() ([]Fence, error) {
+		fenceKey := c.key(fenceSet)
+		return withRetry(c, func() ([]Fence, error) {
+			return ContainingFences(c.reader(fenceKey), fenceKey, lat, lon)
+		})
+	})
+}
@@ -0,0 +1,120 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestGeoClient(t *testing.T) {
+	const zSetClient = "test:client"
+
+	geoClient := NewGeoClient(client, bitDepth)
+	geoClient.RemoveCoordinatesByKeys(zSetClient, "Philadelphia")
+
+	added, err := geoClient.AddCoordinates(zSetClient, GeoKey{Lat: 39.9523, Lon: -75.1638, Label: "Philadelphia"})
+	if err != nil || added != 1 {
+		t.Logf("expected to add 1 added %d error %v\n", added, err)
+		t.Fail()
+	}
+
+	results, err := geoClient.Search(zSetClient, 39.9523, -75.1638, 1000)
+	if err != nil || len(results) != 1 || results[0].Label != "Philadelphia" {
+		t.Logf("unexpected results %v error %v\n", results, err)
+		t.Fail()
+	}
+
+	coordinates, err := geoClient.GetCoordinates(zSetClient, "Philadelphia")
+	if err != nil || coordinates.Label != "Philadelphia" {
+		t.Logf("unexpected coordinates %v error %v\n", coordinates, err)
+		t.Fail()
+	}
+}
+
+func TestGeoClientHealth(t *testing.T) {
+	geoClient := NewGeoClient(client, bitDepth)
+	if err := geoClient.Ping(); err != nil {
+		t.Logf("unexpected ping error %v\n", err)
+		t.Fail()
+	}
+
+	monitor := geoClient.MonitorHealth(WithHealthInterval(10*time.Millisecond), OnUnhealthy(func(err error) {
+		t.Logf("unexpected transition to unhealthy %v\n", err)
+		t.Fail()
+	}))
+	time.Sleep(50 * time.Millisecond)
+	monitor.Stop()
+
+	recorder := httptest.NewRecorder()
+	monitor.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if !monitor.Healthy() || recorder.Code != http.StatusOK {
+		t.Logf("expected a healthy monitor got %t and status %d\n", monitor.Healthy(), recorder.Code)
+		t.Fail()
+	}
+}
+
+func TestGeoClientMonitorHealthZeroInterval(t *testing.T) {
+	geoClient := NewGeoClient(client, bitDepth)
+
+	// a zero interval keeps the default instead of panicking in the ticker
+	monitor := geoClient.MonitorHealth(WithHealthInterval(0), WithHealthInterval(-time.Second))
+	monitor.Stop()
+	if !monitor.Healthy() {
+		t.Logf("expected a healthy monitor got %v\n", monitor.Err())
+		t.Fail()
+	}
+}
+
+func TestGeoClientHealthStatus(t *testing.T) {
+	geoClient := NewGeoClient(client, bitDepth)
+
+	status, err := geoClient.Health(context.Background())
+	if err != nil || status.Role != "master" || status.Latency <= 0 || status.ProbeLatency <= 0 {
+		t.Logf("expected a healthy primary got %+v, %v\n", status, err)
+		t.Fail()
+	}
+
+	// concurrent probes must not read each other's token
+	errs := make(chan error, 20)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := geoClient.Health(context.Background())
+			errs <- err
+		}()
+	}
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Logf("expected concurrent checks to pass got %v\n", err)
+			t.Fail()
+		}
+	}
+	if keys := client.Keys("georedis:health:probe:*").Val(); len(keys) != 0 {
+		t.Logf("expected the probe keys to be deleted got %v\n", keys)
+		t.Fail()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := geoClient.Health(ctx); err != context.Canceled {
+		t.Logf("expected the canceled context to end the check got %v\n", err)
+		t.Fail()
+	}
+
+	recorder := httptest.NewRecorder()
+	HealthHandler(geoClient).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	decoded := HealthStatus{}
+	if err := json.NewDecoder(recorder.Body).Decode(&decoded); err != nil || recorder.Code != http.StatusOK || decoded.Role != "master" {
+		t.Logf("expected a healthy JSON status got %d %+v, %v\n", recorder.Code, decoded, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,84 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"cmp"
+	"slices"
+
+	"gopkg.in/redis.v2"
+)
+
+// Cluster is a group of results within the same geohash cell, Lat & Lon are the centroid of its members
+type Cluster struct {
+	Cell  uint64
+	Depth uint8
+	Lat   float64
+	Lon   float64
+	Count int
+	// Label is the label of the only member of a cluster of one, empty otherwise
+	Label string
+}
+
+// ClusterResults groups results by the finest geohash cells which yield at most maxClusters clusters, largest
+// cluster first
+//
+// Cells of a depth are tried from the coarsest one on, so results spread over a large area end up in a few large
+// cells and results close together in small ones.
+func ClusterResults(results []Result, maxClusters int) []Cluster {
+	if len(results) == 0 || maxClusters <= 0 {
+		return []Cluster{}
+	}
+
+	// a depth of 0 puts all results into a single cluster
+	depth := uint8(0)
+	for next := depth + 2; next <= maxBitDepth; next += 2 {
+		if countClusters(results, next) > maxClusters {
+			break
+		}
+		depth = next
+	}
+
+	members := map[uint64][]Result{}
+	for _, result := range results {
+		cell := geohashEncoder{bitDepth: depth}.EncodeInt(result.Lat, result.Lon)
+		members[cell] = append(members[cell], result)
+	}
+
+	clusters := make([]Cluster, 0, len(members))
+	for cell, cellResults := range members {
+		centroid := Summarize(cellResults).Centroid
+		cluster := Cluster{Cell: cell, Depth: depth, Lat: centroid.Lat, Lon: centroid.Lon, Count: len(cellResults)}
+		if len(cellResults) == 1 {
+			cluster.Label = cellResults[0].Label
+		}
+		clusters = append(clusters, cluster)
+	}
+	slices.SortFunc(clusters, func(a, b Cluster) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Cell, b.Cell))
+	})
+
+	return clusters
+}
+
+// SearchClusters searches like Search and returns at most maxClusters clusters of the results, see ClusterResults
+func SearchClusters(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, maxClusters int, options ...SearchOption) ([]Cluster, error) {
+	results, err := Search(client, bucketName, lat, lon, radius, bitDepth, options...)
+	if err != nil && len(results) == 0 {
+		return []Cluster{}, err
+	}
+
+	return ClusterResults(results, maxClusters), err
+}
+
+func countClusters(results []Result, depth uint8) int {
+	cells := make(map[uint64]struct{})
+	for _, result := range results {
+		cells[geohashEncoder{bitDepth: depth}.EncodeInt(result.Lat, result.Lon)] = struct{}{}
+	}
+
+	return len(cells)
+}
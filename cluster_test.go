@@ -0,0 +1,40 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestClusterResults(t *testing.T) {
+	results := []Result{
+		{Label: "berlin1", Lat: 52.52, Lon: 13.405},
+		{Label: "berlin2", Lat: 52.521, Lon: 13.406},
+		{Label: "berlin3", Lat: 52.519, Lon: 13.404},
+		{Label: "paris", Lat: 48.85, Lon: 2.35},
+	}
+
+	clusters := ClusterResults(results, 2)
+	if len(clusters) != 2 || clusters[0].Count != 3 || clusters[1].Label != "paris" {
+		t.Logf("expected a berlin and a paris cluster got %v\n", clusters)
+		t.FailNow()
+	}
+	if clusters[0].Lat < 52.519 || clusters[0].Lat > 52.521 || clusters[0].Label != "" {
+		t.Logf("expected the berlin cluster around its members got %v\n", clusters[0])
+		t.Fail()
+	}
+
+	if clusters := ClusterResults(results, 10); len(clusters) != 4 {
+		t.Logf("expected a cluster per result got %v\n", clusters)
+		t.Fail()
+	}
+	if clusters := ClusterResults(results, 1); len(clusters) != 1 || clusters[0].Count != 4 {
+		t.Logf("expected a single cluster got %v\n", clusters)
+		t.Fail()
+	}
+}
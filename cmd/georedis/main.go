@@ -0,0 +1,401 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Command georedis inspects and modifies georedis buckets from the command line
+//
+// Usage:
+//
+//	georedis [connection flags] <command> [flags] [arguments]
+//
+// Connection flags default to the GEOREDIS_URL, GEOREDIS_ADDR, GEOREDIS_PASSWORD, GEOREDIS_DB and GEOREDIS_BIT_DEPTH
+// environment variables, a redis:// or rediss:// URL takes precedence over address, password and database. Run "georedis help" for the list of commands.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/tapglue/georedis"
+	"github.com/tapglue/georedis/httpapi"
+	"github.com/tapglue/georedis/wsapi"
+
+	"gopkg.in/redis.v2"
+)
+
+const pageSize = 1000
+
+type command struct {
+	usage string
+	run   func(env *environment, args []string) error
+}
+
+type environment struct {
+	client   *redis.Client
+	bitDepth uint8
+	stdin    io.Reader
+	stdout   io.Writer
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"add":    {"add <bucket> <label> <lat> <lon>", runAdd},
+		"remove": {"remove <bucket> <label>...", runRemove},
+		"search": {"search [-limit n] [-json] [-unit m|km|mi|nm] <bucket> <lat> <lon> <radius>", runSearch},
+		"import": {"import [-file path] [-format csv|dump] <bucket>   reads label,lat,lon CSV records or a dump", runImport},
+		"export": {"export [-format csv|dump] <bucket>                writes label,lat,lon CSV records or a dump", runExport},
+		"stats":  {"stats <bucket>", runStats},
+		"serve":  {"serve [-listen addr] [-region-depth n] [-changes n]   serves the REST API of the httpapi package and the wsapi live viewports", runServe},
+	}
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "georedis: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("georedis", flag.ExitOnError)
+	redisURL := flags.String("url", envString("GEOREDIS_URL", ""), "Redis URL, redis://[[user]:password@]host[:port][/db] or rediss:// for TLS")
+	address := flags.String("address", envString("GEOREDIS_ADDR", "127.0.0.1:6379"), "Redis address")
+	password := flags.String("password", envString("GEOREDIS_PASSWORD", ""), "Redis password")
+	database := flags.Int64("database", envInt("GEOREDIS_DB", 0), "Redis database")
+	bitDepth := flags.Int64("bitdepth", envInt("GEOREDIS_BIT_DEPTH", 52), "bit depth used to encode coordinates")
+	flags.Usage = func() { usage(os.Stderr) }
+	flags.Parse(args)
+
+	if flags.NArg() == 0 || flags.Arg(0) == "help" {
+		usage(os.Stderr)
+		return nil
+	}
+
+	if *bitDepth < 0 || *bitDepth > 52 || georedis.ValidateBitDepth(uint8(*bitDepth)) != nil {
+		return georedis.ErrInvalidBitDepth
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown command %q", flags.Arg(0))
+	}
+
+	options := &georedis.ConnectionOptions{Addr: *address, Password: *password, DB: *database}
+	if *redisURL != "" {
+		var err error
+		if options, err = georedis.ParseURL(*redisURL); err != nil {
+			return err
+		}
+	}
+
+	client := georedis.NewRedisClient(options)
+	defer client.Close()
+
+	return cmd.run(&environment{
+		client:   client,
+		bitDepth: uint8(*bitDepth),
+		stdin:    os.Stdin,
+		stdout:   os.Stdout,
+	}, flags.Args()[1:])
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: georedis [-url redis://...] [-address addr] [-password pass] [-database n] [-bitdepth n] <command>\n\ncommands:\n")
+	for _, name := range []string{"add", "remove", "search", "import", "export", "stats", "serve"} {
+		fmt.Fprintf(w, "  %s\n", commands[name].usage)
+	}
+}
+
+func runAdd(env *environment, args []string) error {
+	if len(args) != 4 {
+		return errors.New("usage: " + commands["add"].usage)
+	}
+	lat, lon, err := parseLatLon(args[2], args[3])
+	if err != nil {
+		return err
+	}
+
+	added, err := georedis.AddCoordinates(env.client, args[0], env.bitDepth, georedis.GeoKey{Lat: lat, Lon: lon, Label: args[1]})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stdout, "added %d\n", added)
+	return nil
+}
+
+func runRemove(env *environment, args []string) error {
+	if len(args) < 2 {
+		return errors.New("usage: " + commands["remove"].usage)
+	}
+
+	removed, err := georedis.RemoveCoordinatesByKeys(env.client, args[0], args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stdout, "removed %d\n", removed)
+	return nil
+}
+
+func runSearch(env *environment, args []string) error {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	limit := flags.Int("limit", 0, "maximum number of results")
+	asJSON := flags.Bool("json", false, "print results as JSON")
+	unitName := flags.String("unit", "m", "unit of the radius and distances, m, km, mi or nm")
+	flags.Parse(args)
+
+	if flags.NArg() != 4 {
+		return errors.New("usage: " + commands["search"].usage)
+	}
+	unit, err := georedis.ParseUnit(*unitName)
+	if err != nil {
+		return err
+	}
+	lat, lon, err := parseLatLon(flags.Arg(1), flags.Arg(2))
+	if err != nil {
+		return err
+	}
+	radius, err := strconv.ParseFloat(flags.Arg(3), 64)
+	if err != nil || radius <= 0 {
+		return fmt.Errorf("invalid radius %q", flags.Arg(3))
+	}
+
+	options := []georedis.SearchOption{georedis.WithUnit(unit)}
+	if *limit > 0 {
+		options = append(options, georedis.WithLimit(*limit))
+	}
+
+	results, err := georedis.Search(env.client, flags.Arg(0), lat, lon, radius, env.bitDepth, options...)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return json.NewEncoder(env.stdout).Encode(results)
+	}
+	for _, result := range results {
+		fmt.Fprintf(env.stdout, "%s\t%f\t%f\t%.1f\n", result.Label, result.Lat, result.Lon, result.Distance)
+	}
+	return nil
+}
+
+func runImport(env *environment, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "", "file to read, defaults to stdin")
+	format := flags.String("format", "csv", "input format, csv or dump")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: " + commands["import"].usage)
+	}
+
+	input := env.stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	switch *format {
+	case "dump":
+		header, restored, err := georedis.Restore(env.client, input, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		if header.BitDepth != env.bitDepth {
+			fmt.Fprintf(env.stdout, "warning: dump bit depth %d differs from %d\n", header.BitDepth, env.bitDepth)
+		}
+		fmt.Fprintf(env.stdout, "added %d\n", restored)
+		return nil
+	case "csv":
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = 3
+
+	var (
+		batch []georedis.GeoKey
+		total int64
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		added, err := georedis.AddCoordinates(env.client, flags.Arg(0), env.bitDepth, batch...)
+		total += added
+		batch = batch[:0]
+		return err
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		lat, lon, err := parseLatLon(record[1], record[2])
+		if err != nil {
+			return err
+		}
+		batch = append(batch, georedis.GeoKey{Lat: lat, Lon: lon, Label: record[0]})
+		if len(batch) == pageSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stdout, "added %d\n", total)
+	return nil
+}
+
+func runExport(env *environment, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "csv", "output format, csv or dump")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: " + commands["export"].usage)
+	}
+
+	switch *format {
+	case "dump":
+		return georedis.Dump(env.client, flags.Arg(0), env.bitDepth, env.stdout)
+	case "csv":
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	writer := csv.NewWriter(env.stdout)
+	err := eachCoordinate(env, flags.Arg(0), func(coordinate georedis.GeoKey) error {
+		return writer.Write([]string{
+			coordinate.Label,
+			strconv.FormatFloat(coordinate.Lat, 'f', -1, 64),
+			strconv.FormatFloat(coordinate.Lon, 'f', -1, 64),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func runStats(env *environment, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: " + commands["stats"].usage)
+	}
+
+	count, err := georedis.CountCoordinates(env.client, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(env.stdout, "members:\t%d\n", count)
+	if count == 0 {
+		return nil
+	}
+
+	minLat, minLon, maxLat, maxLon := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	err = eachCoordinate(env, args[0], func(coordinate georedis.GeoKey) error {
+		minLat, maxLat = math.Min(minLat, coordinate.Lat), math.Max(maxLat, coordinate.Lat)
+		minLon, maxLon = math.Min(minLon, coordinate.Lon), math.Max(maxLon, coordinate.Lon)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stdout, "bounds:\t%f,%f %f,%f\n", minLat, minLon, maxLat, maxLon)
+	return nil
+}
+
+func runServe(env *environment, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := flags.String("listen", envString("GEOREDIS_LISTEN", ":8080"), "address to listen on")
+	regionDepth := flags.Uint("region-depth", wsapi.DefaultRegionDepth, "region depth of the live viewport updates")
+	changes := flags.Int64("changes", -1, "record writes in the change stream trimmed to about n entries, 0 keeps all")
+	flags.Parse(args)
+
+	if *regionDepth > uint(env.bitDepth) || georedis.ValidateBitDepth(uint8(*regionDepth)) != nil {
+		return fmt.Errorf("invalid region depth %d, it has to be an even value between 2 and the bit depth %d", *regionDepth, env.bitDepth)
+	}
+
+	options := []httpapi.Option{}
+	if *changes >= 0 {
+		options = append(options, httpapi.WithChangeStream(*changes))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", httpapi.New(env.client, env.bitDepth, options...))
+	mux.Handle("GET /buckets/{name}/live", wsapi.New(env.client, env.bitDepth, wsapi.WithRegionDepth(uint8(*regionDepth))))
+
+	fmt.Fprintf(env.stdout, "listening on %s\n", *listen)
+	return http.ListenAndServe(*listen, mux)
+}
+
+func eachCoordinate(env *environment, bucket string, fn func(georedis.GeoKey) error) error {
+	for offset := int64(0); ; offset += pageSize {
+		coordinates, err := georedis.ListCoordinates(env.client, bucket, env.bitDepth, offset, pageSize)
+		if err != nil {
+			return err
+		}
+		for _, coordinate := range coordinates {
+			if err := fn(coordinate); err != nil {
+				return err
+			}
+		}
+		if len(coordinates) < pageSize {
+			return nil
+		}
+	}
+}
+
+func parseLatLon(rawLat, rawLon string) (float64, float64, error) {
+	lat, err := strconv.ParseFloat(rawLat, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latitude %q", rawLat)
+	}
+	lon, err := strconv.ParseFloat(rawLon, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid longitude %q", rawLon)
+	}
+
+	return lat, lon, nil
+}
+
+func envString(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func envInt(name string, fallback int64) int64 {
+	if value, err := strconv.ParseInt(os.Getenv(name), 10, 64); err == nil {
+		return value
+	}
+	return fallback
+}
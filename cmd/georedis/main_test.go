@@ -0,0 +1,110 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tapglue/georedis"
+
+	"gopkg.in/redis.v2"
+)
+
+var (
+	address  = flag.String("address", "127.0.0.1:6379", "Redis address")
+	password = flag.String("password", "", "Redis password")
+	database = flag.Int64("database", 0, "Redis database")
+
+	client *redis.Client
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	client = redis.NewTCPClient(&redis.Options{
+		Addr:     *address,
+		Password: *password,
+		DB:       *database,
+		PoolSize: 2,
+	})
+
+	os.Exit(m.Run())
+}
+
+func TestUsage(t *testing.T) {
+	output := &bytes.Buffer{}
+	usage(output)
+
+	for _, name := range []string{"-url", "-address", "-bitdepth", "-unit", "-region-depth"} {
+		if !strings.Contains(output.String(), name) {
+			t.Logf("expected the usage to mention %s got %q\n", name, output)
+			t.Fail()
+		}
+	}
+}
+
+func TestRunRejectsInvalidArguments(t *testing.T) {
+	if err := run([]string{"-bitdepth", "53", "stats", "test:cli"}); err != georedis.ErrInvalidBitDepth {
+		t.Logf("expected ErrInvalidBitDepth got %v\n", err)
+		t.Fail()
+	}
+	if err := run([]string{"unknown"}); err == nil {
+		t.Logf("expected an error for an unknown command\n")
+		t.Fail()
+	}
+
+	env := &environment{bitDepth: 52, stdout: &bytes.Buffer{}}
+	if err := runSearch(env, []string{"test:cli", "52.52", "13.405", "-5"}); err == nil {
+		t.Logf("expected an error for a negative radius\n")
+		t.Fail()
+	}
+	for _, depth := range []string{"0", "3", "54", "276"} {
+		if err := runServe(env, []string{"-listen", "127.0.0.1:0", "-region-depth", depth}); err == nil || !strings.Contains(err.Error(), "region depth") {
+			t.Logf("expected an invalid region depth error for %s got %v\n", depth, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestImportSearchExport(t *testing.T) {
+	const zSetCLI = "test:cli:cities"
+
+	client.Del(zSetCLI)
+	output := &bytes.Buffer{}
+	env := &environment{
+		client:   client,
+		bitDepth: 52,
+		stdin:    strings.NewReader("Lisbon,38.7223,-9.1393\nPorto,41.1579,-8.6291\n"),
+		stdout:   output,
+	}
+
+	if err := runImport(env, []string{zSetCLI}); err != nil || output.String() != "added 2\n" {
+		t.Logf("expected to import 2 got %q error %v\n", output, err)
+		t.FailNow()
+	}
+
+	output.Reset()
+	if err := runSearch(env, []string{"-json", "-unit", "km", zSetCLI, "38.7223", "-9.1393", "10"}); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	results := []georedis.Result{}
+	if err := json.Unmarshal(output.Bytes(), &results); err != nil || len(results) != 1 || results[0].Label != "Lisbon" {
+		t.Logf("expected Lisbon got %q error %v\n", output, err)
+		t.Fail()
+	}
+
+	output.Reset()
+	if err := runExport(env, []string{zSetCLI}); err != nil || strings.Count(output.String(), "\n") != 2 {
+		t.Logf("expected 2 exported records got %q error %v\n", output, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,164 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const defaultRedisPort = "6379"
+
+// ConnectionOptions describes how to connect to redis, including TLS and ACL authentication
+type ConnectionOptions struct {
+	Addr string
+	// Username selects an ACL user (redis 6 or newer), leave it empty to authenticate with Password only
+	Username string
+	Password string
+	DB       int64
+	// TLSConfig enables TLS when set, ServerName defaults to the host of Addr
+	TLSConfig *tls.Config
+
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	PoolSize     int
+}
+
+// ParseURL parses redis://[[user]:password@]host[:port][/db] URLs, rediss:// enables TLS
+func ParseURL(rawURL string) (*ConnectionOptions, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	opt := &ConnectionOptions{}
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		opt.TLSConfig = &tls.Config{}
+	default:
+		return nil, fmt.Errorf("invalid redis URL scheme %q", u.Scheme)
+	}
+
+	host, port := u.Hostname(), u.Port()
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = defaultRedisPort
+	}
+	opt.Addr = net.JoinHostPort(host, port)
+
+	if u.User != nil {
+		opt.Username = u.User.Username()
+		opt.Password, _ = u.User.Password()
+	}
+
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		if opt.DB, err = strconv.ParseInt(path, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid redis database %q", path)
+		}
+	}
+
+	return opt, nil
+}
+
+// NewRedisClient returns a redis client connecting with the provided options
+func NewRedisClient(opt *ConnectionOptions) *redis.Client {
+	options := &redis.Options{
+		Addr:         opt.Addr,
+		Dialer:       opt.dial,
+		DB:           opt.DB,
+		DialTimeout:  opt.DialTimeout,
+		ReadTimeout:  opt.ReadTimeout,
+		WriteTimeout: opt.WriteTimeout,
+		PoolSize:     opt.PoolSize,
+	}
+	// the redis client only knows the single argument AUTH, ACL users are authenticated while dialing
+	if opt.Username == "" {
+		options.Password = opt.Password
+	}
+
+	return redis.NewClient(options)
+}
+
+// NewGeoClientFromOptions returns a GeoClient connecting with the provided options
+func NewGeoClientFromOptions(opt *ConnectionOptions, bitDepth uint8, options ...ClientOption) *GeoClient {
+	return NewGeoClient(NewRedisClient(opt), bitDepth, options...)
+}
+
+// NewGeoClientFromURL returns a GeoClient connecting to a redis:// or rediss:// URL
+func NewGeoClientFromURL(rawURL string, bitDepth uint8, options ...ClientOption) (*GeoClient, error) {
+	opt, err := ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewGeoClientFromOptions(opt, bitDepth, options...), nil
+}
+
+func (opt *ConnectionOptions) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: opt.DialTimeout}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if opt.TLSConfig != nil {
+		config := opt.TLSConfig.Clone()
+		if config.ServerName == "" {
+			config.ServerName, _, _ = net.SplitHostPort(opt.Addr)
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", opt.Addr, config)
+	} else {
+		conn, err = dialer.Dial("tcp", opt.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.Username != "" {
+		if err := authenticate(conn, opt.Username, opt.Password, opt.DialTimeout); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// authenticate sends AUTH username password on a fresh connection
+func authenticate(conn net.Conn, username, password string, timeout time.Duration) error {
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	command := fmt.Sprintf("*3\r\n$4\r\nAUTH\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(username), username, len(password), password)
+	if _, err := conn.Write([]byte(command)); err != nil {
+		return err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	reply = strings.TrimRight(reply, "\r\n")
+	if reply != "+OK" {
+		return fmt.Errorf("redis: authentication failed: %s", strings.TrimPrefix(reply, "-"))
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"math"
+	"slices"
+
+	"gopkg.in/redis.v2"
+)
+
+// ErrInvalidRoute is returned for corridor routes which are no LineString of at least two valid points
+var ErrInvalidRoute = errors.New("invalid route")
+
+// SearchCorridor returns the members within width meters of a route, a LineString like the ones ParseWKT returns,
+// nearest to the route first and only the nearest "limit" ones (all if limit is negative)
+//
+// The distance of a result is the one to the nearest segment of the route. Segments are straight lines on an
+// equirectangular projection around each member, which is accurate for segments of up to a few hundred kilometers
+// away from the poles. Routes crossing the antimeridian are not supported.
+func SearchCorridor(client *redis.Client, bucketName string, route Geometry, width float64, bitDepth uint8, limit int) ([]Result, error) {
+	line, ok := route.(LineString)
+	if !ok || len(line) < 2 {
+		return []Result{}, ErrInvalidRoute
+	}
+	for _, point := range line {
+		if err := validateLatLon(point.Lat, point.Lon); err != nil {
+			return []Result{}, err
+		}
+	}
+	if !(width > 0 && width <= MaxRadius) {
+		return []Result{}, ErrInvalidRadius
+	}
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return []Result{}, err
+	}
+
+	minLat, maxLat, west, east := line.bounds(width)
+	depth, cells := coverBox(minLat, maxLat, west, east, maxPolygonCells, func(s, w, n, e float64) bool {
+		// the cell can only hold members of the corridor when the route passes within width of its circumcircle
+		centerLat, centerLon := (s+n)/2, (w+e)/2
+		return line.distance(centerLat, centerLon) <= width+Haversine(centerLat, centerLon, n, e)
+	})
+	if depth > bitDepth {
+		for idx := range cells {
+			cells[idx] >>= depth - bitDepth
+		}
+		depth = bitDepth
+	}
+
+	candidates, err := fetchRanges(client, bucketName, cellRanges(cells, bitDepth-depth), true)
+	defer releaseCandidates(candidates)
+	if err != nil {
+		return []Result{}, err
+	}
+
+	results := []Result{}
+	for _, candidate := range dedupeCandidates(candidates) {
+		result := decodeResult(0, 0, bitDepth, candidate, Haversine)
+		if result.Distance = line.distance(result.Lat, result.Lon); result.Distance <= width {
+			results = append(results, result)
+		}
+	}
+	slices.SortFunc(results, byDistance)
+	if limit >= 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// bounds returns the bounding box of the route widened by width meters on every side
+func (l LineString) bounds(width float64) (minLat, maxLat, west, east float64) {
+	minLat, maxLat, west, east = math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	for _, point := range l {
+		minLat, maxLat = min(minLat, point.Lat), max(maxLat, point.Lat)
+		west, east = min(west, point.Lon), max(east, point.Lon)
+	}
+
+	latDelta := degrees(width / earthRadius)
+	minLat, maxLat = max(minLat-latDelta, -90), min(maxLat+latDelta, 90)
+
+	// meridians converge towards the poles, a degree of longitude is shortest at the latitude furthest from the
+	// equator
+	if cos := math.Cos(radians(max(-minLat, maxLat))); cos > 0 {
+		lonDelta := latDelta / cos
+		west, east = max(west-lonDelta, -180), min(east+lonDelta, 180)
+	} else {
+		west, east = -180, 180
+	}
+
+	return minLat, maxLat, west, east
+}
+
+// distance returns the distance in meters from a point to the nearest segment of the route
+func (l LineString) distance(lat, lon float64) float64 {
+	scale := math.Cos(radians(lat))
+	project := func(point Point) (float64, float64) {
+		return radians(point.Lon-lon) * scale * earthRadius, radians(point.Lat-lat) * earthRadius
+	}
+
+	nearest := math.Inf(1)
+	ax, ay := project(l[0])
+	for _, point := range l[1:] {
+		bx, by := project(point)
+
+		// the point is the origin, clamp its projection onto the segment to the segment
+		dx, dy := bx-ax, by-ay
+		t := 0.0
+		if length := dx*dx + dy*dy; length > 0 {
+			t = min(max(-(ax*dx+ay*dy)/length, 0), 1)
+		}
+		nearest = min(nearest, math.Hypot(ax+t*dx, ay+t*dy))
+
+		ax, ay = bx, by
+	}
+
+	return nearest
+}
@@ -0,0 +1,53 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSearchCorridor(t *testing.T) {
+	const zSetCorridor = "test:search:corridor"
+
+	client.Del(zSetCorridor)
+	AddCoordinates(client, zSetCorridor, bitDepth,
+		GeoKey{Lat: 52.52, Lon: 13.40, Label: "on"},
+		GeoKey{Lat: 52.5218, Lon: 13.41, Label: "near"},
+		GeoKey{Lat: 52.54, Lon: 13.40, Label: "far"},
+		GeoKey{Lat: 52.52, Lon: 13.47, Label: "beyond"},
+	)
+
+	route, err := ParseWKT("LINESTRING(13.37 52.52, 13.45 52.52)")
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	results, err := SearchCorridor(client, zSetCorridor, route, 500, bitDepth, -1)
+	if found := labels(results); err != nil || !slices.Equal(found, []string{"on", "near"}) {
+		t.Logf("expected on and near got %v error %v\n", found, err)
+		t.FailNow()
+	}
+	if math.Abs(results[1].Distance-200) > 5 {
+		t.Logf("expected near to be about 200m from the route got %f\n", results[1].Distance)
+		t.Fail()
+	}
+
+	results, err = SearchCorridor(client, zSetCorridor, route, 500, bitDepth, 1)
+	if found := labels(results); err != nil || !slices.Equal(found, []string{"on"}) {
+		t.Logf("expected only on got %v error %v\n", found, err)
+		t.Fail()
+	}
+
+	if _, err := SearchCorridor(client, zSetCorridor, Point{Lat: 52.52, Lon: 13.40}, 500, bitDepth, -1); err != ErrInvalidRoute {
+		t.Logf("expected ErrInvalidRoute got %v\n", err)
+		t.Fail()
+	}
+}
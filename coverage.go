@@ -0,0 +1,119 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"sort"
+
+	"gopkg.in/redis.v2"
+)
+
+// maxCoverageCells bounds the cells checked by a single coverage analysis
+const maxCoverageCells = 4096
+
+// ErrCoverageTooLarge is returned when a coverage analysis spans more than 4096 cells
+var ErrCoverageTooLarge = errors.New("coverage spans too many cells, use a lower cell depth")
+
+type (
+	// CoverageReport tells which cells of a region are served by members of a bucket
+	CoverageReport struct {
+		Cells   int
+		Covered int
+		// Fraction is Covered divided by Cells
+		Fraction float64
+		// Uncovered are the cells without a member within the service radius, ordered by cell
+		Uncovered []CoverageGap
+	}
+
+	// CoverageGap is a cell without a member within the service radius, Lat & Lon are the center of the cell
+	CoverageGap struct {
+		Cell uint64
+		Lat  float64
+		Lon  float64
+	}
+)
+
+// Coverage checks for every cell of cellDepth bits intersecting region whether a member of the bucket is within
+// serviceRadius meters of its center
+//
+// The members around all cells are fetched at once and matched in memory.
+func Coverage(client *redis.Client, bucketName string, bitDepth uint8, region Region, cellDepth uint8, serviceRadius float64) (CoverageReport, error) {
+	report := CoverageReport{Uncovered: []CoverageGap{}}
+	if err := validateRegionDepth(bitDepth, cellDepth); err != nil {
+		return report, err
+	}
+
+	cells, err := regionCells(region, cellDepth, maxCoverageCells, ErrCoverageTooLarge)
+	if err != nil {
+		return report, err
+	}
+
+	centers := make([]Point, len(cells))
+	cellRanges := make([][]geoRange, len(cells))
+	all := []geoRange{}
+	for idx, cell := range cells {
+		centers[idx].Lat, centers[idx].Lon = geohashEncoder{bitDepth: cellDepth}.DecodeInt(cell)
+		if cellRanges[idx], err = queryRanges(centers[idx].Lat, centers[idx].Lon, serviceRadius, bitDepth); err != nil {
+			return report, err
+		}
+		all = append(all, cellRanges[idx]...)
+	}
+
+	candidates, err := fetchRanges(client, bucketName, mergeRanges(all), true)
+	defer releaseCandidates(candidates)
+	if err != nil {
+		return report, err
+	}
+	candidates = dedupeCandidates(candidates)
+
+	for idx, cell := range cells {
+		if coveredBy(candidates, cellRanges[idx], centers[idx], serviceRadius, bitDepth) {
+			report.Covered++
+			continue
+		}
+		report.Uncovered = append(report.Uncovered, CoverageGap{Cell: cell, Lat: centers[idx].Lat, Lon: centers[idx].Lon})
+	}
+	report.Cells = len(cells)
+	report.Fraction = float64(report.Covered) / float64(report.Cells)
+
+	return report, nil
+}
+
+// coveredBy returns whether one of the candidates inside ranges is within radius of center, the candidates have to
+// be sorted by score
+func coveredBy(candidates []redis.Z, ranges []geoRange, center Point, radius float64, bitDepth uint8) bool {
+	for _, r := range ranges {
+		start := sort.Search(len(candidates), func(i int) bool { return candidates[i].Score >= r.Lower })
+		for idx := start; idx < len(candidates) && candidates[idx].Score <= r.Upper; idx++ {
+			if decodeResult(center.Lat, center.Lon, bitDepth, candidates[idx], Haversine).Distance <= radius {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// mergeRanges sorts ranges and merges the overlapping ones
+func mergeRanges(ranges []geoRange) []geoRange {
+	slices.SortFunc(ranges, func(a, b geoRange) int {
+		return cmp.Compare(a.Lower, b.Lower)
+	})
+
+	merged := []geoRange{}
+	for _, r := range ranges {
+		if last := len(merged) - 1; last >= 0 && r.Lower <= merged[last].Upper {
+			merged[last].Upper = max(merged[last].Upper, r.Upper)
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return merged
+}
@@ -0,0 +1,45 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	"github.com/tapglue/georedis/internal/geohash"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestCoverage(t *testing.T) {
+	const zSetCoverage = "test:coverage"
+	const cellDepth = 24
+
+	client.Del(zSetCoverage)
+	AddCoordinates(client, zSetCoverage, bitDepth, GeoKey{Label: "courier", Lat: 52.52, Lon: 13.405})
+
+	region := Region{South: 52.4, West: 13.2, North: 52.6, East: 13.6}
+	report, err := Coverage(client, zSetCoverage, bitDepth, region, cellDepth, 5000)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if report.Covered == 0 || report.Covered == report.Cells || report.Cells != report.Covered+len(report.Uncovered) {
+		t.Logf("expected a partially covered region got %+v\n", report)
+		t.FailNow()
+	}
+	courierCell := geohash.EncodeInt(52.52, 13.405, cellDepth)
+	for _, gap := range report.Uncovered {
+		if gap.Cell == courierCell {
+			t.Logf("expected the cell of the courier to be covered\n")
+			t.Fail()
+		}
+	}
+
+	if report, err := Coverage(client, zSetCoverage, bitDepth, region, cellDepth, 100000); err != nil || report.Fraction != 1 {
+		t.Logf("expected a covered region got %+v error %v\n", report, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,303 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	// DensityHigh is emitted when the count of a cell rises above the threshold
+	DensityHigh DensityAlertType = "high"
+	// DensityNormal is emitted when the count of an alerting cell falls to the clear threshold
+	DensityNormal DensityAlertType = "normal"
+)
+
+// densityLuaBody writes or removes members and keeps the member count of the cell each member is in
+//
+// KEYS are the bucket, its payloads, the cell counts, the alerting cells and the attributes. ARGV holds the cell
+// size as a score divisor, the threshold, the clear threshold and "add" followed by score, label, payload triples
+// (payloads are prefixed with "=" and empty when not set) or "rem" followed by labels. The reply is a flat list of
+// alert type, cell and count for every cell crossing a threshold.
+const densityLuaBody = `
+local divisor = tonumber(ARGV[1])
+local threshold = tonumber(ARGV[2])
+local clear = tonumber(ARGV[3])
+local touched = {}
+
+local function move(from, to)
+  if from == to then return end
+  if from then
+    if redis.call('HINCRBY', KEYS[3], from, -1) <= 0 then redis.call('HDEL', KEYS[3], from) end
+    touched[from] = true
+  end
+  if to then
+    redis.call('HINCRBY', KEYS[3], to, 1)
+    touched[to] = true
+  end
+end
+
+local function cell(score)
+  if not score then return nil end
+  return string.format('%d', math.floor(tonumber(score) / divisor))
+end
+
+if ARGV[4] == 'add' then
+  for i = 5, #ARGV, 3 do
+    local previous = cell(redis.call('ZSCORE', KEYS[1], ARGV[i + 1]))
+    redis.call('ZADD', KEYS[1], ARGV[i], ARGV[i + 1])
+    if ARGV[i + 2] ~= '' then redis.call('HSET', KEYS[2], ARGV[i + 1], string.sub(ARGV[i + 2], 2)) end
+    move(previous, cell(ARGV[i]))
+  end
+else
+  for i = 5, #ARGV do
+    local previous = cell(redis.call('ZSCORE', KEYS[1], ARGV[i]))
+    redis.call('ZREM', KEYS[1], ARGV[i])
+    redis.call('HDEL', KEYS[2], ARGV[i])
+    redis.call('HDEL', KEYS[5], ARGV[i])
+    move(previous, nil)
+  end
+end
+
+local reply = {}
+for c in pairs(touched) do
+  local count = tonumber(redis.call('HGET', KEYS[3], c) or '0')
+  if count > threshold and redis.call('SADD', KEYS[4], c) == 1 then
+    reply[#reply + 1] = 'high'
+    reply[#reply + 1] = c
+    reply[#reply + 1] = count
+  elseif count <= clear and redis.call('SREM', KEYS[4], c) == 1 then
+    reply[#reply + 1] = 'normal'
+    reply[#reply + 1] = c
+    reply[#reply + 1] = count
+  end
+end
+
+return reply
+`
+
+var densityScript = newLuaScript(densityLuaBody)
+
+type (
+	// DensityAlertType is the kind of a DensityAlert
+	DensityAlertType string
+
+	// DensityAlert reports a cell becoming crowded or calming down again, Lat & Lon are the center of the cell
+	DensityAlert struct {
+		Type   DensityAlertType
+		Bucket string
+		Cell   uint64
+		Lat    float64
+		Lon    float64
+		Count  int64
+		Time   time.Time
+	}
+
+	// DensityCell is the member count of a cell, Lat & Lon are the center of the cell
+	DensityCell struct {
+		Cell  uint64
+		Lat   float64
+		Lon   float64
+		Count int64
+	}
+
+	// DensityMonitor writes location updates, counts the members of every cell of cellDepth bits and alerts when a
+	// cell holds more than threshold members
+	//
+	// An alerting cell only alerts again after its count fell to the clear threshold, so a count moving around
+	// the threshold doesn't flood the handlers. Counts and alerts are kept next to the bucket and updated in the
+	// same script as the members, so they are shared by all monitors of the bucket with the same cell depth. Only
+	// members written through a DensityMonitor are counted.
+	DensityMonitor struct {
+		client    *redis.Client
+		bitDepth  uint8
+		cellDepth uint8
+		threshold int64
+		clear     int64
+		handlers  []func(DensityAlert)
+	}
+
+	// DensityMonitorOption configures a DensityMonitor
+	DensityMonitorOption func(*DensityMonitor)
+)
+
+// WithDensityHandler calls handler for every alert
+func WithDensityHandler(handler func(DensityAlert)) DensityMonitorOption {
+	return func(m *DensityMonitor) {
+		m.handlers = append(m.handlers, handler)
+	}
+}
+
+// WithClearThreshold sets the count an alerting cell has to fall to before DensityNormal is emitted, 90% of the
+// threshold by default
+func WithClearThreshold(clear int64) DensityMonitorOption {
+	return func(m *DensityMonitor) {
+		m.clear = clear
+	}
+}
+
+// NewDensityMonitor returns a DensityMonitor counting members in cells of cellDepth bits, which has to be a valid
+// bit depth not above bitDepth
+func NewDensityMonitor(client *redis.Client, bitDepth, cellDepth uint8, threshold int64, options ...DensityMonitorOption) (*DensityMonitor, error) {
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return nil, err
+	}
+	if err := ValidateBitDepth(cellDepth); err != nil {
+		return nil, err
+	}
+	if cellDepth > bitDepth {
+		return nil, ErrBitDepthTooLow
+	}
+
+	m := &DensityMonitor{client: client, bitDepth: bitDepth, cellDepth: cellDepth, threshold: threshold, clear: threshold * 9 / 10}
+	for _, option := range options {
+		option(m)
+	}
+	if m.clear >= m.threshold {
+		return nil, fmt.Errorf("clear threshold %d is not below the threshold %d", m.clear, m.threshold)
+	}
+
+	return m, nil
+}
+
+// UpdateCoordinates adds coordinates to the set and returns the alerts caused by the moves, after emitting them
+func (m *DensityMonitor) UpdateCoordinates(bucketName string, coordinates ...GeoKey) ([]DensityAlert, error) {
+	if err := ValidateCoordinates(coordinates...); err != nil {
+		return []DensityAlert{}, err
+	}
+	if len(coordinates) == 0 {
+		return []DensityAlert{}, nil
+	}
+
+	args := append(m.scriptArgs(), "add")
+	for _, coordinate := range coordinates {
+		payload := ""
+		if coordinate.Payload != nil {
+			payload = "=" + string(coordinate.Payload)
+		}
+		score := geohashEncoder{bitDepth: m.bitDepth}.EncodeInt(coordinate.Lat, coordinate.Lon)
+		args = append(args, strconv.FormatUint(score, 10), coordinate.Label, payload)
+	}
+
+	return m.run(bucketName, args)
+}
+
+// RemoveCoordinatesByKeys removes coordinates from the set and returns the alerts caused, after emitting them
+func (m *DensityMonitor) RemoveCoordinatesByKeys(bucketName string, coordinatesKeys ...string) ([]DensityAlert, error) {
+	if len(coordinatesKeys) == 0 {
+		return []DensityAlert{}, nil
+	}
+
+	return m.run(bucketName, append(append(m.scriptArgs(), "rem"), coordinatesKeys...))
+}
+
+// Count returns the number of members in the cell containing lat & lon
+func (m *DensityMonitor) Count(bucketName string, lat, lon float64) (int64, error) {
+	cell := geohashEncoder{bitDepth: m.cellDepth}.EncodeInt(lat, lon)
+	count, err := m.client.HGet(densityKey(bucketName, m.cellDepth), strconv.FormatUint(cell, 10)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+
+	return count, err
+}
+
+// Hotspots returns the cells currently alerting, most crowded first
+func (m *DensityMonitor) Hotspots(bucketName string) ([]DensityCell, error) {
+	members, err := m.client.SMembers(densityKey(bucketName, m.cellDepth) + ":alerts").Result()
+	if err != nil || len(members) == 0 {
+		return []DensityCell{}, err
+	}
+
+	counts, err := m.client.HMGet(densityKey(bucketName, m.cellDepth), members...).Result()
+	if err != nil {
+		return []DensityCell{}, err
+	}
+
+	cells := make([]DensityCell, 0, len(members))
+	for idx, member := range members {
+		cell, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			return []DensityCell{}, err
+		}
+		count := int64(0)
+		if value, ok := counts[idx].(string); ok {
+			if count, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return []DensityCell{}, err
+			}
+		}
+		lat, lon := geohashEncoder{bitDepth: m.cellDepth}.DecodeInt(cell)
+		cells = append(cells, DensityCell{Cell: cell, Lat: lat, Lon: lon, Count: count})
+	}
+
+	slices.SortFunc(cells, func(a, b DensityCell) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Cell, b.Cell))
+	})
+
+	return cells, nil
+}
+
+func (m *DensityMonitor) scriptArgs() []string {
+	return []string{
+		strconv.FormatUint(1<<(m.bitDepth-m.cellDepth), 10),
+		strconv.FormatInt(m.threshold, 10),
+		strconv.FormatInt(m.clear, 10),
+	}
+}
+
+func (m *DensityMonitor) run(bucketName string, args []string) ([]DensityAlert, error) {
+	counts := densityKey(bucketName, m.cellDepth)
+	reply, err := densityScript.run(m.client, []string{bucketName, payloadKey(bucketName), counts, counts + ":alerts", attributesKey(bucketName)}, args)
+	if err != nil {
+		return []DensityAlert{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values)%3 != 0 {
+		return []DensityAlert{}, fmt.Errorf("unexpected density script reply %v", reply)
+	}
+
+	now := time.Now()
+	alerts := make([]DensityAlert, 0, len(values)/3)
+	for idx := 0; idx < len(values); idx += 3 {
+		alertType, _ := values[idx].(string)
+		member, _ := values[idx+1].(string)
+		count, ok := values[idx+2].(int64)
+		cell, err := strconv.ParseUint(member, 10, 64)
+		if err != nil || !ok {
+			return []DensityAlert{}, fmt.Errorf("unexpected density script reply %v", values[idx:idx+3])
+		}
+
+		lat, lon := geohashEncoder{bitDepth: m.cellDepth}.DecodeInt(cell)
+		alerts = append(alerts, DensityAlert{
+			Type:   DensityAlertType(alertType),
+			Bucket: bucketName,
+			Cell:   cell,
+			Lat:    lat,
+			Lon:    lon,
+			Count:  count,
+			Time:   now,
+		})
+	}
+
+	for _, alert := range alerts {
+		for _, handler := range m.handlers {
+			handler(alert)
+		}
+	}
+
+	return alerts, nil
+}
+
+func densityKey(bucketName string, cellDepth uint8) string {
+	return bucketName + ":density:" + strconv.Itoa(int(cellDepth))
+}
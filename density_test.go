@@ -0,0 +1,79 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestDensityMonitor(t *testing.T) {
+	const zSetCrowd = "test:density:crowd"
+
+	client.Del(zSetCrowd, zSetCrowd+":density:30", zSetCrowd+":density:30:alerts")
+
+	handled := []DensityAlert{}
+	monitor, err := NewDensityMonitor(client, bitDepth, 30, 3, WithClearThreshold(1),
+		WithDensityHandler(func(alert DensityAlert) { handled = append(handled, alert) }))
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	expect := func(alerts []DensityAlert, err error, expected ...DensityAlertType) {
+		t.Helper()
+		if err != nil || len(alerts) != len(expected) {
+			t.Logf("expected %v got %v error %v\n", expected, alerts, err)
+			t.Fail()
+			return
+		}
+		for idx := range alerts {
+			if alerts[idx].Type != expected[idx] {
+				t.Logf("expected %v got %v\n", expected, alerts)
+				t.Fail()
+			}
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		alerts, err := monitor.UpdateCoordinates(zSetCrowd, GeoKey{Lat: 52.52, Lon: 13.405, Label: fmt.Sprintf("p%d", i)})
+		expect(alerts, err)
+	}
+
+	alerts, err := monitor.UpdateCoordinates(zSetCrowd, GeoKey{Lat: 52.52, Lon: 13.405, Label: "p3"})
+	expect(alerts, err, DensityHigh)
+
+	if count, err := monitor.Count(zSetCrowd, 52.52, 13.405); err != nil || count != 4 {
+		t.Logf("expected 4 members in the cell got %d error %v\n", count, err)
+		t.Fail()
+	}
+
+	// moving around the threshold doesn't alert again until the count fell to the clear threshold
+	alerts, err = monitor.UpdateCoordinates(zSetCrowd, GeoKey{Lat: 48.85, Lon: 2.35, Label: "p3"})
+	expect(alerts, err)
+	alerts, err = monitor.UpdateCoordinates(zSetCrowd, GeoKey{Lat: 52.52, Lon: 13.405, Label: "p3"})
+	expect(alerts, err)
+
+	if hotspots, err := monitor.Hotspots(zSetCrowd); err != nil || len(hotspots) != 1 || hotspots[0].Count != 4 {
+		t.Logf("expected a single hotspot got %v error %v\n", hotspots, err)
+		t.Fail()
+	}
+
+	alerts, err = monitor.RemoveCoordinatesByKeys(zSetCrowd, "p0", "p1", "p2")
+	expect(alerts, err, DensityNormal)
+
+	if len(handled) != 2 {
+		t.Logf("expected 2 alerts to be handled got %v\n", handled)
+		t.Fail()
+	}
+
+	if _, err := NewDensityMonitor(client, bitDepth, 30, 3, WithClearThreshold(3)); err == nil {
+		t.Logf("expected a clear threshold at the threshold to be rejected\n")
+		t.Fail()
+	}
+}
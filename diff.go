@@ -0,0 +1,97 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"slices"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const diffBatchSize = 500
+
+// RegionDiffResult lists the members which entered, left or stayed in a region between two times, ordered by label
+type RegionDiffResult struct {
+	Entered []string
+	Left    []string
+	Stayed  []string
+}
+
+// RegionDiff compares the members inside region at t1 with the ones inside at t2, using the histories of the
+// bucket, see AppendHistory
+//
+// A member is located at a time by the last entry of its history recorded at or before it, members without such an
+// entry are nowhere.
+func RegionDiff(client *redis.Client, bucketName string, region Region, t1, t2 time.Time) (RegionDiffResult, error) {
+	diff := RegionDiffResult{Entered: []string{}, Left: []string{}, Stayed: []string{}}
+
+	labels, err := client.ZRange(historyIndexKey(bucketName), 0, -1).Result()
+	if err != nil {
+		return diff, err
+	}
+	// the index is ordered by the time of the last append, the result by label
+	slices.Sort(labels)
+
+	for start := 0; start < len(labels); start += diffBatchSize {
+		batch := labels[start:min(start+diffBatchSize, len(labels))]
+		before, err := historyPositions(client, bucketName, batch, t1)
+		if err != nil {
+			return diff, err
+		}
+		after, err := historyPositions(client, bucketName, batch, t2)
+		if err != nil {
+			return diff, err
+		}
+
+		for idx, label := range batch {
+			wasInside := before[idx] != nil && region.Contains(before[idx].Lat, before[idx].Lon)
+			isInside := after[idx] != nil && region.Contains(after[idx].Lat, after[idx].Lon)
+			switch {
+			case wasInside && isInside:
+				diff.Stayed = append(diff.Stayed, label)
+			case wasInside:
+				diff.Left = append(diff.Left, label)
+			case isInside:
+				diff.Entered = append(diff.Entered, label)
+			}
+		}
+	}
+
+	return diff, nil
+}
+
+// historyPositions returns the last recorded location at or before at of every label, nil when there is none
+func historyPositions(client *redis.Client, bucketName string, labels []string, at time.Time) ([]*Point, error) {
+	positions := make([]*Point, len(labels))
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	cmds := make([]*redis.Cmd, len(labels))
+	_, err := multi.Exec(func() error {
+		for idx, label := range labels {
+			cmds[idx] = redis.NewCmd("XREVRANGE", HistoryKey(bucketName, label), formatStreamID(at), "-", "COUNT", "1")
+			multi.Process(cmds[idx])
+		}
+		return nil
+	})
+	if err != nil {
+		return positions, err
+	}
+
+	for idx := range cmds {
+		entries, err := parseChanges(cmds[idx].Val())
+		if err != nil {
+			return positions, err
+		}
+		if len(entries) > 0 {
+			positions[idx] = entries[0].After
+		}
+	}
+
+	return positions, nil
+}
@@ -0,0 +1,54 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestRegionDiff(t *testing.T) {
+	const zSetDiff = "test:diff"
+
+	client.Del(zSetDiff + ":histories")
+	for _, label := range []string{"stays", "leaves", "enters", "outside"} {
+		client.Del(HistoryKey(zSetDiff, label))
+	}
+
+	berlin := Region{South: 52.3, West: 13, North: 52.7, East: 13.8}
+	inside, outside := GeoKey{Lat: 52.52, Lon: 13.405}, GeoKey{Lat: 48.85, Lon: 2.35}
+	at := func(key GeoKey, label string) GeoKey {
+		key.Label = label
+		return key
+	}
+
+	AppendHistory(client, zSetDiff, HistoryLimits{}, at(inside, "stays"), at(inside, "leaves"), at(outside, "enters"), at(outside, "outside"))
+	time.Sleep(5 * time.Millisecond)
+	t1 := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	AppendHistory(client, zSetDiff, HistoryLimits{}, at(outside, "leaves"), at(inside, "enters"))
+	time.Sleep(5 * time.Millisecond)
+
+	diff, err := RegionDiff(client, zSetDiff, berlin, t1, time.Now())
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if !slices.Equal(diff.Entered, []string{"enters"}) || !slices.Equal(diff.Left, []string{"leaves"}) || !slices.Equal(diff.Stayed, []string{"stays"}) {
+		t.Logf("unexpected diff %v\n", diff)
+		t.Fail()
+	}
+
+	// nobody was anywhere before the first entries
+	diff, err = RegionDiff(client, zSetDiff, berlin, t1.Add(-time.Hour), t1)
+	if err != nil || !slices.Equal(diff.Entered, []string{"leaves", "stays"}) || len(diff.Left) != 0 {
+		t.Logf("expected the members inside at t1 to enter got %v error %v\n", diff, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,133 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"math"
+	"reflect"
+)
+
+const (
+	earthRadius = 6371000
+
+	// WGS84 ellipsoid used by Vincenty
+	wgs84SemiMajorAxis = 6378137
+	wgs84Flattening    = 1 / 298.257223563
+	wgs84SemiMinorAxis = wgs84SemiMajorAxis * (1 - wgs84Flattening)
+
+	vincentyIterations = 200
+)
+
+// DistanceFunc returns the distance in meters between two points
+type DistanceFunc func(lat1, lon1, lat2, lon2 float64) float64
+
+// WithDistance sets the formula used to filter and sort results, Haversine is used by default
+func WithDistance(distance DistanceFunc) SearchOption {
+	return func(o *searchOptions) {
+		o.distance = distance
+	}
+}
+
+// distanceKey identifies the builtin formulas in cache keys, other formulas can't be told apart as closures created
+// from the same function share their code pointer, it returns false for them
+func distanceKey(distance DistanceFunc) (string, bool) {
+	switch reflect.ValueOf(distance).Pointer() {
+	case reflect.ValueOf(Haversine).Pointer():
+		return "haversine", true
+	case reflect.ValueOf(Equirectangular).Pointer():
+		return "equirectangular", true
+	case reflect.ValueOf(Vincenty).Pointer():
+		return "vincenty", true
+	}
+
+	return "", false
+}
+
+// Haversine returns the great circle distance on a sphere with the mean earth radius
+func Haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := radians(lat1), radians(lat2)
+	dPhi, dLambda := radians(lat2-lat1), radians(lon2-lon1)
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// Bearing returns the initial great circle bearing from the first to the second point in degrees clockwise from
+// north, between 0 and 360
+func Bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := radians(lat1), radians(lat2)
+	dLambda := radians(lon2 - lon1)
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// Equirectangular approximates the distance on a flat projection, it is the fastest formula and accurate
+// to well below a percent for radii of a few kilometers away from the poles
+func Equirectangular(lat1, lon1, lat2, lon2 float64) float64 {
+	dLambda := math.Remainder(radians(lon2-lon1), 2*math.Pi)
+	x := dLambda * math.Cos(radians(lat1+lat2)/2)
+	y := radians(lat2 - lat1)
+
+	return earthRadius * math.Hypot(x, y)
+}
+
+// Vincenty returns the geodesic distance on the WGS84 ellipsoid, accurate to millimeters
+//
+// The iteration doesn't converge for nearly antipodal points, Haversine is used for them.
+func Vincenty(lat1, lon1, lat2, lon2 float64) float64 {
+	const a, b, f = wgs84SemiMajorAxis, wgs84SemiMinorAxis, wgs84Flattening
+
+	l := radians(lon2 - lon1)
+	u1 := math.Atan((1 - f) * math.Tan(radians(lat1)))
+	u2 := math.Atan((1 - f) * math.Tan(radians(lat2)))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+
+	lambda := l
+	for i := 0; i < vincentyIterations; i++ {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma := math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0
+		}
+		cosSigma := sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cosSqAlpha := 1 - sinAlpha*sinAlpha
+		cos2SigmaM := 0.0
+		if cosSqAlpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cosSqAlpha
+		}
+		c := f / 16 * cosSqAlpha * (4 + f*(4-3*cosSqAlpha))
+
+		previous := lambda
+		lambda = l + (1-c)*f*sinAlpha*(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-previous) > 1e-12 {
+			continue
+		}
+
+		uSq := cosSqAlpha * (a*a - b*b) / (b * b)
+		bigA := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+		bigB := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+		deltaSigma := bigB * sinSigma * (cos2SigmaM + bigB/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+			bigB/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+
+		return b * bigA * (sigma - deltaSigma)
+	}
+
+	return Haversine(lat1, lon1, lat2, lon2)
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
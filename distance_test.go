@@ -0,0 +1,80 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+	"github.com/tapglue/georedis/memorystore"
+)
+
+func TestDistanceFuncs(t *testing.T) {
+	cases := []struct {
+		name      string
+		distance  DistanceFunc
+		lat1      float64
+		lon1      float64
+		lat2      float64
+		lon2      float64
+		expected  float64
+		tolerance float64
+	}{
+		// Flinders Peak to Buninyong, the reference of Vincenty's paper
+		{"vincenty", Vincenty, -37.95103342, 144.42486789, -37.65282114, 143.92649554, 54972.271, 0.01},
+		{"haversine", Haversine, 51.5007, -0.1246, 40.6892, -74.0445, 5574840, 100},
+		{"equirectangular", Equirectangular, 52.5200, 13.4050, 52.5300, 13.4150, 1305, 5},
+		{"equirectangular antimeridian", Equirectangular, -16.5, 179.999, -16.5, -179.999, 213, 1},
+		{"vincenty antipodal", Vincenty, 0, 0, 0.5, 179.7, 19936288, 20000},
+	}
+
+	for _, c := range cases {
+		if d := c.distance(c.lat1, c.lon1, c.lat2, c.lon2); math.Abs(d-c.expected) > c.tolerance {
+			t.Logf("%s: expected %f got %f\n", c.name, c.expected, d)
+			t.Fail()
+		}
+	}
+}
+
+func TestSearchWithDistance(t *testing.T) {
+	coreClient := NewCoreClient(memorystore.New(), 52)
+	coreClient.AddCoordinates("test:distance", GeoKey{Lat: 52.5300, Lon: 13.4150, Label: "near"})
+
+	for _, distance := range []DistanceFunc{Haversine, Equirectangular, Vincenty} {
+		results, err := coreClient.Search("test:distance", 52.5200, 13.4050, 2000, WithDistance(distance))
+		if err != nil || len(results) != 1 {
+			t.Logf("expected one result got %v error %v\n", results, err)
+			t.FailNow()
+		}
+		if expected := distance(52.5200, 13.4050, 52.5300, 13.4150); math.Abs(results[0].Distance-expected) > 1 {
+			t.Logf("expected distance %f got %f\n", expected, results[0].Distance)
+			t.Fail()
+		}
+	}
+}
+
+func TestBearing(t *testing.T) {
+	cases := []struct {
+		lat1, lon1, lat2, lon2 float64
+		expected               float64
+	}{
+		{0, 0, 1, 0, 0},
+		{0, 0, 0, 1, 90},
+		{0, 0, -1, 0, 180},
+		{0, 0, 0, -1, 270},
+		{0, 179.5, 0, -179.5, 90},
+		// Baghdad to Osaka
+		{35, 45, 35, 135, 60.16},
+	}
+
+	for _, c := range cases {
+		if bearing := Bearing(c.lat1, c.lon1, c.lat2, c.lon2); math.Abs(bearing-c.expected) > 0.01 {
+			t.Logf("expected a bearing of %f from %v,%v to %v,%v got %f\n", c.expected, c.lat1, c.lon1, c.lat2, c.lon2, bearing)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,152 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	dumpFormat    = "georedis-dump"
+	dumpVersion   = 1
+	dumpBatchSize = 1000
+)
+
+type (
+	// DumpHeader is the first line of a dump and describes its content
+	DumpHeader struct {
+		Format   string    `json:"format"`
+		Version  int       `json:"version"`
+		Bucket   string    `json:"bucket"`
+		BitDepth uint8     `json:"bitDepth"`
+		Created  time.Time `json:"created"`
+	}
+
+	dumpMember struct {
+		Label   string  `json:"label"`
+		Score   float64 `json:"score"`
+		Payload []byte  `json:"payload,omitempty"`
+	}
+)
+
+// Dump writes all members of the set to w as newline delimited JSON
+//
+// The first line is a DumpHeader, every following line holds the label, raw score and payload of one member.
+// Members are read in pages so the set is never loaded into memory at once.
+func Dump(client *redis.Client, bucketName string, bitDepth uint8, w io.Writer) error {
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+
+	err := encoder.Encode(DumpHeader{
+		Format:   dumpFormat,
+		Version:  dumpVersion,
+		Bucket:   bucketName,
+		BitDepth: bitDepth,
+		Created:  time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	for offset := int64(0); ; offset += dumpBatchSize {
+		members, err := client.ZRangeWithScores(bucketName, offset, offset+dumpBatchSize-1).Result()
+		if err != nil {
+			return err
+		}
+
+		labels := make([]string, len(members))
+		for idx := range members {
+			labels[idx] = members[idx].Member
+		}
+		payloads, err := GetPayloads(client, bucketName, labels...)
+		if err != nil {
+			return err
+		}
+
+		for idx := range members {
+			member := dumpMember{Label: members[idx].Member, Score: members[idx].Score, Payload: payloads[idx]}
+			if err := encoder.Encode(member); err != nil {
+				return err
+			}
+		}
+		if len(members) < dumpBatchSize {
+			break
+		}
+	}
+
+	return buffered.Flush()
+}
+
+// Restore reads a dump written by Dump and adds its members to the set
+//
+// If bucketName is empty the bucket recorded in the dump is used. Scores are restored unchanged so
+// the bit depth of the target set must match the one in the returned header.
+func Restore(client *redis.Client, r io.Reader, bucketName string) (DumpHeader, int64, error) {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+
+	header := DumpHeader{}
+	if err := decoder.Decode(&header); err != nil {
+		return header, 0, fmt.Errorf("dump: invalid header: %s", err)
+	}
+	if header.Format != dumpFormat {
+		return header, 0, fmt.Errorf("dump: unknown format %q", header.Format)
+	}
+	if header.Version > dumpVersion {
+		return header, 0, fmt.Errorf("dump: unsupported version %d", header.Version)
+	}
+	if bucketName == "" {
+		bucketName = header.Bucket
+	}
+
+	var (
+		restored int64
+		batch    = make([]redis.Z, 0, dumpBatchSize)
+		payloads = []string{}
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if len(payloads) != 0 {
+			if err := client.HMSet(payloadKey(bucketName), payloads[0], payloads[1], payloads[2:]...).Err(); err != nil {
+				return err
+			}
+		}
+		added, err := client.ZAdd(bucketName, batch...).Result()
+		restored += added
+		batch, payloads = batch[:0], payloads[:0]
+		return err
+	}
+
+	for {
+		member := dumpMember{}
+		err := decoder.Decode(&member)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return header, restored, fmt.Errorf("dump: invalid member: %s", err)
+		}
+
+		batch = append(batch, redis.Z{Score: member.Score, Member: member.Label})
+		if member.Payload != nil {
+			payloads = append(payloads, member.Label, string(member.Payload))
+		}
+		if len(batch) == dumpBatchSize {
+			if err := flush(); err != nil {
+				return header, restored, err
+			}
+		}
+	}
+
+	return header, restored, flush()
+}
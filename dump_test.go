@@ -0,0 +1,56 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+const (
+	zSetDump    = "test:dump:source"
+	zSetRestore = "test:dump:target"
+)
+
+func TestDumpRestore(t *testing.T) {
+	client.Del(zSetDump, zSetRestore)
+	AddCoordinates(client, zSetDump, bitDepth, manyCoordinates...)
+
+	dump := &bytes.Buffer{}
+	if err := Dump(client, zSetDump, bitDepth, dump); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	header, restored, err := Restore(client, dump, zSetRestore)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if header.Bucket != zSetDump || header.BitDepth != bitDepth {
+		t.Logf("unexpected header %v\n", header)
+		t.Fail()
+	}
+	if restored != int64(len(manyCoordinates)) {
+		t.Logf("expected to restore: %d restored: %d\n", len(manyCoordinates), restored)
+		t.Fail()
+	}
+
+	people, err := SearchByRadius(client, zSetRestore, 1, 1, 1000, bitDepth)
+	if err != nil || len(people) != 2 {
+		t.Logf("unexpected search result %v error %v\n", people, err)
+		t.Fail()
+	}
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	if _, _, err := Restore(client, bytes.NewBufferString(`{"format":"other"}`), zSetRestore); err == nil {
+		t.Logf("expected an error for an unknown format\n")
+		t.Fail()
+	}
+}
@@ -0,0 +1,128 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+	"github.com/tapglue/georedis/memorystore"
+)
+
+func labels(results []Result) []string {
+	found := make([]string, len(results))
+	for idx := range results {
+		found[idx] = results[idx].Label
+	}
+
+	return found
+}
+
+func TestSearchAcrossAntimeridian(t *testing.T) {
+	coreClient := NewCoreClient(memorystore.New(), 52)
+	coreClient.AddCoordinates("test:antimeridian",
+		GeoKey{Lat: -16.5, Lon: 179.999, Label: "east"},
+		GeoKey{Lat: -16.5, Lon: -179.999, Label: "west"},
+	)
+
+	for _, lon := range []float64{179.9995, -179.9995} {
+		results, err := coreClient.Search("test:antimeridian", -16.5, lon, 1000)
+		if found := labels(results); err != nil || len(found) != 2 || !slices.Contains(found, "east") || !slices.Contains(found, "west") {
+			t.Logf("expected both sides of the date line from %f got %v error %v\n", lon, found, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestSearchNearPoles(t *testing.T) {
+	coreClient := NewCoreClient(memorystore.New(), 52)
+	coreClient.AddCoordinates("test:poles",
+		GeoKey{Lat: 89.9, Lon: 0, Label: "greenwich"},
+		GeoKey{Lat: 89.9, Lon: 90, Label: "asia"},
+		GeoKey{Lat: 89.9, Lon: 180, Label: "dateline"},
+		GeoKey{Lat: 89.9, Lon: -90, Label: "america"},
+		GeoKey{Lat: 89, Lon: 0, Label: "far"},
+		GeoKey{Lat: 75, Lon: 10.0174, Label: "svalbard-east"},
+		GeoKey{Lat: 75, Lon: 9.9826, Label: "svalbard-west"},
+	)
+
+	results, err := coreClient.Search("test:poles", 90, 0, 15000)
+	if found := labels(results); err != nil || len(found) != 4 || slices.Contains(found, "far") {
+		t.Logf("expected the 4 members around the pole got %v error %v\n", found, err)
+		t.Fail()
+	}
+
+	results, err = coreClient.Search("test:poles", 75, 10, 1000)
+	if found := labels(results); err != nil || len(found) != 2 {
+		t.Logf("expected the members east and west at 75° got %v error %v\n", found, err)
+		t.Fail()
+	}
+}
+
+func TestSearchGlobalRadius(t *testing.T) {
+	coreClient := NewCoreClient(memorystore.New(), 52)
+	coreClient.AddCoordinates("test:global",
+		GeoKey{Lat: 0, Lon: 0, Label: "origin"},
+		GeoKey{Lat: 0, Lon: 90, Label: "quarter"},
+		GeoKey{Lat: 0, Lon: 180, Label: "antipode"},
+		GeoKey{Lat: -89, Lon: 45, Label: "south"},
+	)
+
+	results, err := coreClient.Search("test:global", 0, 0, MaxRadius)
+	if found := labels(results); err != nil || len(found) != 4 || found[0] != "origin" || found[3] != "antipode" {
+		t.Logf("expected all members nearest first got %v error %v\n", found, err)
+		t.Fail()
+	}
+
+	results, err = coreClient.Search("test:global", 0, 0, 15000000)
+	if found := labels(results); err != nil || len(found) != 3 || slices.Contains(found, "antipode") {
+		t.Logf("expected all members but the antipode got %v error %v\n", found, err)
+		t.Fail()
+	}
+
+	for _, radius := range []float64{0, -1, MaxRadius + 1} {
+		if _, err := coreClient.Search("test:global", 0, 0, radius); err != ErrInvalidRadius {
+			t.Logf("expected ErrInvalidRadius for %f got %v\n", radius, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestSearchLimitReturnsNearest(t *testing.T) {
+	coreClient := NewCoreClient(memorystore.New(), 52)
+
+	random := rand.New(rand.NewSource(1))
+	coordinates := make([]GeoKey, 200)
+	for idx := range coordinates {
+		coordinates[idx] = GeoKey{
+			Lat:   48.1374 + (random.Float64()-0.5)*0.01,
+			Lon:   11.5755 + (random.Float64()-0.5)*0.01,
+			Label: strconv.Itoa(idx),
+		}
+	}
+	coreClient.AddCoordinates("test:limit", coordinates...)
+
+	all, err := coreClient.Search("test:limit", 48.1374, 11.5755, 1000)
+	if err != nil || len(all) < 10 {
+		t.Logf("expected candidates got %d error %v\n", len(all), err)
+		t.FailNow()
+	}
+
+	nearest, err := coreClient.Search("test:limit", 48.1374, 11.5755, 1000, WithLimit(5))
+	if err != nil || !slices.Equal(labels(nearest), labels(all[:5])) {
+		t.Logf("expected %v got %v error %v\n", labels(all[:5]), labels(nearest), err)
+		t.Fail()
+	}
+
+	unlimited, err := coreClient.Search("test:limit", 48.1374, 11.5755, 1000, WithLimit(-5))
+	if err != nil || !slices.Equal(labels(unlimited), labels(all)) {
+		t.Logf("expected a negative limit to return all %d got %d error %v\n", len(all), len(unlimited), err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,187 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"github.com/tapglue/georedis/internal/geohash"
+
+	"gopkg.in/redis.v2"
+)
+
+type (
+	// Encoder maps coordinates to the scores of a bucket and plans which scores a search has to read
+	//
+	// Scores have to fit into 53 bits so sorted sets store them exactly. All writers and readers of a bucket have to
+	// use the same encoder.
+	Encoder interface {
+		EncodeInt(lat, lon float64) uint64
+		// DecodeInt returns the center of the cell of a score
+		DecodeInt(score uint64) (lat, lon float64)
+		// Neighbors returns the cell of a score and the cells around it
+		Neighbors(score uint64) []uint64
+		// Cover returns ranges of scores holding at least every score within radius meters of lat & lon
+		Cover(lat, lon, radius float64) ([]ScoreRange, error)
+	}
+
+	// ScoreRange is a range of scores, both ends inclusive
+	ScoreRange struct {
+		Min uint64
+		Max uint64
+	}
+
+	// geohashEncoder is the integer geohash encoding used by the package level functions, it is the only user of
+	// the geohash package
+	geohashEncoder struct {
+		bitDepth uint8
+	}
+)
+
+// GeohashEncoder returns the Encoder of the package level functions for a bit depth
+func GeohashEncoder(bitDepth uint8) (Encoder, error) {
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return nil, err
+	}
+
+	return geohashEncoder{bitDepth: bitDepth}, nil
+}
+
+func (e geohashEncoder) EncodeInt(lat, lon float64) uint64 {
+	return geohash.EncodeInt(lat, lon, e.bitDepth)
+}
+
+func (e geohashEncoder) DecodeInt(score uint64) (float64, float64) {
+	lat, lon, _, _ := geohash.DecodeInt(score, e.bitDepth)
+	return lat, lon
+}
+
+// neighborDirections are the lat/lon offsets of the eight cells around a cell
+var neighborDirections = [8][2]float64{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+
+// Neighbors returns the cell and its neighbors, wrapping around the antimeridian
+//
+// Neighbors east of 180° or west of -180° continue on the other side of the date line instead of being clamped,
+// cells beyond the poles don't exist and are left out.
+func (e geohashEncoder) Neighbors(score uint64) []uint64 {
+	cells := make([]uint64, 1, len(neighborDirections)+1)
+	cells[0] = score
+
+	lat, lon, latErr, lonErr := e.bounds(score)
+	for _, direction := range neighborDirections {
+		neighborLat := lat + direction[0]*latErr*2
+		if neighborLat < -90 || neighborLat > 90 {
+			continue
+		}
+
+		neighborLon := lon + direction[1]*lonErr*2
+		if neighborLon > 180 {
+			neighborLon -= 360
+		} else if neighborLon < -180 {
+			neighborLon += 360
+		}
+
+		cells = append(cells, e.EncodeInt(neighborLat, neighborLon))
+	}
+
+	return cells
+}
+
+// bounds returns the center of the cell of a score and half its height and width
+func (e geohashEncoder) bounds(score uint64) (float64, float64, float64, float64) {
+	return geohash.DecodeInt(score, e.bitDepth)
+}
+
+// bbox returns the bounding box of the cell of a score
+func (e geohashEncoder) bbox(score uint64) (float64, float64, float64, float64) {
+	return geohash.DecodeBboxInt(score, e.bitDepth)
+}
+
+func (e geohashEncoder) Cover(lat, lon, radius float64) ([]ScoreRange, error) {
+	ranges, err := queryRanges(lat, lon, radius, e.bitDepth)
+	if err != nil {
+		return []ScoreRange{}, err
+	}
+
+	covering := make([]ScoreRange, len(ranges))
+	for idx := range ranges {
+		covering[idx] = ScoreRange{Min: uint64(ranges[idx].Lower), Max: uint64(ranges[idx].Upper)}
+	}
+
+	return covering, nil
+}
+
+// AddCoordinatesWithEncoder adds coordinates to the set like AddCoordinates with the scores of encoder
+func AddCoordinatesWithEncoder(client *redis.Client, bucketName string, encoder Encoder, coordinates ...GeoKey) (int64, error) {
+	if err := ValidateCoordinates(coordinates...); err != nil {
+		return 0, err
+	}
+
+	encodedCoordinates := make([]redis.Z, len(coordinates))
+	payloads := []string{}
+	for key, value := range coordinates {
+		encodedCoordinates[key] = redis.Z{
+			Score:  float64(encoder.EncodeInt(value.Lat, value.Lon)),
+			Member: value.Label,
+		}
+		if value.Payload != nil {
+			payloads = append(payloads, value.Label, string(value.Payload))
+		}
+	}
+
+	if len(payloads) == 0 {
+		return client.ZAdd(bucketName, encodedCoordinates...).Result()
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	var added *redis.IntCmd
+	_, err := multi.Exec(func() error {
+		added = multi.ZAdd(bucketName, encodedCoordinates...)
+		multi.HMSet(payloadKey(bucketName), payloads[0], payloads[1], payloads[2:]...)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return added.Val(), nil
+}
+
+// SearchWithEncoder searches a bucket written with AddCoordinatesWithEncoder like Search
+func SearchWithEncoder(client *redis.Client, bucketName string, encoder Encoder, lat, lon, radius float64, options ...SearchOption) ([]Result, error) {
+	opts := newSearchOptions(options)
+	radius = opts.unit.ToMeters(radius)
+
+	covering, err := encoder.Cover(lat, lon, radius)
+	if err != nil {
+		return []Result{}, err
+	}
+	ranges := make([]geoRange, len(covering))
+	for idx := range covering {
+		ranges[idx] = geoRange{Lower: float64(covering[idx].Min), Upper: float64(covering[idx].Max)}
+	}
+
+	var candidates []redis.Z
+	var fetchErr error
+	if since := opts.since(); !since.IsZero() {
+		if candidates, err = fetchFreshRanges(client, bucketName, bucketName, ranges, since); err != nil {
+			releaseCandidates(candidates)
+			return []Result{}, err
+		}
+	} else if candidates, fetchErr = fetchRanges(client, bucketName, ranges, opts.failFast); fetchErr != nil && opts.failFast {
+		releaseCandidates(candidates)
+		return []Result{}, fetchErr
+	}
+
+	candidates = matchCandidates(candidates, opts.labelMatch)
+	results := rankEncoded(lat, lon, radius, encoder, candidates, opts.rankLimit(), opts.distance)
+	releaseCandidates(candidates)
+	if results, err = completeResults(client, bucketName, results, opts); err != nil {
+		return []Result{}, err
+	}
+
+	return results, fetchErr
+}
@@ -0,0 +1,31 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGeohashEncoderNeighbors(t *testing.T) {
+	encoder := geohashEncoder{bitDepth: 10}
+
+	cell := encoder.EncodeInt(0, 179.9)
+	neighbors := encoder.Neighbors(cell)
+	if len(neighbors) != 9 || neighbors[0] != cell {
+		t.Logf("expected the cell and its 8 neighbors got %v\n", neighbors)
+		t.FailNow()
+	}
+	if !slices.Contains(neighbors, encoder.EncodeInt(0, -179.9)) {
+		t.Logf("expected the neighbors to wrap around the antimeridian got %v\n", neighbors)
+		t.Fail()
+	}
+
+	if neighbors := encoder.Neighbors(encoder.EncodeInt(89.9, 0)); len(neighbors) != 6 {
+		t.Logf("expected no neighbors beyond the pole got %v\n", neighbors)
+		t.Fail()
+	}
+}
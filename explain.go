@@ -0,0 +1,115 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import "gopkg.in/redis.v2"
+
+type (
+	// SearchPlan describes how Search reads a bucket for a radius search
+	SearchPlan struct {
+		// BitDepth is the bit depth of the bucket
+		BitDepth uint8
+		// CellDepth is the bit depth of the cells covering the radius, 0 when the whole bucket is read
+		CellDepth uint8
+		Ranges    []PlannedRange
+		// Candidates is the number of members read before the exact distance filter
+		Candidates int64
+	}
+
+	// PlannedRange is a range of scores read by a search
+	PlannedRange struct {
+		ScoreRange
+		Cells []PlannedCell
+		// Candidates is the number of members with scores in the range
+		Candidates int64
+	}
+
+	// PlannedCell is one of the cells making up a PlannedRange
+	PlannedCell struct {
+		Cell uint64
+		// Geohash is the base32 geohash all points of the cell start with, it is empty for cells of less than 5 bits
+		Geohash string
+		Lat     float64
+		Lon     float64
+		// Area is the area of the cell in square meters
+		Area float64
+	}
+)
+
+// ExplainSearch returns the plan of Search for a radius search with the estimated number of candidates, without
+// running the search
+//
+// Only the unit and tuning of options change the plan. The candidates are counted with ZCOUNT, a search reads as many members
+// and drops those out of the radius.
+func ExplainSearch(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, options ...SearchOption) (SearchPlan, error) {
+	opts := newSearchOptions(options)
+
+	ranges, depth, err := planRanges(lat, lon, opts.unit.ToMeters(radius), bitDepth, opts.tuning)
+	if err != nil {
+		return SearchPlan{}, err
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	counts := make([]*redis.IntCmd, len(ranges))
+	_, err = multi.Exec(func() error {
+		for idx := range ranges {
+			query := ranges[idx].query(0, 0)
+			counts[idx] = multi.ZCount(bucketName, query.Min, query.Max)
+		}
+		return nil
+	})
+	if err != nil {
+		return SearchPlan{}, err
+	}
+
+	plan := SearchPlan{BitDepth: bitDepth, CellDepth: depth, Ranges: make([]PlannedRange, len(ranges))}
+	shift := bitDepth - depth
+	for idx := range ranges {
+		lower, upper := uint64(ranges[idx].Lower), uint64(ranges[idx].Upper)
+
+		planned := PlannedRange{
+			ScoreRange: ScoreRange{Min: lower, Max: upper},
+			Candidates: counts[idx].Val(),
+		}
+		for cell := lower >> shift; cell < upper>>shift; cell++ {
+			planned.Cells = append(planned.Cells, plannedCell(cell, depth))
+		}
+
+		plan.Ranges[idx] = planned
+		plan.Candidates += planned.Candidates
+	}
+
+	return plan, nil
+}
+
+// ExplainSearch returns the plan of Search for a radius search without running the search
+func (c *GeoClient) ExplainSearch(bucketName string, lat, lon, radius float64, options ...SearchOption) (SearchPlan, error) {
+	return handleOp(c, Operation{Name: "ExplainSearch", Bucket: bucketName, Args: []any{lat, lon, radius, options}}, func() (SearchPlan, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() (SearchPlan, error) {
+			return ExplainSearch(c.reader(key), key, lat, lon, radius, c.bitDepth, options...)
+		})
+	})
+}
+
+func plannedCell(cell uint64, depth uint8) PlannedCell {
+	minLat, minLon, maxLat, maxLon := geohashEncoder{bitDepth: depth}.bbox(cell)
+	lat, lon := (minLat+maxLat)/2, (minLon+maxLon)/2
+
+	planned := PlannedCell{
+		Cell: cell,
+		Lat:  lat,
+		Lon:  lon,
+		Area: cellArea(minLat, minLon, maxLat, maxLon),
+	}
+	if precision := int(depth) / 5; precision > 0 {
+		planned.Geohash = geohashString(lat, lon, precision)
+	}
+
+	return planned
+}
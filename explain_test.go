@@ -0,0 +1,57 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestExplainSearch(t *testing.T) {
+	const zSetExplain = "test:explain"
+
+	client.Del(zSetExplain)
+	AddCoordinates(client, zSetExplain, bitDepth,
+		GeoKey{Label: "berlin", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "hamburg", Lat: 53.5511, Lon: 9.9937},
+	)
+
+	plan, err := ExplainSearch(client, zSetExplain, 52.52, 13.405, 5, bitDepth, WithUnit(Kilometers))
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if plan.BitDepth != bitDepth || plan.CellDepth == 0 || plan.CellDepth > bitDepth || len(plan.Ranges) == 0 {
+		t.Logf("unexpected plan %+v\n", plan)
+		t.FailNow()
+	}
+	if plan.Candidates != 1 {
+		t.Logf("expected 1 candidate got %d\n", plan.Candidates)
+		t.Fail()
+	}
+
+	cells := 0
+	for _, planned := range plan.Ranges {
+		for _, cell := range planned.Cells {
+			cells++
+			if cell.Area <= 0 || len(cell.Geohash) != int(plan.CellDepth)/5 {
+				t.Logf("unexpected cell %+v\n", cell)
+				t.Fail()
+			}
+		}
+	}
+	if cells != 9 {
+		t.Logf("expected the cell of the center and its 8 neighbors got %d cells\n", cells)
+		t.Fail()
+	}
+
+	if plan, err := ExplainSearch(client, zSetExplain, 52.52, 13.405, MaxRadius, bitDepth); err != nil ||
+		plan.CellDepth != 0 || plan.Candidates != 2 {
+		t.Logf("expected the whole bucket to be read got %+v, %v\n", plan, err)
+		t.Fail()
+	}
+}
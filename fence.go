@@ -0,0 +1,351 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	// maxFenceCells bounds the number of cells indexing a single circular fence
+	maxFenceCells = 16
+
+	// maxPolygonCells bounds the cells of the bounding box of a polygon fence, before the cells outside the
+	// polygon are dropped
+	maxPolygonCells = 64
+)
+
+// ErrFenceNotFound is returned when a fence is not part of the fence set
+var ErrFenceNotFound = errors.New("fence not found")
+
+type (
+	// Fence is a named circular or polygon area
+	//
+	// Geometry is the Polygon or MultiPolygon of polygon fences, their Lat & Lon are the center of the bounding box
+	// and Radius is 0. Geometry is nil for circular fences.
+	//
+	// Roaming fences follow the member Anchor of the bucket AnchorBucket, see CreateRoamingFence.
+	Fence struct {
+		Name     string
+		Lat      float64
+		Lon      float64
+		Radius   float64
+		Geometry Geometry
+
+		AnchorBucket string
+		Anchor       string
+	}
+
+	// storedFence is the fence as persisted, together with the cells indexing it
+	storedFence struct {
+		Name   string   `json:"name"`
+		Lat    float64  `json:"lat"`
+		Lon    float64  `json:"lon"`
+		Radius float64  `json:"radius"`
+		Depth  uint8    `json:"depth"`
+		Cells  []uint64 `json:"cells"`
+
+		Polygons MultiPolygon `json:"polygons,omitempty"`
+
+		AnchorBucket string `json:"anchorBucket,omitempty"`
+		Anchor       string `json:"anchor,omitempty"`
+	}
+)
+
+// CreateFence stores a circular fence of radius meters around lat & lon, replacing a fence with the same name
+//
+// Fences are indexed by the geohash cells covering them, so looking up the fences containing a point only reads
+// the cells of that point.
+func CreateFence(client *redis.Client, fenceSet, name string, lat, lon, radius float64) error {
+	if err := validateLatLon(lat, lon); err != nil {
+		return &CoordinateError{Key: GeoKey{Lat: lat, Lon: lon, Label: name}, Err: err}
+	}
+	if !(radius > 0 && radius <= MaxRadius) {
+		return ErrInvalidRadius
+	}
+
+	fence := storedFence{Name: name, Lat: lat, Lon: lon, Radius: radius}
+	fence.Depth, fence.Cells = coverCircle(lat, lon, radius)
+
+	return storeFence(client, fenceSet, fence)
+}
+
+// CreatePolygonFence stores a Polygon or MultiPolygon fence, replacing a fence with the same name
+//
+// The fence is indexed by the cells of its bounding box which overlap the polygons, containment is checked
+// exactly against the rings. Polygons must not cross the antimeridian.
+func CreatePolygonFence(client *redis.Client, fenceSet, name string, geometry Geometry) error {
+	multi, err := polygons(geometry)
+	if err != nil {
+		return err
+	}
+
+	minLat, maxLat, west, east := multi.bounds()
+	fence := storedFence{Name: name, Lat: (minLat + maxLat) / 2, Lon: (west + east) / 2, Polygons: multi}
+	fence.Depth, fence.Cells = coverBox(minLat, maxLat, west, east, maxPolygonCells, multi.intersectsBox)
+
+	return storeFence(client, fenceSet, fence)
+}
+
+// storeFence writes a fence and its cells, removing the cells of the fence it replaces
+func storeFence(client *redis.Client, fenceSet string, fence storedFence) error {
+	previous, err := getFence(client, fenceSet, fence.Name)
+	if err != nil && err != ErrFenceNotFound {
+		return err
+	}
+
+	encoded, err := json.Marshal(fence)
+	if err != nil {
+		return err
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	_, err = multi.Exec(func() error {
+		if previous != nil {
+			unindexFence(multi, fenceSet, previous)
+		}
+		multi.HSet(fenceKey(fenceSet), fence.Name, string(encoded))
+		for _, cell := range fence.Cells {
+			multi.SAdd(fenceCellKey(fenceSet, fence.Depth, cell), fence.Name)
+		}
+		multi.ZIncrBy(fenceDepthsKey(fenceSet), float64(len(fence.Cells)), strconv.Itoa(int(fence.Depth)))
+		if fence.Anchor != "" {
+			multi.SAdd(anchorKey(fenceSet, fence.AnchorBucket, fence.Anchor), fence.Name)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// DeleteFence removes a fence, ErrFenceNotFound is returned when there is none with that name
+func DeleteFence(client *redis.Client, fenceSet, name string) error {
+	fence, err := getFence(client, fenceSet, name)
+	if err != nil {
+		return err
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	_, err = multi.Exec(func() error {
+		unindexFence(multi, fenceSet, fence)
+		multi.HDel(fenceKey(fenceSet), name)
+		return nil
+	})
+
+	return err
+}
+
+// ListFences returns all fences of the fence set ordered by name
+func ListFences(client *redis.Client, fenceSet string) ([]Fence, error) {
+	values, err := client.HVals(fenceKey(fenceSet)).Result()
+	if err != nil {
+		return []Fence{}, err
+	}
+
+	fences := make([]Fence, 0, len(values))
+	for _, value := range values {
+		fence := storedFence{}
+		if err := json.Unmarshal([]byte(value), &fence); err != nil {
+			return []Fence{}, err
+		}
+		fences = append(fences, fence.fence())
+	}
+	slices.SortFunc(fences, func(a, b Fence) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return fences, nil
+}
+
+// ContainingFences returns the fences containing lat & lon, nearest center first
+func ContainingFences(client *redis.Client, fenceSet string, lat, lon float64) ([]Fence, error) {
+	if err := validateLatLon(lat, lon); err != nil {
+		return []Fence{}, &CoordinateError{Key: GeoKey{Lat: lat, Lon: lon}, Err: err}
+	}
+
+	depths, err := client.ZRangeByScore(fenceDepthsKey(fenceSet), redis.ZRangeByScore{Min: "(0", Max: "+inf"}).Result()
+	if err != nil {
+		return []Fence{}, err
+	}
+	if len(depths) == 0 {
+		return []Fence{}, nil
+	}
+
+	keys := make([]string, 0, len(depths))
+	for _, value := range depths {
+		depth, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, fenceCellKey(fenceSet, uint8(depth), geohashEncoder{bitDepth: uint8(depth)}.EncodeInt(lat, lon)))
+	}
+
+	names, err := client.SUnion(keys...).Result()
+	if err != nil || len(names) == 0 {
+		return []Fence{}, err
+	}
+
+	values, err := client.HMGet(fenceKey(fenceSet), names...).Result()
+	if err != nil {
+		return []Fence{}, err
+	}
+
+	fences := []Fence{}
+	distances := map[string]float64{}
+	for _, value := range values {
+		encoded, ok := value.(string)
+		if !ok {
+			continue
+		}
+		fence := storedFence{}
+		if err := json.Unmarshal([]byte(encoded), &fence); err != nil {
+			return []Fence{}, err
+		}
+		if fence.contains(lat, lon) {
+			distances[fence.Name] = Haversine(lat, lon, fence.Lat, fence.Lon)
+			fences = append(fences, fence.fence())
+		}
+	}
+	slices.SortFunc(fences, func(a, b Fence) int {
+		return cmp.Compare(distances[a.Name], distances[b.Name])
+	})
+
+	return fences, nil
+}
+
+func getFence(client *redis.Client, fenceSet, name string) (*storedFence, error) {
+	value, err := client.HGet(fenceKey(fenceSet), name).Result()
+	if err == redis.Nil {
+		return nil, ErrFenceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fence := &storedFence{}
+	if err := json.Unmarshal([]byte(value), fence); err != nil {
+		return nil, err
+	}
+
+	return fence, nil
+}
+
+// unindexFence queues the removal of a fence from its cells
+func unindexFence(multi *redis.Multi, fenceSet string, fence *storedFence) {
+	for _, cell := range fence.Cells {
+		multi.SRem(fenceCellKey(fenceSet, fence.Depth, cell), fence.Name)
+	}
+	multi.ZIncrBy(fenceDepthsKey(fenceSet), -float64(len(fence.Cells)), strconv.Itoa(int(fence.Depth)))
+	if fence.Anchor != "" {
+		multi.SRem(anchorKey(fenceSet, fence.AnchorBucket, fence.Anchor), fence.Name)
+	}
+}
+
+func (f storedFence) contains(lat, lon float64) bool {
+	if len(f.Polygons) > 0 {
+		return f.Polygons.Contains(lat, lon)
+	}
+
+	return Haversine(lat, lon, f.Lat, f.Lon) <= f.Radius
+}
+
+func (f storedFence) fence() Fence {
+	fence := Fence{Name: f.Name, Lat: f.Lat, Lon: f.Lon, Radius: f.Radius, AnchorBucket: f.AnchorBucket, Anchor: f.Anchor}
+	switch len(f.Polygons) {
+	case 0:
+	case 1:
+		fence.Geometry = f.Polygons[0]
+	default:
+		fence.Geometry = f.Polygons
+	}
+
+	return fence
+}
+
+func fenceKey(fenceSet string) string {
+	return fenceSet + ":fences"
+}
+
+func fenceDepthsKey(fenceSet string) string {
+	return fenceSet + ":fences:depths"
+}
+
+func anchorKey(fenceSet, bucketName, label string) string {
+	return fenceSet + ":fences:anchor:" + bucketName + ":" + label
+}
+
+func fenceCellKey(fenceSet string, depth uint8, cell uint64) string {
+	return fmt.Sprintf("%s:fences:%d:%d", fenceSet, depth, cell)
+}
+
+// coverCircle returns the depth and cells covering the bounding box of a circle
+func coverCircle(lat, lon, radius float64) (uint8, []uint64) {
+	dLat := radius / metersPerDegree
+	poleward := math.Abs(lat) + dLat
+	if poleward >= 90 {
+		return coverBox(lat-dLat, lat+dLat, -180, 180, maxFenceCells, nil)
+	}
+
+	dLon := dLat / math.Cos(poleward*math.Pi/180)
+	return coverBox(lat-dLat, lat+dLat, lon-dLon, lon+dLon, maxFenceCells, nil)
+}
+
+// coverBox returns the finest depth, and its cells, covering a bounding box with at most maxCells cells
+//
+// west and east may extend beyond ±180 for boxes crossing the antimeridian. When keep is set only the cells it
+// accepts, by their south, west, north and east bounds, are returned.
+func coverBox(minLat, maxLat, west, east float64, maxCells int, keep func(s, w, n, e float64) bool) (uint8, []uint64) {
+	minLat, maxLat = max(minLat, -90), min(maxLat, 90)
+
+	bits := uint8(maxBitDepth / 2)
+	for ; bits > 1; bits-- {
+		rows, cols := boxCells(minLat, maxLat, west, east, bits)
+		if rows*cols <= maxCells {
+			break
+		}
+	}
+
+	n := 1 << bits
+	cellHeight, cellWidth := 180/float64(n), 360/float64(n)
+	firstRow, firstCol := bandRow(minLat, bits), int(math.Floor((west+180)/cellWidth))
+	rows, cols := boxCells(minLat, maxLat, west, east, bits)
+
+	cells := make([]uint64, 0, rows*cols)
+	for row := firstRow; row < firstRow+rows; row++ {
+		south := -90 + float64(row)*cellHeight
+		for col := firstCol; col < firstCol+cols; col++ {
+			cellWest := -180 + float64(((col%n)+n)%n)*cellWidth
+			if keep != nil && !keep(south, cellWest, south+cellHeight, cellWest+cellWidth) {
+				continue
+			}
+			cells = append(cells, geohashEncoder{bitDepth: bits * 2}.EncodeInt(south+cellHeight/2, cellWest+cellWidth/2))
+		}
+	}
+	slices.Sort(cells)
+
+	return bits * 2, slices.Compact(cells)
+}
+
+// boxCells returns the number of rows and columns of cells with latBits bits per axis covering a bounding box
+func boxCells(minLat, maxLat, west, east float64, latBits uint8) (int, int) {
+	n := 1 << latBits
+	cellWidth := 360 / float64(n)
+	cols := int(math.Floor((east+180)/cellWidth)) - int(math.Floor((west+180)/cellWidth)) + 1
+
+	return bandRows(minLat, maxLat, latBits), min(cols, n)
+}
@@ -0,0 +1,87 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestFences(t *testing.T) {
+	const fenceSet = "test:fences"
+
+	for _, fence := range []Fence{
+		{Name: "berlin", Lat: 52.52, Lon: 13.405, Radius: 20000},
+		{Name: "mitte", Lat: 52.52, Lon: 13.405, Radius: 2000},
+		{Name: "potsdam", Lat: 52.39, Lon: 13.065, Radius: 10000},
+	} {
+		if err := CreateFence(client, fenceSet, fence.Name, fence.Lat, fence.Lon, fence.Radius); err != nil {
+			t.Logf("error encountered %q\n", err)
+			t.FailNow()
+		}
+	}
+
+	containing, err := ContainingFences(client, fenceSet, 52.53, 13.41)
+	if err != nil || len(containing) != 2 || containing[0].Name != "berlin" && containing[0].Name != "mitte" {
+		t.Logf("expected berlin and mitte got %v error %v\n", containing, err)
+		t.Fail()
+	}
+
+	// moving a fence replaces its cells
+	CreateFence(client, fenceSet, "mitte", 48.137, 11.575, 2000)
+	containing, err = ContainingFences(client, fenceSet, 52.53, 13.41)
+	if err != nil || len(containing) != 1 || containing[0].Name != "berlin" {
+		t.Logf("expected berlin only got %v error %v\n", containing, err)
+		t.Fail()
+	}
+
+	if err := DeleteFence(client, fenceSet, "berlin"); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.Fail()
+	}
+	if err := DeleteFence(client, fenceSet, "berlin"); err != ErrFenceNotFound {
+		t.Logf("expected ErrFenceNotFound got %v\n", err)
+		t.Fail()
+	}
+
+	fences, err := ListFences(client, fenceSet)
+	if err != nil || len(fences) != 2 || fences[0].Name != "mitte" || fences[1].Name != "potsdam" {
+		t.Logf("expected mitte and potsdam got %v error %v\n", fences, err)
+		t.Fail()
+	}
+
+	DeleteFence(client, fenceSet, "mitte")
+	DeleteFence(client, fenceSet, "potsdam")
+}
+
+func TestPolygonFences(t *testing.T) {
+	const fenceSet = "test:fences:polygons"
+
+	ring := Polygon{{{Lat: 52.5, Lon: 13.3}, {Lat: 52.5, Lon: 13.5}, {Lat: 52.6, Lon: 13.5}, {Lat: 52.6, Lon: 13.3}}}
+	if err := CreatePolygonFence(client, fenceSet, "box", ring); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if err := CreatePolygonFence(client, fenceSet, "line", LineString{}); err != ErrInvalidPolygon {
+		t.Logf("expected ErrInvalidPolygon got %v\n", err)
+		t.Fail()
+	}
+
+	containing, err := ContainingFences(client, fenceSet, 52.55, 13.4)
+	if err != nil || len(containing) != 1 || containing[0].Geometry == nil {
+		t.Logf("expected the box fence got %v error %v\n", containing, err)
+		t.Fail()
+	}
+
+	containing, err = ContainingFences(client, fenceSet, 52.65, 13.4)
+	if err != nil || len(containing) != 0 {
+		t.Logf("expected no fence got %v error %v\n", containing, err)
+		t.Fail()
+	}
+
+	DeleteFence(client, fenceSet, "box")
+}
@@ -0,0 +1,123 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"gopkg.in/redis.v2"
+)
+
+// Predicate matches an attribute of a member, see SetAttributes
+type Predicate struct {
+	attribute string
+	values    []string
+	numeric   bool
+	min       float64
+	max       float64
+}
+
+// WithFilter returns only members whose attributes match all predicates
+//
+// The attributes of all members within the radius are read in one HMGET before the limit is applied, so filtered
+// searches read more than unfiltered ones. It is supported by Search, SearchWithEncoder, SearchByGeohashPrefix and
+// the searches of native buckets.
+func WithFilter(predicates ...Predicate) SearchOption {
+	return func(o *searchOptions) {
+		o.filters = append(o.filters, predicates...)
+	}
+}
+
+// Equal matches members whose attribute is value
+func Equal(attribute, value string) Predicate {
+	return Predicate{attribute: attribute, values: []string{value}}
+}
+
+// In matches members whose attribute is one of values
+func In(attribute string, values ...string) Predicate {
+	return Predicate{attribute: attribute, values: values}
+}
+
+// Between matches members whose attribute is a number between min and max, both inclusive
+func Between(attribute string, min, max float64) Predicate {
+	return Predicate{attribute: attribute, numeric: true, min: min, max: max}
+}
+
+// String formats the predicate like "vehicle in [bike car]" or "load between 0 and 10"
+func (p Predicate) String() string {
+	if p.numeric {
+		return fmt.Sprintf("%s between %g and %g", p.attribute, p.min, p.max)
+	}
+
+	return fmt.Sprintf("%s in %q", p.attribute, p.values)
+}
+
+func (p Predicate) match(attributes map[string]string) bool {
+	actual, ok := attributes[p.attribute]
+	if !ok {
+		return false
+	}
+	if !p.numeric {
+		return slices.Contains(p.values, actual)
+	}
+
+	value, err := strconv.ParseFloat(actual, 64)
+	return err == nil && value >= p.min && value <= p.max
+}
+
+// rankLimit is the limit results are ranked with, filtered results are limited after filtering
+func (o searchOptions) rankLimit() int {
+	if len(o.filters) > 0 {
+		return -1
+	}
+
+	return o.limit
+}
+
+// filterResults drops the results whose attributes don't match the filters of opts and applies the limit, the
+// attributes of the others are kept if they are searched WithAttributes
+func filterResults(client *redis.Client, bucketName string, results []Result, opts searchOptions) ([]Result, error) {
+	if len(opts.filters) == 0 {
+		return results, nil
+	}
+
+	labels := make([]string, len(results))
+	for idx := range results {
+		labels[idx] = results[idx].Label
+	}
+	attributes, err := GetAttributes(client, bucketName, labels...)
+	if err != nil {
+		return []Result{}, err
+	}
+
+	matches := results[:0]
+	for idx := range results {
+		if opts.limit >= 0 && len(matches) == opts.limit {
+			break
+		}
+		if !opts.matches(attributes[idx]) {
+			continue
+		}
+		if opts.withAttributes {
+			results[idx].Attributes = attributes[idx]
+		}
+		matches = append(matches, results[idx])
+	}
+
+	return matches, nil
+}
+
+func (o searchOptions) matches(attributes map[string]string) bool {
+	for _, predicate := range o.filters {
+		if !predicate.match(attributes) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,47 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestWithFilter(t *testing.T) {
+	const zSetFilter = "test:filter"
+
+	client.Del(zSetFilter, zSetFilter+":attributes")
+	AddCoordinates(client, zSetFilter, bitDepth,
+		GeoKey{Label: "anna", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "ben", Lat: 52.521, Lon: 13.405},
+		GeoKey{Label: "carl", Lat: 52.522, Lon: 13.405},
+		GeoKey{Label: "dora", Lat: 52.523, Lon: 13.405},
+	)
+	SetAttributes(client, zSetFilter, "anna", map[string]string{"vehicle": "car", "status": "idle", "load": "2"})
+	SetAttributes(client, zSetFilter, "ben", map[string]string{"vehicle": "bike", "status": "busy", "load": "5"})
+	SetAttributes(client, zSetFilter, "carl", map[string]string{"vehicle": "bike", "status": "idle", "load": "1"})
+	SetAttributes(client, zSetFilter, "dora", map[string]string{"vehicle": "bike", "status": "idle", "load": "9"})
+
+	results, err := Search(client, zSetFilter, 52.52, 13.405, 1000, bitDepth,
+		WithFilter(Equal("vehicle", "bike"), Equal("status", "idle")), WithLimit(1))
+	if err != nil || len(results) != 1 || results[0].Label != "carl" {
+		t.Logf("expected the nearest idle bike got %v, %v\n", results, err)
+		t.Fail()
+	}
+
+	results, err = Search(client, zSetFilter, 52.52, 13.405, 1000, bitDepth,
+		WithFilter(In("status", "idle", "away"), Between("load", 0, 5)), WithAttributes())
+	if err != nil || len(results) != 2 || results[0].Label != "anna" || results[1].Attributes["vehicle"] != "bike" {
+		t.Logf("expected anna and carl with their attributes got %v, %v\n", results, err)
+		t.Fail()
+	}
+
+	if results, err := Search(client, zSetFilter, 52.52, 13.405, 1000, bitDepth, WithFilter(Equal("color", "red"))); err != nil || len(results) != 0 {
+		t.Logf("expected no results got %v, %v\n", results, err)
+		t.Fail()
+	}
+}
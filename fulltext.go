@@ -0,0 +1,239 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/redis.v2"
+)
+
+// maxQueryResults caps the results of a text query without a limit, it is the default MAXSEARCHRESULTS of
+// RediSearch
+const maxQueryResults = 10000
+
+// SearchDocument is a member with text fields indexed by RediSearch
+type SearchDocument struct {
+	GeoKey
+	Fields map[string]string
+}
+
+// CreateSearchIndex creates the RediSearch index of a bucket with a GEO field and the text fields
+//
+// The index covers the hashes written by IndexMembers, redis answers with an error if it exists already.
+func CreateSearchIndex(client *redis.Client, bucketName string, textFields ...string) error {
+	args := []string{"FT.CREATE", searchIndexName(bucketName), "ON", "HASH", "PREFIX", "1", searchDocumentPrefix(bucketName),
+		"SCHEMA", "location", "GEO", "label", "TAG"}
+	for _, field := range textFields {
+		args = append(args, field, "TEXT")
+	}
+
+	cmd := redis.NewCmd(args...)
+	client.Process(cmd)
+
+	return cmd.Err()
+}
+
+// DropSearchIndex drops the RediSearch index of a bucket, the indexed hashes are kept
+func DropSearchIndex(client *redis.Client, bucketName string) error {
+	cmd := redis.NewCmd("FT.DROPINDEX", searchIndexName(bucketName))
+	client.Process(cmd)
+
+	return cmd.Err()
+}
+
+// IndexMembers adds the documents to the set like AddCoordinates and writes their coordinates and text fields to a
+// hash per member, which RediSearch indexes, in the same transaction
+func IndexMembers(client *redis.Client, bucketName string, bitDepth uint8, documents ...SearchDocument) (int64, error) {
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return 0, err
+	}
+	if len(documents) == 0 {
+		return 0, nil
+	}
+
+	members := make([]redis.Z, len(documents))
+	for idx, document := range documents {
+		if err := ValidateCoordinates(document.GeoKey); err != nil {
+			return 0, err
+		}
+		members[idx] = redis.Z{
+			Score:  float64(geohashEncoder{bitDepth: bitDepth}.EncodeInt(document.Lat, document.Lon)),
+			Member: document.Label,
+		}
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	var added *redis.IntCmd
+	_, err := multi.Exec(func() error {
+		added = multi.ZAdd(bucketName, members...)
+		for _, document := range documents {
+			key := searchDocumentPrefix(bucketName) + document.Label
+			fields := []string{
+				"label", document.Label,
+				"location", strconv.FormatFloat(document.Lon, 'f', -1, 64) + "," + strconv.FormatFloat(document.Lat, 'f', -1, 64),
+			}
+			for name, value := range document.Fields {
+				fields = append(fields, name, value)
+			}
+			multi.Del(key)
+			multi.HMSet(key, fields[0], fields[1], fields[2:]...)
+			if document.Payload != nil {
+				multi.HSet(payloadKey(bucketName), document.Label, string(document.Payload))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return added.Val(), nil
+}
+
+// UnindexMembers removes members like RemoveCoordinatesByKeys together with their indexed hashes
+func UnindexMembers(client *redis.Client, bucketName string, labels ...string) (int64, error) {
+	removed, err := RemoveCoordinatesByKeys(client, bucketName, labels...)
+	if err != nil {
+		return removed, err
+	}
+
+	keys := make([]string, len(labels))
+	for idx := range labels {
+		keys[idx] = searchDocumentPrefix(bucketName) + labels[idx]
+	}
+
+	return removed, client.Del(keys...).Err()
+}
+
+// SearchByRadiusWithQuery returns the members within radius of lat & lon whose text fields match textQuery, nearest
+// first, in a single RediSearch aggregation
+//
+// textQuery uses the RediSearch query syntax, like "vegan" or "@cuisine:pizza". The radius and distances are in
+// meters unless WithUnit is used, WithLimit, WithPayloads and WithMotion are supported. Without a limit at most
+// 10000 results are returned.
+func SearchByRadiusWithQuery(client *redis.Client, bucketName string, lat, lon, radius float64, textQuery string, options ...SearchOption) ([]Result, error) {
+	opts := newSearchOptions(options)
+	radius = opts.unit.ToMeters(radius)
+
+	if err := validateLatLon(lat, lon); err != nil {
+		return []Result{}, &CoordinateError{Key: GeoKey{Lat: lat, Lon: lon}, Err: err}
+	}
+	if !(radius > 0 && radius <= MaxRadius) {
+		return []Result{}, ErrInvalidRadius
+	}
+	limit := opts.limit
+	if limit < 0 || limit > maxQueryResults {
+		limit = maxQueryResults
+	}
+	if limit == 0 {
+		return []Result{}, nil
+	}
+
+	latValue, lonValue := strconv.FormatFloat(lat, 'f', -1, 64), strconv.FormatFloat(lon, 'f', -1, 64)
+	query := fmt.Sprintf("@location:[%s %s %s m]", lonValue, latValue, strconv.FormatFloat(radius, 'f', -1, 64))
+	if textQuery = strings.TrimSpace(textQuery); textQuery != "" {
+		query += " (" + textQuery + ")"
+	}
+
+	cmd := redis.NewCmd("FT.AGGREGATE", searchIndexName(bucketName), query,
+		"LOAD", "2", "@label", "@location",
+		"APPLY", "geodistance(@location, "+lonValue+", "+latValue+")", "AS", "distance",
+		"SORTBY", "2", "@distance", "ASC",
+		"LIMIT", "0", strconv.Itoa(limit),
+	)
+	client.Process(cmd)
+	reply, err := cmd.Result()
+	if err != nil {
+		return []Result{}, err
+	}
+
+	results, err := parseAggregateReply(reply)
+	if err != nil {
+		return []Result{}, err
+	}
+	convertDistances(results, opts.unit)
+	attachGeohashes(results, opts.geohashPrecision)
+
+	if opts.withPayloads {
+		if err := attachPayloads(client, bucketName, results); err != nil {
+			return []Result{}, err
+		}
+	}
+	if opts.withMotion {
+		if err := attachMotion(client, bucketName, results); err != nil {
+			return []Result{}, err
+		}
+	}
+	if opts.withAttributes {
+		if err := attachAttributes(client, bucketName, results); err != nil {
+			return []Result{}, err
+		}
+	}
+
+	return results, nil
+}
+
+// parseAggregateReply parses the rows of FT.AGGREGATE, each a flat list of the loaded fields and their values
+func parseAggregateReply(reply interface{}) ([]Result, error) {
+	rows, ok := reply.([]interface{})
+	if !ok || len(rows) == 0 {
+		return []Result{}, fmt.Errorf("unexpected aggregate reply %v", reply)
+	}
+
+	results := make([]Result, 0, len(rows)-1)
+	// the first element is the number of matches
+	for _, row := range rows[1:] {
+		fields, ok := row.([]interface{})
+		if !ok || len(fields)%2 != 0 {
+			return []Result{}, fmt.Errorf("unexpected aggregate row %v", row)
+		}
+
+		values := map[string]string{}
+		for idx := 0; idx < len(fields); idx += 2 {
+			name, _ := fields[idx].(string)
+			values[name], _ = fields[idx+1].(string)
+		}
+
+		lonValue, latValue, found := strings.Cut(values["location"], ",")
+		if !found {
+			return []Result{}, fmt.Errorf("unexpected location %q", values["location"])
+		}
+		result := Result{Label: values["label"]}
+		var err error
+		if result.Lat, err = strconv.ParseFloat(latValue, 64); err != nil {
+			return []Result{}, err
+		}
+		if result.Lon, err = strconv.ParseFloat(lonValue, 64); err != nil {
+			return []Result{}, err
+		}
+		if result.Distance, err = strconv.ParseFloat(values["distance"], 64); err != nil {
+			return []Result{}, err
+		}
+		results = append(results, result)
+	}
+
+	// rows with the same distance come back in any order
+	slices.SortFunc(results, func(a, b Result) int {
+		return cmp.Or(byDistance(a, b), cmp.Compare(a.Label, b.Label))
+	})
+
+	return results, nil
+}
+
+func searchIndexName(bucketName string) string {
+	return bucketName + ":ft"
+}
+
+func searchDocumentPrefix(bucketName string) string {
+	return bucketName + ":doc:"
+}
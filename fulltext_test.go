@@ -0,0 +1,52 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSearchByRadiusWithQuery(t *testing.T) {
+	const zSetFullText = "test:fulltext"
+
+	DropSearchIndex(client, zSetFullText)
+	UnindexMembers(client, zSetFullText, "vegan", "meat", "far")
+	if err := CreateSearchIndex(client, zSetFullText, "description"); err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "unknown command") {
+			t.Skip("redis runs without RediSearch")
+		}
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	_, err := IndexMembers(client, zSetFullText, bitDepth,
+		SearchDocument{GeoKey: GeoKey{Label: "vegan", Lat: 52.52, Lon: 13.405}, Fields: map[string]string{"description": "vegan pizza"}},
+		SearchDocument{GeoKey: GeoKey{Label: "meat", Lat: 52.521, Lon: 13.405}, Fields: map[string]string{"description": "salami pizza"}},
+		SearchDocument{GeoKey: GeoKey{Label: "far", Lat: 48.85, Lon: 2.35}, Fields: map[string]string{"description": "vegan pizza"}},
+	)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	// documents are indexed asynchronously
+	time.Sleep(100 * time.Millisecond)
+
+	results, err := SearchByRadiusWithQuery(client, zSetFullText, 52.52, 13.405, 2, "vegan", WithUnit(Kilometers))
+	if err != nil || len(results) != 1 || results[0].Label != "vegan" {
+		t.Logf("expected the vegan place nearby got %v error %v\n", results, err)
+		t.Fail()
+	}
+
+	results, err = SearchByRadiusWithQuery(client, zSetFullText, 52.52, 13.405, 2000, "pizza")
+	if err != nil || len(results) != 2 || results[0].Label != "vegan" || results[1].Label != "meat" {
+		t.Logf("expected both places nearby, nearest first got %v error %v\n", results, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,109 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/redis.v2"
+)
+
+// functionsVersion is bumped whenever the function library changes, every version is loaded as its own
+// library so clients of different versions can share a redis during rolling deployments
+const functionsVersion = 1
+
+var (
+	functionLibrary = fmt.Sprintf("georedis_v%d", functionsVersion)
+	searchFunction  = fmt.Sprintf("georedis_search_v%d", functionsVersion)
+
+	functionLibraryCode = fmt.Sprintf(`#!lua name=%s
+local function search(KEYS, ARGV)
+%s
+end
+
+redis.register_function{function_name='%s', callback=search, flags={'no-writes'}}
+`, functionLibrary, searchLuaBody, searchFunction)
+)
+
+// LoadFunctions installs the function library used by SearchByRadiusFunction, replacing a library of the same version
+//
+// Calling it is optional as SearchByRadiusFunction loads the library on demand. Requires redis 7 or newer.
+func LoadFunctions(client *redis.Client) error {
+	cmd := redis.NewCmd("FUNCTION", "LOAD", "REPLACE", functionLibraryCode)
+	client.Process(cmd)
+	return cmd.Err()
+}
+
+// PruneFunctions deletes function libraries installed by older versions of this package
+func PruneFunctions(client *redis.Client) error {
+	cmd := redis.NewCmd("FUNCTION", "LIST", "LIBRARYNAME", "georedis_v*")
+	client.Process(cmd)
+	libraries, err := cmd.Result()
+	if err != nil {
+		return err
+	}
+
+	list, _ := libraries.([]interface{})
+	for _, library := range list {
+		name := functionLibraryName(library)
+		version, err := strconv.Atoi(strings.TrimPrefix(name, "georedis_v"))
+		if err != nil || version >= functionsVersion {
+			continue
+		}
+
+		del := redis.NewCmd("FUNCTION", "DELETE", name)
+		client.Process(del)
+		if err := del.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SearchByRadiusFunction works like SearchByRadiusServerSide but calls a function installed with FUNCTION LOAD
+// instead of a script, the library is (re)registered automatically when redis doesn't know it
+func SearchByRadiusFunction(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, limit int) ([]Result, error) {
+	ranges, err := queryRanges(lat, lon, radius, bitDepth)
+	if err != nil {
+		return []Result{}, err
+	}
+
+	args := append([]string{"FCALL_RO", searchFunction, "1", bucketName}, searchScriptArgs(lat, lon, radius, bitDepth, limit, ranges)...)
+
+	cmd := redis.NewCmd(args...)
+	client.Process(cmd)
+	reply, err := cmd.Result()
+	if err != nil && strings.Contains(err.Error(), "Function not found") {
+		if err := LoadFunctions(client); err != nil {
+			return []Result{}, err
+		}
+
+		cmd = redis.NewCmd(args...)
+		client.Process(cmd)
+		reply, err = cmd.Result()
+	}
+	if err != nil {
+		return []Result{}, err
+	}
+
+	return parseSearchReply(reply)
+}
+
+// functionLibraryName extracts library_name from a FUNCTION LIST entry
+func functionLibraryName(library interface{}) string {
+	fields, _ := library.([]interface{})
+	for idx := 0; idx+1 < len(fields); idx += 2 {
+		if key, _ := fields[idx].(string); key == "library_name" {
+			name, _ := fields[idx+1].(string)
+			return name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,36 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSearchByRadiusFunction(t *testing.T) {
+	const zSetFunction = "test:search:function"
+
+	client.Del(zSetFunction)
+	AddCoordinates(client, zSetFunction, bitDepth,
+		GeoKey{Lat: 39.9523, Lon: -75.1638, Label: "Philadelphia"},
+		GeoKey{Lat: 40.7128, Lon: -74.0060, Label: "New York"},
+	)
+
+	results, err := SearchByRadiusFunction(client, zSetFunction, 39.9523, -75.1638, 5000, bitDepth, -1)
+	if err != nil {
+		t.Skipf("functions not supported: %q\n", err)
+	}
+	if len(results) != 1 || results[0].Label != "Philadelphia" {
+		t.Logf("unexpected results %v\n", results)
+		t.Fail()
+	}
+
+	if err := PruneFunctions(client); err != nil {
+		t.Logf("error encountered: %q\n", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,211 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"gopkg.in/redis.v2"
+)
+
+// base32Alphabet is the alphabet of classic geohash strings, every character holds 5 bits
+const base32Alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// MaxGeohashPrecision is the longest geohash string, 60 bits
+const MaxGeohashPrecision = 12
+
+// ErrInvalidGeohash is returned for geohash strings which are empty, too long or contain characters outside the
+// geohash alphabet
+var ErrInvalidGeohash = errors.New("invalid geohash")
+
+// GeohashKey is a GeoKey located by a base32 geohash string instead of coordinates
+type GeohashKey struct {
+	Label   string
+	Geohash string
+	Payload []byte
+}
+
+// WithGeohash sets the Geohash of each result to its base32 geohash string of precision characters
+func WithGeohash(precision int) SearchOption {
+	return func(o *searchOptions) {
+		o.geohashPrecision = precision
+	}
+}
+
+// EncodeGeohash returns the base32 geohash string of precision characters of a coordinate
+func EncodeGeohash(lat, lon float64, precision int) (string, error) {
+	if precision < 1 || precision > MaxGeohashPrecision {
+		return "", ErrInvalidGeohash
+	}
+	if err := validateLatLon(lat, lon); err != nil {
+		return "", &CoordinateError{Key: GeoKey{Lat: lat, Lon: lon}, Err: err}
+	}
+
+	return geohashString(lat, lon, precision), nil
+}
+
+// DecodeGeohash returns the center of the cell of a base32 geohash string
+func DecodeGeohash(hash string) (float64, float64, error) {
+	cell, bits, err := parseGeohash(hash)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if bits%2 == 0 {
+		lat, lon := geohashEncoder{bitDepth: bits}.DecodeInt(cell)
+		return lat, lon, nil
+	}
+
+	// the last bit of an odd hash halves the longitude, the center lies between its two halves split by latitude
+	cells := geohashEncoder{bitDepth: bits + 1}
+	southLat, lon := cells.DecodeInt(cell << 1)
+	northLat, _ := cells.DecodeInt(cell<<1 | 1)
+
+	return (southLat + northLat) / 2, lon, nil
+}
+
+// AddByGeohash adds members located by base32 geohash strings to the set like AddCoordinates
+//
+// Members are stored at the center of their geohash cell, hashes longer than the bit depth lose their extra
+// precision.
+func AddByGeohash(client *redis.Client, bucketName string, bitDepth uint8, keys ...GeohashKey) (int64, error) {
+	coordinates, err := geohashCoordinates(keys)
+	if err != nil {
+		return 0, err
+	}
+
+	return AddCoordinates(client, bucketName, bitDepth, coordinates...)
+}
+
+// SearchByGeohashPrefix returns all members within the cell of a base32 geohash prefix, nearest to the center of
+// the cell first
+//
+// The cell has to be at least as large as the cells of the bucket, prefixes of more than bitDepth bits return
+// ErrBitDepthTooLow.
+func SearchByGeohashPrefix(client *redis.Client, bucketName string, bitDepth uint8, prefix string, options ...SearchOption) ([]Result, error) {
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return []Result{}, err
+	}
+	cell, bits, err := parseGeohash(prefix)
+	if err != nil {
+		return []Result{}, err
+	}
+	if bits > bitDepth {
+		return []Result{}, ErrBitDepthTooLow
+	}
+	lat, lon, err := DecodeGeohash(prefix)
+	if err != nil {
+		return []Result{}, err
+	}
+
+	opts := newSearchOptions(options)
+	shift := bitDepth - bits
+	ranges := []geoRange{{
+		Lower: float64(cell << shift),
+		Upper: float64((cell+1)<<shift - 1),
+	}}
+
+	var candidates []redis.Z
+	var fetchErr error
+	if since := opts.since(); !since.IsZero() {
+		if candidates, err = fetchFreshRanges(client, bucketName, bucketName, ranges, since); err != nil {
+			releaseCandidates(candidates)
+			return []Result{}, err
+		}
+	} else if candidates, fetchErr = fetchRanges(client, bucketName, ranges, opts.failFast); fetchErr != nil && opts.failFast {
+		releaseCandidates(candidates)
+		return []Result{}, fetchErr
+	}
+	candidates = matchCandidates(candidates, opts.labelMatch)
+	results := rankResults(lat, lon, math.Inf(1), bitDepth, candidates, opts.rankLimit(), opts.distance)
+	releaseCandidates(candidates)
+	if results, err = completeResults(client, bucketName, results, opts); err != nil {
+		return []Result{}, err
+	}
+
+	return results, fetchErr
+}
+
+// AddByGeohash adds members located by base32 geohash strings to the set like AddCoordinates
+func (c *GeoClient) AddByGeohash(bucketName string, keys ...GeohashKey) (int64, error) {
+	return handleOp(c, Operation{Name: "AddByGeohash", Bucket: bucketName, Write: true, Args: []any{keys}}, func() (int64, error) {
+		coordinates, err := geohashCoordinates(keys)
+		if err != nil {
+			return 0, err
+		}
+
+		return c.AddCoordinates(bucketName, coordinates...)
+	})
+}
+
+// SearchByGeohashPrefix returns all members within the cell of a base32 geohash prefix
+func (c *GeoClient) SearchByGeohashPrefix(bucketName, prefix string, options ...SearchOption) ([]Result, error) {
+	return handleOp(c, Operation{Name: "SearchByGeohashPrefix", Bucket: bucketName, Args: []any{prefix, options}}, func() ([]Result, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() ([]Result, error) {
+			return SearchByGeohashPrefix(c.reader(key), key, c.bitDepth, prefix, options...)
+		})
+	})
+}
+
+func geohashCoordinates(keys []GeohashKey) ([]GeoKey, error) {
+	coordinates := make([]GeoKey, len(keys))
+	for idx := range keys {
+		lat, lon, err := DecodeGeohash(keys[idx].Geohash)
+		if err != nil {
+			return []GeoKey{}, err
+		}
+		coordinates[idx] = GeoKey{Lat: lat, Lon: lon, Label: keys[idx].Label, Payload: keys[idx].Payload}
+	}
+
+	return coordinates, nil
+}
+
+// parseGeohash returns the integer cell of a base32 geohash string and its number of bits
+func parseGeohash(hash string) (uint64, uint8, error) {
+	if hash == "" || len(hash) > MaxGeohashPrecision {
+		return 0, 0, ErrInvalidGeohash
+	}
+
+	var cell uint64
+	for _, char := range strings.ToLower(hash) {
+		value := strings.IndexRune(base32Alphabet, char)
+		if value < 0 {
+			return 0, 0, ErrInvalidGeohash
+		}
+		cell = cell<<5 | uint64(value)
+	}
+
+	return cell, uint8(len(hash) * 5), nil
+}
+
+func geohashString(lat, lon float64, precision int) string {
+	bits := uint8(precision * 5)
+	// the integer geohash is encoded with an even depth and cut to the bits of the string
+	hash := geohashEncoder{bitDepth: bits + bits%2}.EncodeInt(lat, lon) >> (bits % 2)
+
+	chars := make([]byte, precision)
+	for idx := precision - 1; idx >= 0; idx-- {
+		chars[idx] = base32Alphabet[hash&31]
+		hash >>= 5
+	}
+
+	return string(chars)
+}
+
+// attachGeohashes sets the geohash strings of the results unless precision is 0
+func attachGeohashes(results []Result, precision int) {
+	if precision <= 0 {
+		return
+	}
+	precision = min(precision, MaxGeohashPrecision)
+
+	for idx := range results {
+		results[idx].Geohash = geohashString(results[idx].Lat, results[idx].Lon, precision)
+	}
+}
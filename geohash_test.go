@@ -0,0 +1,73 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestGeohashStrings(t *testing.T) {
+	for precision, expected := range map[int]string{1: "u", 4: "u4pr", 5: "u4pru", 10: "u4pruydqqv"} {
+		hash, err := EncodeGeohash(57.64911, 10.40744, precision)
+		if err != nil || hash != expected {
+			t.Logf("expected %q got %q, %v\n", expected, hash, err)
+			t.Fail()
+		}
+
+		lat, lon, err := DecodeGeohash(hash)
+		if err != nil || math.Abs(lat-57.64911) > 45.0/float64(precision*precision) ||
+			math.Abs(lon-10.40744) > 45.0/float64(precision*precision) {
+			t.Logf("expected the center of %q near the coordinate got %f, %f, %v\n", hash, lat, lon, err)
+			t.Fail()
+		}
+	}
+
+	for _, hash := range []string{"", "u4pa", "u4pruydqqvj00"} {
+		if _, _, err := DecodeGeohash(hash); err != ErrInvalidGeohash {
+			t.Logf("expected ErrInvalidGeohash for %q got %v\n", hash, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestSearchByGeohashPrefix(t *testing.T) {
+	const zSetGeohash = "test:geohash"
+
+	client.Del(zSetGeohash)
+	_, err := AddByGeohash(client, zSetGeohash, bitDepth,
+		GeohashKey{Label: "inside1", Geohash: "u33dc0"},
+		GeohashKey{Label: "inside2", Geohash: "u33dbf"},
+		GeohashKey{Label: "outside", Geohash: "u33e00"},
+	)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	results, err := SearchByGeohashPrefix(client, zSetGeohash, bitDepth, "u33d", WithGeohash(6))
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if len(results) != 2 {
+		t.Logf("expected 2 results got %v\n", results)
+		t.FailNow()
+	}
+	for _, result := range results {
+		if result.Geohash != "u33dc0" && result.Geohash != "u33dbf" {
+			t.Logf("unexpected geohash %q of %s\n", result.Geohash, result.Label)
+			t.Fail()
+		}
+	}
+
+	if _, err := SearchByGeohashPrefix(client, zSetGeohash, 10, "u33d"); err != ErrBitDepthTooLow {
+		t.Logf("expected ErrBitDepthTooLow got %v\n", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,89 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+type (
+	// GeoJSONGeometry is the GeoJSON representation of a Geometry
+	GeoJSONGeometry struct {
+		Type        string      `json:"type"`
+		Coordinates interface{} `json:"coordinates"`
+	}
+
+	// GeoJSONFeature is a GeoJSON feature
+	GeoJSONFeature struct {
+		Type       string                 `json:"type"`
+		Geometry   GeoJSONGeometry        `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	}
+
+	// GeoJSONFeatureCollection is a GeoJSON feature collection
+	GeoJSONFeatureCollection struct {
+		Type     string           `json:"type"`
+		Features []GeoJSONFeature `json:"features"`
+	}
+)
+
+// ToGeoJSON converts a Geometry to its GeoJSON representation
+func ToGeoJSON(geometry Geometry) GeoJSONGeometry {
+	var coordinates interface{}
+
+	switch g := geometry.(type) {
+	case Point:
+		coordinates = geoJSONPosition(g)
+	case LineString:
+		coordinates = geoJSONPositions(g)
+	case Polygon:
+		coordinates = geoJSONRings(g)
+	case MultiPolygon:
+		polygons := make([][][][2]float64, len(g))
+		for idx := range g {
+			polygons[idx] = geoJSONRings(g[idx])
+		}
+		coordinates = polygons
+	}
+
+	return GeoJSONGeometry{Type: geometry.geometryType(), Coordinates: coordinates}
+}
+
+// ResultsToGeoJSON converts search results to a feature collection of points
+// with the label and distance as properties
+func ResultsToGeoJSON(results []Result) GeoJSONFeatureCollection {
+	features := make([]GeoJSONFeature, len(results))
+	for idx := range results {
+		features[idx] = GeoJSONFeature{
+			Type:     "Feature",
+			Geometry: ToGeoJSON(Point{Lat: results[idx].Lat, Lon: results[idx].Lon}),
+			Properties: map[string]interface{}{
+				"label":    results[idx].Label,
+				"distance": results[idx].Distance,
+			},
+		}
+	}
+
+	return GeoJSONFeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+func geoJSONPosition(point Point) [2]float64 {
+	return [2]float64{point.Lon, point.Lat}
+}
+
+func geoJSONPositions(points []Point) [][2]float64 {
+	positions := make([][2]float64, len(points))
+	for idx := range points {
+		positions[idx] = geoJSONPosition(points[idx])
+	}
+
+	return positions
+}
+
+func geoJSONRings(polygon Polygon) [][][2]float64 {
+	rings := make([][][2]float64, len(polygon))
+	for idx := range polygon {
+		rings[idx] = geoJSONPositions(polygon[idx])
+	}
+
+	return rings
+}
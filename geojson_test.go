@@ -0,0 +1,42 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestResultsToGeoJSON(t *testing.T) {
+	collection := ResultsToGeoJSON([]Result{{Label: "Philadelphia", Lat: 39.9523, Lon: -75.1638, Distance: 12}})
+
+	encoded, err := json.Marshal(collection)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	expected := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[-75.1638,39.9523]},"properties":{"distance":12,"label":"Philadelphia"}}]}`
+	if string(encoded) != expected {
+		t.Logf("expected %s got %s\n", expected, encoded)
+		t.Fail()
+	}
+}
+
+func TestToGeoJSONPolygon(t *testing.T) {
+	geometry := ToGeoJSON(Polygon{{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 1}, {Lat: 1, Lon: 1}, {Lat: 0, Lon: 0}}})
+	if geometry.Type != "Polygon" {
+		t.Logf("expected type Polygon got %s\n", geometry.Type)
+		t.Fail()
+	}
+	rings, ok := geometry.Coordinates.([][][2]float64)
+	if !ok || len(rings) != 1 || rings[0][1] != [2]float64{1, 0} {
+		t.Logf("unexpected coordinates %v\n", geometry.Coordinates)
+		t.Fail()
+	}
+}
@@ -0,0 +1,33 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+type (
+	// Geometry is implemented by all the shapes which can be used as query input
+	Geometry interface {
+		geometryType() string
+	}
+
+	// Point is a single location
+	Point struct {
+		Lat float64
+		Lon float64
+	}
+
+	// LineString is an ordered list of points, for example a route
+	LineString []Point
+
+	// Polygon is a list of linear rings, the first one being the exterior ring and the rest holes
+	Polygon [][]Point
+
+	// MultiPolygon is a collection of polygons
+	MultiPolygon []Polygon
+)
+
+func (Point) geometryType() string        { return "Point" }
+func (LineString) geometryType() string   { return "LineString" }
+func (Polygon) geometryType() string      { return "Polygon" }
+func (MultiPolygon) geometryType() string { return "MultiPolygon" }
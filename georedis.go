@@ -7,21 +7,64 @@
 package georedis
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
-	"math"
-	"sort"
-
-	"github.com/tapglue/geohash"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
 
 	"gopkg.in/redis.v2"
 )
 
 type (
 	// GeoKey provides support for encoding a location with a label and coordinates
+	//
+	// A non nil Payload is stored alongside the coordinates in a companion hash
 	GeoKey struct {
-		Lat   float64
-		Lon   float64
-		Label string
+		Lat     float64
+		Lon     float64
+		Label   string
+		Payload []byte
+	}
+
+	// Result is a single search hit with its decoded coordinates and the distance to the search center, in meters
+	// unless the search used WithUnit
+	//
+	// Payload is only set when searching WithPayloads, Motion when searching WithMotion, Geohash when searching
+	// WithGeohash, Attributes when searching WithAttributes and Bucket by SearchByRadiusMultiBucket
+	Result struct {
+		Label      string
+		Lat        float64
+		Lon        float64
+		Distance   float64
+		Payload    []byte
+		Motion     *Motion
+		Geohash    string
+		Attributes map[string]string
+		Bucket     string
+	}
+
+	// SearchOption configures the behavior of Search
+	SearchOption func(*searchOptions)
+
+	searchOptions struct {
+		limit          int
+		withPayloads   bool
+		withMotion     bool
+		withAttributes bool
+		failFast       bool
+		distance       DistanceFunc
+		unit           Unit
+		freshness      time.Duration
+		updatedSince   time.Time
+		tuning         SearchTuning
+		filters        []Predicate
+		labelMatch     string
+		stats          *SearchStats
+
+		geohashPrecision int
 	}
 
 	geoRange struct {
@@ -31,6 +74,10 @@ type (
 )
 
 var (
+	// ErrMemberNotFound is returned when a label is not part of the set
+	ErrMemberNotFound = errors.New("member not found")
+
+	// rangeIndex maps radii in meters to the cell depth which covers them
 	rangeIndex = map[uint8]float64{
 		0:  0.6,      //52
 		1:  1,        //50
@@ -59,8 +106,20 @@ var (
 		24: 10018863, //4
 	}
 	rangeIndexLen = uint8(len(rangeIndex))
+
+	// candidatePool recycles the buffers holding the raw members of a search, it holds pointers so putting them
+	// back doesn't allocate
+	candidatePool = sync.Pool{
+		New: func() interface{} {
+			candidates := make([]redis.Z, 0, 256)
+			return &candidates
+		},
+	}
 )
 
+// maxPooledCandidates keeps exceptionally large candidate buffers from being retained by the pool
+const maxPooledCandidates = 1 << 16
+
 func rangeDepth(radius float64) uint8 {
 	var i uint8
 	for i = 0; i < rangeIndexLen-1; i++ {
@@ -74,188 +133,470 @@ func rangeDepth(radius float64) uint8 {
 
 // AddCoordinates adds coordinates to the set
 func AddCoordinates(client *redis.Client, bucketName string, bitDepth uint8, coordinates ...GeoKey) (int64, error) {
-	encodedCoordinates := make([]redis.Z, len(coordinates))
-
-	for key, value := range coordinates {
-		encodedCoordinate := geohash.EncodeInt(
-			value.Lat,
-			value.Lon,
-			bitDepth,
-		)
-		encodedCoordinates[key] = redis.Z{
-			Score:  float64(encodedCoordinate),
-			Member: value.Label,
-		}
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return 0, err
 	}
 
-	return client.ZAdd(bucketName, encodedCoordinates...).Result()
+	return AddCoordinatesWithEncoder(client, bucketName, geohashEncoder{bitDepth: bitDepth}, coordinates...)
 }
 
-// RemoveCoordinatesByKeys removes coordinates from the set
+// RemoveCoordinatesByKeys removes coordinates, their payloads, attributes, tags, last seen times and motions from
+// the set
 func RemoveCoordinatesByKeys(client *redis.Client, bucketName string, coordinatesKeys ...string) (int64, error) {
-	return client.ZRem(bucketName, coordinatesKeys...).Result()
+	// the transaction returns its connection before the tags are cleared, a listener may hold another one of the pool
+	multi := client.Multi()
+	var removed *redis.IntCmd
+	_, err := multi.Exec(func() error {
+		removed = multi.ZRem(bucketName, coordinatesKeys...)
+		multi.HDel(payloadKey(bucketName), coordinatesKeys...)
+		multi.HDel(attributesKey(bucketName), coordinatesKeys...)
+		multi.ZRem(lastSeenKey(bucketName), coordinatesKeys...)
+		multi.HDel(motionKey(bucketName), coordinatesKeys...)
+		return nil
+	})
+	multi.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := clearTags(client, bucketName, coordinatesKeys...); err != nil {
+		return removed.Val(), err
+	}
+
+	return removed.Val(), clearNames(client, bucketName, coordinatesKeys...)
+}
+
+// GetCoordinates returns the decoded coordinates of a label or ErrMemberNotFound
+func GetCoordinates(client *redis.Client, bucketName string, bitDepth uint8, label string) (GeoKey, error) {
+	cmd := client.ZScore(bucketName, label)
+	if cmd.Err() == redis.Nil {
+		return GeoKey{}, ErrMemberNotFound
+	}
+	if cmd.Err() != nil {
+		return GeoKey{}, cmd.Err()
+	}
+
+	lat, lon := geohashEncoder{bitDepth: bitDepth}.DecodeInt(uint64(cmd.Val()))
+	return GeoKey{Lat: lat, Lon: lon, Label: label}, nil
+}
+
+// CountCoordinates returns the number of coordinates in the set
+func CountCoordinates(client *redis.Client, bucketName string) (int64, error) {
+	return client.ZCard(bucketName).Result()
+}
+
+// ListCoordinates returns up to count coordinates from the set starting at offset, in the order they are stored
+func ListCoordinates(client *redis.Client, bucketName string, bitDepth uint8, offset, count int64) ([]GeoKey, error) {
+	if count <= 0 {
+		return []GeoKey{}, nil
+	}
+
+	members, err := client.ZRangeWithScores(bucketName, offset, offset+count-1).Result()
+	if err != nil {
+		return []GeoKey{}, err
+	}
+
+	coordinates := make([]GeoKey, len(members))
+	for idx := range members {
+		lat, lon := geohashEncoder{bitDepth: bitDepth}.DecodeInt(uint64(members[idx].Score))
+		coordinates[idx] = GeoKey{Lat: lat, Lon: lon, Label: members[idx].Member}
+	}
+
+	return coordinates, nil
 }
 
 // SearchByRadius returns all keys which are in a certain range from the provided lat & lon coordinates
+//
+// When some ranges can't be queried the keys of the others are returned with a PartialResultsError.
 func SearchByRadius(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8) ([]string, error) {
-	radiusBitDepth := rangeDepth(radius)
-	ranges, err := getQueryRangesFromBitDepth(lat, lon, radiusBitDepth, bitDepth)
+	ranges, err := queryRanges(lat, lon, radius, bitDepth)
 	if err != nil {
 		return []string{}, err
 	}
 
-	return queryByRanges(client, bucketName, ranges, lat, lon, bitDepth)
+	return queryByRanges(client, bucketName, ranges, lat, lon, radius, bitDepth)
 }
 
-// SearchByRadiusWithLimit returns all keys which are in a certain range from the provided lat & lon coordinates and returns only the first "limit" items
+// SearchByRadiusWithLimit returns all keys which are in a certain range from the provided lat & lon coordinates and returns only the nearest "limit" items (all if limit is negative)
 func SearchByRadiusWithLimit(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, limit int) ([]string, error) {
-	radiusBitDepth := rangeDepth(radius)
-	ranges, err := getQueryRangesFromBitDepth(lat, lon, radiusBitDepth, bitDepth)
+	ranges, err := queryRanges(lat, lon, radius, bitDepth)
 	if err != nil {
 		return []string{}, err
 	}
 
-	return queryByRangesWithLimit(client, bucketName, ranges, lat, lon, bitDepth, limit)
+	return queryByRangesWithLimit(client, bucketName, ranges, lat, lon, radius, bitDepth, limit)
 }
 
-type uint64Slice []uint64
+// cacheKey identifies the options of a search within radius in cache keys, it has to cover every field which
+// changes results, searches with a DistanceFunc other than the builtin ones return false and must not be cached
+func (o searchOptions) cacheKey(radius float64) (string, bool) {
+	distance, ok := distanceKey(o.distance)
+	if !ok {
+		return "", false
+	}
+	// a failing precision fails the search itself, it is never cached
+	depth, _ := o.tuning.precision(radius)
 
-func (p uint64Slice) Len() int           { return len(p) }
-func (p uint64Slice) Less(i, j int) bool { return p[i] < p[j] }
-func (p uint64Slice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+	return fmt.Sprintf("%d:%t:%t:%t:%s:%g:%d:%d:%d:%q:%q:%d:%d:%g", o.limit, o.withPayloads, o.withMotion,
+		o.withAttributes, distance, o.unit, o.freshness, o.updatedSince.Unix(), o.geohashPrecision, o.filters,
+		o.labelMatch, depth, o.tuning.MinCells, o.tuning.MaxOverFetch), true
+}
 
-func getQueryRangesFromBitDepth(lat, lon float64, radiusBitDepth, bitDepth uint8) ([]geoRange, error) {
-	bitDiff := bitDepth - radiusBitDepth
-	if bitDiff < 0 {
-		return []geoRange{}, fmt.Errorf("bitDepth must be high enough to calculate range within radius")
+// since returns the earliest last seen time of results, zero when they aren't filtered by it
+func (o searchOptions) since() time.Time {
+	if o.freshness <= 0 {
+		return o.updatedSince
+	}
+	if fresh := time.Now().Add(-o.freshness); fresh.After(o.updatedSince) {
+		return fresh
 	}
 
-	hash := geohash.EncodeInt(lat, lon, radiusBitDepth)
-	neighbors := geohash.EncodeNeighborsInt(hash, radiusBitDepth)
+	return o.updatedSince
+}
 
-	neighbors = append(neighbors, hash)
-	sort.Sort(uint64Slice(neighbors))
+// WithLimit returns only the nearest "limit" items, a negative limit returns all of them
+func WithLimit(limit int) SearchOption {
+	return func(o *searchOptions) {
+		o.limit = limit
+	}
+}
 
-	if radiusBitDepth <= 4 {
-		neighbors = uniqueInSlice(neighbors)
+func newSearchOptions(options []SearchOption) searchOptions {
+	opts := searchOptions{limit: -1, distance: Haversine, unit: Meters}
+	for _, option := range options {
+		option(&opts)
 	}
 
-	ranges := []geoRange{}
+	return opts
+}
 
-	for i := 0; i < len(neighbors); i++ {
-		lowerRange := float64(neighbors[i])
-		upperRange := lowerRange + 1
+// Search returns all members which are in a certain range from the provided lat & lon coordinates, nearest first,
+// together with their coordinates and distance
+//
+// The radius and distances are in meters unless WithUnit is used.
+func Search(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, options ...SearchOption) ([]Result, error) {
+	return searchKey(client, bucketName, bucketName, lat, lon, radius, bitDepth, newSearchOptions(options))
+}
 
-		for len(neighbors) > i+1 && float64(neighbors[i+1]) == upperRange {
-			neighbors = neighbors[1:]
-			upperRange = float64(neighbors[i] + 1)
-		}
+// searchKey searches the members of key, a bucket or a subset of its members, and reads their payloads, last seen
+// times, motions and attributes from bucketName
+func searchKey(client *redis.Client, key, bucketName string, lat, lon, radius float64, bitDepth uint8, opts searchOptions) ([]Result, error) {
+	radius = opts.unit.ToMeters(radius)
+	lap := opts.startLaps()
 
-		ranges = append(ranges, geoRange{Lower: lowerRange, Upper: upperRange})
+	ranges, _, err := planRanges(lat, lon, radius, bitDepth, opts.tuning)
+	if err != nil {
+		return []Result{}, err
+	}
+	planned := opts.lap(&lap)
+
+	var candidates []redis.Z
+	var fetchErr error
+	if since := opts.since(); !since.IsZero() {
+		if candidates, err = fetchFreshRanges(client, key, bucketName, ranges, since); err != nil {
+			releaseCandidates(candidates)
+			return []Result{}, err
+		}
+	} else if candidates, fetchErr = fetchRanges(client, key, ranges, opts.failFast); fetchErr != nil && opts.failFast {
+		releaseCandidates(candidates)
+		return []Result{}, fetchErr
 	}
+	fetched, candidateCount := opts.lap(&lap), len(candidates)
 
-	for key := range ranges {
-		ranges[key].Lower = leftShift(ranges[key].Lower, bitDiff)
-		ranges[key].Upper = leftShift(ranges[key].Upper, bitDiff)
+	candidates = matchCandidates(candidates, opts.labelMatch)
+	results := rankResults(lat, lon, radius, bitDepth, candidates, opts.rankLimit(), opts.distance)
+	releaseCandidates(candidates)
+	ranked := opts.lap(&lap)
+
+	if results, err = completeResults(client, bucketName, results, opts); err != nil {
+		return []Result{}, err
+	}
+	if opts.stats != nil {
+		*opts.stats = SearchStats{
+			Ranges: len(ranges), Candidates: candidateCount,
+			Plan: planned, Fetch: fetched, Rank: ranked, Complete: opts.lap(&lap),
+			ranges: ranges,
+		}
 	}
 
-	return ranges, nil
+	return results, fetchErr
 }
 
-func queryByRanges(client *redis.Client, bucketName string, ranges []geoRange, lat, lon float64, depth uint8) ([]string, error) {
-	var results []redis.Z
+// completeResults converts the distances of ranked results, filters them and attaches what opts asks for
+func completeResults(client *redis.Client, bucketName string, results []Result, opts searchOptions) ([]Result, error) {
+	convertDistances(results, opts.unit)
+	attachGeohashes(results, opts.geohashPrecision)
 
-	for key := range ranges {
-		res, err := client.ZRangeByScoreWithScores(
-			bucketName,
-			redis.ZRangeByScore{
-				Min: fmt.Sprintf("%f", ranges[key].Lower),
-				Max: fmt.Sprintf("%f", ranges[key].Upper),
-			},
-		).Result()
-		if err == nil {
-			results = append(results, res...)
+	results, err := filterResults(client, bucketName, results, opts)
+	if err != nil {
+		return []Result{}, err
+	}
+
+	if opts.withPayloads {
+		if err := attachPayloads(client, bucketName, results); err != nil {
+			return []Result{}, err
+		}
+	}
+	if opts.withMotion {
+		if err := attachMotion(client, bucketName, results); err != nil {
+			return []Result{}, err
+		}
+	}
+	if opts.withAttributes && len(opts.filters) == 0 {
+		if err := attachAttributes(client, bucketName, results); err != nil {
+			return []Result{}, err
 		}
 	}
 
-	return sortResults(lat, lon, depth, results, -1), nil
+	return results, nil
 }
 
-func queryByRangesWithLimit(client *redis.Client, bucketName string, ranges []geoRange, lat, lon float64, depth uint8, limit int) ([]string, error) {
-	var results []redis.Z
+func getQueryRangesFromBitDepth(lat, lon float64, radiusBitDepth, bitDepth uint8) ([]geoRange, error) {
+	if err := validateLatLon(lat, lon); err != nil {
+		return []geoRange{}, &CoordinateError{Key: GeoKey{Lat: lat, Lon: lon}, Err: err}
+	}
+
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return []geoRange{}, err
+	}
+	if radiusBitDepth > bitDepth {
+		return []geoRange{}, ErrBitDepthTooLow
+	}
+	bitDiff := bitDepth - radiusBitDepth
 
-	limit64 := int64(limit)
+	cells := geohashEncoder{bitDepth: radiusBitDepth}
 
-	for key := range ranges {
-		res, err := client.ZRangeByScoreWithScores(
-			bucketName,
-			redis.ZRangeByScore{
-				Min:   fmt.Sprintf("%f", ranges[key].Lower),
-				Max:   fmt.Sprintf("%f", ranges[key].Upper),
-				Count: limit64,
-			},
-		).Result()
-		if err == nil {
-			results = append(results, res...)
+	return cellRanges(cells.Neighbors(cells.EncodeInt(lat, lon)), bitDiff), nil
+}
+
+// cellRanges merges cells into ranges of scores, bitDiff bits deeper than the cells
+func cellRanges(cells []uint64, bitDiff uint8) []geoRange {
+	slices.Sort(cells)
+	cells = slices.Compact(cells)
+
+	ranges := make([]geoRange, 0, len(cells))
+
+	for i := 0; i < len(cells); {
+		lowerRange := cells[i]
+		upperRange := lowerRange + 1
+
+		for i++; i < len(cells) && cells[i] == upperRange; i++ {
+			upperRange++
 		}
+
+		ranges = append(ranges, geoRange{
+			Lower: float64(lowerRange << bitDiff),
+			Upper: float64(upperRange << bitDiff),
+		})
 	}
 
-	return sortResults(lat, lon, depth, results, limit), nil
+	return ranges
 }
 
-func uniqueInSlice(slice []uint64) []uint64 {
-	result := []uint64{}
-	used := make(map[uint64]byte, len(slice))
+func queryByRanges(client *redis.Client, bucketName string, ranges []geoRange, lat, lon, radius float64, depth uint8) ([]string, error) {
+	candidates, err := fetchRanges(client, bucketName, ranges, false)
+	defer releaseCandidates(candidates)
+
+	return sortResults(lat, lon, radius, depth, candidates, -1), err
+}
 
-	for key := range slice {
-		if used[slice[key]] == 1 {
+func queryByRangesWithLimit(client *redis.Client, bucketName string, ranges []geoRange, lat, lon, radius float64, depth uint8, limit int) ([]string, error) {
+	candidates, err := fetchRanges(client, bucketName, ranges, false)
+	defer releaseCandidates(candidates)
+
+	return sortResults(lat, lon, radius, depth, candidates, limit), err
+}
+
+// fetchRanges collects the members of all ranges into a pooled buffer, release it with releaseCandidates
+// once the candidates are no longer referenced
+//
+// Ranges are always fetched completely, the members are ordered by geohash and not by distance so cutting a
+// range short at the limit would drop members nearer than the ones kept.
+//
+// Failed ranges are reported as a PartialResultsError, or as the first error when failing fast.
+func fetchRanges(client *redis.Client, bucketName string, ranges []geoRange, failFast bool) ([]redis.Z, error) {
+	results := getCandidates()
+	errs := rangeErrors{failFast: failFast}
+
+	for key := range ranges {
+		res, err := client.ZRangeByScoreWithScores(bucketName, ranges[key].query(0, 0)).Result()
+		if err != nil {
+			if errs.add(ranges[key], err) {
+				break
+			}
 			continue
 		}
-		result = append(result, slice[key])
-		used[slice[key]] = 1
+		results = append(results, res...)
 	}
 
-	return result
+	return results, errs.err()
 }
 
-func leftShift(x float64, shift uint8) float64 {
-	return x * math.Pow(2, float64(shift))
+// getCandidates returns an empty pooled candidate buffer, release it with releaseCandidates
+func getCandidates() []redis.Z {
+	return (*candidatePool.Get().(*[]redis.Z))[:0]
 }
 
-type (
-	labelWithDistance struct {
-		Label    string
-		Distance float64
+func releaseCandidates(candidates []redis.Z) {
+	if cap(candidates) > maxPooledCandidates {
+		return
 	}
-	labelsWithDistance []labelWithDistance
-)
+	clear(candidates)
+	candidates = candidates[:0]
+	candidatePool.Put(&candidates)
+}
 
-func (l labelsWithDistance) Len() int           { return len(l) }
-func (l labelsWithDistance) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
-func (l labelsWithDistance) Less(i, j int) bool { return l[i].Distance < l[j].Distance }
+// query returns the ZRANGEBYSCORE arguments of the range, the bounds are integers so they are formatted without
+// the fractional digits fmt's %f would add
+func (r geoRange) query(offset, count int64) redis.ZRangeByScore {
+	return redis.ZRangeByScore{
+		Min:    strconv.FormatFloat(r.Lower, 'f', -1, 64),
+		Max:    strconv.FormatFloat(r.Upper, 'f', -1, 64),
+		Offset: offset,
+		Count:  count,
+	}
+}
 
-func sortResults(lat, lon float64, depth uint8, points []redis.Z, limit int) []string {
-	if limit == -1 {
-		limit = len(points)
-	} else if limit > len(points) {
+func byDistance(a, b Result) int {
+	return cmp.Compare(a.Distance, b.Distance)
+}
+
+func sortResults(lat, lon, radius float64, depth uint8, points []redis.Z, limit int) []string {
+	results := rankResults(lat, lon, radius, depth, points, limit, Haversine)
+
+	asString := make([]string, len(results))
+	for i := range results {
+		asString[i] = results[i].Label
+	}
+
+	return asString
+}
+
+// rankResults decodes the points within radius and returns the nearest "limit" of them (all if limit is negative)
+// ordered by distance
+func rankResults(lat, lon, radius float64, depth uint8, points []redis.Z, limit int, distance DistanceFunc) []Result {
+	return rankEncoded(lat, lon, radius, geohashEncoder{bitDepth: depth}, points, limit, distance)
+}
+
+// rankEncoded ranks points like rankResults, decoding them with encoder
+//
+// When only a few of many points are requested a bounded max-heap keeps the closest ones so the
+// full candidate set is never materialized and sorted
+func rankEncoded(lat, lon, radius float64, encoder Encoder, points []redis.Z, limit int, distance DistanceFunc) []Result {
+	points = dedupeCandidates(points)
+
+	if limit < 0 || limit > len(points) {
 		limit = len(points)
 	}
 
-	results := make([]labelWithDistance, limit)
+	if limit == len(points) {
+		results := make([]Result, 0, len(points))
+		for idx := range points {
+			if result := decodeEncoded(lat, lon, encoder, points[idx], distance); result.Distance <= radius {
+				results = append(results, result)
+			}
+		}
+		slices.SortFunc(results, byDistance)
+
+		return results
+	}
+
+	nearest := make(resultHeap, 0, limit)
 	for idx := range points {
-		pointLat, pointLon, _, _ := geohash.DecodeInt(uint64(points[idx].Score), depth)
-		results[idx] = labelWithDistance{
-			Label:    points[idx].Member,
-			Distance: geohash.DistanceBetweenPoints(lat, lon, pointLat, pointLon),
+		result := decodeEncoded(lat, lon, encoder, points[idx], distance)
+		if result.Distance > radius {
+			continue
+		}
+		if len(nearest) < limit {
+			nearest.push(result)
+		} else if limit > 0 && result.Distance < nearest[0].Distance {
+			nearest[0] = result
+			nearest.down(0)
 		}
 	}
 
-	sort.Sort(labelsWithDistance(results))
+	results := []Result(nearest)
+	slices.SortFunc(results, byDistance)
 
-	asString := make([]string, limit)
-	for i := 0; i < limit; i++ {
-		asString[i] = results[i].Label
+	return results
+}
+
+// dedupeCandidates drops members fetched more than once, e.g. with a score on the boundary of two ranges
+//
+// Ranges are fetched in ascending order so the candidates are usually sorted by score already and duplicates
+// are neighbors, otherwise they are sorted first.
+func dedupeCandidates(points []redis.Z) []redis.Z {
+	if !slices.IsSortedFunc(points, compareCandidates) {
+		slices.SortFunc(points, compareCandidates)
 	}
 
-	return asString
+	return slices.CompactFunc(points, func(a, b redis.Z) bool {
+		return a.Member == b.Member && a.Score == b.Score
+	})
+}
+
+func compareCandidates(a, b redis.Z) int {
+	return cmp.Or(cmp.Compare(a.Score, b.Score), cmp.Compare(a.Member, b.Member))
+}
+
+// dedupeResults keeps the closest result of every label, results have to be sorted by distance
+func dedupeResults(results []Result) []Result {
+	seen := make(map[string]struct{}, len(results))
+	return slices.DeleteFunc(results, func(result Result) bool {
+		if _, ok := seen[result.Label]; ok {
+			return true
+		}
+		seen[result.Label] = struct{}{}
+		return false
+	})
+}
+
+func decodeResult(lat, lon float64, depth uint8, point redis.Z, distance DistanceFunc) Result {
+	return decodeEncoded(lat, lon, geohashEncoder{bitDepth: depth}, point, distance)
+}
+
+func decodeEncoded(lat, lon float64, encoder Encoder, point redis.Z, distance DistanceFunc) Result {
+	pointLat, pointLon := encoder.DecodeInt(uint64(point.Score))
+
+	return Result{
+		Label:    point.Member,
+		Lat:      pointLat,
+		Lon:      pointLon,
+		Distance: distance(lat, lon, pointLat, pointLon),
+	}
+}
+
+// resultHeap is a max-heap of results by distance, the farthest result is at the top
+//
+// It is hand rolled instead of using container/heap to avoid boxing every pushed result in an interface
+type resultHeap []Result
+
+func (h *resultHeap) push(result Result) {
+	*h = append(*h, result)
+
+	for i := len(*h) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if (*h)[parent].Distance >= (*h)[i].Distance {
+			break
+		}
+		(*h)[parent], (*h)[i] = (*h)[i], (*h)[parent]
+		i = parent
+	}
+}
+
+func (h resultHeap) down(i int) {
+	for {
+		largest, left, right := i, 2*i+1, 2*i+2
+		if left < len(h) && h[left].Distance > h[largest].Distance {
+			largest = left
+		}
+		if right < len(h) && h[right].Distance > h[largest].Distance {
+			largest = right
+		}
+		if largest == i {
+			return
+		}
+		h[i], h[largest] = h[largest], h[i]
+		i = largest
+	}
 }
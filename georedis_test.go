@@ -7,6 +7,7 @@ package georedis_test
 
 import (
 	"flag"
+	"os"
 	"testing"
 
 	. "github.com/tapglue/georedis"
@@ -25,6 +26,10 @@ const (
 )
 
 var (
+	address  = flag.String("address", "127.0.0.1:6379", "Redis address")
+	password = flag.String("password", "", "Redis password")
+	database = flag.Int64("database", 0, "Redis database")
+
 	client *redis.Client
 
 	oneCoordinate   = GeoKey{Lat: 1, Lon: 1, Label: "demo"}
@@ -36,10 +41,7 @@ var (
 	}
 )
 
-func init() {
-	address := flag.String("address", "127.0.0.1:6379", "Redis address")
-	password := flag.String("password", "", "Redis password")
-	database := flag.Int64("database", 0, "Redis database")
+func TestMain(m *testing.M) {
 	flag.Parse()
 
 	options := &redis.Options{
@@ -50,6 +52,8 @@ func init() {
 	}
 
 	client = redis.NewTCPClient(options)
+
+	os.Exit(m.Run())
 }
 
 func TestAddCoordinatesOne(t *testing.T) {
@@ -154,3 +158,34 @@ func TestSearchByRadius(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestSearchWithPayloads(t *testing.T) {
+	const zSetPayloads = "test:search:payloads"
+
+	client.Del(zSetPayloads, zSetPayloads+":payload")
+	AddCoordinates(client, zSetPayloads, bitDepth,
+		GeoKey{Lat: 39.9523, Lon: -75.1638, Label: "Philadelphia", Payload: []byte(`{"state":"PA"}`)},
+		GeoKey{Lat: 39.9524, Lon: -75.1639, Label: "Nearby"},
+	)
+
+	results, err := Search(client, zSetPayloads, 39.9523, -75.1638, 1000, bitDepth, WithPayloads())
+	if err != nil {
+		t.Logf("error encountered: %q\n", err)
+		t.FailNow()
+	}
+	if len(results) != 2 || results[0].Label != "Philadelphia" {
+		t.Logf("unexpected results %v\n", results)
+		t.FailNow()
+	}
+	if string(results[0].Payload) != `{"state":"PA"}` || results[1].Payload != nil {
+		t.Logf("unexpected payloads %q %q\n", results[0].Payload, results[1].Payload)
+		t.Fail()
+	}
+
+	RemoveCoordinatesByKeys(client, zSetPayloads, "Philadelphia")
+	payloads, err := GetPayloads(client, zSetPayloads, "Philadelphia")
+	if err != nil || payloads[0] != nil {
+		t.Logf("expected payload to be removed got %q error %v\n", payloads[0], err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,11 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package georedispb contains the protocol buffer and gRPC definitions of the georedis service
+//
+// The Go code is generated from georedis.proto, run go generate after changing it.
+package georedispb
+
+//go:generate protoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. georedis.proto
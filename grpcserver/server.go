@@ -0,0 +1,200 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package grpcserver implements the georedis gRPC service on top of the georedis package
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/tapglue/georedis"
+	pb "github.com/tapglue/georedis/georedispb"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	defaultWatchInterval = time.Second
+	// minWatchInterval keeps clients from running a search per millisecond and stream
+	minWatchInterval = 100 * time.Millisecond
+)
+
+// Server implements georedispb.GeoRedisServer
+type Server struct {
+	pb.UnimplementedGeoRedisServer
+
+	client   *redis.Client
+	bitDepth uint8
+}
+
+// New returns a Server storing coordinates with the provided bit depth
+func New(client *redis.Client, bitDepth uint8) *Server {
+	return &Server{client: client, bitDepth: bitDepth}
+}
+
+// Register registers the server with a grpc.Server
+func (s *Server) Register(server *grpc.Server) {
+	pb.RegisterGeoRedisServer(server, s)
+}
+
+// Add adds or updates members of a bucket
+func (s *Server) Add(ctx context.Context, req *pb.AddRequest) (*pb.AddResponse, error) {
+	if req.GetBucket() == "" {
+		return nil, status.Error(codes.InvalidArgument, "bucket is required")
+	}
+	if len(req.GetMembers()) == 0 {
+		return &pb.AddResponse{}, nil
+	}
+
+	coordinates := make([]georedis.GeoKey, len(req.GetMembers()))
+	for idx, member := range req.GetMembers() {
+		coordinates[idx] = georedis.GeoKey{Lat: member.GetLat(), Lon: member.GetLon(), Label: member.GetLabel()}
+	}
+
+	added, err := georedis.AddCoordinates(s.client, req.GetBucket(), s.bitDepth, coordinates...)
+	if err != nil {
+		return nil, toStatus(err, codes.Unavailable)
+	}
+
+	return &pb.AddResponse{Added: added}, nil
+}
+
+// Remove removes members from a bucket
+func (s *Server) Remove(ctx context.Context, req *pb.RemoveRequest) (*pb.RemoveResponse, error) {
+	if req.GetBucket() == "" {
+		return nil, status.Error(codes.InvalidArgument, "bucket is required")
+	}
+	if len(req.GetLabels()) == 0 {
+		return &pb.RemoveResponse{}, nil
+	}
+
+	removed, err := georedis.RemoveCoordinatesByKeys(s.client, req.GetBucket(), req.GetLabels()...)
+	if err != nil {
+		return nil, toStatus(err, codes.Unavailable)
+	}
+
+	return &pb.RemoveResponse{Removed: removed}, nil
+}
+
+// Search returns all members within a radius, nearest first
+func (s *Server) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	if req.GetBucket() == "" || req.GetRadius() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "bucket and a positive radius are required")
+	}
+
+	results, err := georedis.Search(s.client, req.GetBucket(), req.GetLat(), req.GetLon(), req.GetRadius(), s.bitDepth)
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+
+	return &pb.SearchResponse{Results: toResults(results)}, nil
+}
+
+// Nearest returns the nearest members within a radius, up to a limit
+func (s *Server) Nearest(ctx context.Context, req *pb.NearestRequest) (*pb.SearchResponse, error) {
+	if req.GetBucket() == "" || req.GetRadius() <= 0 || req.GetLimit() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "bucket, a positive radius and a positive limit are required")
+	}
+
+	results, err := georedis.Search(
+		s.client,
+		req.GetBucket(),
+		req.GetLat(),
+		req.GetLon(),
+		req.GetRadius(),
+		s.bitDepth,
+		georedis.WithLimit(int(req.GetLimit())),
+	)
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+
+	return &pb.SearchResponse{Results: toResults(results)}, nil
+}
+
+// Watch periodically evaluates the radius and streams members entering and leaving it, intervals below 100ms are
+// raised to 100ms
+func (s *Server) Watch(req *pb.WatchRequest, stream pb.GeoRedis_WatchServer) error {
+	if req.GetBucket() == "" || req.GetRadius() <= 0 {
+		return status.Error(codes.InvalidArgument, "bucket and a positive radius are required")
+	}
+
+	interval := defaultWatchInterval
+	if req.GetIntervalMs() > 0 {
+		interval = max(time.Duration(req.GetIntervalMs())*time.Millisecond, minWatchInterval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	inside := map[string]georedis.Result{}
+	for {
+		results, err := georedis.Search(s.client, req.GetBucket(), req.GetLat(), req.GetLon(), req.GetRadius(), s.bitDepth)
+		if err != nil {
+			return toStatus(err, codes.Internal)
+		}
+
+		current := make(map[string]georedis.Result, len(results))
+		for _, result := range results {
+			current[result.Label] = result
+			if _, ok := inside[result.Label]; ok {
+				continue
+			}
+			if err := stream.Send(&pb.WatchEvent{Type: pb.WatchEvent_ENTERED, Result: toResult(result)}); err != nil {
+				return err
+			}
+		}
+		for label, result := range inside {
+			if _, ok := current[label]; ok {
+				continue
+			}
+			if err := stream.Send(&pb.WatchEvent{Type: pb.WatchEvent_LEFT, Result: toResult(result)}); err != nil {
+				return err
+			}
+		}
+		inside = current
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func toResult(result georedis.Result) *pb.Result {
+	return &pb.Result{
+		Label:    result.Label,
+		Lat:      result.Lat,
+		Lon:      result.Lon,
+		Distance: result.Distance,
+	}
+}
+
+func toResults(results []georedis.Result) []*pb.Result {
+	converted := make([]*pb.Result, len(results))
+	for idx := range results {
+		converted[idx] = toResult(results[idx])
+	}
+
+	return converted
+}
+
+// toStatus reports invalid coordinates and radii as InvalidArgument and other errors with the fallback code
+func toStatus(err error, fallback codes.Code) error {
+	var coordinateErr *georedis.CoordinateError
+	if errors.As(err, &coordinateErr) || errors.Is(err, georedis.ErrInvalidRadius) ||
+		errors.Is(err, georedis.ErrBitDepthTooLow) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return status.Error(fallback, err.Error())
+}
@@ -0,0 +1,174 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package grpcserver_test
+
+import (
+	"context"
+	"flag"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/tapglue/georedis/georedispb"
+	"github.com/tapglue/georedis/grpcserver"
+
+	"gopkg.in/redis.v2"
+)
+
+const bitDepth = 52
+
+var (
+	address  = flag.String("address", "127.0.0.1:6379", "Redis address")
+	password = flag.String("password", "", "Redis password")
+	database = flag.Int64("database", 0, "Redis database")
+
+	client *redis.Client
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	client = redis.NewTCPClient(&redis.Options{
+		Addr:     *address,
+		Password: *password,
+		DB:       *database,
+		PoolSize: 2,
+	})
+
+	os.Exit(m.Run())
+}
+
+// dial serves a Server over an in-process listener and returns a client connected to it
+func dial(t *testing.T) pb.GeoRedisClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	grpcserver.New(client, bitDepth).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewGeoRedisClient(conn)
+}
+
+func TestInvalidArguments(t *testing.T) {
+	geoRedis := dial(t)
+	ctx := context.Background()
+
+	_, addErr := geoRedis.Add(ctx, &pb.AddRequest{})
+	_, searchErr := geoRedis.Search(ctx, &pb.SearchRequest{Bucket: "test:grpc:invalid"})
+	_, nearestErr := geoRedis.Nearest(ctx, &pb.NearestRequest{Bucket: "test:grpc:invalid", Radius: 1000})
+	for _, err := range []error{addErr, searchErr, nearestErr} {
+		if status.Code(err) != codes.InvalidArgument {
+			t.Logf("expected InvalidArgument got %v\n", err)
+			t.Fail()
+		}
+	}
+
+	stream, err := geoRedis.Watch(ctx, &pb.WatchRequest{Bucket: "test:grpc:invalid"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Logf("expected InvalidArgument got %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestAddSearchRemove(t *testing.T) {
+	const zSetGRPC = "test:grpc:members"
+
+	client.Del(zSetGRPC)
+	geoRedis := dial(t)
+	ctx := context.Background()
+
+	added, err := geoRedis.Add(ctx, &pb.AddRequest{Bucket: zSetGRPC, Members: []*pb.Member{
+		{Label: "Lisbon", Lat: 38.7223, Lon: -9.1393},
+		{Label: "Belem", Lat: 38.6916, Lon: -9.2160},
+		{Label: "Porto", Lat: 41.1579, Lon: -8.6291},
+	}})
+	if err != nil || added.GetAdded() != 3 {
+		t.Logf("expected to add 3 got %v error %v\n", added, err)
+		t.FailNow()
+	}
+
+	found, err := geoRedis.Search(ctx, &pb.SearchRequest{Bucket: zSetGRPC, Lat: 38.7223, Lon: -9.1393, Radius: 10000})
+	if err != nil || len(found.GetResults()) != 2 || found.GetResults()[0].GetLabel() != "Lisbon" {
+		t.Logf("expected Lisbon and Belem got %v error %v\n", found, err)
+		t.Fail()
+	}
+
+	nearest, err := geoRedis.Nearest(ctx, &pb.NearestRequest{Bucket: zSetGRPC, Lat: 38.7223, Lon: -9.1393, Radius: 10000, Limit: 1})
+	if err != nil || len(nearest.GetResults()) != 1 || nearest.GetResults()[0].GetLabel() != "Lisbon" {
+		t.Logf("expected Lisbon got %v error %v\n", nearest, err)
+		t.Fail()
+	}
+
+	removed, err := geoRedis.Remove(ctx, &pb.RemoveRequest{Bucket: zSetGRPC, Labels: []string{"Lisbon", "Faro"}})
+	if err != nil || removed.GetRemoved() != 1 {
+		t.Logf("expected to remove 1 got %v error %v\n", removed, err)
+		t.Fail()
+	}
+}
+
+func TestWatchMinimumInterval(t *testing.T) {
+	const zSetWatch = "test:grpc:watch"
+
+	client.Del(zSetWatch)
+	geoRedis := dial(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := geoRedis.Add(ctx, &pb.AddRequest{Bucket: zSetWatch, Members: []*pb.Member{{Label: "first", Lat: 52.52, Lon: 13.405}}}); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	stream, err := geoRedis.Watch(ctx, &pb.WatchRequest{Bucket: zSetWatch, Lat: 52.52, Lon: 13.405, Radius: 1000, IntervalMs: 1})
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	event, err := stream.Recv()
+	if err != nil || event.GetType() != pb.WatchEvent_ENTERED || event.GetResult().GetLabel() != "first" {
+		t.Logf("expected first to enter got %v error %v\n", event, err)
+		t.FailNow()
+	}
+	start := time.Now()
+
+	if _, err := geoRedis.Add(ctx, &pb.AddRequest{Bucket: zSetWatch, Members: []*pb.Member{{Label: "second", Lat: 52.5201, Lon: 13.405}}}); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	event, err = stream.Recv()
+	if err != nil || event.GetResult().GetLabel() != "second" {
+		t.Logf("expected second to enter got %v error %v\n", event, err)
+		t.FailNow()
+	}
+
+	// a 1ms interval is raised to the minimum, the second evaluation can't follow right away
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Logf("expected the minimum interval between evaluations got %v\n", elapsed)
+		t.Fail()
+	}
+}
@@ -0,0 +1,319 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	defaultHealthInterval = 5 * time.Second
+
+	healthProbePrefix = "georedis:health:probe"
+	healthProbeTTL    = time.Minute
+)
+
+type (
+	// HealthMonitor pings redis in the background and tracks whether it is reachable
+	//
+	// The monitor assumes a healthy connection when started, the callbacks run on every transition from then on.
+	// The redis client reconnects by itself, OnHealthy is the hook to resume work once it did.
+	HealthMonitor struct {
+		client      *GeoClient
+		interval    time.Duration
+		onHealthy   func()
+		onUnhealthy func(error)
+
+		mu      sync.RWMutex
+		healthy bool
+		err     error
+
+		done    chan struct{}
+		stopped chan struct{}
+	}
+
+	// HealthOption configures a HealthMonitor
+	HealthOption func(*HealthMonitor)
+
+	// HealthStatus is the result of Health, ProbeLatency is the time it took to write and read the probe key
+	HealthStatus struct {
+		NodeHealth
+		ProbeLatency time.Duration `json:"probeLatency"`
+		Replicas     []NodeHealth  `json:"replicas,omitempty"`
+	}
+
+	// NodeHealth is the status of a single redis server, Latency is the round trip of a PING and Role the
+	// replication role, "master" or "slave"
+	NodeHealth struct {
+		Latency           time.Duration `json:"latency"`
+		Role              string        `json:"role,omitempty"`
+		ConnectedReplicas int           `json:"connectedReplicas,omitempty"`
+		// LinkUp reports whether a replica is connected to its primary
+		LinkUp bool   `json:"linkUp,omitempty"`
+		Error  string `json:"error,omitempty"`
+	}
+)
+
+// WithHealthInterval sets the time between two pings, intervals which are not positive keep the default
+func WithHealthInterval(interval time.Duration) HealthOption {
+	return func(m *HealthMonitor) {
+		if interval > 0 {
+			m.interval = interval
+		}
+	}
+}
+
+// OnHealthy sets the function called when redis becomes reachable again
+func OnHealthy(fn func()) HealthOption {
+	return func(m *HealthMonitor) {
+		m.onHealthy = fn
+	}
+}
+
+// OnUnhealthy sets the function called with the failing ping's error when redis becomes unreachable
+func OnUnhealthy(fn func(error)) HealthOption {
+	return func(m *HealthMonitor) {
+		m.onUnhealthy = fn
+	}
+}
+
+// Ping checks that the primary and every replica respond
+func (c *GeoClient) Ping() error {
+	return c.handle(Operation{Name: "Ping"}, func() error {
+		if err := c.client.Ping().Err(); err != nil {
+			return err
+		}
+
+		for _, r := range c.replicas.replicas {
+			if err := r.client.Ping().Err(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// MonitorHealth starts a HealthMonitor for the client, stop it with Stop
+func (c *GeoClient) MonitorHealth(options ...HealthOption) *HealthMonitor {
+	m := &HealthMonitor{
+		client:      c,
+		interval:    defaultHealthInterval,
+		onHealthy:   func() {},
+		onUnhealthy: func(error) {},
+		healthy:     true,
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	for _, option := range options {
+		option(m)
+	}
+
+	go m.loop()
+
+	return m
+}
+
+// Healthy reports whether the last ping succeeded
+func (m *HealthMonitor) Healthy() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.healthy
+}
+
+// Err returns the error of the last ping, nil if it succeeded
+func (m *HealthMonitor) Err() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.err
+}
+
+// ServeHTTP answers readiness probes with 200 while healthy and 503 otherwise
+func (m *HealthMonitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := m.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Stop stops the background pings and waits for a running callback to return
+func (m *HealthMonitor) Stop() {
+	select {
+	case <-m.done:
+	default:
+		close(m.done)
+	}
+	<-m.stopped
+}
+
+func (m *HealthMonitor) loop() {
+	defer close(m.stopped)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.check()
+
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *HealthMonitor) check() {
+	err := m.client.Ping()
+
+	m.mu.Lock()
+	changed := m.healthy != (err == nil)
+	m.healthy, m.err = err == nil, err
+	m.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if err != nil {
+		m.onUnhealthy(err)
+	} else {
+		m.onHealthy()
+	}
+}
+
+// Health checks the primary and every replica and returns their status, the error is the first failed check or
+// the error of ctx when it ends first
+//
+// The primary is pinged, written and read back through a probe key of its own next to the buckets which is deleted
+// afterwards and expires after a minute if deleting fails, replicas are pinged only. The checks keep running in the background when ctx ends first.
+func (c *GeoClient) Health(ctx context.Context) (HealthStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return HealthStatus{NodeHealth: NodeHealth{Error: err.Error()}}, err
+	}
+
+	checked := make(chan HealthStatus, 1)
+	go func() {
+		checked <- c.checkHealth()
+	}()
+
+	select {
+	case status := <-checked:
+		return status, status.err()
+	case <-ctx.Done():
+		return HealthStatus{NodeHealth: NodeHealth{Error: ctx.Err().Error()}}, ctx.Err()
+	}
+}
+
+// HealthHandler answers readiness probes with the JSON encoded HealthStatus of the client, with 200 while healthy
+// and 503 otherwise
+func HealthHandler(c *GeoClient) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, err := c.Health(r.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(status)
+	})
+}
+
+func (c *GeoClient) checkHealth() HealthStatus {
+	status := HealthStatus{Replicas: make([]NodeHealth, len(c.replicas.replicas))}
+
+	status.NodeHealth = checkNode(c.client)
+	if status.Error == "" {
+		start := time.Now()
+		status.Error = errorString(probe(c.client, c.key(healthProbePrefix)))
+		status.ProbeLatency = time.Since(start)
+	}
+
+	for idx, r := range c.replicas.replicas {
+		status.Replicas[idx] = checkNode(r.client)
+	}
+
+	return status
+}
+
+// checkNode pings a redis server and reads its replication role
+func checkNode(client *redis.Client) NodeHealth {
+	start := time.Now()
+	if err := client.Ping().Err(); err != nil {
+		return NodeHealth{Error: err.Error()}
+	}
+	node := NodeHealth{Latency: time.Since(start)}
+
+	cmd := redis.NewStringCmd("INFO", "replication")
+	client.Process(cmd)
+	info, err := cmd.Result()
+	if err != nil {
+		node.Error = err.Error()
+		return node
+	}
+
+	fields := parseInfo(info)
+	node.Role = fields["role"]
+	node.ConnectedReplicas, _ = strconv.Atoi(fields["connected_slaves"])
+	node.LinkUp = fields["role"] == "slave" && fields["master_link_status"] == "up"
+
+	return node
+}
+
+// probe writes a random token to a key of its own below prefix, checks that it reads it back and deletes it, so
+// concurrent probes from several processes can't overwrite each other
+func probe(client *redis.Client, prefix string) error {
+	token := strconv.FormatUint(rand.Uint64(), 36)
+	key := prefix + ":" + token
+	if err := client.SetEx(key, healthProbeTTL, token).Err(); err != nil {
+		return err
+	}
+
+	read, err := client.Get(key).Result()
+	if err != nil {
+		return err
+	}
+	if read != token {
+		return fmt.Errorf("health probe read %q instead of %q", read, token)
+	}
+
+	return client.Del(key).Err()
+}
+
+// err returns the first failed check of the status
+func (s HealthStatus) err() error {
+	if s.Error != "" {
+		return errors.New(s.Error)
+	}
+	for _, replica := range s.Replicas {
+		if replica.Error != "" {
+			return errors.New(replica.Error)
+		}
+	}
+
+	return nil
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
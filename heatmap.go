@@ -0,0 +1,85 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"strconv"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	// maxHeatmapCells bounds the cells counted by a single heatmap
+	maxHeatmapCells = 65536
+
+	heatmapBatchSize = 1000
+)
+
+// ErrHeatmapTooLarge is returned when a heatmap spans more than 65536 cells
+var ErrHeatmapTooLarge = errors.New("heatmap spans too many cells, use a lower cell depth")
+
+// Heatmap counts the members of every cell of cellDepth bits intersecting region and returns the cells holding
+// members, ordered by cell
+//
+// Members are counted with ZCOUNT per cell, so they are never transferred. Cells on the border of the region count
+// all their members, including the ones outside of it.
+func Heatmap(client *redis.Client, bucketName string, bitDepth uint8, region Region, cellDepth uint8) ([]DensityCell, error) {
+	if err := validateRegionDepth(bitDepth, cellDepth); err != nil {
+		return []DensityCell{}, err
+	}
+
+	cells, err := regionCells(region, cellDepth, maxHeatmapCells, ErrHeatmapTooLarge)
+	if err != nil {
+		return []DensityCell{}, err
+	}
+
+	heatmap := []DensityCell{}
+	shift := bitDepth - cellDepth
+	for start := 0; start < len(cells); start += heatmapBatchSize {
+		batch := cells[start:min(start+heatmapBatchSize, len(cells))]
+		counts, err := countCells(client, bucketName, batch, shift)
+		if err != nil {
+			return []DensityCell{}, err
+		}
+
+		for idx, cell := range batch {
+			if counts[idx] == 0 {
+				continue
+			}
+			lat, lon := geohashEncoder{bitDepth: cellDepth}.DecodeInt(cell)
+			heatmap = append(heatmap, DensityCell{Cell: cell, Lat: lat, Lon: lon, Count: counts[idx]})
+		}
+	}
+
+	return heatmap, nil
+}
+
+// countCells counts the members whose scores start with the cells, the scores are shift bits deeper than the cells
+func countCells(client *redis.Client, bucketName string, cells []uint64, shift uint8) ([]int64, error) {
+	counts := make([]int64, len(cells))
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	cmds := make([]*redis.IntCmd, len(cells))
+	_, err := multi.Exec(func() error {
+		for idx, cell := range cells {
+			lower, upper := cell<<shift, (cell+1)<<shift
+			cmds[idx] = multi.ZCount(bucketName, strconv.FormatUint(lower, 10), "("+strconv.FormatUint(upper, 10))
+		}
+		return nil
+	})
+	if err != nil {
+		return counts, err
+	}
+
+	for idx := range cmds {
+		counts[idx] = cmds[idx].Val()
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,47 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	"github.com/tapglue/georedis/internal/geohash"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestHeatmap(t *testing.T) {
+	const zSetHeatmap = "test:heatmap"
+	const cellDepth = 20
+
+	client.Del(zSetHeatmap)
+	AddCoordinates(client, zSetHeatmap, bitDepth,
+		GeoKey{Label: "one", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "two", Lat: 52.5201, Lon: 13.4051},
+		GeoKey{Label: "three", Lat: 52.4, Lon: 13.1},
+		GeoKey{Label: "outside", Lat: 48.85, Lon: 2.35},
+	)
+
+	heatmap, err := Heatmap(client, zSetHeatmap, bitDepth, Region{South: 52.3, West: 13, North: 52.7, East: 13.8}, cellDepth)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	counts := map[uint64]int64{}
+	for _, cell := range heatmap {
+		counts[cell.Cell] = cell.Count
+	}
+	if len(counts) != 2 || counts[geohash.EncodeInt(52.52, 13.405, cellDepth)] != 2 || counts[geohash.EncodeInt(52.4, 13.1, cellDepth)] != 1 {
+		t.Logf("unexpected heatmap %v\n", heatmap)
+		t.Fail()
+	}
+
+	if _, err := Heatmap(client, zSetHeatmap, bitDepth, Region{South: -80, West: -170, North: 80, East: 170}, bitDepth); err != ErrHeatmapTooLarge {
+		t.Logf("expected ErrHeatmapTooLarge got %v\n", err)
+		t.Fail()
+	}
+}
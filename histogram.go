@@ -0,0 +1,71 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+
+	"gopkg.in/redis.v2"
+)
+
+// ErrInvalidBands is returned for histograms without bands
+var ErrInvalidBands = errors.New("a histogram needs at least one band")
+
+// DistanceBand counts the members at a distance from From inclusive to To exclusive, To is inclusive for the last
+// band
+type DistanceBand struct {
+	From  float64
+	To    float64
+	Count int64
+}
+
+// DistanceHistogram counts the members within radius of lat & lon in bands of equal width, nearest band first
+//
+// The candidates are decoded and counted in a single pass without ranking them. The radius and the bounds of the
+// bands are in meters unless WithUnit is used, WithLimit and WithPayloads are ignored.
+func DistanceHistogram(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, bands int, options ...SearchOption) ([]DistanceBand, error) {
+	if bands <= 0 {
+		return []DistanceBand{}, ErrInvalidBands
+	}
+
+	opts := newSearchOptions(options)
+	width := radius / float64(bands)
+	histogram := make([]DistanceBand, bands)
+	for idx := range histogram {
+		histogram[idx].From, histogram[idx].To = float64(idx)*width, float64(idx+1)*width
+	}
+	histogram[bands-1].To = radius
+
+	radius = opts.unit.ToMeters(radius)
+	ranges, err := queryRanges(lat, lon, radius, bitDepth)
+	if err != nil {
+		return []DistanceBand{}, err
+	}
+
+	var candidates []redis.Z
+	var fetchErr error
+	if since := opts.since(); !since.IsZero() {
+		if candidates, err = fetchFreshRanges(client, bucketName, bucketName, ranges, since); err != nil {
+			releaseCandidates(candidates)
+			return []DistanceBand{}, err
+		}
+	} else if candidates, fetchErr = fetchRanges(client, bucketName, ranges, opts.failFast); fetchErr != nil && opts.failFast {
+		releaseCandidates(candidates)
+		return []DistanceBand{}, fetchErr
+	}
+	defer releaseCandidates(candidates)
+
+	for _, candidate := range dedupeCandidates(candidates) {
+		result := decodeResult(lat, lon, bitDepth, candidate, opts.distance)
+		if result.Distance > radius {
+			continue
+		}
+		band := int(opts.unit.FromMeters(result.Distance) / width)
+		histogram[min(band, bands-1)].Count++
+	}
+
+	return histogram, fetchErr
+}
@@ -0,0 +1,41 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestDistanceHistogram(t *testing.T) {
+	const zSetHistogram = "test:histogram"
+
+	client.Del(zSetHistogram)
+	// about 0.5, 1.5, 1.6 and 20 km north of the center
+	AddCoordinates(client, zSetHistogram, bitDepth,
+		GeoKey{Label: "near", Lat: 52.5245, Lon: 13.405},
+		GeoKey{Label: "mid1", Lat: 52.5335, Lon: 13.405},
+		GeoKey{Label: "mid2", Lat: 52.5344, Lon: 13.405},
+		GeoKey{Label: "far", Lat: 52.7, Lon: 13.405},
+	)
+
+	histogram, err := DistanceHistogram(client, zSetHistogram, 52.52, 13.405, 3, bitDepth, 3, WithUnit(Kilometers))
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if len(histogram) != 3 || histogram[0].Count != 1 || histogram[1].Count != 2 || histogram[2].Count != 0 ||
+		histogram[1].From != 1 || histogram[2].To != 3 {
+		t.Logf("unexpected histogram %v\n", histogram)
+		t.Fail()
+	}
+
+	if _, err := DistanceHistogram(client, zSetHistogram, 52.52, 13.405, 3, bitDepth, 0); err != ErrInvalidBands {
+		t.Logf("expected ErrInvalidBands got %v\n", err)
+		t.Fail()
+	}
+}
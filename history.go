@@ -0,0 +1,147 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+type (
+	// HistoryEntry is a past location of a member
+	HistoryEntry struct {
+		Lat  float64
+		Lon  float64
+		Time time.Time
+	}
+
+	// HistoryLimits caps the history of every member, a zero value keeps everything
+	HistoryLimits struct {
+		// MaxLen is the approximate number of entries to keep
+		MaxLen int64
+		// MaxAge drops entries older than it, it requires redis 6.2 or newer
+		MaxAge time.Duration
+	}
+)
+
+// WithHistory appends every write of the GeoClient to the history of the members, see AppendHistory
+func WithHistory(limits HistoryLimits) ClientOption {
+	return func(c *GeoClient) {
+		c.history = &limits
+	}
+}
+
+// AppendHistory appends the coordinates to a stream per member, trimmed to limits, and indexes the members with a
+// history by the time of their last append
+//
+// Histories are kept when members are removed, they expire after MaxAge without updates.
+func AppendHistory(client *redis.Client, bucketName string, limits HistoryLimits, coordinates ...GeoKey) error {
+	if err := ValidateCoordinates(coordinates...); err != nil {
+		return err
+	}
+	if len(coordinates) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	multi := client.Multi()
+	defer multi.Close()
+
+	_, err := multi.Exec(func() error {
+		for _, coordinate := range coordinates {
+			key := HistoryKey(bucketName, coordinate.Label)
+
+			args := []string{"XADD", key}
+			if limits.MaxLen > 0 {
+				args = append(args, "MAXLEN", "~", strconv.FormatInt(limits.MaxLen, 10))
+			}
+			args = append(args, "*",
+				"lat", strconv.FormatFloat(coordinate.Lat, 'f', -1, 64),
+				"lon", strconv.FormatFloat(coordinate.Lon, 'f', -1, 64),
+			)
+			multi.Process(redis.NewCmd(args...))
+
+			multi.ZAdd(historyIndexKey(bucketName), redis.Z{Score: unixScore(now), Member: coordinate.Label})
+
+			if limits.MaxAge > 0 {
+				minID := strconv.FormatInt(now.Add(-limits.MaxAge).UnixMilli(), 10)
+				multi.Process(redis.NewCmd("XTRIM", key, "MINID", "~", minID))
+				multi.Expire(key, limits.MaxAge)
+			}
+		}
+		if limits.MaxAge > 0 {
+			multi.ZRemRangeByScore(historyIndexKey(bucketName), "-inf", "("+formatUnixScore(now.Add(-limits.MaxAge)))
+		}
+		return nil
+	})
+
+	return err
+}
+
+// History returns the locations of a member between from and to, both inclusive, oldest first
+func History(client *redis.Client, bucketName, label string, from, to time.Time) ([]HistoryEntry, error) {
+	history, _, err := historyRange(client, HistoryKey(bucketName, label), formatStreamID(from), formatStreamID(to), 0)
+
+	return history, err
+}
+
+// DeleteHistory removes the history of a member
+func DeleteHistory(client *redis.Client, bucketName, label string) error {
+	multi := client.Multi()
+	defer multi.Close()
+
+	_, err := multi.Exec(func() error {
+		multi.Del(HistoryKey(bucketName, label))
+		multi.ZRem(historyIndexKey(bucketName), label)
+		return nil
+	})
+
+	return err
+}
+
+func historyIndexKey(bucketName string) string {
+	return bucketName + ":histories"
+}
+
+// HistoryKey returns the key of the history stream of a member
+func HistoryKey(bucketName, label string) string {
+	return bucketName + ":history:" + label
+}
+
+// historyRange returns up to count entries, all if count is 0, of a history stream between the stream ids start
+// and end together with the id of the last entry
+func historyRange(client *redis.Client, key, start, end string, count int64) ([]HistoryEntry, string, error) {
+	args := []string{"XRANGE", key, start, end}
+	if count > 0 {
+		args = append(args, "COUNT", strconv.FormatInt(count, 10))
+	}
+	cmd := redis.NewCmd(args...)
+	client.Process(cmd)
+	reply, err := cmd.Result()
+	if err != nil {
+		return []HistoryEntry{}, "", err
+	}
+
+	entries, err := parseChanges(reply)
+	if err != nil {
+		return []HistoryEntry{}, "", err
+	}
+
+	history := make([]HistoryEntry, 0, len(entries))
+	last := ""
+	for _, entry := range entries {
+		if entry.After == nil {
+			return []HistoryEntry{}, "", fmt.Errorf("history entry %s without coordinates", entry.ID)
+		}
+		history = append(history, HistoryEntry{Lat: entry.After.Lat, Lon: entry.After.Lon, Time: entry.Time})
+		last = entry.ID
+	}
+
+	return history, last, nil
+}
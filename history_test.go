@@ -0,0 +1,43 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestHistory(t *testing.T) {
+	const zSetHistory = "test:history"
+
+	client.Del(HistoryKey(zSetHistory, "car"))
+
+	geoClient := NewGeoClient(client, bitDepth, WithHistory(HistoryLimits{MaxLen: 100, MaxAge: time.Hour}))
+	from := time.Now().Add(-time.Second)
+	for _, lat := range []float64{52.52, 52.53, 52.54} {
+		if _, err := geoClient.AddCoordinates(zSetHistory, GeoKey{Lat: lat, Lon: 13.405, Label: "car"}); err != nil {
+			t.Logf("error encountered %q\n", err)
+			t.FailNow()
+		}
+	}
+
+	history, err := geoClient.History(zSetHistory, "car", from, time.Now().Add(time.Second))
+	if err != nil || len(history) != 3 {
+		t.Logf("expected 3 entries got %v error %v\n", history, err)
+		t.FailNow()
+	}
+	if history[0].Lat != 52.52 || history[2].Lat != 52.54 || history[2].Lon != 13.405 || history[0].Time.Before(from.Truncate(time.Millisecond)) {
+		t.Logf("unexpected history %v\n", history)
+		t.Fail()
+	}
+
+	if history, err := History(client, zSetHistory, "car", from.Add(-time.Hour), from.Add(-time.Minute)); err != nil || len(history) != 0 {
+		t.Logf("expected no entries before the first write got %v error %v\n", history, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,109 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/redis.v2"
+)
+
+// hotCellsLuaBody counts the members of every cell of a bucket and keeps the n most crowded ones
+//
+// KEYS[1] is the bucket, ARGV holds the divisor turning scores into cells, n and the page size. The members are
+// read in pages ordered by score so the members of a cell are neighbors. The reply is a flat list of cell and
+// count, most crowded first.
+const hotCellsLuaBody = `
+local divisor = tonumber(ARGV[1])
+local n = tonumber(ARGV[2])
+local page = tonumber(ARGV[3])
+
+local top = {}
+local function offer(cell, count)
+  if #top == n and top[n][2] >= count then return end
+  if #top == n then top[n] = nil end
+  local idx = #top + 1
+  while idx > 1 and top[idx - 1][2] < count do
+    top[idx] = top[idx - 1]
+    idx = idx - 1
+  end
+  top[idx] = {cell, count}
+end
+
+local cell, count = nil, 0
+local offset = 0
+while true do
+  local members = redis.call('ZRANGE', KEYS[1], offset, offset + page - 1, 'WITHSCORES')
+  for i = 2, #members, 2 do
+    local memberCell = math.floor(tonumber(members[i]) / divisor)
+    if memberCell == cell then
+      count = count + 1
+    else
+      if cell then offer(cell, count) end
+      cell, count = memberCell, 1
+    end
+  end
+  if #members < page * 2 then break end
+  offset = offset + page
+end
+if cell then offer(cell, count) end
+
+local reply = {}
+for i = 1, #top do
+  reply[#reply + 1] = string.format('%.0f', top[i][1])
+  reply[#reply + 1] = top[i][2]
+end
+
+return reply
+`
+
+var hotCellsScript = newLuaScript(hotCellsLuaBody)
+
+// HotCells returns the n cells of cellDepth bits holding the most members of a bucket, most crowded first
+//
+// The cells are counted inside redis so no member is transferred, the script reads the whole bucket and blocks
+// redis meanwhile, see DensityReport for large buckets. Cells with the same count are ordered by cell.
+func HotCells(client *redis.Client, bucketName string, bitDepth, cellDepth uint8, n int) ([]DensityCell, error) {
+	if err := validateRegionDepth(bitDepth, cellDepth); err != nil {
+		return []DensityCell{}, err
+	}
+	if n <= 0 {
+		return []DensityCell{}, nil
+	}
+
+	args := []string{
+		strconv.FormatUint(1<<(bitDepth-cellDepth), 10),
+		strconv.Itoa(n),
+		strconv.Itoa(reportBatchSize),
+	}
+	reply, err := hotCellsScript.run(client, []string{bucketName}, args)
+	if err != nil {
+		return []DensityCell{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values)%2 != 0 {
+		return []DensityCell{}, fmt.Errorf("unexpected hot cells reply %v", reply)
+	}
+
+	cells := make([]DensityCell, 0, len(values)/2)
+	for idx := 0; idx < len(values); idx += 2 {
+		value, _ := values[idx].(string)
+		cell, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return []DensityCell{}, fmt.Errorf("unexpected hot cells reply %v", values[idx])
+		}
+		count, ok := values[idx+1].(int64)
+		if !ok {
+			return []DensityCell{}, fmt.Errorf("unexpected hot cells reply %v", values[idx+1])
+		}
+		lat, lon := geohashEncoder{bitDepth: cellDepth}.DecodeInt(cell)
+		cells = append(cells, DensityCell{Cell: cell, Lat: lat, Lon: lon, Count: count})
+	}
+
+	return cells, nil
+}
@@ -0,0 +1,40 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	"github.com/tapglue/georedis/internal/geohash"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestHotCells(t *testing.T) {
+	const zSetHotCells = "test:hotcells"
+	const cellDepth = 20
+
+	client.Del(zSetHotCells)
+	AddCoordinates(client, zSetHotCells, bitDepth,
+		GeoKey{Label: "berlin1", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "berlin2", Lat: 52.5201, Lon: 13.4051},
+		GeoKey{Label: "berlin3", Lat: 52.5202, Lon: 13.4052},
+		GeoKey{Label: "paris1", Lat: 48.85, Lon: 2.35},
+		GeoKey{Label: "paris2", Lat: 48.8501, Lon: 2.3501},
+		GeoKey{Label: "rome", Lat: 41.9, Lon: 12.5},
+	)
+
+	cells, err := HotCells(client, zSetHotCells, bitDepth, cellDepth, 2)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if len(cells) != 2 || cells[0].Cell != geohash.EncodeInt(52.52, 13.405, cellDepth) || cells[0].Count != 3 ||
+		cells[1].Cell != geohash.EncodeInt(48.85, 2.35, cellDepth) || cells[1].Count != 2 {
+		t.Logf("unexpected hot cells %v\n", cells)
+		t.Fail()
+	}
+}
@@ -0,0 +1,130 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tapglue/georedis"
+)
+
+const (
+	eventsBatchSize = 100
+	eventsBlock     = 15 * time.Second
+)
+
+// events streams the changes of the members inside a radius as server-sent events
+//
+// The id of every event is the id of the change stream entry it was derived from. Clients reconnecting with a
+// Last-Event-ID header, or a lastEventId parameter, resume after that change, new clients first receive an enter event
+// for every member inside the radius. Writes have to be recorded in the change stream, see WithChangeStream.
+func (h *Handler) events(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := h.bucket(w, r)
+	if !ok {
+		return
+	}
+
+	params, unit, err := parseCircle(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	lat, lon, radius := params["lat"], params["lon"], unit.ToMeters(params["radius"])
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	last := r.Header.Get("Last-Event-ID")
+	if last == "" {
+		last = r.URL.Query().Get("lastEventId")
+	}
+
+	var snapshot []georedis.Result
+	if last == "" {
+		// the stream position is taken before searching, so no change after the search is lost
+		if last, err = georedis.LastChangeID(h.client, bucket); err != nil {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		if snapshot, err = georedis.Search(h.client, bucket, lat, lon, radius, h.bitDepth); err != nil {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, result := range snapshot {
+		writeEvent(w, last, "enter", Result{Label: result.Label, Lat: result.Lat, Lon: result.Lon, Distance: unit.FromMeters(result.Distance)})
+	}
+	flusher.Flush()
+
+	for {
+		changes, err := georedis.TailChanges(h.client, bucket, last, eventsBatchSize, eventsBlock)
+		if r.Context().Err() != nil {
+			return
+		}
+		if err != nil {
+			writeEvent(w, "", "error", errorResponse{Error: err.Error()})
+			flusher.Flush()
+			return
+		}
+		if len(changes) == 0 {
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+
+		for _, change := range changes {
+			last = change.ID
+			eventType, point := classifyChange(change, lat, lon, radius)
+			if eventType == "" {
+				continue
+			}
+			distance := georedis.Haversine(lat, lon, point.Lat, point.Lon)
+			writeEvent(w, change.ID, eventType, Result{Label: change.Label, Lat: point.Lat, Lon: point.Lon, Distance: unit.FromMeters(distance)})
+		}
+		flusher.Flush()
+	}
+}
+
+// classifyChange returns whether a change makes a member enter, move inside or leave a circle, and the coordinates
+// to report, or an empty type when it doesn't touch the circle
+func classifyChange(change georedis.Change, lat, lon, radius float64) (string, georedis.Point) {
+	within := func(point *georedis.Point) bool {
+		return point != nil && georedis.Haversine(lat, lon, point.Lat, point.Lon) <= radius
+	}
+
+	was, is := within(change.Before), within(change.After)
+	switch {
+	case was && is:
+		return "move", *change.After
+	case is:
+		return "enter", *change.After
+	case was && change.After != nil:
+		return "leave", *change.After
+	case was:
+		return "leave", *change.Before
+	}
+
+	return "", georedis.Point{}
+}
+
+func writeEvent(w http.ResponseWriter, id, eventType string, value interface{}) {
+	data, _ := json.Marshal(value)
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, data)
+}
@@ -0,0 +1,282 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package httpapi provides an embeddable http.Handler exposing georedis buckets as a REST API
+//
+// The following routes are served:
+//
+//	PUT    /buckets/{name}/members          adds or updates the members in the JSON body
+//	DELETE /buckets/{name}/members/{label}  removes a member
+//	GET    /buckets/{name}/nearby           searches by radius, see the lat, lon, radius, unit, limit and format parameters
+//	GET    /buckets/{name}/events           streams the members entering, moving in and leaving a radius as server-sent events
+//
+// Nearby results are returned as JSON, or as a GeoJSON feature collection when format=geojson is set
+// or the request accepts application/geo+json.
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tapglue/georedis"
+
+	"gopkg.in/redis.v2"
+)
+
+const maxBodySize = 1 << 20
+
+type (
+	// Handler serves the georedis REST API
+	Handler struct {
+		client      *redis.Client
+		bitDepth    uint8
+		authorize   Authorizer
+		middlewares []Middleware
+		handler     http.Handler
+
+		changes       bool
+		changesMaxLen int64
+	}
+
+	// Authorizer decides if a request may access a bucket, returning an error rejects the request
+	Authorizer func(r *http.Request, bucket string) error
+
+	// Middleware wraps the API handler, for example to add logging or metrics
+	Middleware func(http.Handler) http.Handler
+
+	// Option configures a Handler
+	Option func(*Handler)
+
+	// Member is the JSON representation of a member
+	Member struct {
+		Label string  `json:"label"`
+		Lat   float64 `json:"lat"`
+		Lon   float64 `json:"lon"`
+	}
+
+	// Result is the JSON representation of a search result
+	Result struct {
+		Label    string  `json:"label"`
+		Lat      float64 `json:"lat"`
+		Lon      float64 `json:"lon"`
+		Distance float64 `json:"distance"`
+	}
+
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+// WithAuthorizer sets the function deciding which requests may access a bucket
+func WithAuthorizer(authorize Authorizer) Option {
+	return func(h *Handler) {
+		h.authorize = authorize
+	}
+}
+
+// WithMiddleware adds middlewares around the API, the first one added is the outermost
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(h *Handler) {
+		h.middlewares = append(h.middlewares, middlewares...)
+	}
+}
+
+// WithChangeStream records the writes of the API in the change stream of the bucket, trimmed to about maxLen
+// entries when maxLen is positive, which the events route streams to clients
+func WithChangeStream(maxLen int64) Option {
+	return func(h *Handler) {
+		h.changes, h.changesMaxLen = true, maxLen
+	}
+}
+
+// New returns a Handler storing coordinates with the provided bit depth
+func New(client *redis.Client, bitDepth uint8, options ...Option) *Handler {
+	h := &Handler{client: client, bitDepth: bitDepth}
+	for _, option := range options {
+		option(h)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /buckets/{name}/members", h.putMembers)
+	mux.HandleFunc("DELETE /buckets/{name}/members/{label}", h.deleteMember)
+	mux.HandleFunc("GET /buckets/{name}/nearby", h.nearby)
+	mux.HandleFunc("GET /buckets/{name}/events", h.events)
+
+	h.handler = mux
+	for idx := len(h.middlewares) - 1; idx >= 0; idx-- {
+		h.handler = h.middlewares[idx](h.handler)
+	}
+
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler.ServeHTTP(w, r)
+}
+
+func (h *Handler) bucket(w http.ResponseWriter, r *http.Request) (string, bool) {
+	bucket := r.PathValue("name")
+	if h.authorize != nil {
+		if err := h.authorize(r, bucket); err != nil {
+			writeError(w, http.StatusUnauthorized, err)
+			return "", false
+		}
+	}
+
+	return bucket, true
+}
+
+func (h *Handler) putMembers(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := h.bucket(w, r)
+	if !ok {
+		return
+	}
+
+	members := []Member{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&members); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(members) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("no members provided"))
+		return
+	}
+
+	coordinates := make([]georedis.GeoKey, len(members))
+	for idx, member := range members {
+		if member.Label == "" {
+			writeError(w, http.StatusBadRequest, errors.New("members require a label"))
+			return
+		}
+		coordinates[idx] = georedis.GeoKey{Lat: member.Lat, Lon: member.Lon, Label: member.Label}
+	}
+	if err := georedis.ValidateCoordinates(coordinates...); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var added int64
+	var err error
+	if h.changes {
+		added, err = georedis.AddCoordinatesWithChanges(h.client, bucket, h.bitDepth, h.changesMaxLen, coordinates...)
+	} else {
+		added, err = georedis.AddCoordinates(h.client, bucket, h.bitDepth, coordinates...)
+	}
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, "application/json", map[string]int64{"added": added})
+}
+
+func (h *Handler) deleteMember(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := h.bucket(w, r)
+	if !ok {
+		return
+	}
+
+	var removed int64
+	var err error
+	if h.changes {
+		removed, err = georedis.RemoveCoordinatesByKeysWithChanges(h.client, bucket, h.bitDepth, h.changesMaxLen, r.PathValue("label"))
+	} else {
+		removed, err = georedis.RemoveCoordinatesByKeys(h.client, bucket, r.PathValue("label"))
+	}
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if removed == 0 {
+		writeError(w, http.StatusNotFound, errors.New("member not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) nearby(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := h.bucket(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	params, unit, err := parseCircle(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	options := []georedis.SearchOption{georedis.WithUnit(unit)}
+	if query.Get("limit") != "" {
+		limit, err := strconv.Atoi(query.Get("limit"))
+		if err != nil || limit <= 0 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid limit"))
+			return
+		}
+		options = append(options, georedis.WithLimit(limit))
+	}
+
+	results, err := georedis.Search(h.client, bucket, params["lat"], params["lon"], params["radius"], h.bitDepth, options...)
+	if errors.Is(err, georedis.ErrBitDepthTooLow) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if query.Get("format") == "geojson" || strings.Contains(r.Header.Get("Accept"), "application/geo+json") {
+		writeJSON(w, http.StatusOK, "application/geo+json", georedis.ResultsToGeoJSON(results))
+		return
+	}
+
+	response := make([]Result, len(results))
+	for idx, result := range results {
+		response[idx] = Result{Label: result.Label, Lat: result.Lat, Lon: result.Lon, Distance: result.Distance}
+	}
+	writeJSON(w, http.StatusOK, "application/json", response)
+}
+
+// parseCircle reads and validates the lat, lon, radius and unit parameters
+func parseCircle(query url.Values) (map[string]float64, georedis.Unit, error) {
+	params := map[string]float64{}
+	for _, name := range []string{"lat", "lon", "radius"} {
+		value, err := strconv.ParseFloat(query.Get(name), 64)
+		if err != nil {
+			return nil, 0, errors.New("invalid or missing " + name)
+		}
+		params[name] = value
+	}
+	unit, err := georedis.ParseUnit(query.Get("unit"))
+	if err != nil {
+		return nil, 0, err
+	}
+	if params["radius"] <= 0 || unit.ToMeters(params["radius"]) > georedis.MaxRadius {
+		return nil, 0, georedis.ErrInvalidRadius
+	}
+	if err := georedis.ValidateCoordinates(georedis.GeoKey{Lat: params["lat"], Lon: params["lon"]}); err != nil {
+		return nil, 0, err
+	}
+
+	return params, unit, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, contentType string, value interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, "application/json", errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,103 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package httpapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/tapglue/georedis/httpapi"
+
+	"gopkg.in/redis.v2"
+)
+
+var client = redis.NewTCPClient(&redis.Options{Addr: "127.0.0.1:6379"})
+
+func TestNearbyValidation(t *testing.T) {
+	handler := New(client, 52)
+
+	for _, target := range []string{
+		"/buckets/cities/nearby?lat=1&lon=1",
+		"/buckets/cities/nearby?lat=a&lon=1&radius=10",
+		"/buckets/cities/nearby?lat=1&lon=1&radius=-5",
+		"/buckets/cities/nearby?lat=1&lon=1&radius=5&limit=0",
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Logf("expected status %d for %s got %d\n", http.StatusBadRequest, target, rec.Code)
+			t.Fail()
+		}
+	}
+}
+
+func TestPutMembersValidation(t *testing.T) {
+	handler := New(client, 52)
+
+	for _, body := range []string{"", "[]", "{", `[{"lat": 1, "lon": 1}]`} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("PUT", "/buckets/cities/members", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Logf("expected status %d for %q got %d\n", http.StatusBadRequest, body, rec.Code)
+			t.Fail()
+		}
+	}
+}
+
+func TestAuthorizerAndMiddleware(t *testing.T) {
+	var order []string
+	middleware := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	handler := New(
+		client,
+		52,
+		WithMiddleware(middleware("outer"), middleware("inner")),
+		WithAuthorizer(func(r *http.Request, bucket string) error {
+			if bucket != "public" {
+				return errors.New("access denied")
+			}
+			return nil
+		}),
+	)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/buckets/private/nearby?lat=1&lon=1&radius=10", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Logf("expected status %d got %d\n", http.StatusUnauthorized, rec.Code)
+		t.Fail()
+	}
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Logf("unexpected middleware order %v\n", order)
+		t.Fail()
+	}
+}
+
+func TestEventsValidation(t *testing.T) {
+	handler := New(client, 52, WithChangeStream(1000))
+
+	for _, target := range []string{
+		"/buckets/cities/events?lat=1&lon=1",
+		"/buckets/cities/events?lat=91&lon=1&radius=10",
+		"/buckets/cities/events?lat=1&lon=1&radius=10&unit=parsec",
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Logf("expected status %d for %s got %d\n", http.StatusBadRequest, target, rec.Code)
+			t.Fail()
+		}
+	}
+}
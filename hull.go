@@ -0,0 +1,96 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"cmp"
+	"slices"
+
+	"gopkg.in/redis.v2"
+)
+
+const hullBatchSize = 1000
+
+// ConvexHull returns the convex hull of points as a polygon with a single closed counterclockwise ring, nil when
+// the points don't span an area
+//
+// The hull is computed on the plane of longitudes and latitudes with Andrew's monotone chain, points on both sides
+// of the antimeridian result in a hull spanning the globe. Use ToGeoJSON to render it.
+func ConvexHull(points []Point) Polygon {
+	sorted := slices.Clone(points)
+	slices.SortFunc(sorted, comparePoints)
+	sorted = slices.Compact(sorted)
+	if len(sorted) < 3 {
+		return nil
+	}
+
+	hull := make([]Point, 0, 2*len(sorted))
+	// lower chain from west to east, then the upper chain back
+	for _, point := range sorted {
+		for len(hull) >= 2 && orientation(hull[len(hull)-2], hull[len(hull)-1], point) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, point)
+	}
+	lower := len(hull) + 1
+	for idx := len(sorted) - 2; idx >= 0; idx-- {
+		for len(hull) >= lower && orientation(hull[len(hull)-2], hull[len(hull)-1], sorted[idx]) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, sorted[idx])
+	}
+
+	// the ring is closed by the first point appended again, collinear points leave only two vertices
+	if len(hull) < 4 {
+		return nil
+	}
+
+	return Polygon{hull}
+}
+
+// ResultsHull returns the convex hull of the coordinates of search results, see ConvexHull
+func ResultsHull(results []Result) Polygon {
+	points := make([]Point, len(results))
+	for idx := range results {
+		points[idx] = Point{Lat: results[idx].Lat, Lon: results[idx].Lon}
+	}
+
+	return ConvexHull(points)
+}
+
+// BucketHull returns the convex hull of all members of the set, see ConvexHull
+//
+// Members are read in pages and only the hull of the pages read so far is kept in memory.
+func BucketHull(client *redis.Client, bucketName string, bitDepth uint8) (Polygon, error) {
+	var vertices []Point
+	for offset := int64(0); ; offset += hullBatchSize {
+		members, err := client.ZRangeWithScores(bucketName, offset, offset+hullBatchSize-1).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		points := slices.Clip(vertices)
+		for idx := range members {
+			lat, lon := geohashEncoder{bitDepth: bitDepth}.DecodeInt(uint64(members[idx].Score))
+			points = append(points, Point{Lat: lat, Lon: lon})
+		}
+		if hull := ConvexHull(points); hull != nil {
+			vertices = hull[0][:len(hull[0])-1]
+		} else if len(points) > 0 {
+			// points without an area lie on a line, a later page may span one with its ends
+			vertices = []Point{slices.MinFunc(points, comparePoints), slices.MaxFunc(points, comparePoints)}
+		}
+
+		if len(members) < hullBatchSize {
+			return ConvexHull(vertices), nil
+		}
+	}
+}
+
+// comparePoints orders points from west to east and south to north
+func comparePoints(a, b Point) int {
+	return cmp.Or(cmp.Compare(a.Lon, b.Lon), cmp.Compare(a.Lat, b.Lat))
+}
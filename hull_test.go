@@ -0,0 +1,54 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"slices"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestConvexHull(t *testing.T) {
+	points := []Point{
+		{Lat: 0, Lon: 0}, {Lat: 0, Lon: 2}, {Lat: 2, Lon: 2}, {Lat: 2, Lon: 0},
+		{Lat: 1, Lon: 1}, {Lat: 0, Lon: 1}, {Lat: 2, Lon: 2},
+	}
+
+	hull := ConvexHull(points)
+	expected := Polygon{{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 2}, {Lat: 2, Lon: 2}, {Lat: 2, Lon: 0}, {Lat: 0, Lon: 0}}}
+	if len(hull) != 1 || !slices.Equal(hull[0], expected[0]) {
+		t.Logf("expected %v got %v\n", expected, hull)
+		t.Fail()
+	}
+
+	if hull := ConvexHull([]Point{{Lat: 0, Lon: 0}, {Lat: 1, Lon: 1}, {Lat: 2, Lon: 2}}); hull != nil {
+		t.Logf("expected no hull of collinear points got %v\n", hull)
+		t.Fail()
+	}
+}
+
+func TestBucketHull(t *testing.T) {
+	const zSetHull = "test:hull"
+
+	client.Del(zSetHull)
+	AddCoordinates(client, zSetHull, bitDepth,
+		GeoKey{Label: "a", Lat: 52.5, Lon: 13.3},
+		GeoKey{Label: "b", Lat: 52.5, Lon: 13.5},
+		GeoKey{Label: "c", Lat: 52.6, Lon: 13.4},
+		GeoKey{Label: "inside", Lat: 52.52, Lon: 13.4},
+	)
+
+	hull, err := BucketHull(client, zSetHull, bitDepth)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if len(hull) != 1 || len(hull[0]) != 4 || !hull.Contains(52.52, 13.4) {
+		t.Logf("expected a triangle around the inner member got %v\n", hull)
+		t.Fail()
+	}
+}
@@ -0,0 +1,137 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package geohash implements the integer geohash encoding of georedis
+//
+// An integer geohash of bitDepth bits alternates longitude and latitude bits, starting with longitude, each bit
+// halving the remaining range. It is the binary form of the classic base32 geohash.
+package geohash
+
+import "math"
+
+// MaxBitDepth is the deepest encoding, sorted sets store scores of up to 53 bits exactly
+const MaxBitDepth = 52
+
+// earthRadius is the mean earth radius in meters
+const earthRadius = 6371000.0
+
+// EncodeInt returns the integer geohash of bitDepth bits of a coordinate
+func EncodeInt(lat, lon float64, bitDepth uint8) uint64 {
+	minLat, maxLat, minLon, maxLon := -90.0, 90.0, -180.0, 180.0
+
+	var hash uint64
+	for bit := uint8(0); bit < bitDepth; bit++ {
+		hash <<= 1
+		if bit%2 == 0 {
+			mid := (minLon + maxLon) / 2
+			if lon > mid {
+				hash |= 1
+				minLon = mid
+			} else {
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if lat > mid {
+				hash |= 1
+				minLat = mid
+			} else {
+				maxLat = mid
+			}
+		}
+	}
+
+	return hash
+}
+
+// DecodeBboxInt returns the bounding box of the cell of an integer geohash
+func DecodeBboxInt(hash uint64, bitDepth uint8) (minLat, minLon, maxLat, maxLon float64) {
+	minLat, maxLat, minLon, maxLon = -90.0, 90.0, -180.0, 180.0
+
+	for bit := uint8(0); bit < bitDepth; bit++ {
+		set := hash>>(bitDepth-1-bit)&1 == 1
+		if bit%2 == 0 {
+			mid := (minLon + maxLon) / 2
+			if set {
+				minLon = mid
+			} else {
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if set {
+				minLat = mid
+			} else {
+				maxLat = mid
+			}
+		}
+	}
+
+	return minLat, minLon, maxLat, maxLon
+}
+
+// DecodeInt returns the center of the cell of an integer geohash and half its height and width in degrees
+func DecodeInt(hash uint64, bitDepth uint8) (lat, lon, latErr, lonErr float64) {
+	minLat, minLon, maxLat, maxLon := DecodeBboxInt(hash, bitDepth)
+	latErr, lonErr = (maxLat-minLat)/2, (maxLon-minLon)/2
+
+	return minLat + latErr, minLon + lonErr, latErr, lonErr
+}
+
+// NeighborInt returns the cell next to hash in direction, given as lat and lon steps of -1, 0 or 1
+//
+// Longitudes wrap around the antimeridian, latitudes beyond the poles are clamped to the cell at the pole.
+func NeighborInt(hash uint64, direction [2]int, bitDepth uint8) uint64 {
+	lat, lon, latErr, lonErr := DecodeInt(hash, bitDepth)
+
+	lat = math.Max(-90, math.Min(90, lat+float64(direction[0])*latErr*2))
+	lon += float64(direction[1]) * lonErr * 2
+	if lon > 180 {
+		lon -= 360
+	} else if lon < -180 {
+		lon += 360
+	}
+
+	return EncodeInt(lat, lon, bitDepth)
+}
+
+// NeighborsInt returns the eight cells around hash, clockwise starting north
+func NeighborsInt(hash uint64, bitDepth uint8) []uint64 {
+	directions := [8][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+
+	neighbors := make([]uint64, len(directions))
+	for idx, direction := range directions {
+		neighbors[idx] = NeighborInt(hash, direction, bitDepth)
+	}
+
+	return neighbors
+}
+
+// CellSize returns the height and width of the cells of bitDepth bits in degrees
+func CellSize(bitDepth uint8) (height, width float64) {
+	latBits, lonBits := bitDepth/2, bitDepth-bitDepth/2
+
+	return 180 / float64(uint64(1)<<latBits), 360 / float64(uint64(1)<<lonBits)
+}
+
+// CellSizeMeters returns the height and width in meters of the cells of bitDepth bits at a latitude
+//
+// The height is the same everywhere, the width shrinks with the cosine of the latitude.
+func CellSizeMeters(lat float64, bitDepth uint8) (height, width float64) {
+	latDegrees, lonDegrees := CellSize(bitDepth)
+	metersPerDegree := earthRadius * math.Pi / 180
+
+	return latDegrees * metersPerDegree, lonDegrees * metersPerDegree * math.Cos(lat*math.Pi/180)
+}
+
+// DistanceBetweenPoints returns the great circle distance between two coordinates in meters
+func DistanceBetweenPoints(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dPhi, dLambda := (lat2-lat1)*math.Pi/180, (lon2-lon1)*math.Pi/180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+
+	return earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
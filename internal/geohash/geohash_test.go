@@ -0,0 +1,187 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package geohash
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// coordinate is a random valid coordinate and bit depth for property tests
+type coordinate struct {
+	Lat      float64
+	Lon      float64
+	BitDepth uint8
+}
+
+func (coordinate) Generate(rand *rand.Rand, _ int) reflect.Value {
+	return reflect.ValueOf(coordinate{
+		Lat:      rand.Float64()*180 - 90,
+		Lon:      rand.Float64()*360 - 180,
+		BitDepth: uint8(rand.Intn(MaxBitDepth/2)+1) * 2,
+	})
+}
+
+func TestEncodeInt(t *testing.T) {
+	// the base32 geohash u4pruydqqv holds the same 50 bits
+	var expected uint64
+	for _, char := range "u4pruydqqv" {
+		expected = expected<<5 | uint64(strings.IndexRune("0123456789bcdefghjkmnpqrstuvwxyz", char))
+	}
+	if hash := EncodeInt(57.64911, 10.40744, 50); hash != expected {
+		t.Logf("expected %x got %x\n", expected, hash)
+		t.Fail()
+	}
+
+	if hash := EncodeInt(90, 180, 52); hash != 1<<52-1 {
+		t.Logf("expected the last cell for the north east corner got %x\n", hash)
+		t.Fail()
+	}
+	if hash := EncodeInt(-90, -180, 52); hash != 0 {
+		t.Logf("expected the first cell for the south west corner got %x\n", hash)
+		t.Fail()
+	}
+}
+
+func TestDecodeContainsEncoded(t *testing.T) {
+	property := func(c coordinate) bool {
+		minLat, minLon, maxLat, maxLon := DecodeBboxInt(EncodeInt(c.Lat, c.Lon, c.BitDepth), c.BitDepth)
+		return minLat <= c.Lat && c.Lat <= maxLat && minLon <= c.Lon && c.Lon <= maxLon
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+}
+
+func TestDecodeMatchesCellSize(t *testing.T) {
+	property := func(c coordinate) bool {
+		_, _, latErr, lonErr := DecodeInt(EncodeInt(c.Lat, c.Lon, c.BitDepth), c.BitDepth)
+		height, width := CellSize(c.BitDepth)
+		return latErr*2 == height && lonErr*2 == width
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+}
+
+func TestEncodeDecodedCenter(t *testing.T) {
+	property := func(c coordinate) bool {
+		hash := EncodeInt(c.Lat, c.Lon, c.BitDepth)
+		lat, lon, _, _ := DecodeInt(hash, c.BitDepth)
+		return EncodeInt(lat, lon, c.BitDepth) == hash
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+}
+
+func TestEncodePrefix(t *testing.T) {
+	property := func(c coordinate) bool {
+		coarser := c.BitDepth - c.BitDepth%4
+		if coarser == 0 {
+			return true
+		}
+		return EncodeInt(c.Lat, c.Lon, c.BitDepth)>>(c.BitDepth-coarser) == EncodeInt(c.Lat, c.Lon, coarser)
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+}
+
+func TestNeighborsAdjacent(t *testing.T) {
+	property := func(c coordinate) bool {
+		hash := EncodeInt(c.Lat, c.Lon, c.BitDepth)
+		lat, lon, latErr, lonErr := DecodeInt(hash, c.BitDepth)
+
+		for _, neighbor := range NeighborsInt(hash, c.BitDepth) {
+			neighborLat, neighborLon, _, _ := DecodeInt(neighbor, c.BitDepth)
+			lonDistance := math.Abs(neighborLon - lon)
+			lonDistance = math.Min(lonDistance, 360-lonDistance)
+			if math.Abs(neighborLat-lat) > latErr*2+1e-9 || lonDistance > lonErr*2+1e-9 {
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+}
+
+func TestNeighborsSymmetric(t *testing.T) {
+	property := func(c coordinate) bool {
+		// the cells at the poles are clamped, so their neighbors aren't symmetric
+		if math.Abs(c.Lat) > 80 {
+			return true
+		}
+
+		hash := EncodeInt(c.Lat, c.Lon, c.BitDepth)
+		for _, neighbor := range NeighborsInt(hash, c.BitDepth) {
+			if !slices.Contains(NeighborsInt(neighbor, c.BitDepth), hash) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+}
+
+func TestNeighborsWrapAntimeridian(t *testing.T) {
+	east := EncodeInt(0, 179.99, 20)
+	west := EncodeInt(0, -179.99, 20)
+
+	if !slices.Contains(NeighborsInt(east, 20), west) || !slices.Contains(NeighborsInt(west, 20), east) {
+		t.Logf("expected the cells on both sides of the antimeridian to be neighbors\n")
+		t.Fail()
+	}
+}
+
+func TestCellSizeMeters(t *testing.T) {
+	height, width := CellSizeMeters(0, 2)
+	if math.Abs(height-DistanceBetweenPoints(-45, 0, 45, 0)) > 1 || math.Abs(width-DistanceBetweenPoints(0, 0, 0, 180)) > 1 {
+		t.Logf("unexpected cell size %f x %f\n", height, width)
+		t.Fail()
+	}
+
+	_, equator := CellSizeMeters(0, 26)
+	if _, width := CellSizeMeters(60, 26); math.Abs(width-equator/2) > 1e-6 {
+		t.Logf("expected cells at 60° to be half as wide as at the equator got %f\n", width)
+		t.Fail()
+	}
+}
+
+func TestDistanceBetweenPoints(t *testing.T) {
+	property := func(a, b coordinate) bool {
+		distance := DistanceBetweenPoints(a.Lat, a.Lon, b.Lat, b.Lon)
+		return distance >= 0 && distance <= math.Pi*earthRadius+1 &&
+			math.Abs(distance-DistanceBetweenPoints(b.Lat, b.Lon, a.Lat, a.Lon)) < 1e-6 &&
+			DistanceBetweenPoints(a.Lat, a.Lon, a.Lat, a.Lon) == 0
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,177 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package kafkasink implements georedis.EventSink on top of a Kafka producer
+//
+// Changes and fence events are published as JSON messages keyed by label, so all messages of a member end up in
+// the same partition and keep their order. Every message carries its kind in the "type" header.
+package kafkasink
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/tapglue/georedis"
+)
+
+const (
+	// DefaultChangeTopic receives the changes when no other topic is configured
+	DefaultChangeTopic = "georedis.changes"
+	// DefaultFenceTopic receives the fence events when no other topic is configured
+	DefaultFenceTopic = "georedis.fences"
+
+	defaultTimeout = 10 * time.Second
+)
+
+type (
+	// MessageWriter publishes messages, it is implemented by *kafka.Writer which must not set a Topic itself
+	MessageWriter interface {
+		WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	}
+
+	// Sink publishes changes and fence events to Kafka
+	Sink struct {
+		writer      MessageWriter
+		changeTopic string
+		fenceTopic  string
+		timeout     time.Duration
+	}
+
+	// Option configures a Sink
+	Option func(*Sink)
+
+	// Change is the JSON value of a change message
+	Change struct {
+		ID        string    `json:"id"`
+		Bucket    string    `json:"bucket"`
+		Type      string    `json:"type"`
+		Label     string    `json:"label"`
+		Lat       *float64  `json:"lat,omitempty"`
+		Lon       *float64  `json:"lon,omitempty"`
+		BeforeLat *float64  `json:"before_lat,omitempty"`
+		BeforeLon *float64  `json:"before_lon,omitempty"`
+		Time      time.Time `json:"time"`
+	}
+
+	// FenceEvent is the JSON value of a fence event message
+	FenceEvent struct {
+		Type     string    `json:"type"`
+		Fence    string    `json:"fence"`
+		Label    string    `json:"label"`
+		Lat      float64   `json:"lat"`
+		Lon      float64   `json:"lon"`
+		Distance float64   `json:"distance,omitempty"`
+		Time     time.Time `json:"time"`
+	}
+)
+
+// WithChangeTopic sets the topic of the change messages
+func WithChangeTopic(topic string) Option {
+	return func(s *Sink) {
+		s.changeTopic = topic
+	}
+}
+
+// WithFenceTopic sets the topic of the fence event messages
+func WithFenceTopic(topic string) Option {
+	return func(s *Sink) {
+		s.fenceTopic = topic
+	}
+}
+
+// WithTimeout sets how long a single write may take, 10s by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Sink) {
+		s.timeout = timeout
+	}
+}
+
+// New returns a Sink publishing through writer
+//
+// Use a kafka.Writer with the kafka.Hash balancer so messages are partitioned by label:
+//
+//	kafkasink.New(&kafka.Writer{Addr: kafka.TCP("localhost:9092"), Balancer: &kafka.Hash{}})
+func New(writer MessageWriter, options ...Option) *Sink {
+	s := &Sink{writer: writer, changeTopic: DefaultChangeTopic, fenceTopic: DefaultFenceTopic, timeout: defaultTimeout}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// WriteChanges implements georedis.EventSink
+func (s *Sink) WriteChanges(bucketName string, changes ...georedis.Change) error {
+	messages := make([]kafka.Message, len(changes))
+	for idx, change := range changes {
+		value := Change{ID: change.ID, Bucket: bucketName, Type: string(change.Type), Label: change.Label, Time: change.Time}
+		if change.After != nil {
+			value.Lat, value.Lon = &change.After.Lat, &change.After.Lon
+		}
+		if change.Before != nil {
+			value.BeforeLat, value.BeforeLon = &change.Before.Lat, &change.Before.Lon
+		}
+
+		message, err := s.message(s.changeTopic, change.Label, string(change.Type), change.Time, value)
+		if err != nil {
+			return err
+		}
+		message.Headers = append(message.Headers, kafka.Header{Key: "bucket", Value: []byte(bucketName)})
+		messages[idx] = message
+	}
+
+	return s.write(messages)
+}
+
+// WriteFenceEvents implements georedis.EventSink
+func (s *Sink) WriteFenceEvents(events ...georedis.FenceEvent) error {
+	messages := make([]kafka.Message, len(events))
+	for idx, event := range events {
+		value := FenceEvent{
+			Type:     string(event.Type),
+			Fence:    event.Fence,
+			Label:    event.Label,
+			Lat:      event.Lat,
+			Lon:      event.Lon,
+			Distance: event.Distance,
+			Time:     event.Time,
+		}
+
+		var err error
+		if messages[idx], err = s.message(s.fenceTopic, event.Label, string(event.Type), event.Time, value); err != nil {
+			return err
+		}
+	}
+
+	return s.write(messages)
+}
+
+func (s *Sink) message(topic, label, messageType string, t time.Time, value interface{}) (kafka.Message, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	return kafka.Message{
+		Topic:   topic,
+		Key:     []byte(label),
+		Value:   data,
+		Headers: []kafka.Header{{Key: "type", Value: []byte(messageType)}},
+		Time:    t,
+	}, nil
+}
+
+func (s *Sink) write(messages []kafka.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	return s.writer.WriteMessages(ctx, messages...)
+}
@@ -0,0 +1,69 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package kafkasink_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/tapglue/georedis"
+	. "github.com/tapglue/georedis/kafkasink"
+)
+
+type recorder struct {
+	messages []kafka.Message
+}
+
+func (r *recorder) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.messages = append(r.messages, msgs...)
+	return nil
+}
+
+func TestSinkKeysMessagesByLabel(t *testing.T) {
+	var _ georedis.EventSink = &Sink{}
+
+	writer := &recorder{}
+	sink := New(writer, WithFenceTopic("fences"))
+
+	now := time.Now()
+	err := sink.WriteChanges("drivers",
+		georedis.Change{ID: "1-0", Type: georedis.ChangeAdd, Label: "a", After: &georedis.Point{Lat: 1, Lon: 2}, Time: now},
+		georedis.Change{ID: "2-0", Type: georedis.ChangeRemove, Label: "b", Before: &georedis.Point{Lat: 3, Lon: 4}, Time: now},
+	)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if err := sink.WriteFenceEvents(georedis.FenceEvent{Type: georedis.FenceExit, Fence: "depot", Label: "c", Time: now}); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	if len(writer.messages) != 3 {
+		t.Logf("expected 3 messages got %v\n", writer.messages)
+		t.FailNow()
+	}
+	for idx, expected := range []struct{ topic, key, kind string }{
+		{DefaultChangeTopic, "a", "add"},
+		{DefaultChangeTopic, "b", "remove"},
+		{"fences", "c", "exit"},
+	} {
+		message := writer.messages[idx]
+		if message.Topic != expected.topic || string(message.Key) != expected.key || string(message.Headers[0].Value) != expected.kind {
+			t.Logf("expected %v got %s %s %v\n", expected, message.Topic, message.Key, message.Headers)
+			t.Fail()
+		}
+	}
+
+	change := Change{}
+	if err := json.Unmarshal(writer.messages[1].Value, &change); err != nil || change.Bucket != "drivers" || change.Lat != nil || *change.BeforeLon != 4 {
+		t.Logf("unexpected change %s error %v\n", writer.messages[1].Value, err)
+		t.Fail()
+	}
+}
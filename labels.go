@@ -0,0 +1,132 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import "gopkg.in/redis.v2"
+
+// WithLabelMatch returns only members whose label matches a glob pattern like "driver:*"
+//
+// Patterns use the syntax of the redis MATCH and KEYS commands: * matches any sequence, ? any character, [abc] and
+// [a-z] a character of a class, [^abc] a character outside it and \ escapes the next character. Candidates are
+// matched before their coordinates are decoded.
+func WithLabelMatch(pattern string) SearchOption {
+	return func(o *searchOptions) {
+		o.labelMatch = pattern
+	}
+}
+
+// matchCandidates drops the candidates whose label doesn't match pattern, in place
+func matchCandidates(candidates []redis.Z, pattern string) []redis.Z {
+	if pattern == "" {
+		return candidates
+	}
+
+	matches := candidates[:0]
+	for idx := range candidates {
+		if matchGlob(pattern, candidates[idx].Member) {
+			matches = append(matches, candidates[idx])
+		}
+	}
+
+	return matches
+}
+
+// matchResults drops the results whose label doesn't match pattern and keeps at most limit, -1 keeps all
+func matchResults(results []Result, pattern string, limit int) []Result {
+	if pattern == "" {
+		return results
+	}
+
+	matches := results[:0]
+	for idx := range results {
+		if limit >= 0 && len(matches) == limit {
+			break
+		}
+		if matchGlob(pattern, results[idx].Label) {
+			matches = append(matches, results[idx])
+		}
+	}
+
+	return matches
+}
+
+// matchGlob reports whether label matches the redis glob pattern
+func matchGlob(pattern, label string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for idx := 0; idx <= len(label); idx++ {
+				if matchGlob(pattern[1:], label[idx:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(label) == 0 {
+				return false
+			}
+			label = label[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(label) == 0 {
+				return false
+			}
+			matched, rest := matchClass(pattern[1:], label[0])
+			if !matched {
+				return false
+			}
+			label = label[1:]
+			pattern = rest
+		default:
+			if pattern[0] == '\\' && len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			if len(label) == 0 || pattern[0] != label[0] {
+				return false
+			}
+			label = label[1:]
+			pattern = pattern[1:]
+		}
+	}
+
+	return len(label) == 0
+}
+
+// matchClass matches char against the character class at the start of pattern, after its opening bracket, and
+// returns the pattern after the class
+func matchClass(pattern string, char byte) (bool, string) {
+	negate := len(pattern) > 0 && pattern[0] == '^'
+	if negate {
+		pattern = pattern[1:]
+	}
+
+	matched := false
+	for len(pattern) > 0 && pattern[0] != ']' {
+		switch {
+		case pattern[0] == '\\' && len(pattern) > 1:
+			matched = matched || pattern[1] == char
+			pattern = pattern[2:]
+		case len(pattern) > 2 && pattern[1] == '-' && pattern[2] != ']':
+			low, high := min(pattern[0], pattern[2]), max(pattern[0], pattern[2])
+			matched = matched || (char >= low && char <= high)
+			pattern = pattern[3:]
+		default:
+			matched = matched || pattern[0] == char
+			pattern = pattern[1:]
+		}
+	}
+	if len(pattern) > 0 {
+		pattern = pattern[1:]
+	}
+
+	return matched != negate, pattern
+}
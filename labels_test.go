@@ -0,0 +1,57 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"testing"
+
+	"gopkg.in/redis.v2"
+)
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		pattern string
+		label   string
+		matches bool
+	}{
+		{"driver:*", "driver:42", true},
+		{"driver:*", "rider:42", false},
+		{"*:42", "driver:42", true},
+		{"d?iver:*", "driver:", true},
+		{"driver:[0-9]*", "driver:7x", true},
+		{"driver:[0-9]*", "driver:x7", false},
+		{"driver:[^0-9]", "driver:x", true},
+		{"driver:[^0-9]", "driver:7", false},
+		{"car\\*", "car*", true},
+		{"car\\*", "cars", false},
+		{"a**b", "ab", true},
+		{"", "", true},
+		{"?", "", false},
+	}
+
+	for _, c := range cases {
+		if matchGlob(c.pattern, c.label) != c.matches {
+			t.Logf("expected %q matching %q to be %t\n", c.pattern, c.label, c.matches)
+			t.Fail()
+		}
+	}
+}
+
+func TestMatchCandidates(t *testing.T) {
+	candidates := []redis.Z{{Member: "driver:1"}, {Member: "rider:1"}, {Member: "driver:2"}}
+
+	matches := matchCandidates(candidates, "driver:*")
+	if len(matches) != 2 || matches[0].Member != "driver:1" || matches[1].Member != "driver:2" {
+		t.Logf("unexpected matches %v\n", matches)
+		t.Fail()
+	}
+
+	results := matchResults([]Result{{Label: "driver:1"}, {Label: "rider:1"}, {Label: "driver:2"}}, "driver:*", 1)
+	if len(results) != 1 || results[0].Label != "driver:1" {
+		t.Logf("unexpected results %v\n", results)
+		t.Fail()
+	}
+}
@@ -0,0 +1,241 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const defaultListenerDelay = 100 * time.Millisecond
+
+type (
+	// FenceListener evaluates the fences of a FenceTracker whenever a bucket changes, whichever process wrote to it
+	//
+	// It subscribes to the keyspace notifications of the buckets, redis has to be configured with
+	// notify-keyspace-events including "K" and "z" (or "KA"). Changes are found by comparing a bucket to a snapshot
+	// kept next to it, which reads the whole bucket, so notifications arriving within the delay are coalesced into a
+	// single evaluation. Members written through the FenceTracker itself don't emit their events twice.
+	FenceListener struct {
+		tracker *FenceTracker
+		buckets []string
+		delay   time.Duration
+		onError func(error)
+		pubsub  *redis.PubSub
+
+		mu    sync.Mutex
+		dirty map[string]bool
+
+		// evaluating serializes evaluations, they share the diff key of a bucket
+		evaluating sync.Mutex
+
+		done    chan struct{}
+		stopped sync.WaitGroup
+	}
+
+	// ListenerOption configures a FenceListener
+	ListenerOption func(*FenceListener)
+)
+
+// WithListenerDelay sets how long notifications are collected before the changed buckets are evaluated, delays which
+// are not positive keep the default
+func WithListenerDelay(delay time.Duration) ListenerOption {
+	return func(l *FenceListener) {
+		if delay > 0 {
+			l.delay = delay
+		}
+	}
+}
+
+// WithListenerErrorHandler sets the function receiving errors of background evaluations
+func WithListenerErrorHandler(handler func(error)) ListenerOption {
+	return func(l *FenceListener) {
+		l.onError = handler
+	}
+}
+
+// Listen starts a FenceListener for the buckets, they are evaluated once before it returns to catch up with changes
+// made while nobody was listening
+func (t *FenceTracker) Listen(bucketNames []string, options ...ListenerOption) (*FenceListener, error) {
+	l := &FenceListener{
+		tracker: t,
+		buckets: bucketNames,
+		delay:   defaultListenerDelay,
+		onError: func(error) {},
+		pubsub:  t.client.PubSub(),
+		dirty:   map[string]bool{},
+		done:    make(chan struct{}),
+	}
+	for _, option := range options {
+		option(l)
+	}
+
+	patterns := make([]string, len(bucketNames))
+	for idx, bucketName := range bucketNames {
+		patterns[idx] = "__keyspace@*__:" + bucketName
+	}
+	if err := l.pubsub.PSubscribe(patterns...); err != nil {
+		l.pubsub.Close()
+		return nil, err
+	}
+
+	for _, bucketName := range bucketNames {
+		if _, err := l.Evaluate(bucketName); err != nil {
+			l.onError(err)
+		}
+	}
+
+	l.stopped.Add(2)
+	go l.receive()
+	go l.loop()
+
+	return l, nil
+}
+
+// Close unsubscribes and waits for a running evaluation to finish
+func (l *FenceListener) Close() error {
+	close(l.done)
+	err := l.pubsub.Close()
+	l.stopped.Wait()
+
+	return err
+}
+
+// Evaluate compares a bucket to its snapshot and emits the events of the members which changed since
+func (l *FenceListener) Evaluate(bucketName string) ([]FenceEvent, error) {
+	l.evaluating.Lock()
+	defer l.evaluating.Unlock()
+
+	t := l.tracker
+	diff := snapshotKey(bucketName, t.fenceSet) + ":diff"
+
+	// the transaction returns its connection before the diff is read, the pubsub already holds one of the pool
+	multi := t.client.Multi()
+	_, err := multi.Exec(func() error {
+		multi.ZUnionStore(diff, redis.ZStore{Weights: []int64{1, -1}}, bucketName, snapshotKey(bucketName, t.fenceSet))
+		multi.ZUnionStore(snapshotKey(bucketName, t.fenceSet), redis.ZStore{}, bucketName)
+		return nil
+	})
+	multi.Close()
+	if err != nil {
+		return []FenceEvent{}, err
+	}
+
+	lower, err := t.client.ZRangeByScoreWithScores(diff, redis.ZRangeByScore{Min: "-inf", Max: "(0"}).Result()
+	if err != nil {
+		return []FenceEvent{}, err
+	}
+	upper, err := t.client.ZRangeByScoreWithScores(diff, redis.ZRangeByScore{Min: "(0", Max: "+inf"}).Result()
+	if err != nil {
+		return []FenceEvent{}, err
+	}
+	t.client.Del(diff)
+
+	changed := append(lower, upper...)
+	if len(changed) == 0 {
+		return []FenceEvent{}, nil
+	}
+
+	updated, previous, removed := []GeoKey{}, []*GeoKey{}, []GeoKey{}
+	for _, member := range changed {
+		cmd := t.client.ZScore(bucketName, member.Member)
+		if cmd.Err() == redis.Nil {
+			lat, lon := geohashEncoder{bitDepth: t.bitDepth}.DecodeInt(uint64(-member.Score))
+			removed = append(removed, GeoKey{Lat: lat, Lon: lon, Label: member.Member})
+			continue
+		}
+		if cmd.Err() != nil {
+			return []FenceEvent{}, cmd.Err()
+		}
+
+		score := cmd.Val()
+		lat, lon := geohashEncoder{bitDepth: t.bitDepth}.DecodeInt(uint64(score))
+		updated = append(updated, GeoKey{Lat: lat, Lon: lon, Label: member.Member})
+		if old := score - member.Score; old != 0 {
+			lat, lon := geohashEncoder{bitDepth: t.bitDepth}.DecodeInt(uint64(old))
+			previous = append(previous, &GeoKey{Lat: lat, Lon: lon, Label: member.Member})
+		} else {
+			previous = append(previous, nil)
+		}
+	}
+
+	events := []FenceEvent{}
+	if len(updated) > 0 {
+		if events, err = t.evaluate(bucketName, updated, previous, nil); err != nil {
+			return events, err
+		}
+	}
+	if len(removed) > 0 {
+		exits, err := t.evaluateRemoved(bucketName, removed)
+		events = append(events, exits...)
+		if err != nil {
+			return events, err
+		}
+	}
+
+	return events, nil
+}
+
+// receive marks the buckets named by incoming notifications as dirty
+func (l *FenceListener) receive() {
+	defer l.stopped.Done()
+
+	for {
+		msg, err := l.pubsub.Receive()
+		select {
+		case <-l.done:
+			return
+		default:
+		}
+		if err != nil {
+			l.onError(err)
+			time.Sleep(l.delay)
+			continue
+		}
+
+		if pmsg, ok := msg.(*redis.PMessage); ok {
+			if _, bucketName, ok := strings.Cut(pmsg.Channel, ":"); ok {
+				l.mu.Lock()
+				l.dirty[bucketName] = true
+				l.mu.Unlock()
+			}
+		}
+	}
+}
+
+// loop evaluates the dirty buckets every delay
+func (l *FenceListener) loop() {
+	defer l.stopped.Done()
+
+	ticker := time.NewTicker(l.delay)
+	defer ticker.Stop()
+
+	for {
+		l.mu.Lock()
+		dirty := l.dirty
+		l.dirty = map[string]bool{}
+		l.mu.Unlock()
+
+		for bucketName := range dirty {
+			if _, err := l.Evaluate(bucketName); err != nil {
+				l.onError(err)
+			}
+		}
+
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func snapshotKey(bucketName, fenceSet string) string {
+	return bucketName + ":snapshot:" + fenceSet
+}
@@ -0,0 +1,298 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	// LiveEntered is emitted when a member moves into the area of a LiveQuery
+	LiveEntered LiveQueryEventType = "entered"
+	// LiveLeft is emitted when a member moves out of the area of a LiveQuery or is removed
+	LiveLeft LiveQueryEventType = "left"
+	// LiveMoved is emitted when a member inside the area of a LiveQuery moves and stays inside
+	LiveMoved LiveQueryEventType = "moved"
+
+	liveQueryBatchSize = 100
+	liveQueryBlock     = time.Second
+)
+
+type (
+	// LiveQueryEventType is the kind of a LiveQueryEvent
+	LiveQueryEventType string
+
+	// LiveQueryEvent reports a change of the result set of a LiveQuery, Lat & Lon are the coordinates after the
+	// change or, for removed members, before
+	LiveQueryEvent struct {
+		Type  LiveQueryEventType
+		Label string
+		Lat   float64
+		Lon   float64
+		Time  time.Time
+	}
+
+	// LiveQuery keeps the result set of a standing search up to date by following the change stream of its bucket
+	//
+	// The bucket is searched once when the query is registered, afterwards only the changes are read, so writes
+	// have to be recorded with WithChangeStream or AddCoordinatesWithChanges.
+	LiveQuery struct {
+		client     *redis.Client
+		bucketName string
+		bitDepth   uint8
+		contains   func(lat, lon float64) bool
+		distance   func(lat, lon float64) float64
+		handlers   []func(LiveQueryEvent)
+		onError    func(error)
+
+		mu      sync.Mutex
+		members map[string]Point
+		last    string
+
+		done    chan struct{}
+		stopped chan struct{}
+	}
+
+	// LiveQueryOption configures a LiveQuery
+	LiveQueryOption func(*LiveQuery)
+)
+
+// WithLiveQueryHandler calls handler for every change of the result set, in the order they happened
+func WithLiveQueryHandler(handler func(LiveQueryEvent)) LiveQueryOption {
+	return func(q *LiveQuery) {
+		q.handlers = append(q.handlers, handler)
+	}
+}
+
+// WithLiveQueryErrorHandler sets the function receiving errors reading the change stream
+func WithLiveQueryErrorHandler(handler func(error)) LiveQueryOption {
+	return func(q *LiveQuery) {
+		q.onError = handler
+	}
+}
+
+// RegisterLiveQuery starts maintaining the members within radius meters of lat & lon, results are ordered by
+// distance
+func RegisterLiveQuery(client *redis.Client, bucketName string, bitDepth uint8, lat, lon, radius float64, options ...LiveQueryOption) (*LiveQuery, error) {
+	if err := ValidateCoordinates(GeoKey{Lat: lat, Lon: lon}); err != nil {
+		return nil, err
+	}
+	if !(radius > 0 && radius <= MaxRadius) {
+		return nil, ErrInvalidRadius
+	}
+
+	q := newLiveQuery(client, bucketName, bitDepth, options)
+	q.distance = func(memberLat, memberLon float64) float64 {
+		return Haversine(lat, lon, memberLat, memberLon)
+	}
+	q.contains = func(memberLat, memberLon float64) bool {
+		return q.distance(memberLat, memberLon) <= radius
+	}
+
+	return q, q.start(func() ([]Result, error) {
+		return Search(client, bucketName, lat, lon, radius, bitDepth)
+	})
+}
+
+// RegisterPolygonLiveQuery starts maintaining the members inside a Polygon or MultiPolygon, results are ordered by
+// label
+func RegisterPolygonLiveQuery(client *redis.Client, bucketName string, bitDepth uint8, geometry Geometry, options ...LiveQueryOption) (*LiveQuery, error) {
+	multi, err := polygons(geometry)
+	if err != nil {
+		return nil, err
+	}
+
+	q := newLiveQuery(client, bucketName, bitDepth, options)
+	q.contains = multi.Contains
+
+	return q, q.start(func() ([]Result, error) {
+		return searchPolygon(client, bucketName, bitDepth, multi)
+	})
+}
+
+// Results returns the current result set
+func (q *LiveQuery) Results() []Result {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	results := make([]Result, 0, len(q.members))
+	for label, point := range q.members {
+		result := Result{Label: label, Lat: point.Lat, Lon: point.Lon}
+		if q.distance != nil {
+			result.Distance = q.distance(point.Lat, point.Lon)
+		}
+		results = append(results, result)
+	}
+	slices.SortFunc(results, func(a, b Result) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.Label, b.Label))
+	})
+
+	return results
+}
+
+// Close stops following the change stream
+func (q *LiveQuery) Close() error {
+	close(q.done)
+	<-q.stopped
+
+	return nil
+}
+
+func newLiveQuery(client *redis.Client, bucketName string, bitDepth uint8, options []LiveQueryOption) *LiveQuery {
+	q := &LiveQuery{
+		client:     client,
+		bucketName: bucketName,
+		bitDepth:   bitDepth,
+		onError:    func(error) {},
+		members:    map[string]Point{},
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	for _, option := range options {
+		option(q)
+	}
+
+	return q
+}
+
+// start takes the position in the change stream before the initial search, so no change after it is lost, and
+// follows the stream from there
+func (q *LiveQuery) start(search func() ([]Result, error)) error {
+	last, err := LastChangeID(q.client, q.bucketName)
+	if err != nil {
+		return err
+	}
+	results, err := search()
+	if err != nil {
+		return err
+	}
+
+	q.last = last
+	for _, result := range results {
+		q.members[result.Label] = Point{Lat: result.Lat, Lon: result.Lon}
+	}
+
+	go q.loop()
+
+	return nil
+}
+
+func (q *LiveQuery) loop() {
+	defer close(q.stopped)
+
+	for {
+		select {
+		case <-q.done:
+			return
+		default:
+		}
+
+		changes, err := TailChanges(q.client, q.bucketName, q.last, liveQueryBatchSize, liveQueryBlock)
+		if err != nil {
+			q.onError(err)
+			select {
+			case <-q.done:
+				return
+			case <-time.After(liveQueryBlock):
+			}
+			continue
+		}
+
+		for _, event := range q.apply(changes) {
+			for _, handler := range q.handlers {
+				handler(event)
+			}
+		}
+	}
+}
+
+// apply updates the result set with changes and returns the resulting events
+//
+// The result set rather than the coordinates before a change decide whether a member was inside, so changes
+// seen twice don't emit twice.
+func (q *LiveQuery) apply(changes []Change) []LiveQueryEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events := []LiveQueryEvent{}
+	for _, change := range changes {
+		q.last = change.ID
+
+		previous, known := q.members[change.Label]
+		inside := change.After != nil && q.contains(change.After.Lat, change.After.Lon)
+
+		switch {
+		case inside:
+			eventType := LiveEntered
+			if known {
+				if previous == *change.After {
+					continue
+				}
+				eventType = LiveMoved
+			}
+			q.members[change.Label] = *change.After
+			events = append(events, LiveQueryEvent{Type: eventType, Label: change.Label, Lat: change.After.Lat, Lon: change.After.Lon, Time: change.Time})
+		case known:
+			delete(q.members, change.Label)
+			point := previous
+			if change.After != nil {
+				point = *change.After
+			}
+			events = append(events, LiveQueryEvent{Type: LiveLeft, Label: change.Label, Lat: point.Lat, Lon: point.Lon, Time: change.Time})
+		}
+	}
+
+	return events
+}
+
+// searchPolygon returns the members inside polygons by reading the cells covering them
+func searchPolygon(client *redis.Client, bucketName string, bitDepth uint8, multi MultiPolygon) ([]Result, error) {
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return []Result{}, err
+	}
+
+	minLat, maxLat, west, east := multi.bounds()
+	depth, cells := coverBox(minLat, maxLat, west, east, maxPolygonCells, multi.intersectsBox)
+	if depth > bitDepth {
+		for idx := range cells {
+			cells[idx] >>= depth - bitDepth
+		}
+		depth, cells = bitDepth, slices.Compact(cells)
+	}
+
+	// adjacent cells are merged, the upper bound of a range is inclusive so neighbouring ranges would overlap
+	ranges := []geoRange{}
+	for _, cell := range cells {
+		lower, upper := float64(cell<<(bitDepth-depth)), float64((cell+1)<<(bitDepth-depth))
+		if last := len(ranges) - 1; last >= 0 && ranges[last].Upper == lower {
+			ranges[last].Upper = upper
+			continue
+		}
+		ranges = append(ranges, geoRange{Lower: lower, Upper: upper})
+	}
+
+	candidates, err := fetchRanges(client, bucketName, ranges, true)
+	defer releaseCandidates(candidates)
+	if err != nil {
+		return []Result{}, err
+	}
+
+	results := []Result{}
+	for _, candidate := range candidates {
+		result := decodeResult(0, 0, bitDepth, candidate, Haversine)
+		if multi.Contains(result.Lat, result.Lon) {
+			result.Distance = 0
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
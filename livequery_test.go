@@ -0,0 +1,54 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"testing"
+)
+
+func TestLiveQueryApply(t *testing.T) {
+	q := newLiveQuery(nil, "test", 52, nil)
+	q.contains = func(lat, lon float64) bool {
+		return lat >= 0 && lat <= 1 && lon >= 0 && lon <= 1
+	}
+	q.members["a"] = Point{Lat: 0.5, Lon: 0.5}
+
+	events := q.apply([]Change{
+		{ID: "1-0", Type: ChangeUpdate, Label: "a", Before: &Point{Lat: 0.5, Lon: 0.5}, After: &Point{Lat: 0.6, Lon: 0.5}},
+		{ID: "2-0", Type: ChangeAdd, Label: "b", After: &Point{Lat: 2, Lon: 2}},
+		{ID: "3-0", Type: ChangeUpdate, Label: "b", Before: &Point{Lat: 2, Lon: 2}, After: &Point{Lat: 0.1, Lon: 0.1}},
+		{ID: "3-0", Type: ChangeUpdate, Label: "b", Before: &Point{Lat: 2, Lon: 2}, After: &Point{Lat: 0.1, Lon: 0.1}},
+		{ID: "4-0", Type: ChangeUpdate, Label: "a", Before: &Point{Lat: 0.6, Lon: 0.5}, After: &Point{Lat: 3, Lon: 3}},
+		{ID: "5-0", Type: ChangeRemove, Label: "b", Before: &Point{Lat: 0.1, Lon: 0.1}},
+		{ID: "6-0", Type: ChangeRemove, Label: "c", Before: &Point{Lat: 0.2, Lon: 0.2}},
+	})
+
+	expected := []struct {
+		eventType LiveQueryEventType
+		label     string
+		lat       float64
+	}{
+		{LiveMoved, "a", 0.6},
+		{LiveEntered, "b", 0.1},
+		{LiveLeft, "a", 3},
+		{LiveLeft, "b", 0.1},
+	}
+	if len(events) != len(expected) {
+		t.Logf("expected %d events got %v\n", len(expected), events)
+		t.FailNow()
+	}
+	for idx, event := range events {
+		if event.Type != expected[idx].eventType || event.Label != expected[idx].label || event.Lat != expected[idx].lat {
+			t.Logf("expected %v got %v\n", expected[idx], event)
+			t.Fail()
+		}
+	}
+
+	if len(q.members) != 0 || q.last != "6-0" {
+		t.Logf("expected an empty result set at 6-0 got %v at %s\n", q.members, q.last)
+		t.Fail()
+	}
+}
@@ -0,0 +1,63 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// WithLogger logs failed attempts of GeoClient operations, retried ones included, and searches returning partial
+// results to logger
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *GeoClient) {
+		c.logger = logger
+	}
+}
+
+// WithSlowSearchThreshold logs searches of a GeoClient taking threshold or longer to the logger of WithLogger,
+// together with their parameters and the ranges and candidates they read
+func WithSlowSearchThreshold(threshold time.Duration) ClientOption {
+	return func(c *GeoClient) {
+		c.slowSearch = threshold
+	}
+}
+
+// WithSweeperLogger logs every sweep of a StaleSweeper and its failures to logger
+func WithSweeperLogger(logger *slog.Logger) SweeperOption {
+	return func(s *StaleSweeper) {
+		s.logger = logger
+	}
+}
+
+// observeError reports a failed attempt of an operation to the metrics and the logger
+func (c *GeoClient) observeError(attempt int, err error) {
+	if c.metrics != nil {
+		c.metrics.ObserveError(err)
+	}
+	if c.logger != nil {
+		c.logger.Warn("georedis: operation failed", "attempt", attempt, "retryable", IsRetryable(err), "error", err)
+	}
+}
+
+// logSearch logs searches returning partial results and slow searches
+func (c *GeoClient) logSearch(bucketName string, lat, lon, radius float64, stats SearchStats) {
+	if c.logger == nil {
+		return
+	}
+
+	var partial *PartialResultsError
+	if errors.As(stats.Err, &partial) {
+		c.logger.Warn("georedis: partial search results", "bucket", bucketName, "failedRanges", len(partial.Failed),
+			"results", stats.Results, "error", partial)
+	}
+
+	if c.slowSearch > 0 && stats.Duration >= c.slowSearch {
+		c.logger.Warn("georedis: slow search", "bucket", bucketName, "lat", lat, "lon", lon, "radius", radius,
+			"duration", stats.Duration, "ranges", stats.Ranges, "candidates", stats.Candidates, "results", stats.Results)
+	}
+}
@@ -0,0 +1,48 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestLogger(t *testing.T) {
+	const zSetLogger = "test:logger"
+
+	client.Del(zSetLogger)
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	geoClient := NewGeoClient(client, bitDepth, WithLogger(logger), WithSlowSearchThreshold(time.Nanosecond))
+	geoClient.AddCoordinates(zSetLogger, GeoKey{Label: "a", Lat: 52.52, Lon: 13.405})
+
+	if _, err := geoClient.Search(zSetLogger, 52.52, 13.405, 1000); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	if line := logs.String(); !strings.Contains(line, `"msg":"georedis: slow search"`) || !strings.Contains(line, `"bucket":"test:logger"`) ||
+		!strings.Contains(line, `"candidates":1`) {
+		t.Logf("expected the slow search to be logged got %s\n", line)
+		t.Fail()
+	}
+
+	logs.Reset()
+	if _, err := geoClient.Search(zSetLogger, 91, 0, 1000); err == nil {
+		t.Log("expected an invalid latitude to fail")
+		t.FailNow()
+	}
+
+	if line := logs.String(); !strings.Contains(line, `"msg":"georedis: operation failed"`) || !strings.Contains(line, `"attempt":1`) {
+		t.Logf("expected the failure to be logged got %s\n", line)
+		t.Fail()
+	}
+}
@@ -0,0 +1,170 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+// ErrInvalidCacheSize is returned for local cache sizes which are not positive
+var ErrInvalidCacheSize = errors.New("cache size must be positive")
+
+type (
+	// LocalCache is an in-process, size and TTL bounded LRU cache in front of searches and coordinate lookups
+	//
+	// Writes made through the cache invalidate all cached entries of the bucket. Writes made by other processes
+	// become visible once the TTL expires.
+	LocalCache struct {
+		client  *redis.Client
+		size    int
+		ttl     time.Duration
+		mu      sync.Mutex
+		entries map[string]*list.Element
+		order   *list.List
+	}
+
+	localCacheEntry struct {
+		key     string
+		bucket  string
+		value   interface{}
+		expires time.Time
+	}
+)
+
+// NewLocalCache returns a LocalCache holding at most size entries for at most ttl
+func NewLocalCache(client *redis.Client, size int, ttl time.Duration) (*LocalCache, error) {
+	if size <= 0 {
+		return nil, ErrInvalidCacheSize
+	}
+
+	return &LocalCache{
+		client:  client,
+		size:    size,
+		ttl:     ttl,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}, nil
+}
+
+// Search works like the package level Search but serves repeated identical queries from memory
+func (c *LocalCache) Search(bucketName string, lat, lon, radius float64, bitDepth uint8, options ...SearchOption) ([]Result, error) {
+	optionsKey, ok := newSearchOptions(options).cacheKey(radius)
+	if !ok {
+		return Search(c.client, bucketName, lat, lon, radius, bitDepth, options...)
+	}
+	key := fmt.Sprintf("search:%s:%g:%g:%g:%d:%s", bucketName, lat, lon, radius, bitDepth, optionsKey)
+	if value, ok := c.get(key); ok {
+		return append([]Result(nil), value.([]Result)...), nil
+	}
+
+	results, err := Search(c.client, bucketName, lat, lon, radius, bitDepth, options...)
+	if err != nil {
+		return results, err
+	}
+
+	c.set(key, bucketName, append([]Result(nil), results...))
+	return results, nil
+}
+
+// GetCoordinates works like the package level GetCoordinates but serves repeated lookups from memory
+func (c *LocalCache) GetCoordinates(bucketName string, bitDepth uint8, label string) (GeoKey, error) {
+	key := fmt.Sprintf("get:%s:%d:%s", bucketName, bitDepth, label)
+	if value, ok := c.get(key); ok {
+		return value.(GeoKey), nil
+	}
+
+	coordinates, err := GetCoordinates(c.client, bucketName, bitDepth, label)
+	if err != nil {
+		return coordinates, err
+	}
+
+	c.set(key, bucketName, coordinates)
+	return coordinates, nil
+}
+
+// AddCoordinates adds coordinates to the set and invalidates the cached entries of the bucket
+func (c *LocalCache) AddCoordinates(bucketName string, bitDepth uint8, coordinates ...GeoKey) (int64, error) {
+	defer c.Invalidate(bucketName)
+	return AddCoordinates(c.client, bucketName, bitDepth, coordinates...)
+}
+
+// RemoveCoordinatesByKeys removes coordinates from the set and invalidates the cached entries of the bucket
+func (c *LocalCache) RemoveCoordinatesByKeys(bucketName string, coordinatesKeys ...string) (int64, error) {
+	defer c.Invalidate(bucketName)
+	return RemoveCoordinatesByKeys(c.client, bucketName, coordinatesKeys...)
+}
+
+// Invalidate drops all cached entries of a bucket
+func (c *LocalCache) Invalidate(bucketName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		if element.Value.(*localCacheEntry).bucket == bucketName {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// Len returns the number of cached entries, including expired ones not evicted yet
+func (c *LocalCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *LocalCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*localCacheEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *LocalCache) set(key, bucketName string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+
+	c.entries[key] = c.order.PushFront(&localCacheEntry{
+		key:     key,
+		bucket:  bucketName,
+		value:   value,
+		expires: time.Now().Add(c.ttl),
+	})
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LocalCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*localCacheEntry).key)
+}
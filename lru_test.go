@@ -0,0 +1,97 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestLocalCache(t *testing.T) {
+	const zSetLocal = "test:search:local"
+
+	client.Del(zSetLocal)
+	cache, err := NewLocalCache(client, 2, time.Minute)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	cache.AddCoordinates(zSetLocal, bitDepth, GeoKey{Lat: 39.9523, Lon: -75.1638, Label: "Philadelphia"})
+
+	results, err := cache.Search(zSetLocal, 39.9523, -75.1638, 1000, bitDepth)
+	if err != nil || len(results) != 1 {
+		t.Logf("unexpected results %v error %v\n", results, err)
+		t.FailNow()
+	}
+
+	// bypassing the cache is not visible until the entry expires or is invalidated
+	AddCoordinates(client, zSetLocal, bitDepth, GeoKey{Lat: 39.9524, Lon: -75.1638, Label: "Nearby"})
+	if results, _ := cache.Search(zSetLocal, 39.9523, -75.1638, 1000, bitDepth); len(results) != 1 {
+		t.Logf("expected a cached result got %v\n", results)
+		t.Fail()
+	}
+
+	cache.RemoveCoordinatesByKeys(zSetLocal, "Philadelphia")
+	if results, _ := cache.Search(zSetLocal, 39.9523, -75.1638, 1000, bitDepth); len(results) != 1 || results[0].Label != "Nearby" {
+		t.Logf("expected the cache to be invalidated got %v\n", results)
+		t.Fail()
+	}
+
+	if _, err := cache.GetCoordinates(zSetLocal, bitDepth, "Philadelphia"); err != ErrMemberNotFound {
+		t.Logf("expected ErrMemberNotFound got %v\n", err)
+		t.Fail()
+	}
+	cache.GetCoordinates(zSetLocal, bitDepth, "Nearby")
+	cache.Search(zSetLocal, 1, 1, 1000, bitDepth)
+	if cache.Len() != 2 {
+		t.Logf("expected the cache to be bounded to 2 entries got %d\n", cache.Len())
+		t.Fail()
+	}
+}
+
+func TestLocalCacheCustomDistance(t *testing.T) {
+	const zSetCustom = "test:search:local:custom"
+
+	client.Del(zSetCustom)
+	cache, err := NewLocalCache(client, 10, time.Minute)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	AddCoordinates(client, zSetCustom, bitDepth, GeoKey{Lat: 39.9523, Lon: -75.1638, Label: "Philadelphia"})
+
+	scaled := func(factor float64) DistanceFunc {
+		return func(lat1, lon1, lat2, lon2 float64) float64 {
+			return Haversine(lat1, lon1, lat2, lon2) * factor
+		}
+	}
+
+	// closures of the same function must not share an entry
+	near, _ := cache.Search(zSetCustom, 39.9524, -75.1638, 1000, bitDepth, WithDistance(scaled(1)))
+	far, _ := cache.Search(zSetCustom, 39.9524, -75.1638, 1000, bitDepth, WithDistance(scaled(1e6)))
+	if len(near) != 1 || len(far) != 0 || cache.Len() != 0 {
+		t.Logf("expected custom distances to bypass the cache got %v, %v and %d entries\n", near, far, cache.Len())
+		t.Fail()
+	}
+
+	cache.Search(zSetCustom, 39.9524, -75.1638, 1000, bitDepth)
+	cache.Search(zSetCustom, 39.9524, -75.1638, 1000, bitDepth, WithTuning(SearchTuning{MinCells: 16}))
+	if cache.Len() != 2 {
+		t.Logf("expected tuned searches to be cached apart got %d entries\n", cache.Len())
+		t.Fail()
+	}
+}
+
+func TestLocalCacheInvalidSize(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		if _, err := NewLocalCache(client, size, time.Minute); err != ErrInvalidCacheSize {
+			t.Logf("expected ErrInvalidCacheSize for size %d got %v\n", size, err)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,247 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package memorystore implements georedis.CoreStore in memory, for tests and prototypes without a redis server
+//
+// A Store only backs a georedis.CoreClient. GeoClient, the package functions and the features built on them take a
+// *redis.Client and still need a redis server.
+package memorystore
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	"github.com/tapglue/georedis"
+)
+
+type (
+	// Store implements georedis.CoreStore with sorted slices and maps guarded by a mutex
+	Store struct {
+		mu     sync.RWMutex
+		sets   map[string]*sortedSet
+		hashes map[string]map[string][]byte
+	}
+
+	// sortedSet keeps members ordered by score and label like redis does
+	sortedSet struct {
+		scores  map[string]float64
+		members []georedis.Member
+	}
+)
+
+var _ georedis.CoreStore = (*Store)(nil)
+
+// New returns an empty Store
+func New() *Store {
+	return &Store{
+		sets:   map[string]*sortedSet{},
+		hashes: map[string]map[string][]byte{},
+	}
+}
+
+// ZAdd adds members to a sorted set, updating the score of existing ones
+func (s *Store) ZAdd(key string, members ...georedis.Member) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, ok := s.sets[key]
+	if !ok {
+		set = &sortedSet{scores: map[string]float64{}}
+		s.sets[key] = set
+	}
+
+	added := int64(0)
+	for _, member := range members {
+		if score, ok := set.scores[member.Label]; ok {
+			set.remove(georedis.Member{Label: member.Label, Score: score})
+		} else {
+			added++
+		}
+		set.insert(member)
+	}
+
+	return added, nil
+}
+
+// ZRem removes members from a sorted set
+func (s *Store) ZRem(key string, members ...string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, ok := s.sets[key]
+	if !ok {
+		return 0, nil
+	}
+
+	removed := int64(0)
+	for _, label := range members {
+		if score, ok := set.scores[label]; ok {
+			set.remove(georedis.Member{Label: label, Score: score})
+			removed++
+		}
+	}
+	if len(set.members) == 0 {
+		delete(s.sets, key)
+	}
+
+	return removed, nil
+}
+
+// ZScore returns the score of a member or georedis.ErrMemberNotFound
+func (s *Store) ZScore(key, member string) (float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if set, ok := s.sets[key]; ok {
+		if score, ok := set.scores[member]; ok {
+			return score, nil
+		}
+	}
+
+	return 0, georedis.ErrMemberNotFound
+}
+
+// ZCard returns the number of members of a sorted set
+func (s *Store) ZCard(key string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if set, ok := s.sets[key]; ok {
+		return int64(len(set.members)), nil
+	}
+
+	return 0, nil
+}
+
+// ZRange returns the members between the start and stop index, negative indexes count from the end
+func (s *Store) ZRange(key string, start, stop int64) ([]georedis.Member, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set, ok := s.sets[key]
+	if !ok {
+		return []georedis.Member{}, nil
+	}
+
+	length := int64(len(set.members))
+	if start < 0 {
+		start = max(length+start, 0)
+	}
+	if stop < 0 {
+		stop = length + stop
+	}
+	stop = min(stop, length-1)
+	if start > stop {
+		return []georedis.Member{}, nil
+	}
+
+	return slices.Clone(set.members[start : stop+1]), nil
+}
+
+// ZRangeByScore returns the members with a score between min and max
+func (s *Store) ZRangeByScore(key string, min, max float64, offset, count int64) ([]georedis.Member, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set, ok := s.sets[key]
+	if !ok {
+		return []georedis.Member{}, nil
+	}
+
+	first, _ := slices.BinarySearchFunc(set.members, min, func(m georedis.Member, score float64) int {
+		return cmp.Compare(m.Score, score)
+	})
+	last := first
+	for last < len(set.members) && set.members[last].Score <= max {
+		last++
+	}
+
+	members := set.members[first:last]
+	if count > 0 {
+		members = members[min64(offset, len(members)):]
+		members = members[:min64(count, len(members))]
+	}
+
+	return slices.Clone(members), nil
+}
+
+// HSet sets hash fields
+func (s *Store) HSet(key string, values map[string][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, ok := s.hashes[key]
+	if !ok {
+		hash = map[string][]byte{}
+		s.hashes[key] = hash
+	}
+	for field, value := range values {
+		hash[field] = slices.Clone(value)
+	}
+
+	return nil
+}
+
+// HMGet returns the values of hash fields, nil for missing ones
+func (s *Store) HMGet(key string, fields ...string) ([][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	values := make([][]byte, len(fields))
+	hash := s.hashes[key]
+	for idx, field := range fields {
+		if value, ok := hash[field]; ok {
+			values[idx] = slices.Clone(value)
+		}
+	}
+
+	return values, nil
+}
+
+// HDel deletes hash fields
+func (s *Store) HDel(key string, fields ...string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, ok := s.hashes[key]
+	if !ok {
+		return 0, nil
+	}
+
+	deleted := int64(0)
+	for _, field := range fields {
+		if _, ok := hash[field]; ok {
+			delete(hash, field)
+			deleted++
+		}
+	}
+	if len(hash) == 0 {
+		delete(s.hashes, key)
+	}
+
+	return deleted, nil
+}
+
+func (s *sortedSet) insert(member georedis.Member) {
+	idx, _ := slices.BinarySearchFunc(s.members, member, compareMembers)
+	s.members = slices.Insert(s.members, idx, member)
+	s.scores[member.Label] = member.Score
+}
+
+func (s *sortedSet) remove(member georedis.Member) {
+	if idx, ok := slices.BinarySearchFunc(s.members, member, compareMembers); ok {
+		s.members = slices.Delete(s.members, idx, idx+1)
+	}
+	delete(s.scores, member.Label)
+}
+
+func compareMembers(a, b georedis.Member) int {
+	return cmp.Or(cmp.Compare(a.Score, b.Score), cmp.Compare(a.Label, b.Label))
+}
+
+func min64(value int64, length int) int {
+	return int(min(value, int64(length)))
+}
@@ -0,0 +1,81 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package memorystore_test
+
+import (
+	"testing"
+
+	"github.com/tapglue/georedis"
+	"github.com/tapglue/georedis/memorystore"
+)
+
+func TestCoreClient(t *testing.T) {
+	client := georedis.NewCoreClient(memorystore.New(), 52)
+
+	added, err := client.AddCoordinates("cities",
+		georedis.GeoKey{Lat: 48.8566, Lon: 2.3522, Label: "Paris", Payload: []byte("capital")},
+		georedis.GeoKey{Lat: 48.8049, Lon: 2.1204, Label: "Versailles"},
+		georedis.GeoKey{Lat: 45.7640, Lon: 4.8357, Label: "Lyon"},
+	)
+	if err != nil || added != 3 {
+		t.Logf("expected to add 3 added %d error %v\n", added, err)
+		t.Fail()
+	}
+
+	results, err := client.Search("cities", 48.8566, 2.3522, 30000, georedis.WithPayloads())
+	if err != nil || len(results) != 2 || results[0].Label != "Paris" || results[1].Label != "Versailles" ||
+		string(results[0].Payload) != "capital" {
+		t.Logf("unexpected results %v error %v\n", results, err)
+		t.Fail()
+	}
+
+	if added, _ := client.AddCoordinates("cities", georedis.GeoKey{Lat: 48.8584, Lon: 2.2945, Label: "Paris"}); added != 0 {
+		t.Logf("expected moving a member not to add it again\n")
+		t.Fail()
+	}
+	if count, _ := client.CountCoordinates("cities"); count != 3 {
+		t.Logf("expected 3 members got %d\n", count)
+		t.Fail()
+	}
+
+	removed, err := client.RemoveCoordinatesByKeys("cities", "Paris", "Marseille")
+	if err != nil || removed != 1 {
+		t.Logf("expected to remove 1 removed %d error %v\n", removed, err)
+		t.Fail()
+	}
+	if _, err := client.GetCoordinates("cities", "Paris"); err != georedis.ErrMemberNotFound {
+		t.Logf("expected ErrMemberNotFound got %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestZRangeByScore(t *testing.T) {
+	store := memorystore.New()
+	store.ZAdd("set",
+		georedis.Member{Label: "c", Score: 3},
+		georedis.Member{Label: "a", Score: 1},
+		georedis.Member{Label: "b", Score: 2},
+		georedis.Member{Label: "d", Score: 4},
+	)
+
+	members, _ := store.ZRangeByScore("set", 2, 4, 0, 0)
+	if len(members) != 3 || members[0].Label != "b" || members[2].Label != "d" {
+		t.Logf("unexpected members %v\n", members)
+		t.Fail()
+	}
+
+	members, _ = store.ZRangeByScore("set", 1, 4, 1, 2)
+	if len(members) != 2 || members[0].Label != "b" || members[1].Label != "c" {
+		t.Logf("unexpected limited members %v\n", members)
+		t.Fail()
+	}
+
+	members, _ = store.ZRange("set", -2, -1)
+	if len(members) != 2 || members[0].Label != "c" || members[1].Label != "d" {
+		t.Logf("unexpected range %v\n", members)
+		t.Fail()
+	}
+}
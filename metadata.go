@@ -0,0 +1,222 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+
+	"gopkg.in/redis.v2"
+)
+
+type (
+	// MetadataStore stores the payloads of members, see WithMetadata
+	MetadataStore interface {
+		// GetPayloads returns the payloads of the labels, in the same order, nil if a label has none
+		GetPayloads(bucketName string, labels ...string) ([][]byte, error)
+		// SetPayloads stores payloads by label
+		SetPayloads(bucketName string, payloads map[string][]byte) error
+		// DeletePayloads removes the payloads of the labels
+		DeletePayloads(bucketName string, labels ...string) error
+	}
+
+	// HashMetadata stores payloads in the hash <bucket>:payload like the package level functions
+	HashMetadata struct {
+		client *redis.Client
+	}
+
+	// JSONMetadata stores every payload as a RedisJSON document <bucket>:json:<label>, payloads have to be JSON
+	JSONMetadata struct {
+		client *redis.Client
+	}
+)
+
+// WithMetadata makes the GeoClient store payloads in store instead of the hash <bucket>:payload
+//
+// Payloads are written after their members and read after searching WithPayloads. Features reading the hash
+// directly, like change streams or dumps, don't see payloads of other stores.
+func WithMetadata(store MetadataStore) ClientOption {
+	return func(c *GeoClient) {
+		c.metadata = store
+	}
+}
+
+// NewHashMetadata returns the MetadataStore used by default
+func NewHashMetadata(client *redis.Client) *HashMetadata {
+	return &HashMetadata{client: client}
+}
+
+// GetPayloads implements MetadataStore
+func (m *HashMetadata) GetPayloads(bucketName string, labels ...string) ([][]byte, error) {
+	return GetPayloads(m.client, bucketName, labels...)
+}
+
+// SetPayloads implements MetadataStore
+func (m *HashMetadata) SetPayloads(bucketName string, payloads map[string][]byte) error {
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	pairs := make([]string, 0, len(payloads)*2)
+	for label, payload := range payloads {
+		pairs = append(pairs, label, string(payload))
+	}
+
+	return m.client.HMSet(payloadKey(bucketName), pairs[0], pairs[1], pairs[2:]...).Err()
+}
+
+// DeletePayloads implements MetadataStore
+func (m *HashMetadata) DeletePayloads(bucketName string, labels ...string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	return m.client.HDel(payloadKey(bucketName), labels...).Err()
+}
+
+// NewJSONMetadata returns a MetadataStore keeping payloads in RedisJSON documents, it requires the RedisJSON module
+func NewJSONMetadata(client *redis.Client) *JSONMetadata {
+	return &JSONMetadata{client: client}
+}
+
+// GetPayloads implements MetadataStore
+func (m *JSONMetadata) GetPayloads(bucketName string, labels ...string) ([][]byte, error) {
+	payloads := make([][]byte, len(labels))
+	if len(labels) == 0 {
+		return payloads, nil
+	}
+
+	args := []string{"JSON.MGET"}
+	for _, label := range labels {
+		args = append(args, jsonKey(bucketName, label))
+	}
+	cmd := redis.NewCmd(append(args, ".")...)
+	m.client.Process(cmd)
+	reply, err := cmd.Result()
+	if err != nil {
+		return payloads, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(labels) {
+		return payloads, fmt.Errorf("unexpected JSON.MGET reply %v", reply)
+	}
+	for idx := range values {
+		if value, ok := values[idx].(string); ok {
+			payloads[idx] = []byte(value)
+		}
+	}
+
+	return payloads, nil
+}
+
+// SetPayloads implements MetadataStore
+func (m *JSONMetadata) SetPayloads(bucketName string, payloads map[string][]byte) error {
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	multi := m.client.Multi()
+	defer multi.Close()
+
+	_, err := multi.Exec(func() error {
+		for label, payload := range payloads {
+			multi.Process(redis.NewCmd("JSON.SET", jsonKey(bucketName, label), ".", string(payload)))
+		}
+		return nil
+	})
+
+	return err
+}
+
+// DeletePayloads implements MetadataStore
+func (m *JSONMetadata) DeletePayloads(bucketName string, labels ...string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(labels))
+	for idx, label := range labels {
+		keys[idx] = jsonKey(bucketName, label)
+	}
+
+	return m.client.Del(keys...).Err()
+}
+
+// GetPath returns the JSON value at a path of the payload of a label, like ".status", or ErrMemberNotFound
+func (m *JSONMetadata) GetPath(bucketName, label, path string) ([]byte, error) {
+	cmd := redis.NewCmd("JSON.GET", jsonKey(bucketName, label), path)
+	m.client.Process(cmd)
+	reply, err := cmd.Result()
+	if err == redis.Nil {
+		return nil, ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := reply.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected JSON.GET reply %v", reply)
+	}
+
+	return []byte(value), nil
+}
+
+// SetPath replaces the JSON value at a path of the payload of a label without rewriting the whole document
+func (m *JSONMetadata) SetPath(bucketName, label, path string, value []byte) error {
+	cmd := redis.NewCmd("JSON.SET", jsonKey(bucketName, label), path, string(value))
+	m.client.Process(cmd)
+
+	return cmd.Err()
+}
+
+func jsonKey(bucketName, label string) string {
+	return bucketName + ":json:" + label
+}
+
+// splitPayloads moves the payloads of coordinates to the metadata store of the GeoClient, it returns the
+// coordinates unchanged without one
+func (c *GeoClient) splitPayloads(coordinates []GeoKey) ([]GeoKey, map[string][]byte) {
+	if c.metadata == nil {
+		return coordinates, nil
+	}
+
+	stripped := make([]GeoKey, len(coordinates))
+	payloads := map[string][]byte{}
+	for idx := range coordinates {
+		stripped[idx] = coordinates[idx]
+		if coordinates[idx].Payload != nil {
+			payloads[coordinates[idx].Label] = coordinates[idx].Payload
+			stripped[idx].Payload = nil
+		}
+	}
+
+	return stripped, payloads
+}
+
+// attachMetadata reads the payloads of results from the metadata store of the GeoClient
+func (c *GeoClient) attachMetadata(bucketName string, results []Result) error {
+	labels := make([]string, len(results))
+	for idx := range results {
+		labels[idx] = results[idx].Label
+	}
+
+	payloads, err := c.metadata.GetPayloads(bucketName, labels...)
+	if err != nil {
+		return err
+	}
+
+	for idx := range results {
+		results[idx].Payload = payloads[idx]
+	}
+
+	return nil
+}
+
+// withoutPayloads keeps Search from reading payloads from the hash when the GeoClient has a metadata store
+func withoutPayloads(o *searchOptions) {
+	o.withPayloads = false
+}
@@ -0,0 +1,78 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func testMetadataStore(t *testing.T, bucketName string, store MetadataStore) {
+	geoClient := NewGeoClient(client, bitDepth, WithMetadata(store))
+
+	_, err := geoClient.AddCoordinates(bucketName,
+		GeoKey{Label: "shop", Lat: 52.52, Lon: 13.405, Payload: []byte(`{"status":"open"}`)},
+		GeoKey{Label: "bare", Lat: 52.521, Lon: 13.405},
+	)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	results, err := geoClient.Search(bucketName, 52.52, 13.405, 1000, WithPayloads())
+	if err != nil || len(results) != 2 || string(results[0].Payload) != `{"status":"open"}` || results[1].Payload != nil {
+		t.Logf("unexpected results %v, %v\n", results, err)
+		t.Fail()
+	}
+
+	geoClient.RemoveCoordinatesByKeys(bucketName, "shop")
+	if payloads, err := store.GetPayloads(bucketName, "shop"); err != nil || payloads[0] != nil {
+		t.Logf("expected the payload to be removed with its member got %q, %v\n", payloads, err)
+		t.Fail()
+	}
+}
+
+func TestHashMetadata(t *testing.T) {
+	const zSetHashMetadata = "test:metadata:hash"
+
+	client.Del(zSetHashMetadata, zSetHashMetadata+":payload")
+	testMetadataStore(t, zSetHashMetadata, NewHashMetadata(client))
+}
+
+func TestJSONMetadata(t *testing.T) {
+	const zSetJSONMetadata = "test:metadata:json"
+
+	client.Del(zSetJSONMetadata, zSetJSONMetadata+":json:shop", zSetJSONMetadata+":json:bare")
+	store := NewJSONMetadata(client)
+	if err := store.SetPayloads(zSetJSONMetadata, map[string][]byte{"probe": []byte("{}")}); err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "unknown command") {
+			t.Skip("redis runs without RedisJSON")
+		}
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	store.DeletePayloads(zSetJSONMetadata, "probe")
+
+	testMetadataStore(t, zSetJSONMetadata, store)
+
+	NewGeoClient(client, bitDepth, WithMetadata(store)).AddCoordinates(zSetJSONMetadata,
+		GeoKey{Label: "shop", Lat: 52.52, Lon: 13.405, Payload: []byte(`{"status":"open","seats":4}`)},
+	)
+	if err := store.SetPath(zSetJSONMetadata, "shop", ".status", []byte(`"closed"`)); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if status, err := store.GetPath(zSetJSONMetadata, "shop", ".status"); err != nil || string(status) != `"closed"` {
+		t.Logf("expected the updated status got %s, %v\n", status, err)
+		t.Fail()
+	}
+	if seats, err := store.GetPath(zSetJSONMetadata, "shop", ".seats"); err != nil || string(seats) != "4" {
+		t.Logf("expected the other fields to be kept got %s, %v\n", seats, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,103 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import "time"
+
+type (
+	// Metrics receives measurements of GeoClient operations and QueryCache lookups, prommetrics implements it
+	// for Prometheus
+	//
+	// Methods are called synchronously by the operations, so they should return quickly.
+	Metrics interface {
+		// ObserveSearch is called after every search of a GeoClient
+		ObserveSearch(bucketName string, stats SearchStats)
+		// ObserveError is called for every failed attempt of a GeoClient operation, retried ones included
+		ObserveError(err error)
+		// ObserveCache is called for every lookup of a QueryCache
+		ObserveCache(bucketName string, hit bool)
+	}
+
+	// SearchStats describes a single search, Ranges and Candidates are zero for searches run by native GEO
+	// commands
+	SearchStats struct {
+		Duration time.Duration
+		// Ranges is the number of score ranges read
+		Ranges int
+		// Candidates is the number of members read from the ranges before they were filtered by distance
+		Candidates int
+		Results    int
+		Err        error
+
+		// Plan, Fetch, Rank and Complete break the duration of a successful search down into planning the ranges,
+		// reading them, ranking the candidates by distance and attaching what the options ask for, they are zero
+		// for searches run by native GEO commands
+		Plan, Fetch, Rank, Complete time.Duration
+
+		ranges []geoRange
+	}
+)
+
+// startLaps returns the start of the first phase of a search recording stats
+func (o searchOptions) startLaps() time.Time {
+	if o.stats == nil {
+		return time.Time{}
+	}
+
+	return time.Now()
+}
+
+// lap returns the time since the last phase of a search recording stats ended and starts the next one
+func (o searchOptions) lap(last *time.Time) time.Duration {
+	if o.stats == nil {
+		return 0
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(*last)
+	*last = now
+
+	return elapsed
+}
+
+// WithMetrics reports the searches and errors of a GeoClient to metrics
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(c *GeoClient) {
+		c.metrics = metrics
+	}
+}
+
+// withStats makes Search record its ranges and candidates in stats
+func withStats(stats *SearchStats) SearchOption {
+	return func(o *searchOptions) {
+		o.stats = stats
+	}
+}
+
+// observed reports whether the searches of the GeoClient are measured
+func (c *GeoClient) observed() bool {
+	return c.metrics != nil || c.logger != nil || c.slowLog != nil
+}
+
+// observeSearch reports a search which started at start to the metrics, the logger and the slow log
+func (c *GeoClient) observeSearch(bucketName string, lat, lon, radius float64, start time.Time, stats *SearchStats, results []Result, err error) {
+	if !c.observed() {
+		return
+	}
+
+	stats.Duration = time.Since(start)
+	stats.Results = len(results)
+	stats.Err = err
+	if c.metrics != nil {
+		c.metrics.ObserveSearch(bucketName, *stats)
+	}
+	c.logSearch(bucketName, lat, lon, radius, *stats)
+	if c.slowLog != nil {
+		if err := c.slowLog.record(bucketName, lat, lon, radius, *stats); err != nil && c.logger != nil {
+			c.logger.Warn("georedis: recording slow search failed", "bucket", bucketName, "error", err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+type recordedMetrics struct {
+	searches []SearchStats
+	errors   []error
+	cache    []bool
+}
+
+func (m *recordedMetrics) ObserveSearch(bucketName string, stats SearchStats) {
+	m.searches = append(m.searches, stats)
+}
+
+func (m *recordedMetrics) ObserveError(err error) {
+	m.errors = append(m.errors, err)
+}
+
+func (m *recordedMetrics) ObserveCache(bucketName string, hit bool) {
+	m.cache = append(m.cache, hit)
+}
+
+func TestMetrics(t *testing.T) {
+	const zSetMetrics = "test:metrics"
+
+	client.Del(zSetMetrics)
+	metrics := &recordedMetrics{}
+	geoClient := NewGeoClient(client, bitDepth, WithMetrics(metrics))
+	geoClient.AddCoordinates(zSetMetrics, GeoKey{Label: "a", Lat: 52.52, Lon: 13.405}, GeoKey{Label: "b", Lat: 52.521, Lon: 13.405})
+
+	if _, err := geoClient.Search(zSetMetrics, 52.52, 13.405, 1000); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	if len(metrics.searches) != 1 || metrics.searches[0].Ranges == 0 || metrics.searches[0].Candidates < 2 ||
+		metrics.searches[0].Results != 2 || metrics.searches[0].Duration <= 0 {
+		t.Logf("expected the search to be observed got %+v\n", metrics.searches)
+		t.Fail()
+	}
+
+	if _, err := geoClient.Search(zSetMetrics, 91, 0, 1000); err == nil || len(metrics.errors) != 1 {
+		t.Logf("expected the failed search to be observed got %v, %v\n", err, metrics.errors)
+		t.Fail()
+	}
+
+	cache, err := NewQueryCache(client, time.Minute, 20, WithCacheMetrics(metrics))
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	cache.Search(zSetMetrics, 52.52, 13.405, 1000, bitDepth)
+	cache.Search(zSetMetrics, 52.52, 13.405, 1000, bitDepth)
+	if len(metrics.cache) != 2 || !metrics.cache[1] {
+		t.Logf("expected the second lookup to hit got %v\n", metrics.cache)
+		t.Fail()
+	}
+}
@@ -0,0 +1,81 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+type (
+	// Operation describes a call of a GeoClient method passing through the middlewares
+	Operation struct {
+		// Name is the name of the method, like "Search"
+		Name string
+		// Bucket is the bucket or fence set the operation works on, empty for operations on several buckets
+		Bucket string
+		// Write is set for operations modifying data
+		Write bool
+		// Args are the remaining arguments of the method in their order
+		Args []any
+
+		run func() error
+	}
+
+	// OpHandler handles an operation, the innermost handler runs the method
+	OpHandler func(op Operation) error
+
+	// Middleware wraps the handler of the operations, see WithMiddleware
+	Middleware func(next OpHandler) OpHandler
+)
+
+// WithMiddleware passes all operations of a GeoClient through the middlewares, the first one outermost
+//
+// A middleware may inspect the operation, return an error instead of calling next, call next several times or
+// wrap its error. The results of the method are only set when the innermost handler ran, a middleware returning
+// nil without calling next makes the method return zero values. Operations calling other operations, like
+// AddByGeohash and RemoveEverywhere, pass through the middlewares again for each of them.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(c *GeoClient) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// Run runs the operation, it is the innermost OpHandler
+func (op Operation) Run() error {
+	return op.run()
+}
+
+// chainMiddlewares composes the handler running operations through the middlewares of the client
+func (c *GeoClient) chainMiddlewares() {
+	if len(c.middlewares) == 0 {
+		c.handler = nil
+		return
+	}
+
+	handler := OpHandler(Operation.Run)
+	for idx := len(c.middlewares) - 1; idx >= 0; idx-- {
+		handler = c.middlewares[idx](handler)
+	}
+	c.handler = handler
+}
+
+// handle runs fn as the operation op through the middlewares
+func (c *GeoClient) handle(op Operation, fn func() error) error {
+	if c.handler == nil {
+		return fn()
+	}
+
+	op.run = fn
+	return c.handler(op)
+}
+
+// handleOp runs fn as the operation op through the middlewares and returns its result
+func handleOp[T any](c *GeoClient, op Operation, fn func() (T, error)) (T, error) {
+	var result T
+	err := c.handle(op, func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,97 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestMiddleware(t *testing.T) {
+	const zSetMiddleware = "test:middleware"
+
+	client.Del(zSetMiddleware)
+	errReadOnly := errors.New("read only")
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next OpHandler) OpHandler {
+			return func(op Operation) error {
+				calls = append(calls, name+":"+op.Name+":"+op.Bucket)
+				return next(op)
+			}
+		}
+	}
+	readOnly := false
+	guard := func(next OpHandler) OpHandler {
+		return func(op Operation) error {
+			if readOnly && op.Write {
+				return errReadOnly
+			}
+			return next(op)
+		}
+	}
+
+	geoClient := NewGeoClient(client, bitDepth, WithMiddleware(record("outer"), guard), WithMiddleware(record("inner")))
+
+	if added, err := geoClient.AddCoordinates(zSetMiddleware, GeoKey{Label: "a", Lat: 52.52, Lon: 13.405}); err != nil || added != 1 {
+		t.Logf("expected the member to be added got %d, %v\n", added, err)
+		t.Fail()
+	}
+
+	if len(calls) != 2 || calls[0] != "outer:AddCoordinates:"+zSetMiddleware || calls[1] != "inner:AddCoordinates:"+zSetMiddleware {
+		t.Logf("expected the outer middleware first got %v\n", calls)
+		t.Fail()
+	}
+
+	readOnly, calls = true, nil
+	if _, err := geoClient.RemoveCoordinatesByKeys(zSetMiddleware, "a"); err != errReadOnly {
+		t.Logf("expected the write to be rejected got %v\n", err)
+		t.Fail()
+	}
+
+	if len(calls) != 1 {
+		t.Logf("expected the inner middleware to be skipped got %v\n", calls)
+		t.Fail()
+	}
+
+	results, err := geoClient.Search(zSetMiddleware, 52.52, 13.405, 1000)
+	if err != nil || len(results) != 1 || results[0].Label != "a" {
+		t.Logf("expected reads to pass got %v, %v\n", results, err)
+		t.Fail()
+	}
+}
+
+func TestMiddlewareCallingNextTwice(t *testing.T) {
+	const zSetTwice = "drivers"
+
+	twice := func(next OpHandler) OpHandler {
+		return func(op Operation) error {
+			if err := next(op); err != nil {
+				return err
+			}
+			return next(op)
+		}
+	}
+	geoClient := NewGeoClient(client, bitDepth, WithNamespace("test-twice"), WithMiddleware(twice))
+	geoClient.Wipe()
+
+	if _, err := geoClient.AddCoordinates(zSetTwice, GeoKey{Label: "a", Lat: 52.52, Lon: 13.405}); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	if count := client.ZCard("test-twice:" + zSetTwice).Val(); count != 1 {
+		t.Logf("expected the member in the namespaced bucket got %d\n", count)
+		t.Fail()
+	}
+	if exists := client.Exists("test-twice:test-twice:" + zSetTwice).Val(); exists {
+		t.Logf("expected the second call not to prefix the bucket again\n")
+		t.Fail()
+	}
+}
@@ -0,0 +1,198 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+// Motion is the speed and heading of a member derived from its last two fixes
+type Motion struct {
+	// Speed is in meters per second
+	Speed float64
+	// Heading is in degrees clockwise from north, 0 when the member didn't move
+	Heading float64
+}
+
+// WithMotion returns the motion stored for each result, nil for members without one
+func WithMotion() SearchOption {
+	return func(o *searchOptions) {
+		o.withMotion = true
+	}
+}
+
+// WithMotionTracking records the last seen time of every update and sets the motion of the events, see
+// AddCoordinatesWithMotion
+func WithMotionTracking() FenceTrackerOption {
+	return func(t *FenceTracker) {
+		t.motion = true
+	}
+}
+
+// AddCoordinatesWithMotion adds coordinates to the set like AddCoordinates, records at as their last seen time and
+// returns and stores the motion since the previous fix, nil for members without a previous fix seen before at
+func AddCoordinatesWithMotion(client *redis.Client, bucketName string, bitDepth uint8, at time.Time, coordinates ...GeoKey) (int64, []*Motion, error) {
+	motions, err := measureMotion(client, bucketName, bitDepth, at, coordinates)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	added, err := AddCoordinates(client, bucketName, bitDepth, coordinates...)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return added, motions, storeMotion(client, bucketName, at, coordinates, motions)
+}
+
+// GetMotion returns the motions stored for the labels, in the same order, nil if a label has none
+func GetMotion(client *redis.Client, bucketName string, labels ...string) ([]*Motion, error) {
+	motions := make([]*Motion, len(labels))
+	if len(labels) == 0 {
+		return motions, nil
+	}
+
+	values, err := client.HMGet(motionKey(bucketName), labels...).Result()
+	if err != nil {
+		return motions, err
+	}
+
+	for idx := range values {
+		value, ok := values[idx].(string)
+		if !ok {
+			continue
+		}
+		if motions[idx], err = parseMotion(value); err != nil {
+			return motions, err
+		}
+	}
+
+	return motions, nil
+}
+
+// measureMotion derives the motions of the coordinates from the stored positions and last seen times, it has to
+// run before the coordinates are written
+func measureMotion(client *redis.Client, bucketName string, bitDepth uint8, at time.Time, coordinates []GeoKey) ([]*Motion, error) {
+	motions := make([]*Motion, len(coordinates))
+	if len(coordinates) == 0 {
+		return motions, nil
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	positions := make([]*redis.FloatCmd, len(coordinates))
+	seen := make([]*redis.FloatCmd, len(coordinates))
+	_, err := multi.Exec(func() error {
+		for idx := range coordinates {
+			positions[idx] = multi.ZScore(bucketName, coordinates[idx].Label)
+			seen[idx] = multi.ZScore(lastSeenKey(bucketName), coordinates[idx].Label)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return motions, err
+	}
+
+	for idx, coordinate := range coordinates {
+		score, last := positions[idx].Val(), seen[idx].Val()
+		elapsed := unixScore(at) - last
+		if positions[idx].Err() != nil || seen[idx].Err() != nil || elapsed <= 0 {
+			continue
+		}
+
+		lat, lon := geohashEncoder{bitDepth: bitDepth}.DecodeInt(uint64(score))
+		motions[idx] = newMotion(lat, lon, coordinate.Lat, coordinate.Lon, elapsed)
+	}
+
+	return motions, nil
+}
+
+func newMotion(fromLat, fromLon, toLat, toLon, seconds float64) *Motion {
+	distance := Haversine(fromLat, fromLon, toLat, toLon)
+	motion := &Motion{Speed: distance / seconds}
+	if distance > 0 {
+		motion.Heading = Bearing(fromLat, fromLon, toLat, toLon)
+	}
+
+	return motion
+}
+
+// storeMotion records the last seen times and the motions, motions which can't be derived are removed so they
+// don't outlive the fix they belong to
+func storeMotion(client *redis.Client, bucketName string, at time.Time, coordinates []GeoKey, motions []*Motion) error {
+	if len(coordinates) == 0 {
+		return nil
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	_, err := multi.Exec(func() error {
+		seen := make([]redis.Z, len(coordinates))
+		for idx, coordinate := range coordinates {
+			seen[idx] = redis.Z{Score: unixScore(at), Member: coordinate.Label}
+			if motions[idx] == nil {
+				multi.HDel(motionKey(bucketName), coordinate.Label)
+			} else {
+				multi.HSet(motionKey(bucketName), coordinate.Label, formatMotion(motions[idx]))
+			}
+		}
+		multi.ZAdd(lastSeenKey(bucketName), seen...)
+		return nil
+	})
+
+	return err
+}
+
+func attachMotion(client *redis.Client, bucketName string, results []Result) error {
+	labels := make([]string, len(results))
+	for idx := range results {
+		labels[idx] = results[idx].Label
+	}
+
+	motions, err := GetMotion(client, bucketName, labels...)
+	if err != nil {
+		return err
+	}
+
+	for idx := range results {
+		results[idx].Motion = motions[idx]
+	}
+
+	return nil
+}
+
+func formatMotion(motion *Motion) string {
+	return strconv.FormatFloat(motion.Speed, 'f', -1, 64) + " " + strconv.FormatFloat(motion.Heading, 'f', -1, 64)
+}
+
+func parseMotion(value string) (*Motion, error) {
+	speed, heading, ok := strings.Cut(value, " ")
+	if !ok {
+		return nil, fmt.Errorf("malformed motion %q", value)
+	}
+
+	motion := &Motion{}
+	var err error
+	if motion.Speed, err = strconv.ParseFloat(speed, 64); err != nil {
+		return nil, err
+	}
+	if motion.Heading, err = strconv.ParseFloat(heading, 64); err != nil {
+		return nil, err
+	}
+
+	return motion, nil
+}
+
+func motionKey(bucketName string) string {
+	return bucketName + ":motion"
+}
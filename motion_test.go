@@ -0,0 +1,50 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestAddCoordinatesWithMotion(t *testing.T) {
+	const zSetMotion = "test:motion"
+
+	client.Del(zSetMotion, zSetMotion+":seen", zSetMotion+":motion")
+
+	start := time.Now()
+	_, motions, err := AddCoordinatesWithMotion(client, zSetMotion, bitDepth, start, GeoKey{Lat: 52.52, Lon: 13.405, Label: "car"})
+	if err != nil || motions[0] != nil {
+		t.Logf("expected no motion for the first fix got %v error %v\n", motions, err)
+		t.FailNow()
+	}
+
+	// about 1112 meters north in 100 seconds, the decoded cells may put the heading just west of north
+	_, motions, err = AddCoordinatesWithMotion(client, zSetMotion, bitDepth, start.Add(100*time.Second), GeoKey{Lat: 52.53, Lon: 13.405, Label: "car"})
+	if err != nil || motions[0] == nil {
+		t.Logf("expected a motion got %v error %v\n", motions, err)
+		t.FailNow()
+	}
+	if math.Abs(motions[0].Speed-11.12) > 0.05 || math.Min(motions[0].Heading, 360-motions[0].Heading) > 0.01 {
+		t.Logf("expected 11.12 m/s heading north got %v\n", *motions[0])
+		t.Fail()
+	}
+
+	results, err := Search(client, zSetMotion, 52.53, 13.405, 100, bitDepth, WithMotion())
+	if err != nil || len(results) != 1 || results[0].Motion == nil || *results[0].Motion != *motions[0] {
+		t.Logf("expected the stored motion got %v error %v\n", results, err)
+		t.Fail()
+	}
+
+	RemoveCoordinatesByKeys(client, zSetMotion, "car")
+	if stored, err := GetMotion(client, zSetMotion, "car"); err != nil || stored[0] != nil {
+		t.Logf("expected the motion to be removed with the member got %v error %v\n", stored, err)
+		t.Fail()
+	}
+}
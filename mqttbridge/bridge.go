@@ -0,0 +1,216 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package mqttbridge connects MQTT devices to georedis buckets
+//
+// A Bridge subscribes to a topic filter carrying device locations, writes them through a georedis.Writer and
+// publishes fence events back to a topic per device. By default devices publish
+//
+//	{"lat": 52.52, "lon": 13.405}
+//
+// to devices/{label}/location and receive the events of their label on devices/{label}/events.
+package mqttbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/tapglue/georedis"
+)
+
+const (
+	// DefaultTopic is the topic filter subscribed to when no other one is configured
+	DefaultTopic = "devices/+/location"
+	// DefaultEventTopic is the topic template events are published to when no other one is configured
+	DefaultEventTopic = "devices/{label}/events"
+
+	publishTimeout = 10 * time.Second
+)
+
+// ErrNoLabel is returned by the default parser for payloads without a label on topics without a wildcard segment
+var ErrNoLabel = errors.New("location without a label")
+
+type (
+	// Bridge writes device locations received over MQTT and publishes fence events to the devices
+	Bridge struct {
+		client     mqtt.Client
+		writer     *georedis.Writer
+		bucketName string
+		topic      string
+		eventTopic string
+		qos        byte
+		parse      Parser
+		onError    func(error)
+	}
+
+	// Parser turns a message into the coordinates of a device
+	Parser func(topic string, payload []byte) (georedis.GeoKey, error)
+
+	// Option configures a Bridge
+	Option func(*Bridge)
+
+	// Location is the JSON payload understood by the default parser, Label overrides the label taken from the topic
+	Location struct {
+		Label string  `json:"label,omitempty"`
+		Lat   float64 `json:"lat"`
+		Lon   float64 `json:"lon"`
+	}
+
+	// Event is the JSON payload of the published fence events
+	Event struct {
+		Type     georedis.FenceEventType `json:"type"`
+		Fence    string                  `json:"fence"`
+		Label    string                  `json:"label"`
+		Lat      float64                 `json:"lat"`
+		Lon      float64                 `json:"lon"`
+		Distance float64                 `json:"distance,omitempty"`
+		Time     time.Time               `json:"time"`
+	}
+)
+
+// WithTopic sets the topic filter locations are received from, the first single level wildcard of the default
+// parser's filter names the label
+func WithTopic(topic string) Option {
+	return func(b *Bridge) {
+		b.topic = topic
+	}
+}
+
+// WithEventTopic sets the topic events are published to, {label} is replaced by the label of the event
+func WithEventTopic(template string) Option {
+	return func(b *Bridge) {
+		b.eventTopic = template
+	}
+}
+
+// WithQoS sets the quality of service of the subscription and the published events, 0 by default
+func WithQoS(qos byte) Option {
+	return func(b *Bridge) {
+		b.qos = qos
+	}
+}
+
+// WithParser replaces the default JSON parser
+func WithParser(parse Parser) Option {
+	return func(b *Bridge) {
+		b.parse = parse
+	}
+}
+
+// WithErrorHandler sets the function receiving malformed messages and failed writes or publishes
+func WithErrorHandler(handler func(error)) Option {
+	return func(b *Bridge) {
+		b.onError = handler
+	}
+}
+
+// New returns a Bridge writing the locations received by client to bucketName, the client has to be connected
+// before Start is called
+func New(client mqtt.Client, writer *georedis.Writer, bucketName string, options ...Option) *Bridge {
+	b := &Bridge{
+		client:     client,
+		writer:     writer,
+		bucketName: bucketName,
+		topic:      DefaultTopic,
+		eventTopic: DefaultEventTopic,
+		onError:    func(error) {},
+	}
+	for _, option := range options {
+		option(b)
+	}
+	if b.parse == nil {
+		b.parse = JSONParser(b.topic)
+	}
+
+	return b
+}
+
+// Start subscribes to the location topic
+func (b *Bridge) Start() error {
+	token := b.client.Subscribe(b.topic, b.qos, b.receive)
+	token.Wait()
+
+	return token.Error()
+}
+
+// Stop unsubscribes from the location topic, buffered locations are written by the writer
+func (b *Bridge) Stop() error {
+	token := b.client.Unsubscribe(b.topic)
+	token.Wait()
+
+	return token.Error()
+}
+
+// Handle publishes a fence event to the topic of its label, use it as a georedis.FenceTracker event handler
+func (b *Bridge) Handle(event georedis.FenceEvent) {
+	payload, err := json.Marshal(Event{
+		Type:     event.Type,
+		Fence:    event.Fence,
+		Label:    event.Label,
+		Lat:      event.Lat,
+		Lon:      event.Lon,
+		Distance: event.Distance,
+		Time:     event.Time,
+	})
+	if err != nil {
+		b.onError(err)
+		return
+	}
+
+	topic := strings.ReplaceAll(b.eventTopic, "{label}", event.Label)
+	token := b.client.Publish(topic, b.qos, false, payload)
+	if !token.WaitTimeout(publishTimeout) {
+		b.onError(fmt.Errorf("publishing to %s timed out", topic))
+		return
+	}
+	if err := token.Error(); err != nil {
+		b.onError(err)
+	}
+}
+
+func (b *Bridge) receive(_ mqtt.Client, message mqtt.Message) {
+	coordinate, err := b.parse(message.Topic(), message.Payload())
+	if err != nil {
+		b.onError(fmt.Errorf("message on %s: %w", message.Topic(), err))
+		return
+	}
+
+	if err := b.writer.Update(b.bucketName, coordinate); err != nil {
+		b.onError(err)
+	}
+}
+
+// JSONParser returns a Parser reading Location payloads and taking the label from the topic level matching the
+// first single level wildcard of filter
+func JSONParser(filter string) Parser {
+	level := -1
+	for idx, part := range strings.Split(filter, "/") {
+		if part == "+" {
+			level = idx
+			break
+		}
+	}
+
+	return func(topic string, payload []byte) (georedis.GeoKey, error) {
+		location := Location{}
+		if err := json.Unmarshal(payload, &location); err != nil {
+			return georedis.GeoKey{}, err
+		}
+
+		label := location.Label
+		if parts := strings.Split(topic, "/"); label == "" && level >= 0 && level < len(parts) {
+			label = parts[level]
+		}
+		if label == "" {
+			return georedis.GeoKey{}, ErrNoLabel
+		}
+
+		return georedis.GeoKey{Lat: location.Lat, Lon: location.Lon, Label: label}, nil
+	}
+}
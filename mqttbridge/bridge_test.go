@@ -0,0 +1,73 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package mqttbridge_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/tapglue/georedis"
+	. "github.com/tapglue/georedis/mqttbridge"
+)
+
+type (
+	fakeToken struct{}
+
+	fakeClient struct {
+		mqtt.Client
+		published map[string][]byte
+	}
+)
+
+func (fakeToken) Wait() bool                     { return true }
+func (fakeToken) WaitTimeout(time.Duration) bool { return true }
+func (fakeToken) Done() <-chan struct{}          { return nil }
+func (fakeToken) Error() error                   { return nil }
+
+func (c *fakeClient) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	c.published[topic] = payload.([]byte)
+	return fakeToken{}
+}
+
+func TestJSONParser(t *testing.T) {
+	parse := JSONParser("fleet/+/+/location")
+
+	coordinate, err := parse("fleet/truck-7/eu/location", []byte(`{"lat": 52.52, "lon": 13.405}`))
+	if err != nil || coordinate.Label != "truck-7" || coordinate.Lat != 52.52 || coordinate.Lon != 13.405 {
+		t.Logf("unexpected coordinate %v error %v\n", coordinate, err)
+		t.Fail()
+	}
+
+	coordinate, err = parse("fleet/truck-7/eu/location", []byte(`{"label": "trailer-2", "lat": 1, "lon": 2}`))
+	if err != nil || coordinate.Label != "trailer-2" {
+		t.Logf("expected the payload label to win got %v error %v\n", coordinate, err)
+		t.Fail()
+	}
+
+	if _, err := JSONParser("locations")("locations", []byte(`{"lat": 1, "lon": 2}`)); err != ErrNoLabel {
+		t.Logf("expected ErrNoLabel got %v\n", err)
+		t.Fail()
+	}
+	if _, err := parse("fleet/truck-7/eu/location", []byte(`{`)); err == nil {
+		t.Logf("expected malformed payloads to fail\n")
+		t.Fail()
+	}
+}
+
+func TestHandlePublishesToDeviceTopic(t *testing.T) {
+	client := &fakeClient{published: map[string][]byte{}}
+	bridge := New(client, nil, "devices", WithEventTopic("fleet/{label}/alerts"))
+
+	bridge.Handle(georedis.FenceEvent{Type: georedis.FenceEnter, Fence: "depot", Label: "truck-7", Lat: 52.52, Lon: 13.405})
+
+	event := Event{}
+	if err := json.Unmarshal(client.published["fleet/truck-7/alerts"], &event); err != nil || event.Type != georedis.FenceEnter || event.Fence != "depot" {
+		t.Logf("unexpected publishes %v error %v\n", client.published, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,113 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"slices"
+
+	"gopkg.in/redis.v2"
+)
+
+// SearchByRadiusMultiBucket searches like Search in all buckets at once and returns the merged results sorted by
+// distance, each with the bucket it was found in
+//
+// The range queries of all buckets are pipelined in a single round trip. Options apply to every bucket, the limit
+// also applies to the merged results.
+func SearchByRadiusMultiBucket(client *redis.Client, buckets []string, lat, lon, radius float64, bitDepth uint8, options ...SearchOption) ([]Result, error) {
+	opts := newSearchOptions(options)
+	meters := opts.unit.ToMeters(radius)
+
+	ranges, _, err := planRanges(lat, lon, meters, bitDepth, opts.tuning)
+	if err != nil {
+		return []Result{}, err
+	}
+
+	candidates, err := fetchBucketRanges(client, buckets, ranges, opts)
+	if err != nil {
+		return []Result{}, err
+	}
+
+	merged := []Result{}
+	for idx, bucketName := range buckets {
+		matched := matchCandidates(candidates[idx], opts.labelMatch)
+		results := rankResults(lat, lon, meters, bitDepth, matched, opts.rankLimit(), opts.distance)
+		if results, err = completeResults(client, bucketName, results, opts); err != nil {
+			return []Result{}, err
+		}
+
+		for key := range results {
+			results[key].Bucket = bucketName
+		}
+		merged = append(merged, results...)
+	}
+
+	slices.SortStableFunc(merged, byDistance)
+	if opts.limit >= 0 && len(merged) > opts.limit {
+		merged = merged[:opts.limit]
+	}
+
+	return merged, nil
+}
+
+// SearchByRadiusMultiBucket searches like Search in all buckets at once and returns the merged results
+func (c *GeoClient) SearchByRadiusMultiBucket(buckets []string, lat, lon, radius float64, options ...SearchOption) ([]Result, error) {
+	return handleOp(c, Operation{Name: "SearchByRadiusMultiBucket", Args: []any{buckets, lat, lon, radius, options}}, func() ([]Result, error) {
+		keys := make([]string, len(buckets))
+		for idx := range buckets {
+			keys[idx] = c.key(buckets[idx])
+		}
+
+		results, err := withRetry(c, func() ([]Result, error) {
+			return SearchByRadiusMultiBucket(c.client, keys, lat, lon, radius, c.bitDepth, options...)
+		})
+		for idx := range results {
+			results[idx].Bucket = c.unkey(results[idx].Bucket)
+		}
+
+		return results, err
+	})
+}
+
+// fetchBucketRanges returns the members of every bucket inside the ranges, in the order of buckets
+func fetchBucketRanges(client *redis.Client, buckets []string, ranges []geoRange, opts searchOptions) ([][]redis.Z, error) {
+	candidates := make([][]redis.Z, len(buckets))
+
+	if since := opts.since(); !since.IsZero() {
+		for idx, bucketName := range buckets {
+			fresh, err := fetchFreshRanges(client, bucketName, bucketName, ranges, since)
+			if err != nil {
+				return nil, err
+			}
+			candidates[idx] = fresh
+		}
+		return candidates, nil
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	cmds := make([][]*redis.ZSliceCmd, len(buckets))
+	_, err := multi.Exec(func() error {
+		for idx, bucketName := range buckets {
+			cmds[idx] = make([]*redis.ZSliceCmd, len(ranges))
+			for key := range ranges {
+				cmds[idx][key] = multi.ZRangeByScoreWithScores(bucketName, ranges[key].query(0, 0))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range cmds {
+		for _, cmd := range cmds[idx] {
+			candidates[idx] = append(candidates[idx], cmd.Val()...)
+		}
+	}
+
+	return candidates, nil
+}
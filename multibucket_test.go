@@ -0,0 +1,45 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSearchByRadiusMultiBucket(t *testing.T) {
+	const zSetCafes, zSetBars = "test:multi:cafes", "test:multi:bars"
+
+	client.Del(zSetCafes, zSetBars)
+	AddCoordinates(client, zSetCafes, bitDepth,
+		GeoKey{Label: "espresso", Lat: 52.521, Lon: 13.405},
+		GeoKey{Label: "far", Lat: 48.137, Lon: 11.575},
+	)
+	AddCoordinates(client, zSetBars, bitDepth,
+		GeoKey{Label: "pub", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "tavern", Lat: 52.522, Lon: 13.405},
+	)
+
+	results, err := SearchByRadiusMultiBucket(client, []string{zSetCafes, zSetBars}, 52.52, 13.405, 1000, bitDepth)
+	if err != nil || len(results) != 3 {
+		t.Logf("expected 3 results got %v, %v\n", results, err)
+		t.FailNow()
+	}
+
+	if results[0].Label != "pub" || results[0].Bucket != zSetBars ||
+		results[1].Label != "espresso" || results[1].Bucket != zSetCafes ||
+		results[2].Label != "tavern" || results[2].Bucket != zSetBars {
+		t.Logf("expected the results of both buckets sorted by distance got %v\n", results)
+		t.Fail()
+	}
+
+	results, err = SearchByRadiusMultiBucket(client, []string{zSetCafes, zSetBars}, 52.52, 13.405, 1000, bitDepth, WithLimit(2))
+	if err != nil || len(results) != 2 || results[1].Label != "espresso" {
+		t.Logf("expected the 2 nearest results got %v, %v\n", results, err)
+		t.Fail()
+	}
+}
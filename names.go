@@ -0,0 +1,184 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/redis.v2"
+)
+
+// maxPrefixMatches bounds the number of names a prefix search reads from the name index
+const maxPrefixMatches = 10000
+
+// setNamesLuaBody replaces the names of members in the name index
+//
+// KEYS are the name index and the names of the members, ARGV holds label, name, index entry triples. Entries are
+// the lower case name and the label separated by a zero byte, so members with the same name sort next to each
+// other. An empty name removes the member from the index.
+const setNamesLuaBody = `
+for i = 1, #ARGV, 3 do
+  local previous = redis.call('HGET', KEYS[2], ARGV[i])
+  if previous then
+    redis.call('ZREM', KEYS[1], string.lower(previous) .. '\0' .. ARGV[i])
+  end
+
+  if ARGV[i + 1] == '' then
+    redis.call('HDEL', KEYS[2], ARGV[i])
+  else
+    redis.call('ZADD', KEYS[1], 0, ARGV[i + 2])
+    redis.call('HSET', KEYS[2], ARGV[i], ARGV[i + 1])
+  end
+end
+
+return 0
+`
+
+var setNamesScript = newLuaScript(setNamesLuaBody)
+
+// SetNames indexes the names of members, by label, for SearchByRadiusWithPrefix, empty names remove them
+//
+// Names are matched case insensitively. RemoveCoordinatesByKeys removes the names together with their member,
+// names of members removed otherwise never match a search.
+func SetNames(client *redis.Client, bucketName string, names map[string]string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	args := make([]string, 0, len(names)*3)
+	for label, name := range names {
+		args = append(args, label, name, nameEntry(name, label))
+	}
+
+	_, err := setNamesScript.run(client, []string{nameIndexKey(bucketName), namesKey(bucketName)}, args)
+	return err
+}
+
+// SearchByRadiusWithPrefix searches like Search for members whose name starts with prefix, see SetNames
+//
+// The names are read from a lexicographic index, up to 10000 of them per search, and only the members found there
+// are decoded and filtered by distance.
+func SearchByRadiusWithPrefix(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, prefix string, options ...SearchOption) ([]Result, error) {
+	opts := newSearchOptions(options)
+	radius = opts.unit.ToMeters(radius)
+
+	// planning validates the coordinates, the radius and the bit depth like Search does
+	if _, _, err := planRanges(lat, lon, radius, bitDepth, opts.tuning); err != nil {
+		return []Result{}, err
+	}
+
+	prefix = strings.ToLower(prefix)
+	cmd := redis.NewStringSliceCmd("ZRANGEBYLEX", nameIndexKey(bucketName), "["+prefix, "["+prefix+"\xff",
+		"LIMIT", "0", strconv.Itoa(maxPrefixMatches))
+	client.Process(cmd)
+	entries, err := cmd.Result()
+	if err != nil {
+		return []Result{}, err
+	}
+
+	labels := make([]string, len(entries))
+	for idx, entry := range entries {
+		_, labels[idx], _ = strings.Cut(entry, "\x00")
+	}
+
+	candidates, err := memberScores(client, bucketName, labels)
+	if err != nil {
+		return []Result{}, err
+	}
+
+	candidates = matchCandidates(candidates, opts.labelMatch)
+	results := rankResults(lat, lon, radius, bitDepth, candidates, opts.rankLimit(), opts.distance)
+
+	return completeResults(client, bucketName, results, opts)
+}
+
+// SetNames indexes the names of members for SearchByRadiusWithPrefix
+func (c *GeoClient) SetNames(bucketName string, names map[string]string) error {
+	return c.handle(Operation{Name: "SetNames", Bucket: bucketName, Write: true, Args: []any{names}}, func() error {
+		key := c.key(bucketName)
+		defer c.wrote(key)
+		err := c.do(func() error {
+			return SetNames(c.client, key, names)
+		})
+		if err != nil {
+			return err
+		}
+
+		labels := make([]string, 0, len(names))
+		for label := range names {
+			labels = append(labels, label)
+		}
+
+		return c.audit.record(c.client, AuditUpdate, key, labels, "set names")
+	})
+}
+
+// SearchByRadiusWithPrefix searches like Search for members whose name starts with prefix
+func (c *GeoClient) SearchByRadiusWithPrefix(bucketName string, lat, lon, radius float64, prefix string, options ...SearchOption) ([]Result, error) {
+	return handleOp(c, Operation{Name: "SearchByRadiusWithPrefix", Bucket: bucketName, Args: []any{lat, lon, radius, prefix, options}}, func() ([]Result, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() ([]Result, error) {
+			return SearchByRadiusWithPrefix(c.reader(key), key, lat, lon, radius, c.bitDepth, prefix, options...)
+		})
+	})
+}
+
+// memberScores returns the labels which are members of the bucket with their scores
+func memberScores(client *redis.Client, bucketName string, labels []string) ([]redis.Z, error) {
+	if len(labels) == 0 {
+		return []redis.Z{}, nil
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	scores := make([]*redis.FloatCmd, len(labels))
+	_, err := multi.Exec(func() error {
+		for idx, label := range labels {
+			scores[idx] = multi.ZScore(bucketName, label)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return []redis.Z{}, err
+	}
+
+	members := make([]redis.Z, 0, len(labels))
+	for idx := range scores {
+		if scores[idx].Err() == nil {
+			members = append(members, redis.Z{Score: scores[idx].Val(), Member: labels[idx]})
+		}
+	}
+
+	return members, nil
+}
+
+// clearNames removes the labels from the name index
+func clearNames(client *redis.Client, bucketName string, labels ...string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	names := make(map[string]string, len(labels))
+	for _, label := range labels {
+		names[label] = ""
+	}
+
+	return SetNames(client, bucketName, names)
+}
+
+func nameEntry(name, label string) string {
+	return strings.ToLower(name) + "\x00" + label
+}
+
+func nameIndexKey(bucketName string) string {
+	return bucketName + ":names"
+}
+
+func namesKey(bucketName string) string {
+	return bucketName + ":name"
+}
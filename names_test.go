@@ -0,0 +1,49 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSearchByRadiusWithPrefix(t *testing.T) {
+	const zSetNames = "test:names"
+
+	client.Del(zSetNames, zSetNames+":names", zSetNames+":name")
+	AddCoordinates(client, zSetNames, bitDepth,
+		GeoKey{Label: "a", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "b", Lat: 52.521, Lon: 13.405},
+		GeoKey{Label: "c", Lat: 52.522, Lon: 13.405},
+		GeoKey{Label: "d", Lat: 48.137, Lon: 11.575},
+	)
+	SetNames(client, zSetNames, map[string]string{
+		"a": "Starbucks",
+		"b": "Star Kebab",
+		"c": "Burger Shack",
+		"d": "Starbucks",
+	})
+
+	results, err := SearchByRadiusWithPrefix(client, zSetNames, 52.52, 13.405, 1000, bitDepth, "star")
+	if err != nil || len(results) != 2 || results[0].Label != "a" || results[1].Label != "b" {
+		t.Logf("expected both nearby names starting with star got %v, %v\n", results, err)
+		t.Fail()
+	}
+
+	SetNames(client, zSetNames, map[string]string{"b": "Kebab"})
+	results, err = SearchByRadiusWithPrefix(client, zSetNames, 52.52, 13.405, 1000, bitDepth, "Star")
+	if err != nil || len(results) != 1 || results[0].Label != "a" {
+		t.Logf("expected the renamed member to stop matching got %v, %v\n", results, err)
+		t.Fail()
+	}
+
+	RemoveCoordinatesByKeys(client, zSetNames, "a")
+	if entries := client.ZCard(zSetNames + ":names").Val(); entries != 3 {
+		t.Logf("expected the removed member to leave the name index got %d entries\n", entries)
+		t.Fail()
+	}
+}
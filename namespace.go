@@ -0,0 +1,145 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"gopkg.in/redis.v2"
+)
+
+const wipeBatchSize = 1000
+
+var (
+	// ErrInvalidNamespace is returned for namespaces which are empty or contain ':' or glob characters
+	ErrInvalidNamespace = errors.New("namespace must not be empty or contain ':', '*', '?', '[', ']' or '\\'")
+	// ErrNoNamespace is returned by the namespace methods of a GeoClient without a namespace
+	ErrNoNamespace = errors.New("client has no namespace")
+)
+
+// WithNamespace prefixes the keys of all buckets and fence sets of a GeoClient with "<namespace>:", so their
+// payloads, histories, fences and all other keys derived from them are kept apart from other namespaces
+//
+// Bucket names passed to and returned by the GeoClient are the ones without the prefix, the operations seen by
+// middlewares too. Namespaces must pass ValidateNamespace for WipeNamespace to remove them.
+func WithNamespace(namespace string) ClientOption {
+	return func(c *GeoClient) {
+		c.namespace = namespace
+	}
+}
+
+// Namespace returns a copy of the GeoClient working in another namespace, it shares the connections of c
+func (c *GeoClient) Namespace(namespace string) *GeoClient {
+	clone := *c
+	clone.namespace = namespace
+
+	return &clone
+}
+
+// ValidateNamespace returns ErrInvalidNamespace unless namespace can't match the keys of other namespaces
+func ValidateNamespace(namespace string) error {
+	if namespace == "" || strings.ContainsAny(namespace, ":*?[]\\") {
+		return ErrInvalidNamespace
+	}
+
+	return nil
+}
+
+// NamespaceBuckets returns the buckets which coordinates were added to in a namespace, without the prefix and
+// sorted by name
+func NamespaceBuckets(client *redis.Client, namespace string) ([]string, error) {
+	buckets, err := client.SMembers(namespaceBucketsKey(namespace)).Result()
+	if err != nil {
+		return []string{}, err
+	}
+	slices.Sort(buckets)
+
+	return buckets, nil
+}
+
+// WipeNamespace deletes all keys of a namespace and returns their number
+//
+// Keys are found with SCAN, keys written while wiping may survive.
+func WipeNamespace(client *redis.Client, namespace string) (int64, error) {
+	if err := ValidateNamespace(namespace); err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	var cursor int64
+	for {
+		next, keys, err := client.Scan(cursor, namespace+":*", wipeBatchSize).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(keys) > 0 {
+			count, err := client.Del(keys...).Result()
+			deleted += count
+			if err != nil {
+				return deleted, err
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	return deleted, nil
+}
+
+// Buckets returns the buckets of the namespace of the GeoClient, see NamespaceBuckets
+func (c *GeoClient) Buckets() ([]string, error) {
+	if c.namespace == "" {
+		return []string{}, ErrNoNamespace
+	}
+
+	return handleOp(c, Operation{Name: "Buckets"}, func() ([]string, error) {
+		return withRetry(c, func() ([]string, error) {
+			return NamespaceBuckets(c.client, c.namespace)
+		})
+	})
+}
+
+// Wipe deletes all keys of the namespace of the GeoClient, see WipeNamespace
+func (c *GeoClient) Wipe() (int64, error) {
+	if c.namespace == "" {
+		return 0, ErrNoNamespace
+	}
+
+	return handleOp(c, Operation{Name: "Wipe", Write: true}, func() (int64, error) {
+		return withRetry(c, func() (int64, error) {
+			return WipeNamespace(c.client, c.namespace)
+		})
+	})
+}
+
+// key returns the key of a bucket or fence set in the namespace of the client
+func (c *GeoClient) key(name string) string {
+	if c.namespace == "" {
+		return name
+	}
+
+	return c.namespace + ":" + name
+}
+
+// unkey returns the name of a bucket or fence set key of the namespace of the client
+func (c *GeoClient) unkey(key string) string {
+	if c.namespace == "" {
+		return key
+	}
+
+	return strings.TrimPrefix(key, c.namespace+":")
+}
+
+// namespaceBucketsKey is the set of the buckets of a namespace, it is inside the namespace so WipeNamespace deletes
+// it and keys of clients without a namespace can't collide with it, bucket names starting with "georedis:" are
+// reserved like for the health probe
+func namespaceBucketsKey(namespace string) string {
+	return namespace + ":georedis:buckets"
+}
@@ -0,0 +1,72 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestNamespace(t *testing.T) {
+	const zSetDrivers = "drivers"
+
+	acme := NewGeoClient(client, bitDepth, WithNamespace("test-acme"))
+	globex := acme.Namespace("test-globex")
+	acme.Wipe()
+	globex.Wipe()
+
+	acme.AddCoordinates(zSetDrivers, GeoKey{Label: "a", Lat: 52.52, Lon: 13.405, Payload: []byte("acme")})
+	acme.SetTags(zSetDrivers, "a", "van")
+	globex.AddCoordinates(zSetDrivers, GeoKey{Label: "b", Lat: 52.52, Lon: 13.405})
+	globex.AddCoordinates("trucks", GeoKey{Label: "c", Lat: 52.52, Lon: 13.405})
+
+	results, err := acme.Search(zSetDrivers, 52.52, 13.405, 1000, WithPayloads())
+	if err != nil || len(results) != 1 || results[0].Label != "a" || string(results[0].Payload) != "acme" {
+		t.Logf("expected only the members of acme got %v, %v\n", results, err)
+		t.Fail()
+	}
+
+	if count := client.ZCard("test-globex:" + zSetDrivers).Val(); count != 1 {
+		t.Logf("expected the bucket to be prefixed got %d members\n", count)
+		t.Fail()
+	}
+
+	if buckets, err := globex.Buckets(); err != nil || len(buckets) != 2 || buckets[0] != zSetDrivers || buckets[1] != "trucks" {
+		t.Logf("expected the buckets of globex got %v, %v\n", buckets, err)
+		t.Fail()
+	}
+
+	if deleted, err := acme.Wipe(); err != nil || deleted < 4 {
+		t.Logf("expected the keys of acme to be deleted got %d, %v\n", deleted, err)
+		t.Fail()
+	}
+
+	if count, err := acme.CountCoordinates(zSetDrivers); err != nil || count != 0 {
+		t.Logf("expected acme to be empty got %d, %v\n", count, err)
+		t.Fail()
+	}
+
+	if count, err := globex.CountCoordinates(zSetDrivers); err != nil || count != 1 {
+		t.Logf("expected globex to be kept got %d, %v\n", count, err)
+		t.Fail()
+	}
+
+	// a bucket named like a namespace by a client without one is not part of that namespace
+	client.Del("test-acme")
+	AddCoordinates(client, "test-acme", bitDepth, GeoKey{Label: "d", Lat: 52.52, Lon: 13.405})
+	acme.AddCoordinates(zSetDrivers, GeoKey{Label: "a", Lat: 52.52, Lon: 13.405})
+	acme.Wipe()
+	if count := client.ZCard("test-acme").Val(); count != 1 {
+		t.Logf("expected the bucket outside of the namespace to be kept got %d members\n", count)
+		t.Fail()
+	}
+
+	if _, err := WipeNamespace(client, "test:*"); err != ErrInvalidNamespace {
+		t.Logf("expected ErrInvalidNamespace got %v\n", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,243 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"gopkg.in/redis.v2"
+)
+
+// maxNativeLatitude is the latitude limit of the redis GEO commands, which use the web mercator range
+const maxNativeLatitude = 85.05112878
+
+// WithNativeGeo makes the GeoClient store and search the buckets with the GEO commands of redis 3.2 or newer
+// instead of its own geohash encoding
+//
+// Native buckets are searched inside redis with GEORADIUS and keep payloads, limits, units, last seen times,
+// histories and audits. Their scores are encoded by redis, so they don't work with features reading the scores directly,
+// like change streams, fences, region subscriptions, density monitors, geohash prefix or tag searches, and WithDistance
+// and the freshness options are ignored by their searches. Latitudes beyond ±85.05112878 are rejected.
+func WithNativeGeo(bucketNames ...string) ClientOption {
+	return func(c *GeoClient) {
+		if c.native == nil {
+			c.native = map[string]bool{}
+		}
+		for _, bucketName := range bucketNames {
+			c.native[bucketName] = true
+		}
+	}
+}
+
+// AddCoordinatesNative adds coordinates to the set with GEOADD
+func AddCoordinatesNative(client *redis.Client, bucketName string, coordinates ...GeoKey) (int64, error) {
+	if err := validateNativeCoordinates(coordinates); err != nil {
+		return 0, err
+	}
+	if len(coordinates) == 0 {
+		return 0, nil
+	}
+
+	args := make([]string, 0, 2+len(coordinates)*3)
+	args = append(args, "GEOADD", bucketName)
+	payloads := []string{}
+	for _, coordinate := range coordinates {
+		args = append(args,
+			strconv.FormatFloat(coordinate.Lon, 'f', -1, 64),
+			strconv.FormatFloat(coordinate.Lat, 'f', -1, 64),
+			coordinate.Label,
+		)
+		if coordinate.Payload != nil {
+			payloads = append(payloads, coordinate.Label, string(coordinate.Payload))
+		}
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	added := redis.NewCmd(args...)
+	_, err := multi.Exec(func() error {
+		multi.Process(added)
+		if len(payloads) > 0 {
+			multi.HMSet(payloadKey(bucketName), payloads[0], payloads[1], payloads[2:]...)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	count, ok := added.Val().(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected GEOADD reply %v", added.Val())
+	}
+
+	return count, nil
+}
+
+// GetCoordinatesNative returns the coordinates of a label with GEOPOS or ErrMemberNotFound
+func GetCoordinatesNative(client *redis.Client, bucketName, label string) (GeoKey, error) {
+	cmd := redis.NewCmd("GEOPOS", bucketName, label)
+	client.Process(cmd)
+	reply, err := cmd.Result()
+	if err != nil {
+		return GeoKey{}, err
+	}
+
+	positions, ok := reply.([]interface{})
+	if !ok || len(positions) != 1 {
+		return GeoKey{}, fmt.Errorf("unexpected GEOPOS reply %v", reply)
+	}
+	if positions[0] == nil {
+		return GeoKey{}, ErrMemberNotFound
+	}
+
+	lat, lon, err := parseNativePosition(positions[0])
+	if err != nil {
+		return GeoKey{}, err
+	}
+
+	return GeoKey{Lat: lat, Lon: lon, Label: label}, nil
+}
+
+// DistanceNative returns the distance in meters between two members with GEODIST or ErrMemberNotFound
+func DistanceNative(client *redis.Client, bucketName, label1, label2 string) (float64, error) {
+	cmd := redis.NewCmd("GEODIST", bucketName, label1, label2, "m")
+	client.Process(cmd)
+	reply, err := cmd.Result()
+	if err == redis.Nil {
+		return 0, ErrMemberNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	value, _ := reply.(string)
+	return strconv.ParseFloat(value, 64)
+}
+
+// SearchNative searches a bucket written with AddCoordinatesNative like Search, using GEORADIUS
+func SearchNative(client *redis.Client, bucketName string, lat, lon, radius float64, options ...SearchOption) ([]Result, error) {
+	opts := newSearchOptions(options)
+	radius = opts.unit.ToMeters(radius)
+
+	if err := validateLatLon(lat, lon); err != nil {
+		return []Result{}, &CoordinateError{Key: GeoKey{Lat: lat, Lon: lon}, Err: err}
+	}
+	if !(radius > 0 && radius <= MaxRadius) {
+		return []Result{}, ErrInvalidRadius
+	}
+	if opts.limit == 0 {
+		return []Result{}, nil
+	}
+
+	args := []string{
+		"GEORADIUS", bucketName,
+		strconv.FormatFloat(lon, 'f', -1, 64),
+		strconv.FormatFloat(lat, 'f', -1, 64),
+		strconv.FormatFloat(radius, 'f', -1, 64), "m",
+		"WITHDIST", "WITHCOORD", "ASC",
+	}
+	// GEORADIUS can't match labels, so matching searches count after matching
+	if limit := opts.rankLimit(); limit > 0 && opts.labelMatch == "" {
+		args = append(args, "COUNT", strconv.Itoa(limit))
+	}
+
+	cmd := redis.NewCmd(args...)
+	client.Process(cmd)
+	reply, err := cmd.Result()
+	if err != nil {
+		return []Result{}, err
+	}
+
+	results, err := parseNativeResults(reply)
+	if err != nil {
+		return []Result{}, err
+	}
+	results = matchResults(results, opts.labelMatch, opts.rankLimit())
+	if results, err = completeResults(client, bucketName, results, opts); err != nil {
+		return []Result{}, err
+	}
+
+	return results, nil
+}
+
+func (c *GeoClient) isNative(bucketName string) bool {
+	return c.native[c.unkey(bucketName)]
+}
+
+// nativeLabels returns the labels of native search results
+func nativeLabels(results []Result, err error) ([]string, error) {
+	labels := make([]string, len(results))
+	for idx := range results {
+		labels[idx] = results[idx].Label
+	}
+
+	return labels, err
+}
+
+func validateNativeCoordinates(coordinates []GeoKey) error {
+	if err := ValidateCoordinates(coordinates...); err != nil {
+		return err
+	}
+	for _, coordinate := range coordinates {
+		if math.Abs(coordinate.Lat) > maxNativeLatitude {
+			return &CoordinateError{Key: coordinate, Err: ErrInvalidLatitude}
+		}
+	}
+
+	return nil
+}
+
+// parseNativeResults parses the GEORADIUS reply of label, distance and [lon, lat] entries
+func parseNativeResults(reply interface{}) ([]Result, error) {
+	entries, ok := reply.([]interface{})
+	if !ok {
+		return []Result{}, fmt.Errorf("unexpected GEORADIUS reply %v", reply)
+	}
+
+	results := make([]Result, len(entries))
+	for idx, entry := range entries {
+		fields, ok := entry.([]interface{})
+		if !ok || len(fields) != 3 {
+			return []Result{}, fmt.Errorf("unexpected GEORADIUS entry %v", entry)
+		}
+
+		results[idx].Label, _ = fields[0].(string)
+		distance, _ := fields[1].(string)
+		var err error
+		if results[idx].Distance, err = strconv.ParseFloat(distance, 64); err != nil {
+			return []Result{}, err
+		}
+		if results[idx].Lat, results[idx].Lon, err = parseNativePosition(fields[2]); err != nil {
+			return []Result{}, err
+		}
+	}
+
+	return results, nil
+}
+
+func parseNativePosition(position interface{}) (float64, float64, error) {
+	coordinates, ok := position.([]interface{})
+	if !ok || len(coordinates) != 2 {
+		return 0, 0, fmt.Errorf("unexpected position %v", position)
+	}
+
+	lonValue, _ := coordinates[0].(string)
+	latValue, _ := coordinates[1].(string)
+	lon, err := strconv.ParseFloat(lonValue, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	lat, err := strconv.ParseFloat(latValue, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return lat, lon, nil
+}
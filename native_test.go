@@ -0,0 +1,58 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestNativeGeo(t *testing.T) {
+	const zSetNative = "test:native"
+
+	client.Del(zSetNative, zSetNative+":payload")
+	geoClient := NewGeoClient(client, bitDepth, WithNativeGeo(zSetNative))
+
+	added, err := geoClient.AddCoordinates(zSetNative,
+		GeoKey{Label: "berlin", Lat: 52.52, Lon: 13.405, Payload: []byte("capital")},
+		GeoKey{Label: "potsdam", Lat: 52.3906, Lon: 13.0645},
+	)
+	if err != nil || added != 2 {
+		t.Logf("expected to add 2 added %d error %v\n", added, err)
+		t.FailNow()
+	}
+
+	results, err := geoClient.Search(zSetNative, 52.52, 13.405, 30, WithUnit(Kilometers), WithPayloads(), WithLimit(1))
+	if err != nil || len(results) != 1 || results[0].Label != "berlin" || string(results[0].Payload) != "capital" {
+		t.Logf("unexpected results %v error %v\n", results, err)
+		t.Fail()
+	}
+
+	labels, err := geoClient.SearchByRadius(zSetNative, 52.52, 13.405, 30000)
+	if err != nil || len(labels) != 2 || labels[1] != "potsdam" {
+		t.Logf("unexpected labels %v error %v\n", labels, err)
+		t.Fail()
+	}
+
+	coordinates, err := geoClient.GetCoordinates(zSetNative, "berlin")
+	if err != nil || math.Abs(coordinates.Lat-52.52) > 1e-5 || math.Abs(coordinates.Lon-13.405) > 1e-5 {
+		t.Logf("unexpected coordinates %v error %v\n", coordinates, err)
+		t.Fail()
+	}
+
+	distance, err := DistanceNative(client, zSetNative, "berlin", "potsdam")
+	if err != nil || math.Abs(distance-Haversine(52.52, 13.405, 52.3906, 13.0645)) > 100 {
+		t.Logf("unexpected distance %f error %v\n", distance, err)
+		t.Fail()
+	}
+
+	if _, err := geoClient.AddCoordinates(zSetNative, GeoKey{Label: "pole", Lat: 89, Lon: 0}); err == nil {
+		t.Logf("expected latitudes beyond the GEO range to be rejected\n")
+		t.Fail()
+	}
+}
@@ -0,0 +1,251 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package natssink implements georedis.EventSink on top of NATS or JetStream
+//
+// Changes and fence events are published as JSON messages to subjects built from templates, so subscribers can
+// pick buckets and areas with subject wildcards. Templates may contain
+//
+//	{bucket}   the bucket of a change, "-" for fence events
+//	{type}     the change or event type
+//	{label}    the label of the member
+//	{fence}    the fence of an event, "-" for changes
+//	{geohash}  the base32 geohash of the coordinates, see WithGeohashPrecision
+//
+// Characters which are special in subjects are replaced by underscores in the values.
+package natssink
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/tapglue/georedis"
+	"github.com/tapglue/georedis/internal/geohash"
+)
+
+const (
+	// DefaultChangeSubject is the subject template of changes when no other one is configured
+	DefaultChangeSubject = "georedis.changes.{bucket}.{geohash}"
+	// DefaultFenceSubject is the subject template of fence events when no other one is configured
+	DefaultFenceSubject = "georedis.fences.{fence}.{geohash}"
+
+	defaultGeohashPrecision = 4
+	maxGeohashPrecision     = 10
+
+	base32Alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+)
+
+type (
+	// Publisher sends a message to a subject, id is set for changes and allows JetStream to drop duplicates
+	Publisher interface {
+		Publish(subject string, data []byte, id string) error
+	}
+
+	// Sink publishes changes and fence events to NATS
+	Sink struct {
+		publisher     Publisher
+		changeSubject string
+		fenceSubject  string
+		precision     int
+	}
+
+	// Option configures a Sink
+	Option func(*Sink)
+
+	// Change is the JSON value of a change message
+	Change struct {
+		ID        string    `json:"id"`
+		Bucket    string    `json:"bucket"`
+		Type      string    `json:"type"`
+		Label     string    `json:"label"`
+		Lat       *float64  `json:"lat,omitempty"`
+		Lon       *float64  `json:"lon,omitempty"`
+		BeforeLat *float64  `json:"before_lat,omitempty"`
+		BeforeLon *float64  `json:"before_lon,omitempty"`
+		Time      time.Time `json:"time"`
+	}
+
+	// FenceEvent is the JSON value of a fence event message
+	FenceEvent struct {
+		Type     string    `json:"type"`
+		Fence    string    `json:"fence"`
+		Label    string    `json:"label"`
+		Lat      float64   `json:"lat"`
+		Lon      float64   `json:"lon"`
+		Distance float64   `json:"distance,omitempty"`
+		Time     time.Time `json:"time"`
+	}
+
+	corePublisher struct {
+		conn *nats.Conn
+	}
+
+	jetStreamPublisher struct {
+		js nats.JetStreamContext
+	}
+)
+
+// Core returns a Publisher sending fire and forget messages over a NATS connection
+func Core(conn *nats.Conn) Publisher {
+	return corePublisher{conn: conn}
+}
+
+// JetStream returns a Publisher waiting for the acknowledgement of a stream, change ids are used as message ids
+func JetStream(js nats.JetStreamContext) Publisher {
+	return jetStreamPublisher{js: js}
+}
+
+func (p corePublisher) Publish(subject string, data []byte, id string) error {
+	return p.conn.Publish(subject, data)
+}
+
+func (p jetStreamPublisher) Publish(subject string, data []byte, id string) error {
+	options := []nats.PubOpt{}
+	if id != "" {
+		options = append(options, nats.MsgId(id))
+	}
+	_, err := p.js.Publish(subject, data, options...)
+
+	return err
+}
+
+// WithChangeSubject sets the subject template of changes
+func WithChangeSubject(template string) Option {
+	return func(s *Sink) {
+		s.changeSubject = template
+	}
+}
+
+// WithFenceSubject sets the subject template of fence events
+func WithFenceSubject(template string) Option {
+	return func(s *Sink) {
+		s.fenceSubject = template
+	}
+}
+
+// WithGeohashPrecision sets the number of characters of {geohash}, from 1 to 10, 4 (about 20 by 40 km) by default
+func WithGeohashPrecision(precision int) Option {
+	return func(s *Sink) {
+		s.precision = min(max(precision, 1), maxGeohashPrecision)
+	}
+}
+
+// New returns a Sink publishing through publisher
+func New(publisher Publisher, options ...Option) *Sink {
+	s := &Sink{
+		publisher:     publisher,
+		changeSubject: DefaultChangeSubject,
+		fenceSubject:  DefaultFenceSubject,
+		precision:     defaultGeohashPrecision,
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// WriteChanges implements georedis.EventSink, changes are located by their coordinates after the change or, for
+// removals, before
+func (s *Sink) WriteChanges(bucketName string, changes ...georedis.Change) error {
+	for _, change := range changes {
+		value := Change{ID: change.ID, Bucket: bucketName, Type: string(change.Type), Label: change.Label, Time: change.Time}
+		point := change.After
+		if change.After != nil {
+			value.Lat, value.Lon = &change.After.Lat, &change.After.Lon
+		}
+		if change.Before != nil {
+			value.BeforeLat, value.BeforeLon = &change.Before.Lat, &change.Before.Lon
+			if point == nil {
+				point = change.Before
+			}
+		}
+
+		hash := "-"
+		if point != nil {
+			hash = geohashString(point.Lat, point.Lon, s.precision)
+		}
+		subject := s.subject(s.changeSubject, bucketName, string(change.Type), change.Label, "-", hash)
+		if err := s.publish(subject, value, change.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteFenceEvents implements georedis.EventSink
+func (s *Sink) WriteFenceEvents(events ...georedis.FenceEvent) error {
+	for _, event := range events {
+		value := FenceEvent{
+			Type:     string(event.Type),
+			Fence:    event.Fence,
+			Label:    event.Label,
+			Lat:      event.Lat,
+			Lon:      event.Lon,
+			Distance: event.Distance,
+			Time:     event.Time,
+		}
+
+		hash := geohashString(event.Lat, event.Lon, s.precision)
+		subject := s.subject(s.fenceSubject, "-", string(event.Type), event.Label, event.Fence, hash)
+		if err := s.publish(subject, value, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Sink) publish(subject string, value interface{}, id string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return s.publisher.Publish(subject, data, id)
+}
+
+func (s *Sink) subject(template, bucket, eventType, label, fence, hash string) string {
+	return strings.NewReplacer(
+		"{bucket}", token(bucket),
+		"{type}", token(eventType),
+		"{label}", token(label),
+		"{fence}", token(fence),
+		"{geohash}", hash,
+	).Replace(template)
+}
+
+// token makes a value usable as a single subject token
+func token(value string) string {
+	if value == "" {
+		return "_"
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, value)
+}
+
+// geohashString returns the base32 geohash of precision characters
+func geohashString(lat, lon float64, precision int) string {
+	bits := uint8(precision * 5)
+	// the integer geohash is encoded with an even depth and cut to the bits of the string
+	hash := geohash.EncodeInt(lat, lon, bits+bits%2) >> (bits % 2)
+
+	chars := make([]byte, precision)
+	for idx := precision - 1; idx >= 0; idx-- {
+		chars[idx] = base32Alphabet[hash&31]
+		hash >>= 5
+	}
+
+	return string(chars)
+}
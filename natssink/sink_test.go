@@ -0,0 +1,62 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package natssink
+
+import (
+	"testing"
+
+	"github.com/tapglue/georedis"
+)
+
+type recorder struct {
+	subjects []string
+	ids      []string
+}
+
+func (r *recorder) Publish(subject string, data []byte, id string) error {
+	r.subjects = append(r.subjects, subject)
+	r.ids = append(r.ids, id)
+	return nil
+}
+
+func TestGeohashString(t *testing.T) {
+	for precision, expected := range map[int]string{1: "u", 4: "u4pr", 5: "u4pru", 10: "u4pruydqqv"} {
+		if hash := geohashString(57.64911, 10.40744, precision); hash != expected {
+			t.Logf("expected %s got %s\n", expected, hash)
+			t.Fail()
+		}
+	}
+}
+
+func TestSinkSubjects(t *testing.T) {
+	publisher := &recorder{}
+	sink := New(publisher, WithGeohashPrecision(5), WithFenceSubject("fences.{fence}.{type}.{label}"))
+
+	err := sink.WriteChanges("fleet.eu",
+		georedis.Change{ID: "1-0", Type: georedis.ChangeAdd, Label: "a", After: &georedis.Point{Lat: 57.64911, Lon: 10.40744}},
+		georedis.Change{ID: "2-0", Type: georedis.ChangeRemove, Label: "a", Before: &georedis.Point{Lat: 57.64911, Lon: 10.40744}},
+	)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if err := sink.WriteFenceEvents(georedis.FenceEvent{Type: georedis.FenceEnter, Fence: "depot", Label: "truck 7"}); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	expected := []string{"georedis.changes.fleet_eu.u4pru", "georedis.changes.fleet_eu.u4pru", "fences.depot.enter.truck_7"}
+	for idx := range expected {
+		if idx >= len(publisher.subjects) || publisher.subjects[idx] != expected[idx] {
+			t.Logf("expected subjects %v got %v\n", expected, publisher.subjects)
+			t.FailNow()
+		}
+	}
+	if publisher.ids[0] != "1-0" || publisher.ids[2] != "" {
+		t.Logf("expected change ids as message ids got %v\n", publisher.ids)
+		t.Fail()
+	}
+}
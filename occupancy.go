@@ -0,0 +1,25 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import "gopkg.in/redis.v2"
+
+// Occupancy returns the labels of the bucket's members inside a fence, as tracked by FenceTracker
+//
+// Occupants are updated in the same transaction as the memberships which emit the enter and exit events, so both
+// always agree. Members inside a deleted fence leave its occupants with their next update.
+func Occupancy(client *redis.Client, fenceSet, bucketName, name string) ([]string, error) {
+	return client.SMembers(occupantsKey(fenceSet, bucketName, name)).Result()
+}
+
+// OccupancyCount returns the number of the bucket's members inside a fence without reading their labels
+func OccupancyCount(client *redis.Client, fenceSet, bucketName, name string) (int64, error) {
+	return client.SCard(occupantsKey(fenceSet, bucketName, name)).Result()
+}
+
+func occupantsKey(fenceSet, bucketName, name string) string {
+	return fenceSet + ":fences:occupants:" + bucketName + ":" + name
+}
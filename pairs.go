@@ -0,0 +1,137 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"gopkg.in/redis.v2"
+)
+
+// pairsBatchSize is the number of members read at once by FindPairsWithin
+const pairsBatchSize = 10000
+
+// Pair is two members closer than the distance passed to FindPairsWithin
+type Pair struct {
+	A        GeoKey
+	B        GeoKey
+	Distance float64
+}
+
+// FindPairsWithin returns all pairs of members of the bucket closer than distance meters, nearest first
+//
+// Members are bucketed into latitude rows as high as distance and columns which are at least as wide, so each
+// member is only compared to the members of the surrounding cells. The whole bucket is read into memory.
+func FindPairsWithin(client *redis.Client, bucketName string, bitDepth uint8, distance float64) ([]Pair, error) {
+	if !(distance > 0 && distance <= MaxRadius) {
+		return []Pair{}, ErrInvalidRadius
+	}
+
+	members := []GeoKey{}
+	for offset := int64(0); ; offset += pairsBatchSize {
+		batch, err := ListCoordinates(client, bucketName, bitDepth, offset, pairsBatchSize)
+		if err != nil {
+			return []Pair{}, err
+		}
+		members = append(members, batch...)
+		if len(batch) < pairsBatchSize {
+			break
+		}
+	}
+
+	return pairsWithin(members, distance), nil
+}
+
+// pairGrid buckets points into rows of equal height and per row columns at least as wide as the height at the
+// row's poleward edge
+type pairGrid struct {
+	rowHeight float64
+	cells     map[[2]int][]int
+}
+
+func pairsWithin(members []GeoKey, distance float64) []Pair {
+	grid := pairGrid{rowHeight: distance / metersPerDegree, cells: map[[2]int][]int{}}
+	for idx, member := range members {
+		row := grid.row(member.Lat)
+		cell := [2]int{row, grid.col(row, member.Lon)}
+		grid.cells[cell] = append(grid.cells[cell], idx)
+	}
+
+	pairs := []Pair{}
+	seen := []int{}
+	for idx, member := range members {
+		row := grid.row(member.Lat)
+		dLon := grid.lonReach(member.Lat, distance)
+
+		for r := row - 1; r <= row+1; r++ {
+			seen = seen[:0]
+			cols := grid.cols(r)
+			first, last := grid.col(r, member.Lon-dLon), grid.col(r, member.Lon+dLon)
+			for c, steps := first, 0; steps < cols; c, steps = (c+1)%cols, steps+1 {
+				// wrapping around the antimeridian can reach a column twice
+				if slices.Contains(seen, c) {
+					break
+				}
+				seen = append(seen, c)
+
+				for _, other := range grid.cells[[2]int{r, c}] {
+					if other <= idx {
+						continue
+					}
+					if d := Haversine(member.Lat, member.Lon, members[other].Lat, members[other].Lon); d < distance {
+						pairs = append(pairs, Pair{A: member, B: members[other], Distance: d})
+					}
+				}
+
+				if c == last && dLon < 180 {
+					break
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(pairs, func(a, b Pair) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.A.Label, b.A.Label), cmp.Compare(a.B.Label, b.B.Label))
+	})
+
+	return pairs
+}
+
+func (g pairGrid) row(lat float64) int {
+	return int(math.Floor((lat + 90) / g.rowHeight))
+}
+
+// cols returns the number of columns of a row, their width in meters is at least the row height everywhere in
+// the row
+func (g pairGrid) cols(row int) int {
+	poleward := max(math.Abs(-90+float64(row)*g.rowHeight), math.Abs(-90+float64(row+1)*g.rowHeight))
+	if poleward >= 90 {
+		return 1
+	}
+
+	width := g.rowHeight / math.Cos(poleward*math.Pi/180)
+	return max(1, int(360/width))
+}
+
+func (g pairGrid) col(row int, lon float64) int {
+	cols := g.cols(row)
+	col := int(math.Floor((lon + 180) / 360 * float64(cols)))
+
+	return ((col % cols) + cols) % cols
+}
+
+// lonReach returns how many degrees of longitude a circle of distance meters around lat spans to either side,
+// 180 when it contains a pole
+func (g pairGrid) lonReach(lat, distance float64) float64 {
+	reach := math.Sin(distance/earthRadius) / math.Cos(lat*math.Pi/180)
+	if reach >= 1 || distance >= earthRadius*math.Pi/2 {
+		return 180
+	}
+
+	return math.Asin(reach) * 180 / math.Pi
+}
@@ -0,0 +1,49 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestPairsWithinMatchesBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	// clusters around the equator, the antimeridian and a pole
+	centers := [][2]float64{{0, 0}, {52.5, 13.4}, {-33.9, 179.99}, {-33.9, -179.99}, {89.99, 0}, {89.99, 120}}
+	for _, distance := range []float64{100, 2500, 50000} {
+		members := []GeoKey{}
+		for _, center := range centers {
+			for i := 0; i < 40; i++ {
+				lat, lon := destination(center[0], center[1], rnd.Float64()*360, rnd.Float64()*distance*3)
+				members = append(members, GeoKey{Lat: lat, Lon: lon, Label: fmt.Sprintf("m%d", len(members))})
+			}
+		}
+
+		expected := 0
+		for i := range members {
+			for j := i + 1; j < len(members); j++ {
+				if Haversine(members[i].Lat, members[i].Lon, members[j].Lat, members[j].Lon) < distance {
+					expected++
+				}
+			}
+		}
+
+		pairs := pairsWithin(members, distance)
+		if len(pairs) != expected {
+			t.Logf("distance %v: expected %d pairs got %d\n", distance, expected, len(pairs))
+			t.Fail()
+		}
+		for idx, pair := range pairs {
+			if pair.Distance >= distance || (idx > 0 && pair.Distance < pairs[idx-1].Distance) {
+				t.Logf("distance %v: unexpected pair %v\n", distance, pair)
+				t.Fail()
+			}
+		}
+	}
+}
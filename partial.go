@@ -0,0 +1,84 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// RangeError is the failure of the query of a single score range
+	RangeError struct {
+		Lower float64
+		Upper float64
+		Err   error
+	}
+
+	// PartialResultsError is returned together with the results of the ranges which could be queried when
+	// some ranges of a search failed, the results may miss members of the failed ranges
+	PartialResultsError struct {
+		Failed []RangeError
+	}
+)
+
+func (e RangeError) Error() string {
+	return fmt.Sprintf("range %.0f-%.0f: %v", e.Lower, e.Upper, e.Err)
+}
+
+func (e RangeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *PartialResultsError) Error() string {
+	failed := make([]string, len(e.Failed))
+	for idx := range e.Failed {
+		failed[idx] = e.Failed[idx].Error()
+	}
+
+	return fmt.Sprintf("partial results, %d ranges failed: %s", len(e.Failed), strings.Join(failed, "; "))
+}
+
+// Unwrap returns the errors of the failed ranges so errors.Is and errors.As see them
+func (e *PartialResultsError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for idx := range e.Failed {
+		errs[idx] = e.Failed[idx]
+	}
+
+	return errs
+}
+
+// WithFailFast stops a search at the first failing range and returns its error without results, instead of
+// returning partial results with a PartialResultsError
+func WithFailFast() SearchOption {
+	return func(o *searchOptions) {
+		o.failFast = true
+	}
+}
+
+// rangeErrors collects the failures of a search
+type rangeErrors struct {
+	failFast bool
+	failed   []RangeError
+}
+
+// add records a failure and reports whether the search has to stop
+func (r *rangeErrors) add(query geoRange, err error) bool {
+	r.failed = append(r.failed, RangeError{Lower: query.Lower, Upper: query.Upper, Err: err})
+	return r.failFast
+}
+
+func (r *rangeErrors) err() error {
+	switch {
+	case len(r.failed) == 0:
+		return nil
+	case r.failFast:
+		return r.failed[0].Err
+	default:
+		return &PartialResultsError{Failed: r.failed}
+	}
+}
@@ -0,0 +1,51 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+	"github.com/tapglue/georedis/memorystore"
+)
+
+var errRangeFailed = errors.New("range failed")
+
+// flakyStore fails every range query but the first one
+type flakyStore struct {
+	*memorystore.Store
+	calls int
+}
+
+func (s *flakyStore) ZRangeByScore(key string, min, max float64, offset, count int64) ([]Member, error) {
+	s.calls++
+	if s.calls > 1 {
+		return nil, errRangeFailed
+	}
+
+	return s.Store.ZRangeByScore(key, min, max, offset, count)
+}
+
+func TestSearchPartialResults(t *testing.T) {
+	store := &flakyStore{Store: memorystore.New()}
+	coreClient := NewCoreClient(store, 52)
+	coreClient.AddCoordinates("test:partial", GeoKey{Lat: 52.5200, Lon: 13.4050, Label: "Berlin"})
+
+	_, err := coreClient.Search("test:partial", 52.5200, 13.4050, 1000)
+	partial := &PartialResultsError{}
+	if !errors.As(err, &partial) || len(partial.Failed) != store.calls-1 || !errors.Is(err, errRangeFailed) {
+		t.Logf("expected %d failed ranges got %v\n", store.calls-1, err)
+		t.Fail()
+	}
+
+	store.calls = 0
+	results, err := coreClient.Search("test:partial", 52.5200, 13.4050, 1000, WithFailFast())
+	if err != errRangeFailed || len(results) != 0 || store.calls != 2 {
+		t.Logf("expected to stop at the first failure got %v after %d calls\n", err, store.calls)
+		t.Fail()
+	}
+}
@@ -0,0 +1,63 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import "gopkg.in/redis.v2"
+
+// WithPayloads returns the payload stored for each result
+func WithPayloads() SearchOption {
+	return func(o *searchOptions) {
+		o.withPayloads = true
+	}
+}
+
+// GetPayloads returns the payloads stored for the labels, in the same order, nil if a label has none
+func GetPayloads(client *redis.Client, bucketName string, labels ...string) ([][]byte, error) {
+	payloads := make([][]byte, len(labels))
+	if len(labels) == 0 {
+		return payloads, nil
+	}
+
+	values, err := client.HMGet(payloadKey(bucketName), labels...).Result()
+	if err != nil {
+		return payloads, err
+	}
+
+	for idx := range values {
+		if value, ok := values[idx].(string); ok {
+			payloads[idx] = []byte(value)
+		}
+	}
+
+	return payloads, nil
+}
+
+// SetPayload stores the payload of a label without changing its coordinates
+func SetPayload(client *redis.Client, bucketName, label string, payload []byte) error {
+	return client.HSet(payloadKey(bucketName), label, string(payload)).Err()
+}
+
+func payloadKey(bucketName string) string {
+	return bucketName + ":payload"
+}
+
+func attachPayloads(client *redis.Client, bucketName string, results []Result) error {
+	labels := make([]string, len(results))
+	for idx := range results {
+		labels[idx] = results[idx].Label
+	}
+
+	payloads, err := GetPayloads(client, bucketName, labels...)
+	if err != nil {
+		return err
+	}
+
+	for idx := range results {
+		results[idx].Payload = payloads[idx]
+	}
+
+	return nil
+}
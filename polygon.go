@@ -0,0 +1,141 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrInvalidPolygon is returned for polygons without an exterior ring of at least 3 points
+var ErrInvalidPolygon = errors.New("invalid polygon")
+
+// Contains reports whether the polygon contains the point, points inside holes are not contained
+//
+// Edges are straight lines in latitude and longitude, polygons must not cross the antimeridian.
+func (p Polygon) Contains(lat, lon float64) bool {
+	inside := false
+	for _, ring := range p {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			a, b := ring[i], ring[j]
+			if (a.Lat > lat) != (b.Lat > lat) && lon < (b.Lon-a.Lon)*(lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+				inside = !inside
+			}
+		}
+	}
+
+	return inside
+}
+
+// Contains reports whether any of the polygons contains the point
+func (m MultiPolygon) Contains(lat, lon float64) bool {
+	for _, polygon := range m {
+		if polygon.Contains(lat, lon) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// polygons returns the geometry as a MultiPolygon, ErrInvalidPolygon is returned for other geometries and
+// polygons with invalid rings
+func polygons(geometry Geometry) (MultiPolygon, error) {
+	var multi MultiPolygon
+	switch g := geometry.(type) {
+	case Polygon:
+		multi = MultiPolygon{g}
+	case MultiPolygon:
+		multi = g
+	default:
+		return nil, ErrInvalidPolygon
+	}
+
+	if len(multi) == 0 {
+		return nil, ErrInvalidPolygon
+	}
+	for _, polygon := range multi {
+		if len(polygon) == 0 {
+			return nil, ErrInvalidPolygon
+		}
+		for _, ring := range polygon {
+			if len(ring) < 3 {
+				return nil, ErrInvalidPolygon
+			}
+			for _, point := range ring {
+				if err := validateLatLon(point.Lat, point.Lon); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return multi, nil
+}
+
+// bounds returns the bounding box of the exterior rings
+func (m MultiPolygon) bounds() (minLat, maxLat, west, east float64) {
+	minLat, maxLat, west, east = math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	for _, polygon := range m {
+		for _, point := range polygon[0] {
+			minLat, maxLat = min(minLat, point.Lat), max(maxLat, point.Lat)
+			west, east = min(west, point.Lon), max(east, point.Lon)
+		}
+	}
+
+	return minLat, maxLat, west, east
+}
+
+// intersectsBox reports whether any of the polygons overlaps the box between south, west, north and east
+//
+// The box overlaps when a vertex lies inside it, an edge crosses its sides or its center is contained, which
+// leaves boxes fully inside a polygon.
+func (m MultiPolygon) intersectsBox(south, west, north, east float64) bool {
+	if m.Contains((south+north)/2, (west+east)/2) {
+		return true
+	}
+
+	corners := [4]Point{{south, west}, {south, east}, {north, east}, {north, west}}
+	for _, polygon := range m {
+		for _, ring := range polygon {
+			for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+				a, b := ring[i], ring[j]
+				if a.Lat >= south && a.Lat <= north && a.Lon >= west && a.Lon <= east {
+					return true
+				}
+				for k := range corners {
+					if segmentsIntersect(a, b, corners[k], corners[(k+1)%len(corners)]) {
+						return true
+					}
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func segmentsIntersect(a, b, c, d Point) bool {
+	d1, d2 := orientation(c, d, a), orientation(c, d, b)
+	d3, d4 := orientation(a, b, c), orientation(a, b, d)
+
+	return d1*d2 <= 0 && d3*d4 <= 0 &&
+		max(a.Lon, b.Lon) >= min(c.Lon, d.Lon) && max(c.Lon, d.Lon) >= min(a.Lon, b.Lon) &&
+		max(a.Lat, b.Lat) >= min(c.Lat, d.Lat) && max(c.Lat, d.Lat) >= min(a.Lat, b.Lat)
+}
+
+// orientation returns the sign of the cross product of b-a and c-a
+func orientation(a, b, c Point) float64 {
+	cross := (b.Lon-a.Lon)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lon-a.Lon)
+	switch {
+	case cross > 0:
+		return 1
+	case cross < 0:
+		return -1
+	}
+
+	return 0
+}
@@ -0,0 +1,39 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestPolygonContains(t *testing.T) {
+	square := Polygon{
+		{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 10}, {Lat: 10, Lon: 10}, {Lat: 10, Lon: 0}, {Lat: 0, Lon: 0}},
+		{{Lat: 4, Lon: 4}, {Lat: 4, Lon: 6}, {Lat: 6, Lon: 6}, {Lat: 6, Lon: 4}},
+	}
+	multi := MultiPolygon{square, {{{Lat: 20, Lon: 20}, {Lat: 20, Lon: 21}, {Lat: 21, Lon: 20}}}}
+
+	tests := []struct {
+		lat, lon float64
+		inside   bool
+	}{
+		{1, 1, true},
+		{5, 5, false},
+		{5, 3, true},
+		{11, 5, false},
+		{20.2, 20.2, true},
+		{20.8, 20.8, false},
+	}
+
+	for _, test := range tests {
+		if inside := multi.Contains(test.lat, test.lon); inside != test.inside {
+			t.Logf("%f, %f: expected inside %t got %t\n", test.lat, test.lon, test.inside, inside)
+			t.Fail()
+		}
+	}
+}
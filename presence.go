@@ -0,0 +1,146 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+// WithLastSeen records the time and the motion of every write of the GeoClient, see TouchMembers and
+// AddCoordinatesWithMotion
+func WithLastSeen() ClientOption {
+	return func(c *GeoClient) {
+		c.lastSeen = true
+	}
+}
+
+// WithFreshness leaves out members whose last seen time is older than window, members without one are left out too
+//
+// The members are filtered inside redis, the limit applies to the fresh members.
+func WithFreshness(window time.Duration) SearchOption {
+	return func(o *searchOptions) {
+		o.freshness = window
+	}
+}
+
+// WithUpdatedSince only returns members last seen at or after since, members without a last seen time are left
+// out
+func WithUpdatedSince(since time.Time) SearchOption {
+	return func(o *searchOptions) {
+		o.updatedSince = since
+	}
+}
+
+// TouchMembers records at as the last seen time of the labels in a companion sorted set scored by unix time, in
+// seconds with millisecond precision
+//
+// RemoveCoordinatesByKeys removes the last seen times together with the members.
+func TouchMembers(client *redis.Client, bucketName string, at time.Time, labels ...string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	members := make([]redis.Z, len(labels))
+	for idx, label := range labels {
+		members[idx] = redis.Z{Score: unixScore(at), Member: label}
+	}
+
+	return client.ZAdd(lastSeenKey(bucketName), members...).Err()
+}
+
+// LastSeen returns the last seen time of a label or ErrMemberNotFound when none was recorded
+func LastSeen(client *redis.Client, bucketName, label string) (time.Time, error) {
+	cmd := client.ZScore(lastSeenKey(bucketName), label)
+	if cmd.Err() == redis.Nil {
+		return time.Time{}, ErrMemberNotFound
+	}
+	if cmd.Err() != nil {
+		return time.Time{}, cmd.Err()
+	}
+
+	return time.UnixMilli(int64(math.Round(cmd.Val() * 1000))), nil
+}
+
+// ListStale returns the labels last seen more than olderThan ago, the longest unseen first
+func ListStale(client *redis.Client, bucketName string, olderThan time.Duration) ([]string, error) {
+	return client.ZRangeByScore(lastSeenKey(bucketName), redis.ZRangeByScore{
+		Min: "-inf",
+		Max: "(" + formatUnixScore(time.Now().Add(-olderThan)),
+	}).Result()
+}
+
+func unixScore(at time.Time) float64 {
+	return float64(at.UnixMilli()) / 1000
+}
+
+func formatUnixScore(at time.Time) string {
+	return strconv.FormatFloat(unixScore(at), 'f', -1, 64)
+}
+
+func lastSeenKey(bucketName string) string {
+	return bucketName + ":seen"
+}
+
+// freshLuaBody returns the members of the ranges passed in ARGV which were last seen at or after a unix time
+//
+// KEYS are the bucket and its last seen times, ARGV holds the unix time followed by min/max range pairs. The reply
+// is a flat list of label and score for every member.
+const freshLuaBody = `
+local since = tonumber(ARGV[1])
+local reply = {}
+for i = 2, #ARGV, 2 do
+  local members = redis.call('ZRANGEBYSCORE', KEYS[1], ARGV[i], ARGV[i + 1], 'WITHSCORES')
+  for j = 1, #members, 2 do
+    local seen = redis.call('ZSCORE', KEYS[2], members[j])
+    if seen and tonumber(seen) >= since then
+      reply[#reply + 1] = members[j]
+      reply[#reply + 1] = members[j + 1]
+    end
+  end
+end
+
+return reply
+`
+
+var freshScript = newLuaScript(freshLuaBody)
+
+// fetchFreshRanges collects the members of all ranges of key seen at or after since, according to the last seen
+// times of bucketName, into a pooled buffer like fetchRanges
+func fetchFreshRanges(client *redis.Client, key, bucketName string, ranges []geoRange, since time.Time) ([]redis.Z, error) {
+	args := make([]string, 0, 1+len(ranges)*2)
+	args = append(args, formatUnixScore(since))
+	for key := range ranges {
+		query := ranges[key].query(0, 0)
+		args = append(args, query.Min, query.Max)
+	}
+
+	candidates := getCandidates()
+	reply, err := freshScript.run(client, []string{key, lastSeenKey(bucketName)}, args)
+	if err != nil {
+		return candidates, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values)%2 != 0 {
+		return candidates, fmt.Errorf("unexpected fresh script reply %v", reply)
+	}
+	for idx := 0; idx < len(values); idx += 2 {
+		label, _ := values[idx].(string)
+		score, _ := values[idx+1].(string)
+		value, err := strconv.ParseFloat(score, 64)
+		if err != nil {
+			return candidates, err
+		}
+		candidates = append(candidates, redis.Z{Member: label, Score: value})
+	}
+
+	return candidates, nil
+}
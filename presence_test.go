@@ -0,0 +1,110 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestLastSeen(t *testing.T) {
+	const zSetPresence = "test:presence"
+
+	client.Del(zSetPresence, zSetPresence+":seen")
+
+	AddCoordinates(client, zSetPresence, bitDepth,
+		GeoKey{Lat: 52.52, Lon: 13.405, Label: "fresh"},
+		GeoKey{Lat: 52.521, Lon: 13.405, Label: "stale"},
+		GeoKey{Lat: 52.522, Lon: 13.405, Label: "unseen"},
+	)
+	now := time.Now()
+	if err := TouchMembers(client, zSetPresence, now, "fresh"); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	TouchMembers(client, zSetPresence, now.Add(-time.Hour), "stale")
+
+	if seen, err := LastSeen(client, zSetPresence, "fresh"); err != nil || seen.Unix() != now.Unix() {
+		t.Logf("expected %v got %v error %v\n", now, seen, err)
+		t.Fail()
+	}
+	if _, err := LastSeen(client, zSetPresence, "unseen"); err != ErrMemberNotFound {
+		t.Logf("expected ErrMemberNotFound got %v\n", err)
+		t.Fail()
+	}
+
+	if stale, err := ListStale(client, zSetPresence, time.Minute); err != nil || !slices.Equal(stale, []string{"stale"}) {
+		t.Logf("expected [stale] got %v error %v\n", stale, err)
+		t.Fail()
+	}
+
+	results, err := Search(client, zSetPresence, 52.52, 13.405, 1000, bitDepth, WithFreshness(time.Minute), WithLimit(1))
+	if err != nil || len(results) != 1 || results[0].Label != "fresh" {
+		t.Logf("expected only the fresh member got %v error %v\n", results, err)
+		t.Fail()
+	}
+
+	results, err = Search(client, zSetPresence, 52.52, 13.405, 1000, bitDepth, WithUpdatedSince(now.Add(-2*time.Hour)))
+	if err != nil || !slices.Equal(labels(results), []string{"fresh", "stale"}) {
+		t.Logf("expected the fresh and stale members got %v error %v\n", results, err)
+		t.Fail()
+	}
+
+	RemoveCoordinatesByKeys(client, zSetPresence, "stale")
+	if _, err := LastSeen(client, zSetPresence, "stale"); err != ErrMemberNotFound {
+		t.Logf("expected the last seen time to be removed with the member got %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestPruneStale(t *testing.T) {
+	const zSetPrune = "test:prune"
+
+	client.Del(zSetPrune, zSetPrune+":seen", zSetPrune+":payload", zSetPrune+":prune:lock")
+
+	AddCoordinates(client, zSetPrune, bitDepth,
+		GeoKey{Lat: 52.52, Lon: 13.405, Label: "fresh"},
+		GeoKey{Lat: 52.52, Lon: 13.405, Label: "stale", Payload: []byte("x")},
+		GeoKey{Lat: 52.52, Lon: 13.405, Label: "unseen"},
+	)
+	TouchMembers(client, zSetPrune, time.Now(), "fresh")
+	TouchMembers(client, zSetPrune, time.Now().Add(-time.Hour), "stale", "gone")
+
+	pruned := make(chan int64, 2)
+	sweeper, err := SweepStale(client, zSetPrune, bitDepth, time.Minute, time.Hour, WithPruneHandler(func(removed int64) { pruned <- removed }))
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	other, _ := SweepStale(client, zSetPrune, bitDepth, time.Minute, time.Hour, WithPruneHandler(func(removed int64) { pruned <- removed }))
+
+	if removed := <-pruned; removed != 1 {
+		t.Logf("expected 1 pruned member got %d\n", removed)
+		t.Fail()
+	}
+	sweeper.Close()
+	other.Close()
+	if len(pruned) != 0 {
+		t.Logf("expected only one sweeper to prune\n")
+		t.Fail()
+	}
+
+	if count, _ := CountCoordinates(client, zSetPrune); count != 2 {
+		t.Logf("expected 2 members left got %d\n", count)
+		t.Fail()
+	}
+	if payloads, _ := GetPayloads(client, zSetPrune, "stale"); payloads[0] != nil {
+		t.Logf("expected the payload to be pruned got %q\n", payloads[0])
+		t.Fail()
+	}
+	if stale, _ := ListStale(client, zSetPrune, time.Minute); len(stale) != 0 {
+		t.Logf("expected no stale members left got %v\n", stale)
+		t.Fail()
+	}
+}
@@ -0,0 +1,150 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package prommetrics implements georedis.Metrics with Prometheus collectors
+//
+// Searches are measured by bucket: their latency by status, the score ranges and candidates they read. Errors are
+// counted by whether they are retryable and cache lookups by bucket and result, so the hit rate is
+//
+//	sum(rate(georedis_cache_lookups_total{result="hit"}[5m])) / sum(rate(georedis_cache_lookups_total[5m]))
+package prommetrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tapglue/georedis"
+)
+
+const (
+	// DefaultNamespace prefixes the metric names when no other namespace is configured
+	DefaultNamespace = "georedis"
+
+	statusOK      = "ok"
+	statusPartial = "partial"
+	statusError   = "error"
+)
+
+type (
+	// Metrics records the measurements of georedis in Prometheus collectors
+	Metrics struct {
+		searchDuration   *prometheus.HistogramVec
+		searchRanges     *prometheus.HistogramVec
+		searchCandidates *prometheus.HistogramVec
+		errors           *prometheus.CounterVec
+		cacheLookups     *prometheus.CounterVec
+	}
+
+	// Option configures Metrics
+	Option func(*config)
+
+	config struct {
+		namespace       string
+		durationBuckets []float64
+	}
+)
+
+var _ georedis.Metrics = (*Metrics)(nil)
+
+// WithNamespace sets the prefix of the metric names, "georedis" by default
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithDurationBuckets sets the buckets of the search latency histogram in seconds, prometheus.DefBuckets by default
+func WithDurationBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.durationBuckets = buckets
+	}
+}
+
+// New registers the collectors with registerer and returns the Metrics to pass to georedis.WithMetrics and
+// georedis.WithCacheMetrics
+func New(registerer prometheus.Registerer, options ...Option) (*Metrics, error) {
+	c := config{namespace: DefaultNamespace, durationBuckets: prometheus.DefBuckets}
+	for _, option := range options {
+		option(&c)
+	}
+
+	m := &Metrics{
+		searchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: c.namespace,
+			Name:      "search_duration_seconds",
+			Help:      "Latency of searches including retries.",
+			Buckets:   c.durationBuckets,
+		}, []string{"bucket", "status"}),
+		searchRanges: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: c.namespace,
+			Name:      "search_ranges",
+			Help:      "Score ranges read per search.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+		}, []string{"bucket"}),
+		searchCandidates: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: c.namespace,
+			Name:      "search_candidates",
+			Help:      "Members read per search before they were filtered by distance.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+		}, []string{"bucket"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: c.namespace,
+			Name:      "errors_total",
+			Help:      "Failed attempts of operations by whether they were retryable.",
+		}, []string{"retryable"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: c.namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Query cache lookups by result.",
+		}, []string{"bucket", "result"}),
+	}
+
+	for _, collector := range []prometheus.Collector{m.searchDuration, m.searchRanges, m.searchCandidates, m.errors, m.cacheLookups} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// ObserveSearch implements georedis.Metrics
+func (m *Metrics) ObserveSearch(bucketName string, stats georedis.SearchStats) {
+	m.searchDuration.WithLabelValues(bucketName, status(stats.Err)).Observe(stats.Duration.Seconds())
+	if stats.Ranges > 0 {
+		m.searchRanges.WithLabelValues(bucketName).Observe(float64(stats.Ranges))
+		m.searchCandidates.WithLabelValues(bucketName).Observe(float64(stats.Candidates))
+	}
+}
+
+// ObserveError implements georedis.Metrics
+func (m *Metrics) ObserveError(err error) {
+	retryable := "false"
+	if georedis.IsRetryable(err) {
+		retryable = "true"
+	}
+	m.errors.WithLabelValues(retryable).Inc()
+}
+
+// ObserveCache implements georedis.Metrics
+func (m *Metrics) ObserveCache(bucketName string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.WithLabelValues(bucketName, result).Inc()
+}
+
+func status(err error) string {
+	var partial *georedis.PartialResultsError
+	switch {
+	case err == nil:
+		return statusOK
+	case errors.As(err, &partial):
+		return statusPartial
+	default:
+		return statusError
+	}
+}
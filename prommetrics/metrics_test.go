@@ -0,0 +1,41 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package prommetrics_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tapglue/georedis"
+	. "github.com/tapglue/georedis/prommetrics"
+)
+
+func TestNewRegistersOnce(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	metrics, err := New(registry, WithNamespace("geo"))
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	metrics.ObserveSearch("drivers", georedis.SearchStats{Duration: time.Millisecond, Ranges: 4, Candidates: 12, Results: 3})
+	metrics.ObserveSearch("drivers", georedis.SearchStats{Err: &georedis.PartialResultsError{}})
+	metrics.ObserveError(errors.New("LOADING redis is loading the dataset in memory"))
+	metrics.ObserveCache("drivers", true)
+
+	if _, err := New(registry, WithNamespace("geo")); err == nil {
+		t.Log("expected registering the collectors twice to fail")
+		t.Fail()
+	}
+
+	if _, err := New(registry, WithNamespace("other")); err != nil {
+		t.Logf("expected another namespace to register got %q\n", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,176 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"encoding/json"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	// ProximityNear is emitted when two watched members come closer than the threshold
+	ProximityNear FenceEventType = "near"
+	// ProximityFar is emitted when two watched members move apart to the threshold or further
+	ProximityFar FenceEventType = "far"
+)
+
+// proximityWatch is a watched pair of members as persisted, Near is the state of the last evaluation
+type proximityWatch struct {
+	Name      string  `json:"name"`
+	BucketA   string  `json:"bucketA"`
+	LabelA    string  `json:"labelA"`
+	BucketB   string  `json:"bucketB"`
+	LabelB    string  `json:"labelB"`
+	Threshold float64 `json:"threshold"`
+	Near      bool    `json:"near"`
+}
+
+// WatchProximity emits ProximityNear when the members labelA of bucketA and labelB of bucketB come closer than
+// threshold meters and ProximityFar when they move apart again, evaluated whenever the tracker updates either
+//
+// The events carry the name of the watch as Fence, the updated member as Label and the distance between the pair.
+// A watch with the same name is replaced, the current distance only sets the initial state and emits nothing.
+func (t *FenceTracker) WatchProximity(name, bucketA, labelA, bucketB, labelB string, threshold float64) error {
+	if !(threshold > 0 && threshold <= MaxRadius) {
+		return ErrInvalidRadius
+	}
+
+	if err := t.UnwatchProximity(name); err != nil && err != ErrFenceNotFound {
+		return err
+	}
+
+	watch := proximityWatch{Name: name, BucketA: bucketA, LabelA: labelA, BucketB: bucketB, LabelB: labelB, Threshold: threshold}
+	a, errA := GetCoordinates(t.client, bucketA, t.bitDepth, labelA)
+	b, errB := GetCoordinates(t.client, bucketB, t.bitDepth, labelB)
+	if errA == nil && errB == nil {
+		watch.Near = Haversine(a.Lat, a.Lon, b.Lat, b.Lon) < threshold
+	}
+
+	encoded, err := json.Marshal(watch)
+	if err != nil {
+		return err
+	}
+
+	multi := t.client.Multi()
+	defer multi.Close()
+
+	_, err = multi.Exec(func() error {
+		multi.HSet(proximityKey(t.fenceSet), name, string(encoded))
+		multi.SAdd(proximityMemberKey(t.fenceSet, bucketA, labelA), name)
+		multi.SAdd(proximityMemberKey(t.fenceSet, bucketB, labelB), name)
+		return nil
+	})
+
+	return err
+}
+
+// UnwatchProximity removes a watch, ErrFenceNotFound is returned when there is none with that name
+func (t *FenceTracker) UnwatchProximity(name string) error {
+	watch, err := t.proximityWatch(name)
+	if err != nil {
+		return err
+	}
+
+	multi := t.client.Multi()
+	defer multi.Close()
+
+	_, err = multi.Exec(func() error {
+		multi.HDel(proximityKey(t.fenceSet), name)
+		multi.SRem(proximityMemberKey(t.fenceSet, watch.BucketA, watch.LabelA), name)
+		multi.SRem(proximityMemberKey(t.fenceSet, watch.BucketB, watch.LabelB), name)
+		return nil
+	})
+
+	return err
+}
+
+// evaluateProximity returns the events of the watches of updated members whose state changed
+func (t *FenceTracker) evaluateProximity(bucketName string, coordinates []GeoKey, now time.Time) ([]FenceEvent, error) {
+	events := []FenceEvent{}
+	for _, coordinate := range coordinates {
+		names, err := t.client.SMembers(proximityMemberKey(t.fenceSet, bucketName, coordinate.Label)).Result()
+		if err != nil {
+			return events, err
+		}
+
+		for _, name := range names {
+			watch, err := t.proximityWatch(name)
+			if err == ErrFenceNotFound {
+				continue
+			}
+			if err != nil {
+				return events, err
+			}
+
+			otherBucket, otherLabel := watch.BucketB, watch.LabelB
+			if watch.BucketB == bucketName && watch.LabelB == coordinate.Label {
+				otherBucket, otherLabel = watch.BucketA, watch.LabelA
+			}
+			other, err := GetCoordinates(t.client, otherBucket, t.bitDepth, otherLabel)
+			if err == ErrMemberNotFound {
+				continue
+			}
+			if err != nil {
+				return events, err
+			}
+
+			distance := Haversine(coordinate.Lat, coordinate.Lon, other.Lat, other.Lon)
+			if near := distance < watch.Threshold; near != watch.Near {
+				watch.Near = near
+				encoded, err := json.Marshal(watch)
+				if err != nil {
+					return events, err
+				}
+				if err := t.client.HSet(proximityKey(t.fenceSet), name, string(encoded)).Err(); err != nil {
+					return events, err
+				}
+
+				eventType := ProximityFar
+				if near {
+					eventType = ProximityNear
+				}
+				events = append(events, FenceEvent{
+					Type:     eventType,
+					Fence:    name,
+					Label:    coordinate.Label,
+					Lat:      coordinate.Lat,
+					Lon:      coordinate.Lon,
+					Distance: distance,
+					Time:     now,
+				})
+			}
+		}
+	}
+
+	return events, nil
+}
+
+func (t *FenceTracker) proximityWatch(name string) (*proximityWatch, error) {
+	value, err := t.client.HGet(proximityKey(t.fenceSet), name).Result()
+	if err == redis.Nil {
+		return nil, ErrFenceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	watch := &proximityWatch{}
+	if err := json.Unmarshal([]byte(value), watch); err != nil {
+		return nil, err
+	}
+
+	return watch, nil
+}
+
+func proximityKey(fenceSet string) string {
+	return fenceSet + ":proximity"
+}
+
+func proximityMemberKey(fenceSet, bucketName, label string) string {
+	return fenceSet + ":proximity:" + bucketName + ":" + label
+}
@@ -0,0 +1,208 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const pruneBatchSize = 500
+
+// ErrInvalidInterval is returned for sweep intervals which are not positive
+var ErrInvalidInterval = errors.New("interval must be positive")
+
+type (
+	// StaleSweeper periodically prunes the members of a bucket not seen for a while
+	//
+	// Sweepers of the same bucket in other processes share a lock in redis, only the one holding it prunes during
+	// an interval.
+	StaleSweeper struct {
+		client     *redis.Client
+		bucketName string
+		bitDepth   uint8
+		olderThan  time.Duration
+		interval   time.Duration
+		maxLen     int64
+		onPrune    func(int64)
+		onError    func(error)
+		logger     *slog.Logger
+
+		done    chan struct{}
+		stopped sync.WaitGroup
+	}
+
+	// SweeperOption configures a StaleSweeper
+	SweeperOption func(*StaleSweeper)
+)
+
+// WithSweeperChangeStream records the pruned members on the change stream of the bucket, see WithChangeStream
+func WithSweeperChangeStream(maxLen int64) SweeperOption {
+	return func(s *StaleSweeper) {
+		s.maxLen = max(maxLen, 0)
+	}
+}
+
+// WithPruneHandler sets the function receiving the number of members removed by every sweep
+func WithPruneHandler(handler func(removed int64)) SweeperOption {
+	return func(s *StaleSweeper) {
+		s.onPrune = handler
+	}
+}
+
+// WithSweeperErrorHandler sets the function receiving failed sweeps
+func WithSweeperErrorHandler(handler func(error)) SweeperOption {
+	return func(s *StaleSweeper) {
+		s.onError = handler
+	}
+}
+
+// PruneStale removes the members last seen more than olderThan ago together with their payloads and returns their
+// number
+//
+// Members without a last seen time are kept, see TouchMembers.
+func PruneStale(client *redis.Client, bucketName string, bitDepth uint8, olderThan time.Duration) (int64, error) {
+	return pruneStale(client, bucketName, bitDepth, -1, olderThan)
+}
+
+// PruneStaleWithChanges prunes like PruneStale and appends the removals to the change stream of the bucket in the
+// same script
+func PruneStaleWithChanges(client *redis.Client, bucketName string, bitDepth uint8, maxLen int64, olderThan time.Duration) (int64, error) {
+	return pruneStale(client, bucketName, bitDepth, max(maxLen, 0), olderThan)
+}
+
+// SweepStale starts pruning the members of a bucket last seen more than olderThan ago every interval
+func SweepStale(client *redis.Client, bucketName string, bitDepth uint8, olderThan, interval time.Duration, options ...SweeperOption) (*StaleSweeper, error) {
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+
+	s := &StaleSweeper{
+		client:     client,
+		bucketName: bucketName,
+		bitDepth:   bitDepth,
+		olderThan:  olderThan,
+		interval:   interval,
+		maxLen:     -1,
+		onPrune:    func(int64) {},
+		onError:    func(error) {},
+		done:       make(chan struct{}),
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	s.stopped.Add(1)
+	go s.loop()
+
+	return s, nil
+}
+
+// Close stops sweeping and waits for a running sweep to finish
+func (s *StaleSweeper) Close() error {
+	close(s.done)
+	s.stopped.Wait()
+
+	return nil
+}
+
+func (s *StaleSweeper) loop() {
+	defer s.stopped.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.sweep(); err != nil {
+			s.onError(err)
+			if s.logger != nil {
+				s.logger.Error("georedis: sweep failed", "bucket", s.bucketName, "error", err)
+			}
+		}
+
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweep prunes when the lock of the bucket is free, the lock is kept until it expires after the interval so
+// sweepers started at different times don't prune right after each other
+func (s *StaleSweeper) sweep() error {
+	if locked, err := acquireLock(s.client, pruneLockKey(s.bucketName), s.interval); err != nil || !locked {
+		return err
+	}
+
+	start := time.Now()
+	removed, err := pruneStale(s.client, s.bucketName, s.bitDepth, s.maxLen, s.olderThan)
+	if err != nil {
+		return err
+	}
+	s.onPrune(removed)
+	if s.logger != nil {
+		s.logger.Info("georedis: swept stale members", "bucket", s.bucketName, "removed", removed, "duration", time.Since(start))
+	}
+
+	return nil
+}
+
+// pruneStale runs the change script in batches until no stale member is left and removes the tags and names of
+// the removed members
+func pruneStale(client *redis.Client, bucketName string, bitDepth uint8, maxLen int64, olderThan time.Duration) (int64, error) {
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return 0, err
+	}
+
+	threshold := formatUnixScore(time.Now().Add(-olderThan))
+	args := []string{strconv.Itoa(int(bitDepth)), strconv.FormatInt(maxLen, 10), "stale", threshold, strconv.Itoa(pruneBatchSize)}
+
+	var removed int64
+	for {
+		labels, err := runStaleScript(client, bucketName, args)
+		removed += int64(len(labels))
+		if err != nil {
+			return removed, err
+		}
+		if err := clearTags(client, bucketName, labels...); err != nil {
+			return removed, err
+		}
+		if err := clearNames(client, bucketName, labels...); err != nil {
+			return removed, err
+		}
+
+		left, err := client.ZCount(lastSeenKey(bucketName), "-inf", "("+threshold).Result()
+		if err != nil || left == 0 {
+			return removed, err
+		}
+	}
+}
+
+func pruneLockKey(bucketName string) string {
+	return bucketName + ":prune:lock"
+}
+
+// acquireLock sets key for ttl unless it is set already and returns whether it did
+func acquireLock(client *redis.Client, key string, ttl time.Duration) (bool, error) {
+	cmd := redis.NewCmd("SET", key, "1", "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	client.Process(cmd)
+	if err := cmd.Err(); err == redis.Nil {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,185 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/redis.v2"
+)
+
+// ErrQuotaExceeded is wrapped by the QuotaError of adds which would exceed the quota of a bucket
+var ErrQuotaExceeded = errors.New("bucket quota exceeded")
+
+// QuotaError reports an add rejected because the bucket would hold more than Quota members, none of its
+// coordinates were written
+type QuotaError struct {
+	Bucket  string
+	Quota   int64
+	Members int64
+	Added   int64
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: %q holds %d of %d members, adding %d", ErrQuotaExceeded, e.Bucket, e.Members, e.Quota, e.Added)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// quotaLuaFunction defines checkQuota(bucket, quota, first, step) which returns an error reply when the labels at
+// ARGV[first + 1], ARGV[first + step + 1], ... would make the bucket exceed the quota stored at key quota
+//
+// Updates of members don't count, labels given twice count once.
+const quotaLuaFunction = `
+local function checkQuota(bucket, quotaKey, first, step)
+  local quota = tonumber(redis.call('GET', quotaKey))
+  if not quota then return nil end
+
+  local added, seen = 0, {}
+  for i = first, #ARGV, step do
+    local label = ARGV[i + 1]
+    if not seen[label] and not redis.call('ZSCORE', bucket, label) then added = added + 1 end
+    seen[label] = true
+  end
+
+  local members = redis.call('ZCARD', bucket)
+  if added > 0 and members + added > quota then
+    return redis.error_reply('QUOTA ' .. quota .. ' ' .. members .. ' ' .. added)
+  end
+  return nil
+end
+`
+
+// quotaAddLuaBody adds members unless they exceed the quota of the bucket
+//
+// KEYS are the bucket, its payloads and its quota. ARGV holds score, label, payload triples, payloads are prefixed
+// with "=" and empty when not set. The reply is the number of added members.
+const quotaAddLuaBody = quotaLuaFunction + `
+local exceeded = checkQuota(KEYS[1], KEYS[3], 1, 3)
+if exceeded then return exceeded end
+
+local count = 0
+for i = 1, #ARGV, 3 do
+  count = count + redis.call('ZADD', KEYS[1], ARGV[i], ARGV[i + 1])
+  if ARGV[i + 2] ~= '' then redis.call('HSET', KEYS[2], ARGV[i + 1], string.sub(ARGV[i + 2], 2)) end
+end
+
+return count
+`
+
+var quotaAddScript = newLuaScript(quotaAddLuaBody)
+
+// WithQuotas makes a GeoClient enforce the quotas of buckets set with SetQuota
+//
+// AddCoordinatesWithChanges checks quotas anyway, buckets using native GEO commands are never checked.
+func WithQuotas() ClientOption {
+	return func(c *GeoClient) {
+		c.quotas = true
+	}
+}
+
+// SetQuota limits the number of members of a bucket, a quota of 0 or less removes the limit
+//
+// The quota is stored in redis next to the bucket, so it applies to all clients checking quotas. A bucket holding
+// more members than a new quota keeps them, adds of new members fail until enough were removed.
+func SetQuota(client *redis.Client, bucketName string, maxMembers int64) error {
+	if maxMembers <= 0 {
+		return client.Del(quotaKey(bucketName)).Err()
+	}
+
+	return client.Set(quotaKey(bucketName), strconv.FormatInt(maxMembers, 10)).Err()
+}
+
+// GetQuota returns the quota of a bucket, 0 when it has none
+func GetQuota(client *redis.Client, bucketName string) (int64, error) {
+	quota, err := client.Get(quotaKey(bucketName)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+
+	return quota, err
+}
+
+// AddCoordinatesWithinQuota adds coordinates like AddCoordinates unless the bucket would exceed its quota, then it
+// returns a QuotaError and adds nothing
+//
+// The quota is checked and the coordinates are added by a single script, so concurrent adds can't exceed it.
+func AddCoordinatesWithinQuota(client *redis.Client, bucketName string, bitDepth uint8, coordinates ...GeoKey) (int64, error) {
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return 0, err
+	}
+	if err := ValidateCoordinates(coordinates...); err != nil {
+		return 0, err
+	}
+	if len(coordinates) == 0 {
+		return 0, nil
+	}
+
+	args := make([]string, 0, len(coordinates)*3)
+	for _, coordinate := range coordinates {
+		payload := ""
+		if coordinate.Payload != nil {
+			payload = "=" + string(coordinate.Payload)
+		}
+		args = append(args, strconv.FormatUint(geohashEncoder{bitDepth: bitDepth}.EncodeInt(coordinate.Lat, coordinate.Lon), 10), coordinate.Label, payload)
+	}
+
+	reply, err := quotaAddScript.run(client, []string{bucketName, payloadKey(bucketName), quotaKey(bucketName)}, args)
+	if err != nil {
+		return 0, quotaError(bucketName, err)
+	}
+
+	count, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected quota script reply %v", reply)
+	}
+
+	return count, nil
+}
+
+// SetQuota limits the number of members of a bucket, see WithQuotas
+func (c *GeoClient) SetQuota(bucketName string, maxMembers int64) error {
+	return c.handle(Operation{Name: "SetQuota", Bucket: bucketName, Write: true, Args: []any{maxMembers}}, func() error {
+		key := c.key(bucketName)
+		return c.do(func() error {
+			return SetQuota(c.client, key, maxMembers)
+		})
+	})
+}
+
+// GetQuota returns the quota of a bucket, 0 when it has none
+func (c *GeoClient) GetQuota(bucketName string) (int64, error) {
+	return handleOp(c, Operation{Name: "GetQuota", Bucket: bucketName}, func() (int64, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() (int64, error) {
+			return GetQuota(c.client, key)
+		})
+	})
+}
+
+// quotaError converts the error reply of checkQuota into a QuotaError
+func quotaError(bucketName string, err error) error {
+	fields := strings.Fields(err.Error())
+	if len(fields) != 4 || fields[0] != "QUOTA" {
+		return err
+	}
+
+	quotaErr := &QuotaError{Bucket: bucketName}
+	quotaErr.Quota, _ = strconv.ParseInt(fields[1], 10, 64)
+	quotaErr.Members, _ = strconv.ParseInt(fields[2], 10, 64)
+	quotaErr.Added, _ = strconv.ParseInt(fields[3], 10, 64)
+
+	return quotaErr
+}
+
+func quotaKey(bucketName string) string {
+	return bucketName + ":quota"
+}
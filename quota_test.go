@@ -0,0 +1,65 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestQuota(t *testing.T) {
+	const zSetQuota = "test:quota"
+
+	client.Del(zSetQuota, zSetQuota+":quota", zSetQuota+":payload")
+	geoClient := NewGeoClient(client, bitDepth, WithQuotas())
+
+	if err := geoClient.SetQuota(zSetQuota, 2); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if quota, err := geoClient.GetQuota(zSetQuota); err != nil || quota != 2 {
+		t.Logf("expected a quota of 2 got %d, %v\n", quota, err)
+		t.Fail()
+	}
+
+	if added, err := geoClient.AddCoordinates(zSetQuota, GeoKey{Label: "a", Lat: 52.52, Lon: 13.405}, GeoKey{Label: "b", Lat: 52.521, Lon: 13.405}); err != nil || added != 2 {
+		t.Logf("expected 2 members added got %d, %v\n", added, err)
+		t.Fail()
+	}
+
+	_, err := geoClient.AddCoordinates(zSetQuota, GeoKey{Label: "a", Lat: 52.53, Lon: 13.405}, GeoKey{Label: "c", Lat: 52.522, Lon: 13.405})
+	var quotaErr *QuotaError
+	if !errors.Is(err, ErrQuotaExceeded) || !errors.As(err, &quotaErr) || quotaErr.Quota != 2 || quotaErr.Members != 2 || quotaErr.Added != 1 {
+		t.Logf("expected a QuotaError got %v\n", err)
+		t.Fail()
+	}
+	if count := client.ZCard(zSetQuota).Val(); count != 2 {
+		t.Logf("expected nothing added got %d members\n", count)
+		t.Fail()
+	}
+
+	if added, err := geoClient.AddCoordinates(zSetQuota, GeoKey{Label: "a", Lat: 52.53, Lon: 13.405, Payload: []byte("moved")}); err != nil || added != 0 {
+		t.Logf("expected updates within the quota got %d, %v\n", added, err)
+		t.Fail()
+	}
+	if payload := client.HGet(zSetQuota+":payload", "a").Val(); payload != "moved" {
+		t.Logf("expected the payload to be written got %q\n", payload)
+		t.Fail()
+	}
+
+	if _, err := AddCoordinatesWithChanges(client, zSetQuota, bitDepth, 0, GeoKey{Label: "c", Lat: 52.522, Lon: 13.405}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Logf("expected the change script to check the quota got %v\n", err)
+		t.Fail()
+	}
+
+	geoClient.SetQuota(zSetQuota, 0)
+	if added, err := geoClient.AddCoordinates(zSetQuota, GeoKey{Label: "c", Lat: 52.522, Lon: 13.405}); err != nil || added != 1 {
+		t.Logf("expected the quota to be removed got %d, %v\n", added, err)
+		t.Fail()
+	}
+}
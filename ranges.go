@@ -0,0 +1,136 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"math"
+	"slices"
+)
+
+const (
+	metersPerDegree = 6371000 * math.Pi / 180
+
+	// MaxRadius is half the circumference of the earth in meters, a search with this radius covers the globe
+	MaxRadius = 6371000 * math.Pi
+
+	// polarLatitude is the latitude from which searches scan a whole latitude band instead of neighbor cells
+	polarLatitude = 85
+
+	// maxBandCells bounds the number of cells, and so ranges, of a latitude band scan
+	maxBandCells = 64
+)
+
+// ErrInvalidRadius is returned for radii which are not positive, NaN or above MaxRadius
+var ErrInvalidRadius = errors.New("radius must be positive and at most MaxRadius")
+
+// queryRanges returns the score ranges covering a radius search
+//
+// Radii beyond the coarsest neighbor cells search the whole bucket and rely on the exact distance filter.
+//
+// Cells shrink towards the poles in longitude, so above 60° the cells are made coarser until the neighbors span
+// as far east and west as north and south. Searches reaching beyond polarLatitude or across a pole scan the
+// whole latitude band instead, the exact distance filter drops what is out of range.
+func queryRanges(lat, lon, radius float64, bitDepth uint8) ([]geoRange, error) {
+	ranges, _, err := planRanges(lat, lon, radius, bitDepth, SearchTuning{})
+	return ranges, err
+}
+
+// planRanges returns the ranges of queryRanges chosen with tuning and the depth of the cells they are made of
+func planRanges(lat, lon, radius float64, bitDepth uint8, tuning SearchTuning) ([]geoRange, uint8, error) {
+	if err := validateLatLon(lat, lon); err != nil {
+		return []geoRange{}, 0, &CoordinateError{Key: GeoKey{Lat: lat, Lon: lon}, Err: err}
+	}
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return []geoRange{}, 0, err
+	}
+	if !(radius > 0 && radius <= MaxRadius) {
+		return []geoRange{}, 0, ErrInvalidRadius
+	}
+	if radius > rangeIndex[rangeIndexLen-1] {
+		return []geoRange{{Lower: 0, Upper: float64(uint64(1) << bitDepth)}}, 0, nil
+	}
+
+	radiusBitDepth, err := tuning.precision(radius)
+	if err != nil {
+		return []geoRange{}, 0, err
+	}
+	dLat := radius / metersPerDegree
+	poleward := math.Abs(lat) + dLat
+	if poleward >= polarLatitude {
+		ranges, depth := latitudeBandRanges(lat-dLat, lat+dLat, bitDepth)
+		return ranges, depth, nil
+	}
+
+	// longitude cells are twice as wide as latitude cells, that makes up for the shrinking up to 60°
+	if stretch := math.Ceil(math.Log2(1 / (2 * math.Cos(poleward*math.Pi/180)))); stretch > 0 {
+		coarser := uint8(stretch) * 2
+		if coarser >= radiusBitDepth {
+			ranges, depth := latitudeBandRanges(lat-dLat, lat+dLat, bitDepth)
+			return ranges, depth, nil
+		}
+		radiusBitDepth -= coarser
+	}
+
+	if tuning.MinCells > 0 || tuning.MaxOverFetch > 0 {
+		if radiusBitDepth > bitDepth {
+			return []geoRange{}, 0, ErrBitDepthTooLow
+		}
+		cells, depth := tuning.refine(lat, lon, radius, radiusBitDepth, bitDepth)
+		return cellRanges(cells, bitDepth-depth), depth, nil
+	}
+
+	ranges, err := getQueryRangesFromBitDepth(lat, lon, radiusBitDepth, bitDepth)
+	return ranges, radiusBitDepth, err
+}
+
+// latitudeBandRanges returns the ranges of all cells intersecting the band between minLat and maxLat around
+// the globe, using the finest cells which keep the band within maxBandCells, and the depth of these cells
+func latitudeBandRanges(minLat, maxLat float64, bitDepth uint8) ([]geoRange, uint8) {
+	minLat, maxLat = max(minLat, -90), min(maxLat, 90)
+
+	latBits := uint8(1)
+	for bits := min(uint8(6), bitDepth/2); bits > 1; bits-- {
+		if (1<<bits)*bandRows(minLat, maxLat, bits) <= maxBandCells {
+			latBits = bits
+			break
+		}
+	}
+
+	cellHeight := 180 / float64(uint64(1)<<latBits)
+	cellWidth := 360 / float64(uint64(1)<<latBits)
+	firstRow := bandRow(minLat, latBits)
+
+	cells := []uint64{}
+	for row := firstRow; row < firstRow+bandRows(minLat, maxLat, latBits); row++ {
+		rowLat := -90 + (float64(row)+0.5)*cellHeight
+		for col := 0; col < 1<<latBits; col++ {
+			cells = append(cells, geohashEncoder{bitDepth: latBits * 2}.EncodeInt(rowLat, -180+(float64(col)+0.5)*cellWidth))
+		}
+	}
+	slices.Sort(cells)
+
+	bitDiff := bitDepth - latBits*2
+	ranges := []geoRange{}
+	for i := 0; i < len(cells); {
+		lower, upper := cells[i], cells[i]+1
+		for i++; i < len(cells) && cells[i] == upper; i++ {
+			upper++
+		}
+		ranges = append(ranges, geoRange{Lower: float64(lower << bitDiff), Upper: float64(upper << bitDiff)})
+	}
+
+	return ranges, latBits * 2
+}
+
+func bandRow(lat float64, latBits uint8) int {
+	rows := 1 << latBits
+	return min(int((lat+90)/180*float64(rows)), rows-1)
+}
+
+func bandRows(minLat, maxLat float64, latBits uint8) int {
+	return bandRow(maxLat, latBits) - bandRow(minLat, latBits) + 1
+}
@@ -0,0 +1,120 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/tapglue/georedis/internal/geohash"
+)
+
+func TestGetQueryRangesMergesNeighbors(t *testing.T) {
+	for _, radiusBitDepth := range []uint8{2, 4, 10, 26, 40, 52} {
+		ranges, err := getQueryRangesFromBitDepth(39.9523, -75.1638, radiusBitDepth, 52)
+		if err != nil {
+			t.Logf("error encountered %q\n", err)
+			t.FailNow()
+		}
+
+		cells := uint64(0)
+		for idx, r := range ranges {
+			if r.Upper <= r.Lower {
+				t.Logf("depth %d: empty range %v\n", radiusBitDepth, r)
+				t.Fail()
+			}
+			if idx > 0 && r.Lower <= ranges[idx-1].Upper {
+				t.Logf("depth %d: ranges %v and %v are not merged or ordered\n", radiusBitDepth, ranges[idx-1], r)
+				t.Fail()
+			}
+			cells += uint64(r.Upper-r.Lower) >> (52 - radiusBitDepth)
+		}
+		if radiusBitDepth > 4 && cells != 9 {
+			t.Logf("depth %d: expected 9 cells to be covered got %d\n", radiusBitDepth, cells)
+			t.Fail()
+		}
+	}
+}
+
+func TestCoverCircleContainsCircle(t *testing.T) {
+	circles := []struct{ lat, lon, radius float64 }{
+		{52.52, 13.405, 50},
+		{52.52, 13.405, 25000},
+		{-16.5, 179.99, 5000},
+		{89.5, 0, 100000},
+		{-60, -45, 2000000},
+	}
+
+	for _, c := range circles {
+		depth, cells := coverCircle(c.lat, c.lon, c.radius)
+		if len(cells) > maxFenceCells {
+			t.Logf("%v: expected at most %d cells got %d\n", c, maxFenceCells, len(cells))
+			t.Fail()
+		}
+
+		for bearing := 0.0; bearing < 360; bearing += 15 {
+			lat, lon := destination(c.lat, c.lon, bearing, c.radius)
+			if !slices.Contains(cells, geohash.EncodeInt(lat, lon, depth)) {
+				t.Logf("%v: point %f, %f on the circle is not covered\n", c, lat, lon)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestCoverPolygonDropsOutsideCells(t *testing.T) {
+	// an L shaped polygon leaves the north east of its bounding box empty
+	polygon := MultiPolygon{{{
+		{Lat: 0, Lon: 0}, {Lat: 0, Lon: 10}, {Lat: 2, Lon: 10}, {Lat: 2, Lon: 2}, {Lat: 10, Lon: 2}, {Lat: 10, Lon: 0},
+	}}}
+	minLat, maxLat, west, east := polygon.bounds()
+
+	depth, all := coverBox(minLat, maxLat, west, east, maxPolygonCells, nil)
+	_, cells := coverBox(minLat, maxLat, west, east, maxPolygonCells, polygon.intersectsBox)
+	if len(cells) >= len(all) {
+		t.Logf("expected fewer than %d cells got %d\n", len(all), len(cells))
+		t.Fail()
+	}
+
+	for _, point := range []Point{{1, 1}, {1, 9.9}, {9.9, 1}, {0.1, 0.1}} {
+		if !slices.Contains(cells, geohash.EncodeInt(point.Lat, point.Lon, depth)) {
+			t.Logf("point %v inside the polygon is not covered\n", point)
+			t.Fail()
+		}
+	}
+	if slices.Contains(cells, geohash.EncodeInt(8, 8, depth)) {
+		t.Logf("expected the cell of 8, 8 outside the polygon to be dropped\n")
+		t.Fail()
+	}
+}
+
+// destination returns the point distance meters away from lat & lon in the direction of bearing
+func destination(lat, lon, bearing, distance float64) (float64, float64) {
+	phi, lambda, theta, delta := radians(lat), radians(lon), radians(bearing), distance/earthRadius
+	phi2 := math.Asin(math.Sin(phi)*math.Cos(delta) + math.Cos(phi)*math.Sin(delta)*math.Cos(theta))
+	lambda2 := lambda + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(phi), math.Cos(delta)-math.Sin(phi)*math.Sin(phi2))
+
+	return phi2 * 180 / math.Pi, math.Remainder(lambda2*180/math.Pi, 360)
+}
+
+func BenchmarkGetQueryRanges(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		getQueryRangesFromBitDepth(39.9523, -75.1638, 26, 52)
+	}
+}
+
+func BenchmarkRankResultsAll(b *testing.B) {
+	points := randomPoints(5000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		rankResults(39.5, -74.5, math.MaxFloat64, 52, points, -1, Haversine)
+	}
+}
@@ -0,0 +1,92 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/tapglue/georedis/internal/geohash"
+
+	"gopkg.in/redis.v2"
+)
+
+func randomPoints(n int) []redis.Z {
+	random := rand.New(rand.NewSource(1))
+	points := make([]redis.Z, n)
+	for idx := range points {
+		lat, lon := 39+random.Float64(), -75+random.Float64()
+		points[idx] = redis.Z{Score: float64(geohash.EncodeInt(lat, lon, 52)), Member: string(rune('a' + idx%26))}
+	}
+
+	return points
+}
+
+func TestRankResultsLimit(t *testing.T) {
+	points := randomPoints(500)
+
+	all := rankResults(39.5, -74.5, math.MaxFloat64, 52, points, -1, Haversine)
+	if len(all) != len(points) {
+		t.Logf("expected %d results got %d\n", len(points), len(all))
+		t.FailNow()
+	}
+
+	for _, limit := range []int{0, 1, 10, 499, 500, 1000} {
+		nearest := rankResults(39.5, -74.5, math.MaxFloat64, 52, points, limit, Haversine)
+
+		expected := limit
+		if expected > len(points) {
+			expected = len(points)
+		}
+		if len(nearest) != expected {
+			t.Logf("limit %d: expected %d results got %d\n", limit, expected, len(nearest))
+			t.Fail()
+			continue
+		}
+		for idx := range nearest {
+			if nearest[idx].Distance != all[idx].Distance {
+				t.Logf("limit %d: result %d has distance %f expected %f\n", limit, idx, nearest[idx].Distance, all[idx].Distance)
+				t.Fail()
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkRankResultsLimit10(b *testing.B) {
+	points := randomPoints(50000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		rankResults(39.5, -74.5, math.MaxFloat64, 52, points, 10, Haversine)
+	}
+}
+
+func TestRankResultsDeduplicates(t *testing.T) {
+	points := randomPoints(20)
+	// the same member fetched by two ranges, out of score order
+	points = append(points, points[3], points[0])
+
+	for _, limit := range []int{-1, 5} {
+		results := rankResults(39.5, -74.5, math.MaxFloat64, 52, slices.Clone(points), limit, Haversine)
+
+		seen := map[string]bool{}
+		for _, result := range results {
+			if seen[result.Label] {
+				t.Logf("limit %d: duplicate result %s\n", limit, result.Label)
+				t.Fail()
+			}
+			seen[result.Label] = true
+		}
+		if limit == -1 && len(results) != 20 {
+			t.Logf("expected 20 results got %d\n", len(results))
+			t.Fail()
+		}
+	}
+}
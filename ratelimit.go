@@ -0,0 +1,213 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const defaultRateLimitKeys = 10000
+
+// ErrRateLimited is wrapped by the RateLimitedError of updates rejected by WithRateLimit
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// Rate limit policies
+const (
+	// RejectOverLimit drops the updates over the limit and returns a RateLimitedError
+	RejectOverLimit RateLimitPolicy = iota
+	// CoalesceOverLimit keeps the latest update of every label over the limit and writes it once the limit allows
+	CoalesceOverLimit
+)
+
+type (
+	// RateLimitPolicy decides what happens to updates over a RateLimit
+	RateLimitPolicy int
+
+	// RateLimit configures token buckets limiting the coordinates a GeoClient writes, every coordinate takes a token
+	RateLimit struct {
+		// Rate is the number of tokens added per second, Burst the maximum number of tokens
+		Rate  float64
+		Burst int
+		// PerLabel keeps a token bucket per label of a bucket instead of one per bucket
+		PerLabel bool
+		Policy   RateLimitPolicy
+		// MaxKeys bounds the token buckets kept in memory before full ones are dropped, 10000 by default
+		MaxKeys int
+	}
+
+	// RateLimitedError lists the labels whose updates were rejected, it wraps ErrRateLimited
+	RateLimitedError struct {
+		Bucket string
+		Labels []string
+	}
+
+	rateLimiter struct {
+		limit RateLimit
+
+		mu      sync.Mutex
+		buckets map[rateLimitKey]*tokenBucket
+	}
+
+	rateLimitKey struct {
+		namespace string
+		bucket    string
+		label     string
+	}
+
+	tokenBucket struct {
+		tokens  float64
+		last    time.Time
+		pending map[string]GeoKey
+	}
+)
+
+// WithRateLimit limits the coordinates added through the GeoClient with in-process token buckets
+//
+// Coordinates within the limit are written right away. With CoalesceOverLimit the coordinates over the limit are
+// written in the background once tokens are available, only the latest coordinate of a label is kept until then
+// and failed background writes are logged, see WithLogger.
+func WithRateLimit(limit RateLimit) ClientOption {
+	return func(c *GeoClient) {
+		if limit.MaxKeys <= 0 {
+			limit.MaxKeys = defaultRateLimitKeys
+		}
+		c.limiter = &rateLimiter{limit: limit, buckets: map[rateLimitKey]*tokenBucket{}}
+	}
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("%s for %d labels of %q", ErrRateLimited, len(e.Labels), e.Bucket)
+}
+
+func (e *RateLimitedError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// admit returns the coordinates within the limit and the error for the rejected ones, coalesced coordinates are
+// scheduled to be written by c
+func (l *rateLimiter) admit(c *GeoClient, bucketName string, coordinates []GeoKey) ([]GeoKey, error) {
+	if l == nil {
+		return coordinates, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	admitted := make([]GeoKey, 0, len(coordinates))
+	var rejected []string
+	for _, coordinate := range coordinates {
+		key := rateLimitKey{namespace: c.namespace, bucket: bucketName}
+		if l.limit.PerLabel {
+			key.label = coordinate.Label
+		}
+
+		// while coordinates are pending newer ones queue behind them, written right away they could be
+		// overwritten by the older pending ones
+		bucket := l.bucket(key, now)
+		if bucket.tokens >= 1 && bucket.pending == nil {
+			bucket.tokens--
+			admitted = append(admitted, coordinate)
+			continue
+		}
+
+		if l.limit.Policy == CoalesceOverLimit {
+			if bucket.pending == nil {
+				bucket.pending = map[string]GeoKey{}
+				l.schedule(c, key, bucket)
+			}
+			bucket.pending[coordinate.Label] = coordinate
+			continue
+		}
+		rejected = append(rejected, coordinate.Label)
+	}
+
+	if len(rejected) > 0 {
+		return admitted, &RateLimitedError{Bucket: bucketName, Labels: rejected}
+	}
+
+	return admitted, nil
+}
+
+// bucket returns the refilled token bucket of key, dropping full ones when too many are kept
+func (l *rateLimiter) bucket(key rateLimitKey, now time.Time) *tokenBucket {
+	bucket, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= l.limit.MaxKeys {
+			l.dropFull(now)
+		}
+		bucket = &tokenBucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(float64(l.limit.Burst), bucket.tokens+now.Sub(bucket.last).Seconds()*l.limit.Rate)
+	bucket.last = now
+
+	return bucket
+}
+
+// dropFull forgets the token buckets without pending coordinates which refilled completely, they are
+// indistinguishable from new ones
+func (l *rateLimiter) dropFull(now time.Time) {
+	for key, bucket := range l.buckets {
+		if bucket.pending == nil && bucket.tokens+now.Sub(bucket.last).Seconds()*l.limit.Rate >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// wait returns how long it takes until the bucket has a token
+func (l *rateLimiter) wait(bucket *tokenBucket) time.Duration {
+	if l.limit.Rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration((1 - bucket.tokens) / l.limit.Rate * float64(time.Second))
+}
+
+// schedule flushes the coalesced coordinates of key once the bucket has a token
+func (l *rateLimiter) schedule(c *GeoClient, key rateLimitKey, bucket *tokenBucket) {
+	time.AfterFunc(l.wait(bucket), func() { l.flush(c, key) })
+}
+
+// flush writes as many coalesced coordinates of key as the bucket has tokens for, the bucket stays pending until
+// the write finished so coordinates arriving meanwhile are written by the next flush
+func (l *rateLimiter) flush(c *GeoClient, key rateLimitKey) {
+	l.mu.Lock()
+	bucket := l.bucket(key, time.Now())
+	pending := make([]GeoKey, 0, min(len(bucket.pending), int(bucket.tokens)))
+	for label, coordinate := range bucket.pending {
+		if len(pending) == cap(pending) {
+			break
+		}
+		pending = append(pending, coordinate)
+		delete(bucket.pending, label)
+	}
+	bucket.tokens -= float64(len(pending))
+	l.mu.Unlock()
+
+	if len(pending) > 0 {
+		_, err := handleOp(c, Operation{Name: "AddCoordinates", Bucket: key.bucket, Write: true, Args: []any{pending}}, func() (int64, error) {
+			return c.addCoordinates(key.bucket, pending)
+		})
+		if err != nil && c.logger != nil {
+			c.logger.Error("georedis: writing coalesced coordinates failed", "bucket", key.bucket, "error", err)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(bucket.pending) == 0 {
+		bucket.pending = nil
+		return
+	}
+	l.schedule(c, key, l.bucket(key, time.Now()))
+}
@@ -0,0 +1,88 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestRateLimitRejects(t *testing.T) {
+	const zSetLimited = "test:ratelimit:reject"
+
+	client.Del(zSetLimited)
+	geoClient := NewGeoClient(client, bitDepth, WithRateLimit(RateLimit{Rate: 0.001, Burst: 1, PerLabel: true}))
+
+	if added, err := geoClient.AddCoordinates(zSetLimited, GeoKey{Label: "a", Lat: 52.52, Lon: 13.405}); err != nil || added != 1 {
+		t.Logf("expected the first update to pass got %d, %v\n", added, err)
+		t.Fail()
+	}
+
+	added, err := geoClient.AddCoordinates(zSetLimited, GeoKey{Label: "a", Lat: 52.53, Lon: 13.405}, GeoKey{Label: "b", Lat: 52.52, Lon: 13.405})
+	var limited *RateLimitedError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &limited) || len(limited.Labels) != 1 || limited.Labels[0] != "a" || added != 1 {
+		t.Logf("expected only the update of a to be rejected got %d, %v\n", added, err)
+		t.Fail()
+	}
+
+	if key, err := geoClient.GetCoordinates(zSetLimited, "a"); err != nil || math.Abs(key.Lat-52.52) > 0.001 {
+		t.Logf("expected the rejected update not to be written got %v, %v\n", key, err)
+		t.Fail()
+	}
+}
+
+func TestRateLimitCoalesces(t *testing.T) {
+	const zSetLimited = "test:ratelimit:coalesce"
+
+	client.Del(zSetLimited)
+	geoClient := NewGeoClient(client, bitDepth, WithRateLimit(RateLimit{Rate: 20, Burst: 1, Policy: CoalesceOverLimit}))
+
+	for _, lat := range []float64{52.52, 52.53, 52.54} {
+		if _, err := geoClient.AddCoordinates(zSetLimited, GeoKey{Label: "a", Lat: lat, Lon: 13.405}); err != nil {
+			t.Logf("error encountered %q\n", err)
+			t.Fail()
+		}
+	}
+
+	if key, err := geoClient.GetCoordinates(zSetLimited, "a"); err != nil || math.Abs(key.Lat-52.52) > 0.001 {
+		t.Logf("expected only the first update to be written yet got %v, %v\n", key, err)
+		t.Fail()
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if key, err := geoClient.GetCoordinates(zSetLimited, "a"); err != nil || math.Abs(key.Lat-52.54) > 0.001 {
+		t.Logf("expected the latest update to be written got %v, %v\n", key, err)
+		t.Fail()
+	}
+}
+
+func TestRateLimitCoalescesAcrossRefill(t *testing.T) {
+	const zSetLimited = "test:ratelimit:refill"
+
+	client.Del(zSetLimited)
+	geoClient := NewGeoClient(client, bitDepth, WithRateLimit(RateLimit{Rate: 20, Burst: 1, Policy: CoalesceOverLimit}))
+
+	// the updates keep arriving while tokens refill, a pending older update must never overwrite a newer one
+	lat := 52.52
+	for idx := 0; idx < 40; idx++ {
+		lat += 0.001
+		if _, err := geoClient.AddCoordinates(zSetLimited, GeoKey{Label: "a", Lat: lat, Lon: 13.405}); err != nil {
+			t.Logf("error encountered %q\n", err)
+			t.Fail()
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if key, err := geoClient.GetCoordinates(zSetLimited, "a"); err != nil || math.Abs(key.Lat-lat) > 0.0001 {
+		t.Logf("expected the latest update %f to be written got %v, %v\n", lat, key, err)
+		t.Fail()
+	}
+}
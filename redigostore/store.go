@@ -0,0 +1,129 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package redigostore implements georedis.CoreStore on top of github.com/gomodule/redigo
+package redigostore
+
+import (
+	"strconv"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/tapglue/georedis"
+)
+
+// Store implements georedis.CoreStore, every command borrows a connection from the pool
+type Store struct {
+	pool *redis.Pool
+}
+
+var _ georedis.CoreStore = (*Store)(nil)
+
+// New returns a Store using the pool
+func New(pool *redis.Pool) *Store {
+	return &Store{pool: pool}
+}
+
+func (s *Store) do(command string, args ...interface{}) (interface{}, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	return conn.Do(command, args...)
+}
+
+// ZAdd adds members to a sorted set
+func (s *Store) ZAdd(key string, members ...georedis.Member) (int64, error) {
+	args := redis.Args{key}
+	for _, member := range members {
+		args = args.Add(member.Score, member.Label)
+	}
+
+	return redis.Int64(s.do("ZADD", args...))
+}
+
+// ZRem removes members from a sorted set
+func (s *Store) ZRem(key string, members ...string) (int64, error) {
+	return redis.Int64(s.do("ZREM", redis.Args{key}.AddFlat(members)...))
+}
+
+// ZScore returns the score of a member or georedis.ErrMemberNotFound
+func (s *Store) ZScore(key, member string) (float64, error) {
+	score, err := redis.Float64(s.do("ZSCORE", key, member))
+	if err == redis.ErrNil {
+		return 0, georedis.ErrMemberNotFound
+	}
+
+	return score, err
+}
+
+// ZCard returns the number of members of a sorted set
+func (s *Store) ZCard(key string) (int64, error) {
+	return redis.Int64(s.do("ZCARD", key))
+}
+
+// ZRange returns the members between the start and stop index
+func (s *Store) ZRange(key string, start, stop int64) ([]georedis.Member, error) {
+	return members(redis.Strings(s.do("ZRANGE", key, start, stop, "WITHSCORES")))
+}
+
+// ZRangeByScore returns the members with a score between min and max
+func (s *Store) ZRangeByScore(key string, min, max float64, offset, count int64) ([]georedis.Member, error) {
+	args := redis.Args{key, min, max, "WITHSCORES"}
+	if count > 0 {
+		args = args.Add("LIMIT", offset, count)
+	}
+
+	return members(redis.Strings(s.do("ZRANGEBYSCORE", args...)))
+}
+
+// HSet sets hash fields
+func (s *Store) HSet(key string, values map[string][]byte) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	_, err := s.do("HSET", redis.Args{key}.AddFlat(values)...)
+	return err
+}
+
+// HMGet returns the values of hash fields, nil for missing ones
+func (s *Store) HMGet(key string, fields ...string) ([][]byte, error) {
+	values := make([][]byte, len(fields))
+	if len(fields) == 0 {
+		return values, nil
+	}
+
+	reply, err := redis.Values(s.do("HMGET", redis.Args{key}.AddFlat(fields)...))
+	if err != nil {
+		return values, err
+	}
+	for idx := range reply {
+		values[idx], _ = reply[idx].([]byte)
+	}
+
+	return values, nil
+}
+
+// HDel deletes hash fields
+func (s *Store) HDel(key string, fields ...string) (int64, error) {
+	return redis.Int64(s.do("HDEL", redis.Args{key}.AddFlat(fields)...))
+}
+
+func members(reply []string, err error) ([]georedis.Member, error) {
+	if err != nil {
+		return []georedis.Member{}, err
+	}
+
+	result := make([]georedis.Member, len(reply)/2)
+	for idx := range result {
+		score, err := strconv.ParseFloat(reply[idx*2+1], 64)
+		if err != nil {
+			return []georedis.Member{}, err
+		}
+		result[idx] = georedis.Member{Label: reply[idx*2], Score: score}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,63 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import "gopkg.in/redis.v2"
+
+const reencodeBatchSize = 1000
+
+// ReencodeBucket changes the bit depth of a bucket and returns the number of re-encoded members
+//
+// Members are read in pages, decoded with oldBitDepth and written re-encoded with newBitDepth to a new key which
+// replaces the bucket atomically once all members were written. Members written to the bucket in the meantime
+// are lost. Decoded coordinates are the centers of the old cells, so a higher bit depth doesn't restore the
+// precision of the original coordinates. Payloads, last seen times and motions are kept as they are.
+func ReencodeBucket(client *redis.Client, bucketName string, oldBitDepth, newBitDepth uint8) (int64, error) {
+	if err := ValidateBitDepth(oldBitDepth); err != nil {
+		return 0, err
+	}
+	if err := ValidateBitDepth(newBitDepth); err != nil {
+		return 0, err
+	}
+
+	target := bucketName + ":reencode"
+	if err := client.Del(target).Err(); err != nil {
+		return 0, err
+	}
+
+	oldCells := geohashEncoder{bitDepth: oldBitDepth}
+	newCells := geohashEncoder{bitDepth: newBitDepth}
+
+	var reencoded int64
+	for offset := int64(0); ; offset += reencodeBatchSize {
+		members, err := client.ZRangeWithScores(bucketName, offset, offset+reencodeBatchSize-1).Result()
+		if err != nil {
+			return 0, err
+		}
+		if len(members) == 0 {
+			break
+		}
+
+		for idx := range members {
+			lat, lon := oldCells.DecodeInt(uint64(members[idx].Score))
+			members[idx].Score = float64(newCells.EncodeInt(lat, lon))
+		}
+		if err := client.ZAdd(target, members...).Err(); err != nil {
+			return 0, err
+		}
+
+		reencoded += int64(len(members))
+		if len(members) < reencodeBatchSize {
+			break
+		}
+	}
+
+	if reencoded == 0 {
+		return 0, nil
+	}
+
+	return reencoded, client.Rename(target, bucketName).Err()
+}
@@ -0,0 +1,43 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestReencodeBucket(t *testing.T) {
+	const zSetReencode = "test:reencode"
+
+	client.Del(zSetReencode)
+	AddCoordinates(client, zSetReencode, bitDepth,
+		GeoKey{Label: "berlin", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "potsdam", Lat: 52.3906, Lon: 13.0645},
+	)
+
+	reencoded, err := ReencodeBucket(client, zSetReencode, bitDepth, 32)
+	if err != nil || reencoded != 2 {
+		t.Logf("expected 2 re-encoded members got %d, %v\n", reencoded, err)
+		t.FailNow()
+	}
+
+	results, err := Search(client, zSetReencode, 52.52, 13.405, 1000, 32)
+	if err != nil || len(results) != 1 || results[0].Label != "berlin" {
+		t.Logf("expected berlin at the new bit depth got %v, %v\n", results, err)
+		t.Fail()
+	}
+
+	if _, err := ReencodeBucket(client, zSetReencode, 32, 31); err != ErrInvalidBitDepth {
+		t.Logf("expected ErrInvalidBitDepth got %v\n", err)
+		t.Fail()
+	}
+	if reencoded, err := ReencodeBucket(client, "test:reencode:empty", bitDepth, 32); err != nil || reencoded != 0 {
+		t.Logf("expected nothing to re-encode got %d, %v\n", reencoded, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,471 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	// RegionMove is published when a member is added or moves
+	RegionMove RegionUpdateType = "move"
+	// RegionRemove is published when a member is removed
+	RegionRemove RegionUpdateType = "remove"
+
+	// maxRegionChannels bounds the channels a single subscription listens to
+	maxRegionChannels = 1024
+
+	defaultSubscriptionBuffer = 256
+)
+
+// ErrRegionTooLarge is returned when a region spans more than 1024 cells of the region depth
+var ErrRegionTooLarge = errors.New("region spans too many cells, use a lower region depth")
+
+// regionLuaBody writes or removes members and publishes every change to the channel of the cell the member is in
+// and, when it moved to another cell, the one it was in before
+//
+// KEYS are the bucket, its payloads and attributes. ARGV holds the channel prefix, the cell size as a score divisor and "add"
+// followed by score, label, payload triples (payloads are prefixed with "=" and empty when not set) or "rem"
+// followed by labels. The reply is the number of added or removed members.
+const regionLuaBody = `
+local prefix = ARGV[1]
+local divisor = tonumber(ARGV[2])
+local count = 0
+
+local function channel(score)
+  return prefix .. string.format('%d', math.floor(tonumber(score) / divisor))
+end
+
+if ARGV[3] == 'add' then
+  for i = 4, #ARGV, 3 do
+    local label = ARGV[i + 1]
+    local previous = redis.call('ZSCORE', KEYS[1], label)
+    count = count + redis.call('ZADD', KEYS[1], ARGV[i], label)
+    if ARGV[i + 2] ~= '' then redis.call('HSET', KEYS[2], label, string.sub(ARGV[i + 2], 2)) end
+
+    local message = cjson.encode({label = label, score = ARGV[i], previous = previous or nil})
+    redis.call('PUBLISH', channel(ARGV[i]), message)
+    if previous and channel(previous) ~= channel(ARGV[i]) then
+      redis.call('PUBLISH', channel(previous), message)
+    end
+  end
+else
+  for i = 4, #ARGV do
+    local previous = redis.call('ZSCORE', KEYS[1], ARGV[i])
+    if previous then
+      count = count + redis.call('ZREM', KEYS[1], ARGV[i])
+      redis.call('HDEL', KEYS[2], ARGV[i])
+      redis.call('HDEL', KEYS[3], ARGV[i])
+      redis.call('PUBLISH', channel(previous), cjson.encode({label = ARGV[i], previous = previous, removed = true}))
+    end
+  end
+end
+
+return count
+`
+
+var regionScript = newLuaScript(regionLuaBody)
+
+type (
+	// Region is a bounding box, West is greater than East for boxes crossing the antimeridian
+	Region struct {
+		South float64
+		West  float64
+		North float64
+		East  float64
+	}
+
+	// RegionUpdateType is the kind of a RegionUpdate
+	RegionUpdateType string
+
+	// RegionUpdate is a change published by a RegionPublisher
+	//
+	// Lat & Lon are the new coordinates of a moved member and Previous its coordinates before, nil for new members.
+	// Lat & Lon of a removed member are its last coordinates.
+	RegionUpdate struct {
+		Type     RegionUpdateType
+		Bucket   string
+		Label    string
+		Lat      float64
+		Lon      float64
+		Previous *Point
+	}
+
+	// RegionPublisher writes location updates and publishes them to a Pub/Sub channel per cell of regionDepth bits
+	//
+	// Members and messages are written by a single script, so subscribers never miss a change which was written
+	// through a RegionPublisher. Subscribers have to use the same region depth.
+	RegionPublisher struct {
+		client      *redis.Client
+		bitDepth    uint8
+		regionDepth uint8
+	}
+
+	// RegionSubscription receives the updates of the members inside a region, or which just left it
+	RegionSubscription struct {
+		bucketName  string
+		bitDepth    uint8
+		regionDepth uint8
+		onError     func(error)
+		pubsub      *redis.PubSub
+		updates     chan RegionUpdate
+
+		mu       sync.Mutex
+		region   Region
+		channels []string
+
+		done    chan struct{}
+		stopped chan struct{}
+	}
+
+	// SubscriptionOption configures a RegionSubscription
+	SubscriptionOption func(*RegionSubscription)
+
+	regionMessage struct {
+		Label    string `json:"label"`
+		Score    string `json:"score"`
+		Previous string `json:"previous"`
+		Removed  bool   `json:"removed"`
+	}
+)
+
+// Contains returns whether lat & lon are inside the region, borders included
+func (r Region) Contains(lat, lon float64) bool {
+	if lat < r.South || lat > r.North {
+		return false
+	}
+	if r.West <= r.East {
+		return lon >= r.West && lon <= r.East
+	}
+
+	return lon >= r.West || lon <= r.East
+}
+
+// RegionAround returns the bounding box of a circle of radius meters, spanning all longitudes when it contains a pole
+func RegionAround(lat, lon, radius float64) Region {
+	dLat := radius / metersPerDegree
+	poleward := math.Abs(lat) + dLat
+	if poleward >= 90 {
+		return Region{South: max(lat-dLat, -90), West: -180, North: min(lat+dLat, 90), East: 180}
+	}
+
+	dLon := dLat / math.Cos(poleward*math.Pi/180)
+	if dLon >= 180 {
+		return Region{South: lat - dLat, West: -180, North: lat + dLat, East: 180}
+	}
+
+	return Region{South: lat - dLat, West: wrapLon(lon - dLon), North: lat + dLat, East: wrapLon(lon + dLon)}
+}
+
+// NewRegionPublisher returns a RegionPublisher publishing to cells of regionDepth bits, which has to be a valid bit
+// depth not above bitDepth
+func NewRegionPublisher(client *redis.Client, bitDepth, regionDepth uint8) (*RegionPublisher, error) {
+	if err := validateRegionDepth(bitDepth, regionDepth); err != nil {
+		return nil, err
+	}
+
+	return &RegionPublisher{client: client, bitDepth: bitDepth, regionDepth: regionDepth}, nil
+}
+
+// UpdateCoordinates adds coordinates to the set and publishes them
+func (p *RegionPublisher) UpdateCoordinates(bucketName string, coordinates ...GeoKey) (int64, error) {
+	if err := ValidateCoordinates(coordinates...); err != nil {
+		return 0, err
+	}
+	if len(coordinates) == 0 {
+		return 0, nil
+	}
+
+	args := append(p.scriptArgs(bucketName), "add")
+	for _, coordinate := range coordinates {
+		payload := ""
+		if coordinate.Payload != nil {
+			payload = "=" + string(coordinate.Payload)
+		}
+		score := geohashEncoder{bitDepth: p.bitDepth}.EncodeInt(coordinate.Lat, coordinate.Lon)
+		args = append(args, strconv.FormatUint(score, 10), coordinate.Label, payload)
+	}
+
+	return p.run(bucketName, args)
+}
+
+// RemoveCoordinatesByKeys removes coordinates from the set and publishes their removal
+func (p *RegionPublisher) RemoveCoordinatesByKeys(bucketName string, coordinatesKeys ...string) (int64, error) {
+	if len(coordinatesKeys) == 0 {
+		return 0, nil
+	}
+
+	return p.run(bucketName, append(append(p.scriptArgs(bucketName), "rem"), coordinatesKeys...))
+}
+
+func (p *RegionPublisher) scriptArgs(bucketName string) []string {
+	return []string{regionChannelPrefix(bucketName, p.regionDepth), strconv.FormatUint(1<<(p.bitDepth-p.regionDepth), 10)}
+}
+
+func (p *RegionPublisher) run(bucketName string, args []string) (int64, error) {
+	reply, err := regionScript.run(p.client, []string{bucketName, payloadKey(bucketName), attributesKey(bucketName)}, args)
+	if err != nil {
+		return 0, err
+	}
+
+	count, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected region script reply %v", reply)
+	}
+
+	return count, nil
+}
+
+// WithSubscriptionErrorHandler sets the function receiving receive errors and malformed messages
+func WithSubscriptionErrorHandler(handler func(error)) SubscriptionOption {
+	return func(s *RegionSubscription) {
+		s.onError = handler
+	}
+}
+
+// WithSubscriptionBuffer sets the number of updates buffered before receiving blocks, 256 by default
+func WithSubscriptionBuffer(size int) SubscriptionOption {
+	return func(s *RegionSubscription) {
+		s.updates = make(chan RegionUpdate, size)
+	}
+}
+
+// SubscribeRegion subscribes to the updates a RegionPublisher with the same region depth publishes for the members
+// of the bucket inside region
+func SubscribeRegion(client *redis.Client, bucketName string, bitDepth, regionDepth uint8, region Region, options ...SubscriptionOption) (*RegionSubscription, error) {
+	if err := validateRegionDepth(bitDepth, regionDepth); err != nil {
+		return nil, err
+	}
+
+	s := &RegionSubscription{
+		bucketName:  bucketName,
+		bitDepth:    bitDepth,
+		regionDepth: regionDepth,
+		onError:     func(error) {},
+		pubsub:      client.PubSub(),
+		updates:     make(chan RegionUpdate, defaultSubscriptionBuffer),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	if err := s.Move(region); err != nil {
+		s.pubsub.Close()
+		return nil, err
+	}
+
+	go s.receive()
+
+	return s, nil
+}
+
+// Updates returns the channel receiving the updates, it is closed by Close
+func (s *RegionSubscription) Updates() <-chan RegionUpdate {
+	return s.updates
+}
+
+// Region returns the region the subscription currently receives updates for
+func (s *RegionSubscription) Region() Region {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.region
+}
+
+// Move changes the region, only the channels of the cells entering and leaving the region are (un)subscribed
+func (s *RegionSubscription) Move(region Region) error {
+	cells, err := regionCells(region, s.regionDepth, maxRegionChannels, ErrRegionTooLarge)
+	if err != nil {
+		return err
+	}
+
+	channels := make([]string, len(cells))
+	for idx, cell := range cells {
+		channels[idx] = regionChannelPrefix(s.bucketName, s.regionDepth) + strconv.FormatUint(cell, 10)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added, removed := []string{}, []string{}
+	for _, channel := range channels {
+		if !slices.Contains(s.channels, channel) {
+			added = append(added, channel)
+		}
+	}
+	for _, channel := range s.channels {
+		if !slices.Contains(channels, channel) {
+			removed = append(removed, channel)
+		}
+	}
+
+	if len(added) > 0 {
+		if err := s.pubsub.Subscribe(added...); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		if err := s.pubsub.Unsubscribe(removed...); err != nil {
+			return err
+		}
+	}
+	s.region, s.channels = region, channels
+
+	return nil
+}
+
+// Close unsubscribes and closes the updates channel
+func (s *RegionSubscription) Close() error {
+	close(s.done)
+	err := s.pubsub.Close()
+	<-s.stopped
+
+	return err
+}
+
+// receive decodes incoming messages and forwards the updates touching the region
+func (s *RegionSubscription) receive() {
+	defer close(s.stopped)
+	defer close(s.updates)
+
+	for {
+		msg, err := s.pubsub.Receive()
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+		if err != nil {
+			s.onError(err)
+			time.Sleep(defaultListenerDelay)
+			continue
+		}
+
+		message, ok := msg.(*redis.Message)
+		if !ok {
+			continue
+		}
+		update, err := s.decode(message.Payload)
+		if err != nil {
+			s.onError(err)
+			continue
+		}
+
+		region := s.Region()
+		if !region.Contains(update.Lat, update.Lon) &&
+			(update.Previous == nil || !region.Contains(update.Previous.Lat, update.Previous.Lon)) {
+			continue
+		}
+
+		select {
+		case s.updates <- update:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *RegionSubscription) decode(payload string) (RegionUpdate, error) {
+	message := regionMessage{}
+	if err := json.Unmarshal([]byte(payload), &message); err != nil {
+		return RegionUpdate{}, err
+	}
+
+	update := RegionUpdate{Type: RegionMove, Bucket: s.bucketName, Label: message.Label}
+	if message.Previous != "" {
+		lat, lon, err := decodeScore(message.Previous, s.bitDepth)
+		if err != nil {
+			return RegionUpdate{}, err
+		}
+		update.Previous = &Point{Lat: lat, Lon: lon}
+	}
+
+	if message.Removed {
+		if update.Previous == nil {
+			return RegionUpdate{}, fmt.Errorf("unexpected region message %s", payload)
+		}
+		update.Type, update.Lat, update.Lon, update.Previous = RegionRemove, update.Previous.Lat, update.Previous.Lon, nil
+		return update, nil
+	}
+
+	var err error
+	update.Lat, update.Lon, err = decodeScore(message.Score, s.bitDepth)
+
+	return update, err
+}
+
+func decodeScore(score string, bitDepth uint8) (float64, float64, error) {
+	value, err := strconv.ParseFloat(score, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	lat, lon := geohashEncoder{bitDepth: bitDepth}.DecodeInt(uint64(value))
+
+	return lat, lon, nil
+}
+
+func validateRegionDepth(bitDepth, regionDepth uint8) error {
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return err
+	}
+	if err := ValidateBitDepth(regionDepth); err != nil {
+		return err
+	}
+	if regionDepth > bitDepth {
+		return ErrBitDepthTooLow
+	}
+
+	return nil
+}
+
+// regionCells returns the cells of depth bits intersecting a region or errTooLarge if there are more than maxCells
+func regionCells(region Region, depth uint8, maxCells int, errTooLarge error) ([]uint64, error) {
+	east := region.East
+	if region.West > region.East {
+		east += 360
+	}
+	minLat, maxLat := max(region.South, -90), min(region.North, 90)
+
+	bits := depth / 2
+	rows, cols := boxCells(minLat, maxLat, region.West, east, bits)
+	if rows*cols > maxCells {
+		return nil, errTooLarge
+	}
+
+	n := 1 << bits
+	cellHeight, cellWidth := 180/float64(n), 360/float64(n)
+	firstRow, firstCol := bandRow(minLat, bits), int(math.Floor((region.West+180)/cellWidth))
+
+	cells := make([]uint64, 0, rows*cols)
+	for row := firstRow; row < firstRow+rows; row++ {
+		for col := firstCol; col < firstCol+cols; col++ {
+			cells = append(cells, geohashEncoder{bitDepth: depth}.EncodeInt(-90+(float64(row)+0.5)*cellHeight, -180+(float64(((col%n)+n)%n)+0.5)*cellWidth))
+		}
+	}
+	slices.Sort(cells)
+
+	return slices.Compact(cells), nil
+}
+
+func regionChannelPrefix(bucketName string, regionDepth uint8) string {
+	return bucketName + ":region:" + strconv.Itoa(int(regionDepth)) + ":"
+}
+
+// wrapLon maps a longitude beyond ±180 back into range
+func wrapLon(lon float64) float64 {
+	return math.Remainder(lon, 360)
+}
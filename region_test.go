@@ -0,0 +1,81 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/tapglue/georedis/internal/geohash"
+)
+
+func TestRegionCellsCoverRegion(t *testing.T) {
+	regions := []Region{
+		{South: 52.4, West: 13.2, North: 52.6, East: 13.6},
+		{South: -18, West: 178.5, North: -16, East: -179.5},
+	}
+	for _, region := range regions {
+		cells, err := regionCells(region, 16, maxRegionChannels, ErrRegionTooLarge)
+		if err != nil {
+			t.Logf("error encountered %q\n", err)
+			t.FailNow()
+		}
+
+		for lat := region.South; lat <= region.North; lat += 0.05 {
+			for lon := region.West; ; lon += 0.05 {
+				normalized := lon
+				if normalized > 180 {
+					normalized -= 360
+				}
+				if !region.Contains(lat, normalized) {
+					break
+				}
+				if !slices.Contains(cells, geohash.EncodeInt(lat, normalized, 16)) {
+					t.Logf("%v: %v, %v is not covered by %v\n", region, lat, normalized, cells)
+					t.FailNow()
+				}
+			}
+		}
+	}
+
+	if _, err := regionCells(Region{South: -80, West: -170, North: 80, East: 170}, 40, maxRegionChannels, ErrRegionTooLarge); err != ErrRegionTooLarge {
+		t.Logf("expected ErrRegionTooLarge got %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestRegionAround(t *testing.T) {
+	region := RegionAround(-17, 179.9, 50000)
+	if region.West <= region.East || !region.Contains(-17, -179.9) || !region.Contains(-17, 179.5) || region.Contains(-17, 178) {
+		t.Logf("unexpected region across the antimeridian %v\n", region)
+		t.Fail()
+	}
+
+	region = RegionAround(89.9, 10, 50000)
+	if region.West != -180 || region.East != 180 || region.North != 90 {
+		t.Logf("expected a region around the pole to span all longitudes got %v\n", region)
+		t.Fail()
+	}
+}
+
+func TestRegionSubscriptionDecode(t *testing.T) {
+	s := &RegionSubscription{bucketName: "bucket", bitDepth: 52}
+	score := strconv.FormatUint(geohash.EncodeInt(52.52, 13.405, 52), 10)
+	previous := strconv.FormatUint(geohash.EncodeInt(48.85, 2.35, 52), 10)
+
+	update, err := s.decode(`{"label":"a","score":"` + score + `","previous":"` + previous + `"}`)
+	if err != nil || update.Type != RegionMove || update.Previous == nil || int(update.Lat) != 52 || int(update.Previous.Lat) != 48 {
+		t.Logf("unexpected move %v error %v\n", update, err)
+		t.Fail()
+	}
+
+	update, err = s.decode(`{"label":"a","previous":"` + previous + `","removed":true}`)
+	if err != nil || update.Type != RegionRemove || update.Previous != nil || int(update.Lon) != 2 {
+		t.Logf("unexpected removal %v error %v\n", update, err)
+		t.Fail()
+	}
+}
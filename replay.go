@@ -0,0 +1,200 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"container/heap"
+	"errors"
+	"strconv"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const replayPageSize = 500
+
+// ErrInvalidSpeedFactor is returned for negative speed factors
+var ErrInvalidSpeedFactor = errors.New("speed factor must not be negative")
+
+type (
+	// ReplayEvent is a stored location of a member sent by a Playback
+	ReplayEvent struct {
+		Label string
+		Lat   float64
+		Lon   float64
+		Time  time.Time
+	}
+
+	// Playback plays the histories of members back in the order they were recorded
+	Playback struct {
+		events  chan ReplayEvent
+		err     error
+		done    chan struct{}
+		stopped chan struct{}
+	}
+
+	// replayCursor pages through the history of a single member
+	replayCursor struct {
+		label   string
+		key     string
+		entries []HistoryEntry
+		last    string
+	}
+
+	// replayQueue is a min-heap of cursors by the time of their next entry
+	replayQueue []*replayCursor
+)
+
+// Replay plays back the histories of the labels between from and to, of all members with a history when no
+// labels are given, see AppendHistory
+//
+// With a speedFactor of 0 the events are sent as fast as they are received, otherwise the gaps between them are the
+// recorded ones divided by speedFactor, 2 plays back twice as fast.
+func Replay(client *redis.Client, bucketName string, from, to time.Time, speedFactor float64, labels ...string) (*Playback, error) {
+	if speedFactor < 0 {
+		return nil, ErrInvalidSpeedFactor
+	}
+
+	if len(labels) == 0 {
+		var err error
+		labels, err = client.ZRangeByScore(historyIndexKey(bucketName), redis.ZRangeByScore{
+			Min: formatUnixScore(from),
+			Max: "+inf",
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	end := formatStreamID(to)
+	queue := make(replayQueue, 0, len(labels))
+	for _, label := range labels {
+		cursor := &replayCursor{label: label, key: HistoryKey(bucketName, label), last: formatStreamID(from)}
+		if err := cursor.fetch(client, end, true); err != nil {
+			return nil, err
+		}
+		if len(cursor.entries) > 0 {
+			queue = append(queue, cursor)
+		}
+	}
+	heap.Init(&queue)
+
+	p := &Playback{events: make(chan ReplayEvent), done: make(chan struct{}), stopped: make(chan struct{})}
+	go p.play(client, queue, end, speedFactor)
+
+	return p, nil
+}
+
+// Events returns the channel receiving the events, it is closed when the replay ended or was closed
+func (p *Playback) Events() <-chan ReplayEvent {
+	return p.events
+}
+
+// Err returns the error which ended the replay early, it is set once the events channel is closed
+func (p *Playback) Err() error {
+	<-p.stopped
+	return p.err
+}
+
+// Close stops the replay
+func (p *Playback) Close() error {
+	select {
+	case <-p.done:
+	default:
+		close(p.done)
+	}
+	<-p.stopped
+
+	return nil
+}
+
+func (p *Playback) play(client *redis.Client, queue replayQueue, end string, speedFactor float64) {
+	defer close(p.stopped)
+	defer close(p.events)
+
+	var start, first time.Time
+	for len(queue) > 0 {
+		cursor := queue[0]
+		entry := cursor.entries[0]
+
+		if speedFactor > 0 {
+			if start.IsZero() {
+				start, first = time.Now(), entry.Time
+			}
+			due := start.Add(time.Duration(float64(entry.Time.Sub(first)) / speedFactor))
+			select {
+			case <-p.done:
+				return
+			case <-time.After(time.Until(due)):
+			}
+		}
+
+		select {
+		case <-p.done:
+			return
+		case p.events <- ReplayEvent{Label: cursor.label, Lat: entry.Lat, Lon: entry.Lon, Time: entry.Time}:
+		}
+
+		cursor.entries = cursor.entries[1:]
+		if len(cursor.entries) == 0 {
+			if p.err = cursor.fetch(client, end, false); p.err != nil {
+				return
+			}
+		}
+		if len(cursor.entries) == 0 {
+			heap.Pop(&queue)
+		} else {
+			heap.Fix(&queue, 0)
+		}
+	}
+}
+
+// fetch reads the next page of entries after the last one, or from the last id itself for the first page
+func (c *replayCursor) fetch(client *redis.Client, end string, first bool) error {
+	start := c.last
+	if !first {
+		start = "(" + c.last
+	}
+
+	entries, last, err := historyRange(client, c.key, start, end, replayPageSize)
+	if err != nil {
+		return err
+	}
+	c.entries = entries
+	if last != "" {
+		c.last = last
+	}
+
+	return nil
+}
+
+func (q replayQueue) Len() int {
+	return len(q)
+}
+
+func (q replayQueue) Less(i, j int) bool {
+	return q[i].entries[0].Time.Before(q[j].entries[0].Time)
+}
+
+func (q replayQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *replayQueue) Push(x interface{}) {
+	*q = append(*q, x.(*replayCursor))
+}
+
+func (q *replayQueue) Pop() interface{} {
+	old := *q
+	cursor := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return cursor
+}
+
+func formatStreamID(at time.Time) string {
+	return strconv.FormatInt(at.UnixMilli(), 10)
+}
@@ -0,0 +1,56 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestReplay(t *testing.T) {
+	const zSetReplay = "test:replay"
+
+	client.Del(zSetReplay+":histories", HistoryKey(zSetReplay, "a"), HistoryKey(zSetReplay, "b"))
+
+	from := time.Now()
+	for _, label := range []string{"a", "b", "a", "b", "a"} {
+		if err := AppendHistory(client, zSetReplay, HistoryLimits{}, GeoKey{Lat: 52.52, Lon: 13.405, Label: label}); err != nil {
+			t.Logf("error encountered %q\n", err)
+			t.FailNow()
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	playback, err := Replay(client, zSetReplay, from, time.Now(), 10)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	defer playback.Close()
+
+	replayed := []string{}
+	var last time.Time
+	for event := range playback.Events() {
+		if event.Time.Before(last) {
+			t.Logf("expected events in time order got %v after %v\n", event.Time, last)
+			t.Fail()
+		}
+		last = event.Time
+		replayed = append(replayed, event.Label)
+	}
+	if err := playback.Err(); err != nil || !slices.Equal(replayed, []string{"a", "b", "a", "b", "a"}) {
+		t.Logf("expected the recorded order got %v error %v\n", replayed, err)
+		t.Fail()
+	}
+
+	if _, err := Replay(client, zSetReplay, from, time.Now(), -1); err != ErrInvalidSpeedFactor {
+		t.Logf("expected ErrInvalidSpeedFactor got %v\n", err)
+		t.Fail()
+	}
+}
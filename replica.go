@@ -0,0 +1,182 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const replicaCheckInterval = time.Second
+
+type (
+	// ReplicaOption configures how a GeoClient reads from replicas
+	//
+	// Deprecated: the replica options are ClientOptions now, ReplicaOption is kept as an alias for existing callers.
+	ReplicaOption = ClientOption
+
+	replica struct {
+		client *redis.Client
+
+		mu      sync.Mutex
+		checked time.Time
+		fresh   bool
+	}
+
+	replicaSet struct {
+		replicas       []*replica
+		next           uint32
+		maxLag         time.Duration
+		readYourWrites time.Duration
+
+		mu         sync.Mutex
+		lastWrites map[string]time.Time
+	}
+)
+
+// WithMaxReplicaLag skips replicas which lost the link to the primary or didn't hear from it for longer than lag,
+// reads fall back to the primary when no replica is fresh enough
+//
+// Replication state is checked at most once per second and replica.
+func WithMaxReplicaLag(lag time.Duration) ClientOption {
+	return func(c *GeoClient) {
+		c.replicas.maxLag = lag
+	}
+}
+
+// WithReadYourWrites sends reads of a bucket to the primary for window after this client wrote to it
+func WithReadYourWrites(window time.Duration) ClientOption {
+	return func(c *GeoClient) {
+		c.replicas.readYourWrites = window
+	}
+}
+
+// NewReplicatedGeoClient returns a GeoClient writing to primary and spreading searches, counts and lookups
+// round robin across the replicas
+func NewReplicatedGeoClient(primary *redis.Client, replicas []*redis.Client, bitDepth uint8, options ...ClientOption) *GeoClient {
+	c := NewGeoClient(primary, bitDepth, options...)
+	for _, client := range replicas {
+		c.replicas.replicas = append(c.replicas.replicas, &replica{client: client})
+	}
+
+	return c
+}
+
+// reader returns the client used to read bucketName
+func (c *GeoClient) reader(bucketName string) *redis.Client {
+	if len(c.replicas.replicas) == 0 {
+		return c.client
+	}
+
+	if client := c.replicas.pick(bucketName); client != nil {
+		return client
+	}
+
+	return c.client
+}
+
+// wrote records a write to bucketName for WithReadYourWrites
+func (c *GeoClient) wrote(bucketName string) {
+	if c.replicas.readYourWrites <= 0 {
+		return
+	}
+
+	c.replicas.mu.Lock()
+	c.replicas.lastWrites[bucketName] = time.Now()
+	c.replicas.mu.Unlock()
+}
+
+func (s *replicaSet) pick(bucketName string) *redis.Client {
+	if s.readYourWrites > 0 {
+		s.mu.Lock()
+		last, ok := s.lastWrites[bucketName]
+		if ok && time.Since(last) > s.readYourWrites {
+			delete(s.lastWrites, bucketName)
+			ok = false
+		}
+		s.mu.Unlock()
+		if ok {
+			return nil
+		}
+	}
+
+	start := atomic.AddUint32(&s.next, 1)
+	for i := range s.replicas {
+		r := s.replicas[(int(start)+i)%len(s.replicas)]
+		if s.maxLag <= 0 || r.isFresh(s.maxLag) {
+			return r.client
+		}
+	}
+
+	return nil
+}
+
+func (s *replicaSet) close() error {
+	var firstErr error
+	for _, r := range s.replicas {
+		if err := r.client.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// isFresh reports whether the replica is connected to its primary and within maxLag, the answer is cached
+// for replicaCheckInterval
+func (r *replica) isFresh(maxLag time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checked) < replicaCheckInterval {
+		return r.fresh
+	}
+
+	cmd := redis.NewStringCmd("INFO", "replication")
+	r.client.Process(cmd)
+	info, err := cmd.Result()
+	r.fresh = err == nil && replicationFresh(info, maxLag)
+	r.checked = time.Now()
+
+	return r.fresh
+}
+
+// replicationFresh parses INFO replication, a primary is always fresh
+func replicationFresh(info string, maxLag time.Duration) bool {
+	fields := parseInfo(info)
+	if fields["role"] == "master" {
+		return true
+	}
+	if fields["role"] != "slave" || fields["master_link_status"] != "up" {
+		return false
+	}
+
+	lastIO, err := strconv.Atoi(fields["master_last_io_seconds_ago"])
+	if err != nil || lastIO < 0 {
+		return false
+	}
+
+	return time.Duration(lastIO)*time.Second <= maxLag
+}
+
+// parseInfo returns the fields of an INFO reply
+func parseInfo(info string) map[string]string {
+	fields := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":"); ok {
+			fields[key] = value
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,53 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+func TestReplicationFresh(t *testing.T) {
+	tests := []struct {
+		info  string
+		fresh bool
+	}{
+		{"# Replication\r\nrole:master\r\nconnected_slaves:1\r\n", true},
+		{"# Replication\r\nrole:slave\r\nmaster_link_status:up\r\nmaster_last_io_seconds_ago:1\r\n", true},
+		{"# Replication\r\nrole:slave\r\nmaster_link_status:up\r\nmaster_last_io_seconds_ago:9\r\n", false},
+		{"# Replication\r\nrole:slave\r\nmaster_link_status:down\r\nmaster_last_io_seconds_ago:-1\r\n", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if fresh := replicationFresh(test.info, 5*time.Second); fresh != test.fresh {
+			t.Logf("expected fresh %t got %t for %q\n", test.fresh, fresh, test.info)
+			t.Fail()
+		}
+	}
+}
+
+func TestReadYourWrites(t *testing.T) {
+	c := NewReplicatedGeoClient(nil, nil, 52, WithReadYourWrites(time.Minute))
+	c.replicas.replicas = []*replica{{client: &redis.Client{}}}
+
+	if c.replicas.pick("bucket") == nil {
+		t.Logf("expected a replica before writing\n")
+		t.Fail()
+	}
+
+	c.wrote("bucket")
+	if c.replicas.pick("bucket") != nil {
+		t.Logf("expected the primary after writing\n")
+		t.Fail()
+	}
+	if c.replicas.pick("other") == nil {
+		t.Logf("expected a replica for other buckets\n")
+		t.Fail()
+	}
+}
@@ -0,0 +1,86 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"math"
+	"slices"
+
+	"gopkg.in/redis.v2"
+)
+
+const reportBatchSize = 1000
+
+// DensitySummary is the member count of every non empty cell of a bucket and their distribution
+type DensitySummary struct {
+	// Cells are ordered by cell
+	Cells   []DensityCell
+	Members int64
+	Mean    float64
+	P50     int64
+	P90     int64
+	P99     int64
+	Max     int64
+}
+
+// DensityReport counts the members of every cell of cellDepth bits of a whole bucket
+//
+// Members are read in pages in the order of their scores, which keeps the members of a cell together, so only a
+// page is held in memory besides the cells. Writes during the report may be counted twice or not at all.
+func DensityReport(client *redis.Client, bucketName string, bitDepth, cellDepth uint8) (DensitySummary, error) {
+	summary := DensitySummary{Cells: []DensityCell{}}
+	if err := validateRegionDepth(bitDepth, cellDepth); err != nil {
+		return summary, err
+	}
+
+	shift := bitDepth - cellDepth
+	for offset := int64(0); ; offset += reportBatchSize {
+		members, err := client.ZRangeWithScores(bucketName, offset, offset+reportBatchSize-1).Result()
+		if err != nil {
+			return DensitySummary{Cells: []DensityCell{}}, err
+		}
+
+		for idx := range members {
+			cell := uint64(members[idx].Score) >> shift
+			if last := len(summary.Cells) - 1; last >= 0 && summary.Cells[last].Cell == cell {
+				summary.Cells[last].Count++
+				continue
+			}
+			lat, lon := geohashEncoder{bitDepth: cellDepth}.DecodeInt(cell)
+			summary.Cells = append(summary.Cells, DensityCell{Cell: cell, Lat: lat, Lon: lon, Count: 1})
+		}
+		summary.Members += int64(len(members))
+
+		if len(members) < reportBatchSize {
+			break
+		}
+	}
+
+	if len(summary.Cells) == 0 {
+		return summary, nil
+	}
+
+	counts := make([]int64, len(summary.Cells))
+	for idx := range summary.Cells {
+		counts[idx] = summary.Cells[idx].Count
+	}
+	slices.Sort(counts)
+
+	summary.Mean = float64(summary.Members) / float64(len(counts))
+	summary.P50 = percentile(counts, 0.5)
+	summary.P90 = percentile(counts, 0.9)
+	summary.P99 = percentile(counts, 0.99)
+	summary.Max = counts[len(counts)-1]
+
+	return summary, nil
+}
+
+// percentile returns the nearest rank percentile of sorted values
+func percentile(sorted []int64, p float64) int64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+
+	return sorted[max(rank, 0)]
+}
@@ -0,0 +1,34 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestDensityReport(t *testing.T) {
+	const zSetReport = "test:report"
+
+	client.Del(zSetReport)
+	AddCoordinates(client, zSetReport, bitDepth,
+		GeoKey{Label: "one", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "two", Lat: 52.5201, Lon: 13.4051},
+		GeoKey{Label: "three", Lat: 52.5202, Lon: 13.4052},
+		GeoKey{Label: "paris", Lat: 48.85, Lon: 2.35},
+	)
+
+	summary, err := DensityReport(client, zSetReport, bitDepth, 20)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if len(summary.Cells) != 2 || summary.Members != 4 || summary.Max != 3 || summary.P50 != 1 || summary.Mean != 2 {
+		t.Logf("unexpected summary %+v\n", summary)
+		t.Fail()
+	}
+}
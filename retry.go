@@ -0,0 +1,145 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// retryableReplies are redis error prefixes of conditions which resolve by themselves
+var retryableReplies = []string{"LOADING", "TRYAGAIN", "MASTERDOWN", "READONLY", "CLUSTERDOWN"}
+
+// RetryPolicy decides how often and how long apart GeoClient operations are attempted
+//
+// The zero value attempts every operation once. Policies only apply to the methods of a GeoClient, the package
+// functions, CoreClient, fences, trackers, webhooks and other helpers taking a *redis.Client attempt once, wrap
+// them in Do to retry them. Writes appending to the change stream or the history of a bucket are attempted once
+// as well, a retry could append their entries twice.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one
+	MaxAttempts int
+	// BaseDelay is the upper bound of the first backoff, it doubles with every retry up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Retryable classifies errors, IsRetryable is used when nil
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy retries transient errors twice with backoffs of up to 50ms and 100ms
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 50 * time.Millisecond, MaxDelay: time.Second}
+
+// WithRetryPolicy retries failed operations of the GeoClient according to policy, see RetryPolicy
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *GeoClient) {
+		c.retry = policy
+	}
+}
+
+// IsRetryable reports whether err is a timeout, a dropped connection or a redis reply signalling a transient
+// condition like a loading dataset or an ongoing failover
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var partial *PartialResultsError
+	if errors.As(err, &partial) {
+		return slices.ContainsFunc(partial.Failed, func(failed RangeError) bool { return IsRetryable(failed.Err) })
+	}
+
+	msg := err.Error()
+	for _, prefix := range retryableReplies {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Do calls fn until it succeeds, returns an error which isn't retryable or the attempts are used up
+func (p RetryPolicy) Do(fn func() error) error {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	err := fn()
+	for attempt := 1; attempt < p.MaxAttempts && retryable(err); attempt++ {
+		time.Sleep(p.backoff(attempt))
+		err = fn()
+	}
+
+	return err
+}
+
+// backoff returns a random delay up to BaseDelay doubled for every previous retry and capped at MaxDelay
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+
+	limit := p.BaseDelay << (attempt - 1)
+	if limit <= 0 || (p.MaxDelay > 0 && limit > p.MaxDelay) {
+		limit = p.MaxDelay
+	}
+
+	return rand.N(limit + 1)
+}
+
+// do runs fn according to the retry policy and reports its failed attempts to the metrics and the logger
+func (c *GeoClient) do(fn func() error) error {
+	if c.metrics == nil && c.logger == nil {
+		return c.retry.Do(fn)
+	}
+
+	attempt := 0
+	return c.retry.Do(func() error {
+		attempt++
+		err := fn()
+		if err != nil {
+			c.observeError(attempt, err)
+		}
+		return err
+	})
+}
+
+// once runs fn a single time for writes which are not idempotent and reports its failure like do
+func (c *GeoClient) once(fn func() error) error {
+	err := fn()
+	if err != nil {
+		c.observeError(1, err)
+	}
+
+	return err
+}
+
+func withRetry[T any](c *GeoClient, fn func() (T, error)) (T, error) {
+	var result T
+	err := c.do(func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,51 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3}
+
+	attempts := 0
+	err := policy.Do(func() error {
+		attempts++
+		return errors.New("LOADING Redis is loading the dataset in memory")
+	})
+	if err == nil || attempts != 3 {
+		t.Logf("expected 3 attempts and an error got %d attempts and %v\n", attempts, err)
+		t.Fail()
+	}
+
+	attempts = 0
+	err = policy.Do(func() error {
+		attempts++
+		if attempts == 1 {
+			return io.EOF
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Logf("expected to succeed on the second attempt got %d attempts and %v\n", attempts, err)
+		t.Fail()
+	}
+
+	attempts = 0
+	err = policy.Do(func() error {
+		attempts++
+		return ErrMemberNotFound
+	})
+	if err != ErrMemberNotFound || attempts != 1 {
+		t.Logf("expected a single attempt got %d attempts and %v\n", attempts, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,134 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"slices"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+// CreateRoamingFence stores a circular fence of radius meters around the member label of bucketName, replacing a
+// fence with the same name
+//
+// FenceTracker moves the fence whenever it updates the anchor and re-evaluates only the members around the old and
+// the new position of the fence. The anchor is never inside its own fence.
+func CreateRoamingFence(client *redis.Client, fenceSet, name, bucketName string, bitDepth uint8, label string, radius float64) error {
+	if !(radius > 0 && radius <= MaxRadius) {
+		return ErrInvalidRadius
+	}
+
+	anchor, err := GetCoordinates(client, bucketName, bitDepth, label)
+	if err != nil {
+		return err
+	}
+
+	fence := storedFence{Name: name, Lat: anchor.Lat, Lon: anchor.Lon, Radius: radius, AnchorBucket: bucketName, Anchor: label}
+	fence.Depth, fence.Cells = coverCircle(anchor.Lat, anchor.Lon, radius)
+
+	return storeFence(client, fenceSet, fence)
+}
+
+// moveRoamingFences moves the fences anchored to a member to its new coordinates and returns them as they were
+// before
+func moveRoamingFences(client *redis.Client, fenceSet, bucketName string, anchor GeoKey) ([]storedFence, error) {
+	names, err := client.SMembers(anchorKey(fenceSet, bucketName, anchor.Label)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	moved := make([]storedFence, 0, len(names))
+	for _, name := range names {
+		previous, err := getFence(client, fenceSet, name)
+		if err == ErrFenceNotFound {
+			continue
+		}
+		if err != nil {
+			return moved, err
+		}
+
+		fence := *previous
+		fence.Lat, fence.Lon = anchor.Lat, anchor.Lon
+		fence.Depth, fence.Cells = coverCircle(anchor.Lat, anchor.Lon, fence.Radius)
+		if err := storeFence(client, fenceSet, fence); err != nil {
+			return moved, err
+		}
+		moved = append(moved, *previous)
+	}
+
+	return moved, nil
+}
+
+// reevaluateRoamingFence emits the events of the members around the old and the new position of a moved fence,
+// members in skip were already evaluated
+func (t *FenceTracker) reevaluateRoamingFence(bucketName string, previous storedFence, lat, lon float64, skip []string, now time.Time) ([]FenceEvent, error) {
+	before, err := Search(t.client, bucketName, previous.Lat, previous.Lon, previous.Radius, t.bitDepth)
+	if err != nil {
+		return nil, err
+	}
+	after, err := Search(t.client, bucketName, lat, lon, previous.Radius, t.bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []Result{}
+	for _, result := range slices.Concat(after, before) {
+		if result.Label == previous.Anchor || slices.Contains(skip, result.Label) ||
+			slices.ContainsFunc(candidates, func(r Result) bool { return r.Label == result.Label }) {
+			continue
+		}
+		candidates = append(candidates, result)
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	labels := make([]string, len(candidates))
+	for idx := range candidates {
+		labels[idx] = candidates[idx].Label
+	}
+	memberships, err := t.memberships(bucketName, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []FenceEvent{}
+	changed, changedPrior, changedMemberships := []string{}, [][]string{}, [][]string{}
+	for idx, candidate := range candidates {
+		inside := slices.ContainsFunc(after, func(r Result) bool { return r.Label == candidate.Label })
+		was := slices.Contains(memberships[idx], previous.Name)
+		if inside == was {
+			continue
+		}
+
+		event := FenceEvent{Label: candidate.Label, Lat: candidate.Lat, Lon: candidate.Lon, Time: now}
+		membership := slices.DeleteFunc(slices.Clone(memberships[idx]), func(name string) bool { return name == previous.Name })
+		if inside {
+			membership = append(membership, previous.Name)
+			events = append(events, event.with(FenceEnter, previous.Name))
+		} else {
+			events = append(events, event.with(FenceExit, previous.Name))
+		}
+		changed, changedMemberships = append(changed, candidate.Label), append(changedMemberships, membership)
+		changedPrior = append(changedPrior, memberships[idx])
+	}
+
+	if len(changed) > 0 {
+		if err := t.storeMemberships(bucketName, changed, changedPrior, changedMemberships); err != nil {
+			return events, err
+		}
+	}
+
+	return events, nil
+}
+
+// withoutAnchored drops the fences anchored to label, a member is never inside its own roaming fence
+func withoutAnchored(fences []Fence, bucketName, label string) []Fence {
+	return slices.DeleteFunc(fences, func(fence Fence) bool {
+		return fence.Anchor == label && fence.AnchorBucket == bucketName
+	})
+}
@@ -0,0 +1,140 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package rueidisstore implements georedis.CoreStore on top of github.com/redis/rueidis
+package rueidisstore
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/rueidis"
+
+	"github.com/tapglue/georedis"
+)
+
+// Store implements georedis.CoreStore
+type Store struct {
+	client rueidis.Client
+}
+
+var _ georedis.CoreStore = (*Store)(nil)
+
+// New returns a Store using the client
+func New(client rueidis.Client) *Store {
+	return &Store{client: client}
+}
+
+func (s *Store) do(key string, command string, args ...string) rueidis.RedisResult {
+	return s.client.Do(context.Background(), s.client.B().Arbitrary(command).Keys(key).Args(args...).Build())
+}
+
+// ZAdd adds members to a sorted set
+func (s *Store) ZAdd(key string, members ...georedis.Member) (int64, error) {
+	args := make([]string, 0, len(members)*2)
+	for _, member := range members {
+		args = append(args, formatFloat(member.Score), member.Label)
+	}
+
+	return s.do(key, "ZADD", args...).AsInt64()
+}
+
+// ZRem removes members from a sorted set
+func (s *Store) ZRem(key string, members ...string) (int64, error) {
+	return s.do(key, "ZREM", members...).AsInt64()
+}
+
+// ZScore returns the score of a member or georedis.ErrMemberNotFound
+func (s *Store) ZScore(key, member string) (float64, error) {
+	score, err := s.do(key, "ZSCORE", member).AsFloat64()
+	if rueidis.IsRedisNil(err) {
+		return 0, georedis.ErrMemberNotFound
+	}
+
+	return score, err
+}
+
+// ZCard returns the number of members of a sorted set
+func (s *Store) ZCard(key string) (int64, error) {
+	return s.do(key, "ZCARD").AsInt64()
+}
+
+// ZRange returns the members between the start and stop index
+func (s *Store) ZRange(key string, start, stop int64) ([]georedis.Member, error) {
+	return members(s.do(key, "ZRANGE", strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10), "WITHSCORES"))
+}
+
+// ZRangeByScore returns the members with a score between min and max
+func (s *Store) ZRangeByScore(key string, min, max float64, offset, count int64) ([]georedis.Member, error) {
+	args := []string{formatFloat(min), formatFloat(max), "WITHSCORES"}
+	if count > 0 {
+		args = append(args, "LIMIT", strconv.FormatInt(offset, 10), strconv.FormatInt(count, 10))
+	}
+
+	return members(s.do(key, "ZRANGEBYSCORE", args...))
+}
+
+// HSet sets hash fields
+func (s *Store) HSet(key string, values map[string][]byte) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	args := make([]string, 0, len(values)*2)
+	for field, value := range values {
+		args = append(args, field, string(value))
+	}
+
+	return s.do(key, "HSET", args...).Error()
+}
+
+// HMGet returns the values of hash fields, nil for missing ones
+func (s *Store) HMGet(key string, fields ...string) ([][]byte, error) {
+	values := make([][]byte, len(fields))
+	if len(fields) == 0 {
+		return values, nil
+	}
+
+	reply, err := s.do(key, "HMGET", fields...).ToArray()
+	if err != nil {
+		return values, err
+	}
+	for idx := range reply {
+		if reply[idx].IsNil() {
+			continue
+		}
+		value, err := reply[idx].ToString()
+		if err != nil {
+			return values, err
+		}
+		values[idx] = []byte(value)
+	}
+
+	return values, nil
+}
+
+// HDel deletes hash fields
+func (s *Store) HDel(key string, fields ...string) (int64, error) {
+	return s.do(key, "HDEL", fields...).AsInt64()
+}
+
+// members converts WITHSCORES replies, AsZScores handles the RESP2 and RESP3 layouts
+func members(result rueidis.RedisResult) ([]georedis.Member, error) {
+	scores, err := result.AsZScores()
+	if err != nil {
+		return []georedis.Member{}, err
+	}
+
+	members := make([]georedis.Member, len(scores))
+	for idx := range scores {
+		members[idx] = georedis.Member{Label: scores[idx].Member, Score: scores[idx].Score}
+	}
+
+	return members, nil
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
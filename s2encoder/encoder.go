@@ -0,0 +1,140 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package s2encoder implements georedis.Encoder with the cell ids of Google's S2 geometry
+//
+// S2 cells are projected from the faces of a cube, so cells of a level have about the same area everywhere, unlike
+// geohash cells which shrink towards the poles. Searches are planned with an S2 region coverer, which covers a
+// circle with a few cells of mixed levels instead of the fixed grid of geohash neighbors.
+//
+// Scores are the cell ids of the members at the configured level without their trailing bits, the level may be at
+// most 25, about 30cm, so the scores fit into the 53 bits sorted sets store exactly.
+package s2encoder
+
+import (
+	"errors"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/tapglue/georedis"
+)
+
+const (
+	// MaxLevel is the finest level whose scores fit into sorted sets
+	MaxLevel = 25
+
+	// DefaultMaxCells is the number of cells covering a search unless WithMaxCells is used
+	DefaultMaxCells = 16
+
+	earthRadius = 6371000
+)
+
+// ErrInvalidLevel is returned for levels outside 1 to 25
+var ErrInvalidLevel = errors.New("level must be between 1 and 25")
+
+type (
+	// Encoder stores members in S2 cells of a level
+	Encoder struct {
+		level    int
+		maxCells int
+		shift    uint
+	}
+
+	// Option configures an Encoder
+	Option func(*Encoder)
+)
+
+// WithMaxCells sets the number of cells the coverer may use for a search, more cells read fewer members outside
+// the radius but query more ranges
+func WithMaxCells(maxCells int) Option {
+	return func(e *Encoder) {
+		e.maxCells = maxCells
+	}
+}
+
+// New returns an Encoder storing members in cells of level, between 1 and 25
+func New(level int, options ...Option) (*Encoder, error) {
+	if level < 1 || level > MaxLevel {
+		return nil, ErrInvalidLevel
+	}
+
+	e := &Encoder{level: level, maxCells: DefaultMaxCells, shift: uint(2*(s2.MaxLevel-level) + 1)}
+	for _, option := range options {
+		option(e)
+	}
+
+	return e, nil
+}
+
+// Level returns the level of the cells members are stored in
+func (e *Encoder) Level() int {
+	return e.level
+}
+
+// EncodeInt returns the score of the cell of lat & lon
+func (e *Encoder) EncodeInt(lat, lon float64) uint64 {
+	return e.score(s2.CellIDFromLatLng(s2.LatLngFromDegrees(lat, lon)).Parent(e.level))
+}
+
+// DecodeInt returns the center of the cell of a score
+func (e *Encoder) DecodeInt(score uint64) (float64, float64) {
+	center := e.cell(score).LatLng()
+	return center.Lat.Degrees(), center.Lng.Degrees()
+}
+
+// Neighbors returns the cell of a score and all cells touching it, including the ones touching only a corner
+func (e *Encoder) Neighbors(score uint64) []uint64 {
+	neighbors := e.cell(score).AllNeighbors(e.level)
+	cells := make([]uint64, 1, len(neighbors)+1)
+	cells[0] = score
+	for _, neighbor := range neighbors {
+		cells = append(cells, e.score(neighbor))
+	}
+
+	return cells
+}
+
+// Cover returns the score ranges of the cells covering the circle of radius meters around lat & lon
+func (e *Encoder) Cover(lat, lon, radius float64) ([]georedis.ScoreRange, error) {
+	if err := georedis.ValidateCoordinates(georedis.GeoKey{Lat: lat, Lon: lon}); err != nil {
+		return []georedis.ScoreRange{}, err
+	}
+	if !(radius > 0 && radius <= georedis.MaxRadius) {
+		return []georedis.ScoreRange{}, georedis.ErrInvalidRadius
+	}
+
+	circle := s2.CapFromCenterAngle(s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lon)), s1.Angle(radius/earthRadius))
+	coverer := &s2.RegionCoverer{MaxLevel: e.level, MaxCells: e.maxCells}
+
+	return e.Ranges(coverer.Covering(circle)), nil
+}
+
+// Ranges returns the score ranges of cells, for example of the covering of a polygon, the cells have to be at the
+// level of the encoder or above
+func (e *Encoder) Ranges(cells s2.CellUnion) []georedis.ScoreRange {
+	ranges := make([]georedis.ScoreRange, 0, len(cells))
+	for _, cell := range cells {
+		r := georedis.ScoreRange{
+			Min: e.score(cell.ChildBeginAtLevel(e.level)),
+			Max: e.score(cell.ChildEndAtLevel(e.level)) - 1,
+		}
+		// coverings are sorted, neighboring cells are merged into one range
+		if last := len(ranges) - 1; last >= 0 && ranges[last].Max+1 == r.Min {
+			ranges[last].Max = r.Max
+			continue
+		}
+		ranges = append(ranges, r)
+	}
+
+	return ranges
+}
+
+func (e *Encoder) score(cell s2.CellID) uint64 {
+	return uint64(cell) >> e.shift
+}
+
+func (e *Encoder) cell(score uint64) s2.CellID {
+	return s2.CellID(score<<e.shift | 1<<(e.shift-1))
+}
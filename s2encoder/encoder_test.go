@@ -0,0 +1,65 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package s2encoder
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tapglue/georedis"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	encoder, err := New(MaxLevel)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	for _, point := range [][2]float64{{52.52, 13.405}, {-33.8688, 151.2093}, {89.9, -179.9}, {0, 0}} {
+		score := encoder.EncodeInt(point[0], point[1])
+		if score >= 1<<53 {
+			t.Logf("score %d of %v doesn't fit into 53 bits\n", score, point)
+			t.Fail()
+		}
+		lat, lon := encoder.DecodeInt(score)
+		if distance := georedis.Haversine(point[0], point[1], lat, lon); distance > 1 {
+			t.Logf("expected %v back got %f, %f\n", point, lat, lon)
+			t.Fail()
+		}
+	}
+
+	if _, err := New(MaxLevel + 1); err != ErrInvalidLevel {
+		t.Logf("expected ErrInvalidLevel got %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestCover(t *testing.T) {
+	encoder, _ := New(20)
+
+	ranges, err := encoder.Cover(52.52, 13.405, 1000)
+	if err != nil || len(ranges) == 0 || len(ranges) > DefaultMaxCells {
+		t.Logf("unexpected covering %v error %v\n", ranges, err)
+		t.FailNow()
+	}
+
+	// points on the circle are covered
+	for bearing := 0.0; bearing < 360; bearing += 30 {
+		dLat := 999 / 111195.0 * math.Cos(bearing*math.Pi/180)
+		dLon := 999 / 111195.0 * math.Sin(bearing*math.Pi/180) / math.Cos(52.52*math.Pi/180)
+		score := encoder.EncodeInt(52.52+dLat, 13.405+dLon)
+
+		covered := false
+		for _, r := range ranges {
+			covered = covered || (score >= r.Min && score <= r.Max)
+		}
+		if !covered {
+			t.Logf("point at bearing %f isn't covered by %v\n", bearing, ranges)
+			t.Fail()
+		}
+	}
+}
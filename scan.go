@@ -0,0 +1,117 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"strconv"
+
+	"gopkg.in/redis.v2"
+)
+
+const scanPageSize = 1000
+
+// MemberScanner pages through all members of a bucket in the order of their scores, see ScanMembers
+type MemberScanner struct {
+	client  *redis.Client
+	bucket  string
+	encoder Encoder
+
+	page    []redis.Z
+	member  GeoKey
+	started bool
+	done    bool
+	err     error
+
+	// lastScore and lastLabel are the position of the last member read, tied counts the members read with lastScore
+	lastScore float64
+	lastLabel string
+	tied      int64
+}
+
+// ScanMembers returns a scanner over all members of a bucket which reads scanPageSize members at a time, so buckets
+// of any size can be exported or audited without loading them into memory
+//
+// Every page continues after the score and label of the last member read instead of at an offset, so members added
+// or removed while scanning don't cause others to be skipped or returned twice. Members whose score changes while
+// scanning may be missed or returned twice.
+func ScanMembers(client *redis.Client, bucketName string, bitDepth uint8) *MemberScanner {
+	return &MemberScanner{client: client, bucket: bucketName, encoder: geohashEncoder{bitDepth: bitDepth}}
+}
+
+// Next advances to the next member, it returns false when all members were read or an error occurred
+func (s *MemberScanner) Next() bool {
+	for len(s.page) == 0 {
+		if s.done || s.err != nil {
+			return false
+		}
+		s.err = s.fetch()
+	}
+
+	point := s.page[0]
+	s.page = s.page[1:]
+
+	if point.Score == s.lastScore {
+		s.tied++
+	} else {
+		s.lastScore, s.tied = point.Score, 1
+	}
+	s.lastLabel = point.Member
+
+	s.member.Label = point.Member
+	s.member.Lat, s.member.Lon = s.encoder.DecodeInt(uint64(point.Score))
+
+	return true
+}
+
+// Member returns the member read by the last call to Next
+func (s *MemberScanner) Member() GeoKey {
+	return s.member
+}
+
+// Err returns the error which ended the scan early
+func (s *MemberScanner) Err() error {
+	return s.err
+}
+
+// fetch reads the next page, members sharing the last score are read again and dropped up to the last label as
+// the set orders them by label, tied is recounted so members added meanwhile before the last label are skipped too
+func (s *MemberScanner) fetch() error {
+	query := redis.ZRangeByScore{Min: "-inf", Max: "+inf", Count: scanPageSize}
+	if s.started {
+		query.Min = strconv.FormatFloat(s.lastScore, 'f', -1, 64)
+		query.Count += s.tied
+	}
+
+	members, err := s.client.ZRangeByScoreWithScores(s.bucket, query).Result()
+	if err != nil {
+		return err
+	}
+	s.done = int64(len(members)) < query.Count
+
+	if s.started {
+		skip := 0
+		for skip < len(members) && members[skip].Score == s.lastScore && members[skip].Member <= s.lastLabel {
+			skip++
+		}
+		members, s.tied = members[skip:], int64(skip)
+	}
+	s.page, s.started = members, true
+
+	return nil
+}
+
+// ScanMembers returns a scanner over all members of a bucket, see ScanMembers
+func (c *GeoClient) ScanMembers(bucketName string) *MemberScanner {
+	scanner, err := handleOp(c, Operation{Name: "ScanMembers", Bucket: bucketName}, func() (*MemberScanner, error) {
+		key := c.key(bucketName)
+		return ScanMembers(c.reader(key), key, c.bitDepth), nil
+	})
+	if err != nil {
+		return &MemberScanner{err: err}
+	}
+
+	return scanner
+}
@@ -0,0 +1,44 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestScanMembers(t *testing.T) {
+	const zSetScan = "test:scan"
+
+	client.Del(zSetScan)
+	coordinates := make([]GeoKey, 2500)
+	for idx := range coordinates {
+		// every fifth member shares its coordinates and score with the previous ones
+		coordinates[idx] = GeoKey{Label: fmt.Sprintf("member-%04d", idx), Lat: 52.52 + float64(idx/5)*0.0001, Lon: 13.405}
+	}
+	AddCoordinates(client, zSetScan, bitDepth, coordinates...)
+
+	seen := map[string]bool{}
+	scanner := ScanMembers(client, zSetScan, bitDepth)
+	for scanner.Next() {
+		member := scanner.Member()
+		if seen[member.Label] {
+			t.Logf("expected every member once got %s twice\n", member.Label)
+			t.FailNow()
+		}
+		seen[member.Label] = true
+
+		if len(seen) == 1200 {
+			client.ZRem(zSetScan, "member-0000", "member-0001")
+		}
+	}
+	if err := scanner.Err(); err != nil || len(seen) != len(coordinates) {
+		t.Logf("expected %d members got %d, %v\n", len(coordinates), len(seen), err)
+		t.Fail()
+	}
+}
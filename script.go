@@ -0,0 +1,171 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/redis.v2"
+)
+
+type luaScript struct {
+	src  string
+	hash string
+}
+
+// searchLuaBody decodes, distance filters and sorts the members of the ranges passed in ARGV
+//
+// KEYS[1] is the bucket, ARGV holds lat, lon, radius, bit depth and limit followed by min/max range pairs.
+// The reply is a flat list of label, distance, lat, lon for every hit, nearest first.
+const searchLuaBody = `
+local lat = tonumber(ARGV[1])
+local lon = tonumber(ARGV[2])
+local radius = tonumber(ARGV[3])
+local depth = tonumber(ARGV[4])
+local limit = tonumber(ARGV[5])
+local rad = math.pi / 180
+
+local function decode(score)
+  local bits = {}
+  for i = depth, 1, -1 do
+    bits[i] = score % 2
+    score = math.floor(score / 2)
+  end
+
+  local minLat, maxLat, minLon, maxLon = -90, 90, -180, 180
+  for i = 1, depth do
+    if i % 2 == 1 then
+      local mid = (minLon + maxLon) / 2
+      if bits[i] == 1 then minLon = mid else maxLon = mid end
+    else
+      local mid = (minLat + maxLat) / 2
+      if bits[i] == 1 then minLat = mid else maxLat = mid end
+    end
+  end
+
+  return (minLat + maxLat) / 2, (minLon + maxLon) / 2
+end
+
+local function distance(lat2, lon2)
+  local dLat = (lat2 - lat) * rad
+  local dLon = (lon2 - lon) * rad
+  local a = math.sin(dLat / 2) ^ 2 + math.cos(lat * rad) * math.cos(lat2 * rad) * math.sin(dLon / 2) ^ 2
+  return 6371000 * 2 * math.atan2(math.sqrt(a), math.sqrt(1 - a))
+end
+
+local hits = {}
+for i = 6, #ARGV, 2 do
+  local members = redis.call('ZRANGEBYSCORE', KEYS[1], ARGV[i], ARGV[i + 1], 'WITHSCORES')
+  for j = 1, #members, 2 do
+    local memberLat, memberLon = decode(tonumber(members[j + 1]))
+    local d = distance(memberLat, memberLon)
+    if d <= radius then
+      hits[#hits + 1] = {members[j], d, memberLat, memberLon}
+    end
+  end
+end
+
+table.sort(hits, function(a, b) return a[2] < b[2] end)
+
+local reply = {}
+for i = 1, #hits do
+  if limit >= 0 and i > limit then break end
+  reply[#reply + 1] = hits[i][1]
+  reply[#reply + 1] = string.format('%.17g', hits[i][2])
+  reply[#reply + 1] = string.format('%.17g', hits[i][3])
+  reply[#reply + 1] = string.format('%.17g', hits[i][4])
+end
+
+return reply
+`
+
+var searchScript = newLuaScript(searchLuaBody)
+
+func newLuaScript(src string) *luaScript {
+	hash := sha1.Sum([]byte(src))
+	return &luaScript{src: src, hash: hex.EncodeToString(hash[:])}
+}
+
+// run executes the script by its hash and only sends the source when redis doesn't have it cached yet
+func (s *luaScript) run(client *redis.Client, keys, args []string) (interface{}, error) {
+	res, err := client.EvalSha(s.hash, keys, args).Result()
+	if err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT") {
+		return client.Eval(s.src, keys, args).Result()
+	}
+
+	return res, err
+}
+
+// SearchByRadiusServerSide works like Search but decodes, filters by radius and sorts the members inside redis
+// using a cached lua script so only the nearest "limit" results (all if limit is -1) are sent back
+func SearchByRadiusServerSide(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, limit int) ([]Result, error) {
+	ranges, err := queryRanges(lat, lon, radius, bitDepth)
+	if err != nil {
+		return []Result{}, err
+	}
+
+	reply, err := searchScript.run(client, []string{bucketName}, searchScriptArgs(lat, lon, radius, bitDepth, limit, ranges))
+	if err != nil {
+		return []Result{}, err
+	}
+
+	return parseSearchReply(reply)
+}
+
+func searchScriptArgs(lat, lon, radius float64, bitDepth uint8, limit int, ranges []geoRange) []string {
+	args := make([]string, 0, 5+len(ranges)*2)
+	args = append(args,
+		strconv.FormatFloat(lat, 'f', -1, 64),
+		strconv.FormatFloat(lon, 'f', -1, 64),
+		strconv.FormatFloat(radius, 'f', -1, 64),
+		strconv.Itoa(int(bitDepth)),
+		strconv.Itoa(limit),
+	)
+	for key := range ranges {
+		query := ranges[key].query(0, 0)
+		args = append(args, query.Min, query.Max)
+	}
+
+	return args
+}
+
+func parseSearchReply(reply interface{}) ([]Result, error) {
+	values, ok := reply.([]interface{})
+	if !ok || len(values)%4 != 0 {
+		return []Result{}, fmt.Errorf("unexpected search script reply %v", reply)
+	}
+
+	results := make([]Result, len(values)/4)
+	for idx := range results {
+		fields := [4]string{}
+		for i := range fields {
+			if fields[i], ok = values[idx*4+i].(string); !ok {
+				return []Result{}, fmt.Errorf("unexpected search script reply %v", values[idx*4+i])
+			}
+		}
+
+		results[idx].Label = fields[0]
+		distance, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return []Result{}, err
+		}
+		lat, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return []Result{}, err
+		}
+		lon, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return []Result{}, err
+		}
+		results[idx].Distance, results[idx].Lat, results[idx].Lon = distance, lat, lon
+	}
+
+	return results, nil
+}
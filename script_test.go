@@ -0,0 +1,43 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSearchByRadiusServerSide(t *testing.T) {
+	const zSetScripted = "test:search:scripted"
+
+	client.Del(zSetScripted)
+	AddCoordinates(client, zSetScripted, bitDepth,
+		GeoKey{Lat: 39.9523, Lon: -75.1638, Label: "Philadelphia"},
+		GeoKey{Lat: 39.9623, Lon: -75.1638, Label: "North"},
+		GeoKey{Lat: 40.7128, Lon: -74.0060, Label: "New York"},
+	)
+
+	results, err := SearchByRadiusServerSide(client, zSetScripted, 39.9523, -75.1638, 5000, bitDepth, -1)
+	if err != nil {
+		t.Logf("error encountered: %q\n", err)
+		t.FailNow()
+	}
+	if len(results) != 2 || results[0].Label != "Philadelphia" || results[1].Label != "North" {
+		t.Logf("unexpected results %v\n", results)
+		t.FailNow()
+	}
+	if results[1].Distance < 1000 || results[1].Distance > 1200 {
+		t.Logf("unexpected distance %f\n", results[1].Distance)
+		t.Fail()
+	}
+
+	results, err = SearchByRadiusServerSide(client, zSetScripted, 39.9523, -75.1638, 5000, bitDepth, 1)
+	if err != nil || len(results) != 1 || results[0].Label != "Philadelphia" {
+		t.Logf("unexpected limited results %v error %v\n", results, err)
+		t.Fail()
+	}
+}
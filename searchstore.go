@@ -0,0 +1,64 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"time"
+
+	"github.com/tapglue/georedis/internal/geohash"
+	"gopkg.in/redis.v2"
+)
+
+// SearchStore searches like Search and replaces the sorted set destination with the results, like GEOSEARCHSTORE,
+// it returns the number of stored members
+//
+// The members keep their scores so destination is a bucket of the same bit depth which can be searched itself. A
+// positive ttl expires destination, empty results delete it.
+func SearchStore(client *redis.Client, bucketName, destination string, lat, lon, radius float64, bitDepth uint8, ttl time.Duration, options ...SearchOption) (int64, error) {
+	results, err := Search(client, bucketName, lat, lon, radius, bitDepth, options...)
+	if err != nil {
+		return 0, err
+	}
+
+	members := make([]redis.Z, len(results))
+	for idx := range results {
+		members[idx] = redis.Z{
+			Score:  float64(geohash.EncodeInt(results[idx].Lat, results[idx].Lon, bitDepth)),
+			Member: results[idx].Label,
+		}
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	_, err = multi.Exec(func() error {
+		multi.Del(destination)
+		if len(members) > 0 {
+			multi.ZAdd(destination, members...)
+			if ttl > 0 {
+				multi.Expire(destination, ttl)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(members)), nil
+}
+
+// SearchStore searches like Search and replaces the sorted set destination with the results
+func (c *GeoClient) SearchStore(bucketName, destination string, lat, lon, radius float64, ttl time.Duration, options ...SearchOption) (int64, error) {
+	return handleOp(c, Operation{Name: "SearchStore", Bucket: bucketName, Write: true, Args: []any{destination, lat, lon, radius, ttl, options}}, func() (int64, error) {
+		bucketKey := c.key(bucketName)
+		destinationKey := c.key(destination)
+		defer c.wrote(destinationKey)
+		return withRetry(c, func() (int64, error) {
+			return SearchStore(c.client, bucketKey, destinationKey, lat, lon, radius, c.bitDepth, ttl, options...)
+		})
+	})
+}
@@ -0,0 +1,51 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSearchStore(t *testing.T) {
+	const zSetSource, zSetDestination = "test:store", "test:store:nearby"
+
+	client.Del(zSetSource, zSetDestination)
+	AddCoordinates(client, zSetSource, bitDepth,
+		GeoKey{Label: "near", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "close", Lat: 52.521, Lon: 13.405},
+		GeoKey{Label: "far", Lat: 48.137, Lon: 11.575},
+	)
+
+	stored, err := SearchStore(client, zSetSource, zSetDestination, 52.52, 13.405, 1000, bitDepth, time.Minute)
+	if err != nil || stored != 2 {
+		t.Logf("expected 2 stored members got %d, %v\n", stored, err)
+		t.FailNow()
+	}
+
+	if ttl := client.TTL(zSetDestination).Val(); ttl <= 0 || ttl > time.Minute {
+		t.Logf("expected the destination to expire got %v\n", ttl)
+		t.Fail()
+	}
+
+	results, err := Search(client, zSetDestination, 52.52, 13.405, 1000, bitDepth)
+	if err != nil || len(results) != 2 || results[0].Label != "near" || results[1].Label != "close" {
+		t.Logf("expected the destination to be searchable got %v, %v\n", results, err)
+		t.Fail()
+	}
+
+	if stored, err = SearchStore(client, zSetSource, zSetDestination, 0, 0, 1000, bitDepth, 0); err != nil || stored != 0 {
+		t.Logf("expected nothing stored got %d, %v\n", stored, err)
+		t.Fail()
+	}
+
+	if exists := client.Exists(zSetDestination).Val(); exists {
+		t.Log("expected empty results to delete the destination")
+		t.Fail()
+	}
+}
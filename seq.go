@@ -0,0 +1,71 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"iter"
+
+	"gopkg.in/redis.v2"
+)
+
+// errStopped stops a chunked search once the loop over a sequence is left
+var errStopped = errors.New("iteration stopped")
+
+// Nearby returns the members within radius meters of lat & lon as a sequence for range loops, the ranges are paged
+// through scanPageSize members at a time like SearchByRadiusChunked and leaving the loop stops reading them
+//
+// Results are not ordered by distance. When err isn't nil it is set to the error which ended the sequence early.
+func Nearby(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, err *error) iter.Seq[Result] {
+	return func(yield func(Result) bool) {
+		searchErr := SearchByRadiusChunked(client, bucketName, lat, lon, radius, bitDepth, scanPageSize, func(chunk []Result) error {
+			for _, result := range chunk {
+				if !yield(result) {
+					return errStopped
+				}
+			}
+			return nil
+		})
+		if errors.Is(searchErr, errStopped) {
+			searchErr = nil
+		}
+		if err != nil {
+			*err = searchErr
+		}
+	}
+}
+
+// All returns the remaining members as a sequence for range loops, leaving the loop stops reading pages, see Err
+func (s *MemberScanner) All() iter.Seq[GeoKey] {
+	return func(yield func(GeoKey) bool) {
+		for s.Next() {
+			if !yield(s.Member()) {
+				return
+			}
+		}
+	}
+}
+
+// Nearby returns the members within radius meters of lat & lon as a sequence, see Nearby
+//
+// Failed pages are not retried as the results before them were already yielded.
+func (c *GeoClient) Nearby(bucketName string, lat, lon, radius float64, err *error) iter.Seq[Result] {
+	return func(yield func(Result) bool) {
+		handleErr := c.handle(Operation{Name: "Nearby", Bucket: bucketName, Args: []any{lat, lon, radius}}, func() error {
+			var searchErr error
+			key := c.key(bucketName)
+			for result := range Nearby(c.reader(key), key, lat, lon, radius, c.bitDepth, &searchErr) {
+				if !yield(result) {
+					break
+				}
+			}
+			return searchErr
+		})
+		if err != nil {
+			*err = handleErr
+		}
+	}
+}
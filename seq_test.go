@@ -0,0 +1,60 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestNearby(t *testing.T) {
+	const zSetNearby = "test:nearby"
+
+	client.Del(zSetNearby)
+	coordinates := make([]GeoKey, 1500)
+	for idx := range coordinates {
+		coordinates[idx] = GeoKey{Label: fmt.Sprintf("member-%04d", idx), Lat: 52.52, Lon: 13.405 + float64(idx)*0.000001}
+	}
+	AddCoordinates(client, zSetNearby, bitDepth, coordinates...)
+	AddCoordinates(client, zSetNearby, bitDepth, GeoKey{Label: "far", Lat: 48.137, Lon: 11.575})
+
+	var err error
+	found := 0
+	for result := range Nearby(client, zSetNearby, 52.52, 13.405, 1000, bitDepth, &err) {
+		if result.Label == "far" {
+			t.Logf("expected only members within the radius got %v\n", result)
+			t.Fail()
+		}
+		found++
+	}
+	if err != nil || found != len(coordinates) {
+		t.Logf("expected %d members got %d, %v\n", len(coordinates), found, err)
+		t.Fail()
+	}
+
+	found = 0
+	for range Nearby(client, zSetNearby, 52.52, 13.405, 1000, bitDepth, &err) {
+		if found++; found == 10 {
+			break
+		}
+	}
+	if err != nil || found != 10 {
+		t.Logf("expected to stop after 10 members got %d, %v\n", found, err)
+		t.Fail()
+	}
+
+	scanned := 0
+	scanner := ScanMembers(client, zSetNearby, bitDepth)
+	for range scanner.All() {
+		scanned++
+	}
+	if scanner.Err() != nil || scanned != len(coordinates)+1 {
+		t.Logf("expected to scan %d members got %d, %v\n", len(coordinates)+1, scanned, scanner.Err())
+		t.Fail()
+	}
+}
@@ -0,0 +1,126 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+// setOperationTTL expires the temporary key of a set operation if the client dies before deleting it
+const setOperationTTL = time.Minute
+
+// Intersect returns the members of bucket a which are also members of bucket b, ordered by label
+//
+// Members are matched by label and keep the coordinates of bucket a. A non nil region, a Polygon or MultiPolygon,
+// limits the result to the members of a inside it.
+func Intersect(client *redis.Client, a, b string, bitDepth uint8, region Geometry) ([]GeoKey, error) {
+	return setOperation(client, a, bitDepth, region, func(multi *redis.Multi, key string) {
+		multi.ZInterStore(key, redis.ZStore{Weights: []int64{1, 0}}, a, b)
+	})
+}
+
+// Union returns the members of bucket a and of bucket b, ordered by label, it requires redis 6.2 or newer
+//
+// Members of both buckets keep the coordinates of bucket a. A non nil region, a Polygon or MultiPolygon, limits the
+// result to the members inside it.
+func Union(client *redis.Client, a, b string, bitDepth uint8, region Geometry) ([]GeoKey, error) {
+	return setOperation(client, a, bitDepth, region, func(multi *redis.Multi, key string) {
+		multi.Process(redis.NewCmd("ZDIFFSTORE", key, "2", b, a))
+		multi.ZUnionStore(key, redis.ZStore{}, key, a)
+	})
+}
+
+// Difference returns the members of bucket a which are no members of bucket b, ordered by label, it requires redis
+// 6.2 or newer
+//
+// A non nil region, a Polygon or MultiPolygon, limits the result to the members of a inside it.
+func Difference(client *redis.Client, a, b string, bitDepth uint8, region Geometry) ([]GeoKey, error) {
+	return setOperation(client, a, bitDepth, region, func(multi *redis.Multi, key string) {
+		multi.Process(redis.NewCmd("ZDIFFSTORE", key, "2", a, b))
+	})
+}
+
+// setOperation stores the result of store in a temporary key next to bucket a and reads it back
+func setOperation(client *redis.Client, a string, bitDepth uint8, region Geometry, store func(multi *redis.Multi, key string)) ([]GeoKey, error) {
+	var shape MultiPolygon
+	if region != nil {
+		var err error
+		if shape, err = polygons(region); err != nil {
+			return []GeoKey{}, err
+		}
+	}
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return []GeoKey{}, err
+	}
+
+	key := a + ":setop:" + strconv.FormatUint(rand.Uint64(), 36)
+	defer client.Del(key)
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	_, err := multi.Exec(func() error {
+		store(multi, key)
+		multi.Expire(key, setOperationTTL)
+		return nil
+	})
+	if err != nil {
+		return []GeoKey{}, err
+	}
+
+	var members []GeoKey
+	if shape != nil {
+		members, err = setOperationInside(client, key, bitDepth, shape)
+	} else {
+		members, err = setOperationMembers(client, key, bitDepth)
+	}
+	if err != nil {
+		return []GeoKey{}, err
+	}
+
+	slices.SortFunc(members, func(x, y GeoKey) int {
+		return cmp.Compare(x.Label, y.Label)
+	})
+
+	return members, nil
+}
+
+func setOperationInside(client *redis.Client, key string, bitDepth uint8, shape MultiPolygon) ([]GeoKey, error) {
+	results, err := searchPolygon(client, key, bitDepth, shape)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]GeoKey, len(results))
+	for idx := range results {
+		members[idx] = GeoKey{Label: results[idx].Label, Lat: results[idx].Lat, Lon: results[idx].Lon}
+	}
+
+	return members, nil
+}
+
+func setOperationMembers(client *redis.Client, key string, bitDepth uint8) ([]GeoKey, error) {
+	members := []GeoKey{}
+	for offset := int64(0); ; offset += dumpBatchSize {
+		page, err := client.ZRangeWithScores(key, offset, offset+dumpBatchSize-1).Result()
+		if err != nil {
+			return nil, err
+		}
+		for idx := range page {
+			lat, lon := geohashEncoder{bitDepth: bitDepth}.DecodeInt(uint64(page[idx].Score))
+			members = append(members, GeoKey{Label: page[idx].Member, Lat: lat, Lon: lon})
+		}
+		if len(page) < dumpBatchSize {
+			return members, nil
+		}
+	}
+}
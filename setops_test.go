@@ -0,0 +1,55 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSetOperations(t *testing.T) {
+	const zSetRestaurants = "test:setops:restaurants"
+	const zSetPartners = "test:setops:partners"
+
+	client.Del(zSetRestaurants, zSetPartners)
+	AddCoordinates(client, zSetRestaurants, bitDepth,
+		GeoKey{Label: "partner", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "independent", Lat: 52.53, Lon: 13.41},
+		GeoKey{Label: "paris", Lat: 48.85, Lon: 2.35},
+	)
+	AddCoordinates(client, zSetPartners, bitDepth,
+		GeoKey{Label: "partner", Lat: 10, Lon: 10},
+		GeoKey{Label: "elsewhere", Lat: 52.51, Lon: 13.4},
+	)
+
+	labels := func(members []GeoKey) []string {
+		labels := []string{}
+		for _, member := range members {
+			labels = append(labels, member.Label)
+		}
+		return labels
+	}
+	berlin := Polygon{{{Lat: 52.3, Lon: 13}, {Lat: 52.3, Lon: 13.8}, {Lat: 52.7, Lon: 13.8}, {Lat: 52.7, Lon: 13}}}
+
+	members, err := Intersect(client, zSetRestaurants, zSetPartners, bitDepth, nil)
+	if err != nil || len(members) != 1 || members[0].Label != "partner" || members[0].Lat < 52 {
+		t.Logf("expected the partner with the coordinates of the restaurant got %v error %v\n", members, err)
+		t.Fail()
+	}
+
+	members, err = Difference(client, zSetRestaurants, zSetPartners, bitDepth, berlin)
+	if err != nil || len(members) != 1 || members[0].Label != "independent" {
+		t.Logf("expected the independent restaurant in berlin got %v error %v\n", labels(members), err)
+		t.Fail()
+	}
+
+	members, err = Union(client, zSetRestaurants, zSetPartners, bitDepth, berlin)
+	if got := labels(members); err != nil || len(got) != 3 || got[0] != "elsewhere" || got[1] != "independent" || got[2] != "partner" {
+		t.Logf("expected all members in berlin got %v error %v\n", got, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,237 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"gopkg.in/redis.v2"
+)
+
+// ErrNoShards is returned by NewShardedGeoClient for an empty list of shards
+var ErrNoShards = errors.New("no shards")
+
+// ShardedGeoClient spreads the members of a bucket across several redis instances
+//
+// Members are assigned to a shard by the first prefixBits bits of their geohash, searches only query the shards
+// owning a prefix which intersects the searched area and merge their results.
+type ShardedGeoClient struct {
+	shards     []*redis.Client
+	bitDepth   uint8
+	prefixBits uint8
+}
+
+// NewShardedGeoClient returns a ShardedGeoClient routing by geohash prefixes of prefixBits bits
+//
+// The number of shards and prefixBits decide where members live, changing either requires moving the data.
+func NewShardedGeoClient(shards []*redis.Client, bitDepth, prefixBits uint8) (*ShardedGeoClient, error) {
+	if len(shards) == 0 {
+		return nil, ErrNoShards
+	}
+	if prefixBits > bitDepth {
+		prefixBits = bitDepth
+	}
+
+	return &ShardedGeoClient{shards: shards, bitDepth: bitDepth, prefixBits: prefixBits}, nil
+}
+
+// Shards returns the redis clients of the shards
+func (c *ShardedGeoClient) Shards() []*redis.Client {
+	return c.shards
+}
+
+// Close closes all shard clients
+func (c *ShardedGeoClient) Close() error {
+	var firstErr error
+	for _, shard := range c.shards {
+		if err := shard.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// AddCoordinates adds coordinates to the shards owning their prefixes
+//
+// Labels are removed from all other shards in the same call so members moving across a prefix boundary are
+// not found twice.
+func (c *ShardedGeoClient) AddCoordinates(bucketName string, coordinates ...GeoKey) (int64, error) {
+	if err := ValidateCoordinates(coordinates...); err != nil {
+		return 0, err
+	}
+
+	byShard := make([][]GeoKey, len(c.shards))
+	labels := make([][]string, len(c.shards))
+	for _, coordinate := range coordinates {
+		shard := c.shardOf(geohashEncoder{bitDepth: c.bitDepth}.EncodeInt(coordinate.Lat, coordinate.Lon))
+		byShard[shard] = append(byShard[shard], coordinate)
+		labels[shard] = append(labels[shard], coordinate.Label)
+	}
+
+	added := make([]int64, len(c.shards))
+	err := c.each(func(idx int, shard *redis.Client) error {
+		for other := range labels {
+			if other == idx || len(labels[other]) == 0 {
+				continue
+			}
+			if _, err := RemoveCoordinatesByKeys(shard, bucketName, labels[other]...); err != nil {
+				return err
+			}
+		}
+
+		if len(byShard[idx]) == 0 {
+			return nil
+		}
+
+		var err error
+		added[idx], err = AddCoordinates(shard, bucketName, c.bitDepth, byShard[idx]...)
+		return err
+	})
+
+	return sum(added), err
+}
+
+// RemoveCoordinatesByKeys removes coordinates, their payloads and attributes from all shards
+func (c *ShardedGeoClient) RemoveCoordinatesByKeys(bucketName string, coordinatesKeys ...string) (int64, error) {
+	removed := make([]int64, len(c.shards))
+	err := c.each(func(idx int, shard *redis.Client) error {
+		var err error
+		removed[idx], err = RemoveCoordinatesByKeys(shard, bucketName, coordinatesKeys...)
+		return err
+	})
+
+	return sum(removed), err
+}
+
+// GetCoordinates returns the decoded coordinates of a label or ErrMemberNotFound
+func (c *ShardedGeoClient) GetCoordinates(bucketName, label string) (GeoKey, error) {
+	found := make([]GeoKey, len(c.shards))
+	ok := make([]bool, len(c.shards))
+	err := c.each(func(idx int, shard *redis.Client) error {
+		coordinates, err := GetCoordinates(shard, bucketName, c.bitDepth, label)
+		if err == ErrMemberNotFound {
+			return nil
+		}
+		found[idx], ok[idx] = coordinates, err == nil
+		return err
+	})
+	if err != nil {
+		return GeoKey{}, err
+	}
+
+	if idx := slices.Index(ok, true); idx != -1 {
+		return found[idx], nil
+	}
+
+	return GeoKey{}, ErrMemberNotFound
+}
+
+// CountCoordinates returns the number of coordinates in the bucket across all shards
+func (c *ShardedGeoClient) CountCoordinates(bucketName string) (int64, error) {
+	counts := make([]int64, len(c.shards))
+	err := c.each(func(idx int, shard *redis.Client) error {
+		var err error
+		counts[idx], err = CountCoordinates(shard, bucketName)
+		return err
+	})
+
+	return sum(counts), err
+}
+
+// Search returns all members which are in a certain range from the provided lat & lon coordinates, nearest first,
+// querying only the shards which can hold members in range
+func (c *ShardedGeoClient) Search(bucketName string, lat, lon, radius float64, options ...SearchOption) ([]Result, error) {
+	opts := newSearchOptions(options)
+
+	ranges, err := queryRanges(lat, lon, opts.unit.ToMeters(radius), c.bitDepth)
+	if err != nil {
+		return []Result{}, err
+	}
+	targets := c.shardsOf(ranges)
+
+	found := make([][]Result, len(c.shards))
+	partial := &PartialResultsError{}
+	err = c.each(func(idx int, shard *redis.Client) error {
+		if !targets[idx] {
+			return nil
+		}
+
+		var err error
+		found[idx], err = Search(shard, bucketName, lat, lon, radius, c.bitDepth, options...)
+		return err
+	})
+	if err != nil && !errors.As(err, &partial) {
+		return []Result{}, err
+	}
+
+	results := slices.Concat(found...)
+	slices.SortFunc(results, byDistance)
+	// a member moving between shards can briefly be stored on both
+	results = dedupeResults(results)
+	if opts.limit >= 0 && len(results) > opts.limit {
+		results = results[:opts.limit]
+	}
+
+	return results, err
+}
+
+func (c *ShardedGeoClient) shardOf(hash uint64) int {
+	if len(c.shards) == 0 {
+		return 0
+	}
+
+	return int((hash >> (c.bitDepth - c.prefixBits)) % uint64(len(c.shards)))
+}
+
+// shardsOf marks the shards owning any prefix of the ranges
+func (c *ShardedGeoClient) shardsOf(ranges []geoRange) []bool {
+	targets := make([]bool, len(c.shards))
+	shift := c.bitDepth - c.prefixBits
+	for _, r := range ranges {
+		first, last := uint64(r.Lower)>>shift, (uint64(r.Upper)-1)>>shift
+		// consecutive prefixes cycle through the shards, so there is no need to look at more than one round
+		for prefix := first; prefix <= last && prefix-first < uint64(len(c.shards)); prefix++ {
+			targets[c.shardOf(prefix<<shift)] = true
+		}
+	}
+
+	return targets
+}
+
+// each runs fn for every shard concurrently and returns the first error
+func (c *ShardedGeoClient) each(fn func(idx int, shard *redis.Client) error) error {
+	errs := make([]error, len(c.shards))
+
+	var wg sync.WaitGroup
+	for idx, shard := range c.shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[idx] = fn(idx, shard)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sum(values []int64) int64 {
+	total := int64(0)
+	for _, value := range values {
+		total += value
+	}
+
+	return total
+}
@@ -0,0 +1,57 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/tapglue/georedis/internal/geohash"
+
+	"gopkg.in/redis.v2"
+)
+
+func TestShardsOfCoversSearchArea(t *testing.T) {
+	shards := make([]*redis.Client, 8)
+	for idx := range shards {
+		shards[idx] = &redis.Client{}
+	}
+	c, err := NewShardedGeoClient(shards, 52, 20)
+	if err != nil {
+		t.Logf("unexpected error %v\n", err)
+		t.FailNow()
+	}
+
+	const lat, lon, radius = 52.5200, 13.4050, 5000.0
+	ranges, err := getQueryRangesFromBitDepth(lat, lon, rangeDepth(radius), c.bitDepth)
+	if err != nil {
+		t.Logf("unexpected error %v\n", err)
+		t.FailNow()
+	}
+	targets := c.shardsOf(ranges)
+
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		pointLat, pointLon := lat+(random.Float64()-0.5)/10, lon+(random.Float64()-0.5)/10
+		hash := geohash.EncodeInt(pointLat, pointLon, c.bitDepth)
+		if !slices.ContainsFunc(ranges, func(r geoRange) bool { return float64(hash) >= r.Lower && float64(hash) < r.Upper }) {
+			continue
+		}
+
+		if shard := c.shardOf(hash); !targets[shard] {
+			t.Logf("point %f,%f is on shard %d which is not searched\n", pointLat, pointLon, shard)
+			t.Fail()
+		}
+	}
+}
+
+func TestShardedGeoClientRequiresShards(t *testing.T) {
+	if _, err := NewShardedGeoClient(nil, 52, 20); err != ErrNoShards {
+		t.Logf("expected ErrNoShards got %v\n", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,170 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	defaultForwarderBatchSize  = 100
+	defaultForwarderRetryDelay = time.Second
+	forwarderBlock             = time.Second
+)
+
+type (
+	// EventSink receives the changes of buckets and fence events to hand them to other systems
+	//
+	// Writes are retried after an error, so sinks should tolerate receiving the same change twice.
+	EventSink interface {
+		WriteChanges(bucketName string, changes ...Change) error
+		WriteFenceEvents(events ...FenceEvent) error
+	}
+
+	// ChangeForwarder hands the change stream of a bucket to an EventSink through a consumer group
+	//
+	// Changes are acknowledged once the sink accepted them, changes the sink failed on are handed over again.
+	// Forwarders of the same group share the work, see ClaimStaleChanges to take over changes of forwarders which
+	// stopped for good.
+	ChangeForwarder struct {
+		client     *redis.Client
+		bucketName string
+		group      string
+		consumer   string
+		sink       EventSink
+		batchSize  int64
+		retryDelay time.Duration
+		onError    func(error)
+
+		done    chan struct{}
+		stopped sync.WaitGroup
+	}
+
+	// ForwarderOption configures a ChangeForwarder
+	ForwarderOption func(*ChangeForwarder)
+)
+
+// WithEventSink writes every event to sink after the handlers, a failed write is returned by the update
+func WithEventSink(sink EventSink) FenceTrackerOption {
+	return func(t *FenceTracker) {
+		t.sinks = append(t.sinks, sink)
+	}
+}
+
+// WithForwarderBatchSize sets the maximum number of changes handed to the sink at once, 100 by default
+func WithForwarderBatchSize(size int64) ForwarderOption {
+	return func(f *ChangeForwarder) {
+		f.batchSize = size
+	}
+}
+
+// WithForwarderRetryDelay sets how long to wait after a failed read or write before trying again, 1s by default
+func WithForwarderRetryDelay(delay time.Duration) ForwarderOption {
+	return func(f *ChangeForwarder) {
+		f.retryDelay = delay
+	}
+}
+
+// WithForwarderErrorHandler sets the function receiving failed reads and writes
+func WithForwarderErrorHandler(handler func(error)) ForwarderOption {
+	return func(f *ChangeForwarder) {
+		f.onError = handler
+	}
+}
+
+// ForwardChanges starts handing the changes of a bucket to sink as consumer of group, the group is created reading
+// the stream from the beginning when missing
+func ForwardChanges(client *redis.Client, bucketName, group, consumer string, sink EventSink, options ...ForwarderOption) (*ChangeForwarder, error) {
+	f := &ChangeForwarder{
+		client:     client,
+		bucketName: bucketName,
+		group:      group,
+		consumer:   consumer,
+		sink:       sink,
+		batchSize:  defaultForwarderBatchSize,
+		retryDelay: defaultForwarderRetryDelay,
+		onError:    func(error) {},
+		done:       make(chan struct{}),
+	}
+	for _, option := range options {
+		option(f)
+	}
+
+	if err := CreateChangeGroup(client, bucketName, group, "0"); err != nil {
+		return nil, err
+	}
+
+	f.stopped.Add(1)
+	go f.loop()
+
+	return f, nil
+}
+
+// Close stops forwarding and waits for the current batch to finish
+func (f *ChangeForwarder) Close() error {
+	close(f.done)
+	f.stopped.Wait()
+
+	return nil
+}
+
+// loop forwards the changes still pending for the consumer, for example after a restart or a failed write, before
+// reading new ones
+func (f *ChangeForwarder) loop() {
+	defer f.stopped.Done()
+
+	pending := true
+	for {
+		select {
+		case <-f.done:
+			return
+		default:
+		}
+
+		var changes []Change
+		var err error
+		if pending {
+			changes, err = readPendingChanges(f.client, f.bucketName, f.group, f.consumer, f.batchSize)
+			pending = len(changes) > 0
+		} else {
+			changes, err = ReadChanges(f.client, f.bucketName, f.group, f.consumer, f.batchSize, forwarderBlock)
+		}
+		if err == nil && len(changes) > 0 {
+			if err = f.sink.WriteChanges(f.bucketName, changes...); err != nil {
+				pending = true
+			} else {
+				_, err = AckChanges(f.client, f.bucketName, f.group, changeIDs(changes)...)
+			}
+		}
+		if err != nil {
+			f.onError(err)
+			select {
+			case <-f.done:
+				return
+			case <-time.After(f.retryDelay):
+			}
+		}
+	}
+}
+
+// readPendingChanges returns changes delivered to the consumer before which were not acknowledged yet
+func readPendingChanges(client *redis.Client, bucketName, group, consumer string, count int64) ([]Change, error) {
+	return readStream(client, []string{"XREADGROUP", "GROUP", group, consumer, "COUNT", strconv.FormatInt(count, 10),
+		"STREAMS", ChangeStreamKey(bucketName), "0"})
+}
+
+func changeIDs(changes []Change) []string {
+	ids := make([]string, len(changes))
+	for idx := range changes {
+		ids[idx] = changes[idx].ID
+	}
+
+	return ids
+}
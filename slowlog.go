@@ -0,0 +1,211 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const defaultSlowLogSize = 128
+
+type (
+	// SlowLog keeps the latest searches of a GeoClient which exceeded a threshold in a ring buffer and optionally
+	// appends them to a redis stream, see WithSlowLog
+	SlowLog struct {
+		thresholds SlowLogThresholds
+		client     *redis.Client
+		stream     string
+		maxLen     int64
+
+		mu      sync.Mutex
+		entries []SlowSearch
+		next    int
+	}
+
+	// SlowLogThresholds selects the searches a SlowLog records, a search is slow when it reaches any non zero
+	// threshold
+	SlowLogThresholds struct {
+		Duration   time.Duration
+		Candidates int
+	}
+
+	// SlowLogOption configures a SlowLog
+	SlowLogOption func(*SlowLog)
+
+	// SlowSearch is a search recorded by a SlowLog, Ranges are the score ranges it read
+	SlowSearch struct {
+		ID     string       `json:"id,omitempty"`
+		Time   time.Time    `json:"time"`
+		Bucket string       `json:"bucket"`
+		Lat    float64      `json:"lat"`
+		Lon    float64      `json:"lon"`
+		Radius float64      `json:"radius"`
+		Ranges []ScoreRange `json:"ranges"`
+
+		Duration   time.Duration `json:"duration"`
+		Plan       time.Duration `json:"plan"`
+		Fetch      time.Duration `json:"fetch"`
+		Rank       time.Duration `json:"rank"`
+		Complete   time.Duration `json:"complete"`
+		Candidates int           `json:"candidates"`
+		Results    int           `json:"results"`
+		Err        string        `json:"error,omitempty"`
+	}
+)
+
+// NewSlowLog returns a SlowLog keeping the latest size searches exceeding thresholds, 128 when size isn't positive
+func NewSlowLog(size int, thresholds SlowLogThresholds, options ...SlowLogOption) *SlowLog {
+	if size <= 0 {
+		size = defaultSlowLogSize
+	}
+
+	l := &SlowLog{thresholds: thresholds, entries: make([]SlowSearch, 0, size)}
+	for _, option := range options {
+		option(l)
+	}
+
+	return l
+}
+
+// WithSlowLogStream also appends the slow searches to the stream key, capped to about maxLen entries unless maxLen
+// is 0, so they can be read with ReadSlowLog across processes
+func WithSlowLogStream(client *redis.Client, key string, maxLen int64) SlowLogOption {
+	return func(l *SlowLog) {
+		l.client, l.stream, l.maxLen = client, key, maxLen
+	}
+}
+
+// WithSlowLog records the slow searches of a GeoClient in log
+func WithSlowLog(log *SlowLog) ClientOption {
+	return func(c *GeoClient) {
+		c.slowLog = log
+	}
+}
+
+// Entries returns the searches in the ring buffer, the latest first
+func (l *SlowLog) Entries() []SlowSearch {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := make([]SlowSearch, 0, len(l.entries))
+	for idx := range l.entries {
+		entries = append(entries, l.entries[(l.next-1-idx+2*len(l.entries))%len(l.entries)])
+	}
+
+	return entries
+}
+
+// Reset empties the ring buffer, the stream is kept
+func (l *SlowLog) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries, l.next = l.entries[:0], 0
+}
+
+// ReadSlowLog returns the latest count searches of the slow log stream key, the latest first
+func ReadSlowLog(client *redis.Client, key string, count int) ([]SlowSearch, error) {
+	cmd := redis.NewCmd("XREVRANGE", key, "+", "-", "COUNT", strconv.Itoa(count))
+	client.Process(cmd)
+	reply, err := cmd.Result()
+	if err != nil {
+		return []SlowSearch{}, err
+	}
+
+	entries, ok := reply.([]interface{})
+	if !ok {
+		return []SlowSearch{}, fmt.Errorf("unexpected stream entries %v", reply)
+	}
+
+	searches := make([]SlowSearch, 0, len(entries))
+	for _, entry := range entries {
+		values, ok := entry.([]interface{})
+		if !ok || len(values) != 2 {
+			return []SlowSearch{}, fmt.Errorf("unexpected stream entry %v", entry)
+		}
+		fields, ok := values[1].([]interface{})
+		if !ok || len(fields) != 2 {
+			return []SlowSearch{}, fmt.Errorf("unexpected stream entry %v", entry)
+		}
+
+		value, _ := fields[1].(string)
+		search := SlowSearch{}
+		if err := json.Unmarshal([]byte(value), &search); err != nil {
+			return []SlowSearch{}, err
+		}
+		search.ID, _ = values[0].(string)
+		searches = append(searches, search)
+	}
+
+	return searches, nil
+}
+
+// slow reports whether a search reached a threshold
+func (t SlowLogThresholds) slow(stats SearchStats) bool {
+	return (t.Duration > 0 && stats.Duration >= t.Duration) || (t.Candidates > 0 && stats.Candidates >= t.Candidates)
+}
+
+// record adds a slow search to the ring buffer and the stream
+func (l *SlowLog) record(bucketName string, lat, lon, radius float64, stats SearchStats) error {
+	if !l.thresholds.slow(stats) {
+		return nil
+	}
+
+	search := SlowSearch{
+		Time:       time.Now(),
+		Bucket:     bucketName,
+		Lat:        lat,
+		Lon:        lon,
+		Radius:     radius,
+		Ranges:     make([]ScoreRange, len(stats.ranges)),
+		Duration:   stats.Duration,
+		Plan:       stats.Plan,
+		Fetch:      stats.Fetch,
+		Rank:       stats.Rank,
+		Complete:   stats.Complete,
+		Candidates: stats.Candidates,
+		Results:    stats.Results,
+	}
+	for idx, r := range stats.ranges {
+		search.Ranges[idx] = ScoreRange{Min: uint64(r.Lower), Max: uint64(r.Upper)}
+	}
+	if stats.Err != nil {
+		search.Err = stats.Err.Error()
+	}
+
+	l.mu.Lock()
+	if len(l.entries) < cap(l.entries) {
+		l.entries = append(l.entries, search)
+	} else {
+		l.entries[l.next] = search
+	}
+	l.next = (l.next + 1) % cap(l.entries)
+	l.mu.Unlock()
+
+	if l.stream == "" {
+		return nil
+	}
+
+	encoded, err := json.Marshal(search)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"XADD", l.stream}
+	if l.maxLen > 0 {
+		args = append(args, "MAXLEN", "~", strconv.FormatInt(l.maxLen, 10))
+	}
+	cmd := redis.NewCmd(append(args, "*", "search", string(encoded))...)
+	l.client.Process(cmd)
+
+	return cmd.Err()
+}
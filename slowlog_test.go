@@ -0,0 +1,50 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSlowLog(t *testing.T) {
+	const zSetSlow, slowStream = "test:slowlog", "test:slowlog:stream"
+
+	client.Del(zSetSlow, slowStream)
+	slowLog := NewSlowLog(2, SlowLogThresholds{Candidates: 1}, WithSlowLogStream(client, slowStream, 0))
+	geoClient := NewGeoClient(client, bitDepth, WithSlowLog(slowLog))
+	geoClient.AddCoordinates(zSetSlow, GeoKey{Label: "a", Lat: 52.52, Lon: 13.405}, GeoKey{Label: "b", Lat: 48.137, Lon: 11.575})
+
+	geoClient.Search(zSetSlow, 52.52, 13.405, 1000)
+	geoClient.Search(zSetSlow, 0, 0, 1000)
+	geoClient.Search(zSetSlow, 48.137, 11.575, 2000)
+	geoClient.Search(zSetSlow, 52.52, 13.405, 3000)
+
+	entries := slowLog.Entries()
+	if len(entries) != 2 || entries[0].Radius != 3000 || entries[1].Radius != 2000 {
+		t.Logf("expected the 2 latest searches reading candidates got %+v\n", entries)
+		t.FailNow()
+	}
+
+	if entry := entries[0]; entry.Bucket != zSetSlow || len(entry.Ranges) == 0 || entry.Candidates == 0 ||
+		entry.Results != 1 || entry.Duration < entry.Fetch {
+		t.Logf("expected the search parameters and timings got %+v\n", entry)
+		t.Fail()
+	}
+
+	stored, err := ReadSlowLog(client, slowStream, 10)
+	if err != nil || len(stored) != 3 || stored[0].Radius != 3000 || stored[0].ID == "" || len(stored[0].Ranges) != len(entries[0].Ranges) {
+		t.Logf("expected the 3 slow searches in the stream got %+v, %v\n", stored, err)
+		t.Fail()
+	}
+
+	slowLog.Reset()
+	if entries := slowLog.Entries(); len(entries) != 0 {
+		t.Logf("expected an empty slow log got %+v\n", entries)
+		t.Fail()
+	}
+}
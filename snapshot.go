@@ -0,0 +1,191 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+// ErrNoSnapshot is returned when a bucket has no snapshot to search
+var ErrNoSnapshot = errors.New("no snapshot found")
+
+type (
+	// Snapshot is a copy of a bucket taken at Time
+	Snapshot struct {
+		Key  string
+		Time time.Time
+	}
+
+	// Snapshotter periodically takes snapshots of a bucket
+	//
+	// Snapshotters of the same bucket in other processes share a lock in redis, only one of them takes a snapshot
+	// per interval.
+	Snapshotter struct {
+		client     *redis.Client
+		bucketName string
+		interval   time.Duration
+		retention  time.Duration
+		onError    func(error)
+
+		done    chan struct{}
+		stopped sync.WaitGroup
+	}
+
+	// SnapshotterOption configures a Snapshotter
+	SnapshotterOption func(*Snapshotter)
+)
+
+// WithSnapshotErrorHandler sets the function receiving failed snapshots
+func WithSnapshotErrorHandler(handler func(error)) SnapshotterOption {
+	return func(s *Snapshotter) {
+		s.onError = handler
+	}
+}
+
+// TakeSnapshot copies the members of a bucket to a key of its own which expires after retention, it requires redis
+// 6.2 or newer
+//
+// Payloads are not part of snapshots. Snapshots older than retention are dropped from the list of snapshots.
+func TakeSnapshot(client *redis.Client, bucketName string, retention time.Duration) (Snapshot, error) {
+	now := time.Now()
+	snapshot := Snapshot{Key: bucketName + ":snapshot:" + formatStreamID(now), Time: now}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	_, err := multi.Exec(func() error {
+		multi.Process(redis.NewCmd("ZRANGESTORE", snapshot.Key, bucketName, "0", "-1"))
+		multi.ZAdd(snapshotIndexKey(bucketName), redis.Z{Score: unixScore(now), Member: snapshot.Key})
+		if retention > 0 {
+			multi.Expire(snapshot.Key, retention)
+			multi.ZRemRangeByScore(snapshotIndexKey(bucketName), "-inf", "("+formatUnixScore(now.Add(-retention)))
+		}
+		return nil
+	})
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+// NearestSnapshot returns the snapshot of a bucket taken closest to at or ErrNoSnapshot
+func NearestSnapshot(client *redis.Client, bucketName string, at time.Time) (Snapshot, error) {
+	score := formatUnixScore(at)
+	before, err := client.ZRevRangeByScoreWithScores(snapshotIndexKey(bucketName), redis.ZRangeByScore{
+		Min: "-inf", Max: score, Count: 1,
+	}).Result()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	after, err := client.ZRangeByScoreWithScores(snapshotIndexKey(bucketName), redis.ZRangeByScore{
+		Min: score, Max: "+inf", Count: 1,
+	}).Result()
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	candidates := append(before, after...)
+	if len(candidates) == 0 {
+		return Snapshot{}, ErrNoSnapshot
+	}
+
+	nearest := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if abs(candidate.Score-unixScore(at)) < abs(nearest.Score-unixScore(at)) {
+			nearest = candidate
+		}
+	}
+
+	return Snapshot{Key: nearest.Member, Time: time.UnixMilli(int64(nearest.Score * 1000))}, nil
+}
+
+// SearchAsOf searches the snapshot of a bucket taken closest to at like Search
+func SearchAsOf(client *redis.Client, bucketName string, at time.Time, lat, lon, radius float64, bitDepth uint8, options ...SearchOption) ([]Result, error) {
+	snapshot, err := NearestSnapshot(client, bucketName, at)
+	if err != nil {
+		return []Result{}, err
+	}
+
+	return Search(client, snapshot.Key, lat, lon, radius, bitDepth, options...)
+}
+
+// StartSnapshots takes a snapshot of a bucket every interval, keeping each for retention
+func StartSnapshots(client *redis.Client, bucketName string, interval, retention time.Duration, options ...SnapshotterOption) (*Snapshotter, error) {
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+
+	s := &Snapshotter{
+		client:     client,
+		bucketName: bucketName,
+		interval:   interval,
+		retention:  retention,
+		onError:    func(error) {},
+		done:       make(chan struct{}),
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	s.stopped.Add(1)
+	go s.loop()
+
+	return s, nil
+}
+
+// Close stops taking snapshots
+func (s *Snapshotter) Close() error {
+	close(s.done)
+	s.stopped.Wait()
+
+	return nil
+}
+
+func (s *Snapshotter) loop() {
+	defer s.stopped.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.snapshot(); err != nil {
+			s.onError(err)
+		}
+
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Snapshotter) snapshot() error {
+	// the lock expires slightly before the next tick so the holder isn't locked out by its own lock
+	if locked, err := acquireLock(s.client, s.bucketName+":snapshot:lock", s.interval*9/10); err != nil || !locked {
+		return err
+	}
+
+	_, err := TakeSnapshot(s.client, s.bucketName, s.retention)
+	return err
+}
+
+func snapshotIndexKey(bucketName string) string {
+	return bucketName + ":snapshots"
+}
+
+func abs(value float64) float64 {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
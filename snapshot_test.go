@@ -0,0 +1,51 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSearchAsOf(t *testing.T) {
+	const zSetSnapshot = "test:snapshot"
+
+	client.Del(zSetSnapshot, zSetSnapshot+":snapshots")
+
+	if _, err := SearchAsOf(client, zSetSnapshot, time.Now(), 52.52, 13.405, 1000, bitDepth); err != ErrNoSnapshot {
+		t.Logf("expected ErrNoSnapshot got %v\n", err)
+		t.Fail()
+	}
+
+	AddCoordinates(client, zSetSnapshot, bitDepth, GeoKey{Label: "before", Lat: 52.52, Lon: 13.405})
+	first, err := TakeSnapshot(client, zSetSnapshot, time.Hour)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	time.Sleep(20 * time.Millisecond)
+	AddCoordinates(client, zSetSnapshot, bitDepth, GeoKey{Label: "after", Lat: 52.521, Lon: 13.405})
+	second, err := TakeSnapshot(client, zSetSnapshot, time.Hour)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	defer client.Del(first.Key, second.Key)
+
+	results, err := SearchAsOf(client, zSetSnapshot, first.Time.Add(time.Millisecond), 52.52, 13.405, 1000, bitDepth)
+	if err != nil || len(results) != 1 || results[0].Label != "before" {
+		t.Logf("expected only the member of the first snapshot got %v error %v\n", results, err)
+		t.Fail()
+	}
+
+	results, err = SearchAsOf(client, zSetSnapshot, second.Time.Add(time.Hour), 52.52, 13.405, 1000, bitDepth)
+	if err != nil || len(results) != 2 {
+		t.Logf("expected both members in the second snapshot got %v error %v\n", results, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,311 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/redis.v2"
+)
+
+// ErrUnsupportedOption is returned by CoreClient.Search for search options which need more than a CoreStore
+var ErrUnsupportedOption = errors.New("search option not supported by CoreClient")
+
+type (
+	// CoreStore is the subset of redis commands the core operations of CoreClient need, implement it to add, remove,
+	// get, count, list and search coordinates through another redis client
+	//
+	// Ranges are inclusive on both ends and a count of 0 returns all members of a range. Missing hash fields
+	// are returned as nil.
+	CoreStore interface {
+		ZAdd(key string, members ...Member) (int64, error)
+		ZRem(key string, members ...string) (int64, error)
+		// ZScore returns ErrMemberNotFound when the member is not part of the set
+		ZScore(key, member string) (float64, error)
+		ZCard(key string) (int64, error)
+		ZRange(key string, start, stop int64) ([]Member, error)
+		ZRangeByScore(key string, min, max float64, offset, count int64) ([]Member, error)
+		HSet(key string, values map[string][]byte) error
+		HMGet(key string, fields ...string) ([][]byte, error)
+		HDel(key string, fields ...string) (int64, error)
+	}
+
+	// Member is an entry of a sorted set
+	Member struct {
+		Label string
+		Score float64
+	}
+
+	// CoreClient runs the core operations on top of a CoreStore, it is a limited adapter and not a pluggable backend
+	//
+	// GeoClient, the package functions and every other feature like fences, trackers, histories or tags need a
+	// *redis.Client as they rely on scripts, transactions and commands outside of CoreStore.
+	CoreClient struct {
+		store    CoreStore
+		bitDepth uint8
+	}
+
+	redisStore struct {
+		client *redis.Client
+	}
+)
+
+// NewCoreClient returns a CoreClient using the store and bit depth
+func NewCoreClient(store CoreStore, bitDepth uint8) *CoreClient {
+	return &CoreClient{store: store, bitDepth: bitDepth}
+}
+
+// NewRedisStore returns a CoreStore backed by a gopkg.in/redis.v2 client
+func NewRedisStore(client *redis.Client) CoreStore {
+	return redisStore{client: client}
+}
+
+// Store returns the underlying store
+func (c *CoreClient) Store() CoreStore {
+	return c.store
+}
+
+// AddCoordinates adds coordinates to the set, payloads are written after the coordinates
+func (c *CoreClient) AddCoordinates(bucketName string, coordinates ...GeoKey) (int64, error) {
+	if err := ValidateBitDepth(c.bitDepth); err != nil {
+		return 0, err
+	}
+	if err := ValidateCoordinates(coordinates...); err != nil {
+		return 0, err
+	}
+
+	members := make([]Member, len(coordinates))
+	payloads := map[string][]byte{}
+	for idx, coordinate := range coordinates {
+		members[idx] = Member{
+			Label: coordinate.Label,
+			Score: float64(geohashEncoder{bitDepth: c.bitDepth}.EncodeInt(coordinate.Lat, coordinate.Lon)),
+		}
+		if coordinate.Payload != nil {
+			payloads[coordinate.Label] = coordinate.Payload
+		}
+	}
+
+	added, err := c.store.ZAdd(bucketName, members...)
+	if err != nil || len(payloads) == 0 {
+		return added, err
+	}
+
+	return added, c.store.HSet(payloadKey(bucketName), payloads)
+}
+
+// RemoveCoordinatesByKeys removes coordinates, their payloads and attributes from the set
+func (c *CoreClient) RemoveCoordinatesByKeys(bucketName string, coordinatesKeys ...string) (int64, error) {
+	removed, err := c.store.ZRem(bucketName, coordinatesKeys...)
+	if err != nil {
+		return removed, err
+	}
+
+	if _, err = c.store.HDel(payloadKey(bucketName), coordinatesKeys...); err != nil {
+		return removed, err
+	}
+
+	_, err = c.store.HDel(attributesKey(bucketName), coordinatesKeys...)
+	return removed, err
+}
+
+// GetCoordinates returns the decoded coordinates of a label or ErrMemberNotFound
+func (c *CoreClient) GetCoordinates(bucketName, label string) (GeoKey, error) {
+	score, err := c.store.ZScore(bucketName, label)
+	if err != nil {
+		return GeoKey{}, err
+	}
+
+	lat, lon := geohashEncoder{bitDepth: c.bitDepth}.DecodeInt(uint64(score))
+	return GeoKey{Lat: lat, Lon: lon, Label: label}, nil
+}
+
+// CountCoordinates returns the number of coordinates in the set
+func (c *CoreClient) CountCoordinates(bucketName string) (int64, error) {
+	return c.store.ZCard(bucketName)
+}
+
+// ListCoordinates returns up to count coordinates from the set starting at offset, in the order they are stored
+func (c *CoreClient) ListCoordinates(bucketName string, offset, count int64) ([]GeoKey, error) {
+	if count <= 0 {
+		return []GeoKey{}, nil
+	}
+
+	members, err := c.store.ZRange(bucketName, offset, offset+count-1)
+	if err != nil {
+		return []GeoKey{}, err
+	}
+
+	coordinates := make([]GeoKey, len(members))
+	for idx := range members {
+		lat, lon := geohashEncoder{bitDepth: c.bitDepth}.DecodeInt(uint64(members[idx].Score))
+		coordinates[idx] = GeoKey{Lat: lat, Lon: lon, Label: members[idx].Label}
+	}
+
+	return coordinates, nil
+}
+
+// Search returns all members which are in a certain range from the provided lat & lon coordinates, nearest first,
+// together with their coordinates and distance
+//
+// It supports WithLimit, WithPayloads, WithFailFast, WithDistance, WithUnit and WithTuning, the other options
+// return ErrUnsupportedOption.
+func (c *CoreClient) Search(bucketName string, lat, lon, radius float64, options ...SearchOption) ([]Result, error) {
+	opts := newSearchOptions(options)
+	if err := opts.coreSupported(); err != nil {
+		return []Result{}, err
+	}
+	radius = opts.unit.ToMeters(radius)
+
+	ranges, _, err := planRanges(lat, lon, radius, c.bitDepth, opts.tuning)
+	if err != nil {
+		return []Result{}, err
+	}
+
+	candidates := getCandidates()
+	defer func() { releaseCandidates(candidates) }()
+
+	errs := rangeErrors{failFast: opts.failFast}
+	for _, r := range ranges {
+		members, err := c.store.ZRangeByScore(bucketName, r.Lower, r.Upper, 0, 0)
+		if err != nil {
+			if errs.add(r, err) {
+				return []Result{}, errs.err()
+			}
+			continue
+		}
+		for _, member := range members {
+			candidates = append(candidates, redis.Z{Member: member.Label, Score: member.Score})
+		}
+	}
+
+	results := rankResults(lat, lon, radius, c.bitDepth, candidates, opts.limit, opts.distance)
+	convertDistances(results, opts.unit)
+
+	if opts.withPayloads && len(results) > 0 {
+		labels := make([]string, len(results))
+		for idx := range results {
+			labels[idx] = results[idx].Label
+		}
+
+		payloads, err := c.store.HMGet(payloadKey(bucketName), labels...)
+		if err != nil {
+			return []Result{}, err
+		}
+		for idx := range results {
+			results[idx].Payload = payloads[idx]
+		}
+	}
+
+	return results, errs.err()
+}
+
+// coreSupported returns an error naming the first option CoreClient.Search can't honour
+func (o searchOptions) coreSupported() error {
+	unsupported := ""
+	switch {
+	case o.withMotion:
+		unsupported = "WithMotion"
+	case o.withAttributes:
+		unsupported = "WithAttributes"
+	case len(o.filters) > 0:
+		unsupported = "WithFilter"
+	case o.labelMatch != "":
+		unsupported = "WithLabelMatch"
+	case o.freshness > 0:
+		unsupported = "WithFreshness"
+	case !o.updatedSince.IsZero():
+		unsupported = "WithUpdatedSince"
+	case o.geohashPrecision > 0:
+		unsupported = "WithGeohash"
+	default:
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnsupportedOption, unsupported)
+}
+
+func (s redisStore) ZAdd(key string, members ...Member) (int64, error) {
+	z := make([]redis.Z, len(members))
+	for idx := range members {
+		z[idx] = redis.Z{Member: members[idx].Label, Score: members[idx].Score}
+	}
+
+	return s.client.ZAdd(key, z...).Result()
+}
+
+func (s redisStore) ZRem(key string, members ...string) (int64, error) {
+	return s.client.ZRem(key, members...).Result()
+}
+
+func (s redisStore) ZScore(key, member string) (float64, error) {
+	cmd := s.client.ZScore(key, member)
+	if cmd.Err() == redis.Nil {
+		return 0, ErrMemberNotFound
+	}
+
+	return cmd.Val(), cmd.Err()
+}
+
+func (s redisStore) ZCard(key string) (int64, error) {
+	return s.client.ZCard(key).Result()
+}
+
+func (s redisStore) ZRange(key string, start, stop int64) ([]Member, error) {
+	z, err := s.client.ZRangeWithScores(key, start, stop).Result()
+	return membersFromZ(z), err
+}
+
+func (s redisStore) ZRangeByScore(key string, min, max float64, offset, count int64) ([]Member, error) {
+	z, err := s.client.ZRangeByScoreWithScores(key, geoRange{Lower: min, Upper: max}.query(offset, count)).Result()
+	return membersFromZ(z), err
+}
+
+func (s redisStore) HSet(key string, values map[string][]byte) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	pairs := make([]string, 0, len(values)*2)
+	for field, value := range values {
+		pairs = append(pairs, field, string(value))
+	}
+
+	return s.client.HMSet(key, pairs[0], pairs[1], pairs[2:]...).Err()
+}
+
+func (s redisStore) HMGet(key string, fields ...string) ([][]byte, error) {
+	values := make([][]byte, len(fields))
+	if len(fields) == 0 {
+		return values, nil
+	}
+
+	reply, err := s.client.HMGet(key, fields...).Result()
+	if err != nil {
+		return values, err
+	}
+	for idx := range reply {
+		if value, ok := reply[idx].(string); ok {
+			values[idx] = []byte(value)
+		}
+	}
+
+	return values, nil
+}
+
+func (s redisStore) HDel(key string, fields ...string) (int64, error) {
+	return s.client.HDel(key, fields...).Result()
+}
+
+func membersFromZ(z []redis.Z) []Member {
+	members := make([]Member, len(z))
+	for idx := range z {
+		members[idx] = Member{Label: z[idx].Member, Score: z[idx].Score}
+	}
+
+	return members
+}
@@ -0,0 +1,60 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+	"github.com/tapglue/georedis/memorystore"
+)
+
+func TestCoreClient(t *testing.T) {
+	const zSetStore = "test:store"
+
+	coreClient := NewCoreClient(NewRedisStore(client), bitDepth)
+	coreClient.RemoveCoordinatesByKeys(zSetStore, "Lisbon", "Porto")
+
+	added, err := coreClient.AddCoordinates(zSetStore,
+		GeoKey{Lat: 38.7223, Lon: -9.1393, Label: "Lisbon", Payload: []byte("capital")},
+		GeoKey{Lat: 41.1579, Lon: -8.6291, Label: "Porto"},
+	)
+	if err != nil || added != 2 {
+		t.Logf("expected to add 2 added %d error %v\n", added, err)
+		t.Fail()
+	}
+
+	results, err := coreClient.Search(zSetStore, 38.7223, -9.1393, 1000, WithPayloads())
+	if err != nil || len(results) != 1 || results[0].Label != "Lisbon" || string(results[0].Payload) != "capital" {
+		t.Logf("unexpected results %v error %v\n", results, err)
+		t.Fail()
+	}
+
+	if _, err := coreClient.GetCoordinates(zSetStore, "Faro"); err != ErrMemberNotFound {
+		t.Logf("expected ErrMemberNotFound got %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestCoreClientUnsupportedOptions(t *testing.T) {
+	coreClient := NewCoreClient(memorystore.New(), bitDepth)
+	coreClient.AddCoordinates("test:store:options", GeoKey{Lat: 38.7223, Lon: -9.1393, Label: "Lisbon"})
+
+	for _, option := range []SearchOption{WithLabelMatch("L*"), WithFreshness(time.Minute), WithFilter(Equal("kind", "city")), WithMotion()} {
+		if _, err := coreClient.Search("test:store:options", 38.7223, -9.1393, 1000, option); !errors.Is(err, ErrUnsupportedOption) {
+			t.Logf("expected ErrUnsupportedOption got %v\n", err)
+			t.Fail()
+		}
+	}
+
+	results, err := coreClient.Search("test:store:options", 38.7223, -9.1393, 1000, WithTuning(SearchTuning{MinCells: 8}))
+	if err != nil || len(results) != 1 {
+		t.Logf("expected the tuned search to find Lisbon got %v error %v\n", results, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,131 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"context"
+	"fmt"
+
+	"gopkg.in/redis.v2"
+)
+
+// ResultStream receives the results of a streamed search, see SearchByRadiusStream
+type ResultStream struct {
+	results chan Result
+	err     error
+	stopped chan struct{}
+}
+
+// SearchByRadiusChunked finds the same members as Search but pages through every range chunkSize members
+// at a time and passes each chunk to fn as soon as it is decoded, so memory stays bounded for dense areas
+//
+// Chunks are not ordered by distance and fn must not retain them after returning. Returning an error from fn
+// stops the search and returns that error.
+func SearchByRadiusChunked(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, chunkSize int64, fn func([]Result) error) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("chunkSize must be positive")
+	}
+
+	ranges, err := queryRanges(lat, lon, radius, bitDepth)
+	if err != nil {
+		return err
+	}
+
+	return searchChunked(context.Background(), client, bucketName, lat, lon, radius, bitDepth, ranges, chunkSize, fn)
+}
+
+// SearchByRadiusStream finds the same members as Search and sends them to the channel of the returned stream as
+// soon as their range page is decoded, so responses can be written before the search is complete
+//
+// Results are not ordered by distance. Cancelling ctx stops the search before the next range query, the channel is
+// closed when the search ended and Err returns ctx.Err() or the error of a failed query. Callers which stop
+// receiving before the channel is closed must cancel ctx.
+func SearchByRadiusStream(ctx context.Context, client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8) (*ResultStream, error) {
+	ranges, err := queryRanges(lat, lon, radius, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ResultStream{results: make(chan Result), stopped: make(chan struct{})}
+	go func() {
+		defer close(s.stopped)
+		defer close(s.results)
+
+		s.err = searchChunked(ctx, client, bucketName, lat, lon, radius, bitDepth, ranges, scanPageSize, func(chunk []Result) error {
+			for _, result := range chunk {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case s.results <- result:
+				}
+			}
+			return nil
+		})
+	}()
+
+	return s, nil
+}
+
+// Results returns the channel receiving the results, it is closed when the search ended
+func (s *ResultStream) Results() <-chan Result {
+	return s.results
+}
+
+// Err returns the error which ended the search early, it waits until the results channel is closed
+func (s *ResultStream) Err() error {
+	<-s.stopped
+	return s.err
+}
+
+// SearchByRadiusStream streams the members within radius meters of lat & lon, see SearchByRadiusStream
+func (c *GeoClient) SearchByRadiusStream(ctx context.Context, bucketName string, lat, lon, radius float64) (*ResultStream, error) {
+	return handleOp(c, Operation{Name: "SearchByRadiusStream", Bucket: bucketName, Args: []any{lat, lon, radius}}, func() (*ResultStream, error) {
+		key := c.key(bucketName)
+		return SearchByRadiusStream(ctx, c.reader(key), key, lat, lon, radius, c.bitDepth)
+	})
+}
+
+// searchChunked pages through the ranges and passes the results within radius to fn, it checks ctx before every
+// range query
+func searchChunked(ctx context.Context, client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, ranges []geoRange, chunkSize int64, fn func([]Result) error) error {
+	chunk := make([]Result, 0, chunkSize)
+	for key := range ranges {
+		for offset := int64(0); ; offset += chunkSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			members, err := client.ZRangeByScoreWithScores(bucketName, ranges[key].query(offset, chunkSize)).Result()
+			if err != nil {
+				return err
+			}
+			if len(members) == 0 {
+				break
+			}
+
+			chunk = chunk[:0]
+			for idx := range members {
+				if result := decodeResult(lat, lon, bitDepth, members[idx], Haversine); result.Distance <= radius {
+					chunk = append(chunk, result)
+				}
+			}
+			if len(chunk) > 0 {
+				if err := fn(chunk); err != nil {
+					return err
+				}
+			}
+
+			if int64(len(members)) < chunkSize {
+				break
+			}
+		}
+	}
+
+	return nil
+}
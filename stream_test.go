@@ -0,0 +1,90 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSearchByRadiusChunked(t *testing.T) {
+	const zSetChunked = "test:search:chunked"
+
+	coordinates := make([]GeoKey, 25)
+	for idx := range coordinates {
+		coordinates[idx] = GeoKey{Lat: 39.9523 + float64(idx)*0.0001, Lon: -75.1638, Label: string(rune('a' + idx))}
+	}
+	client.Del(zSetChunked)
+	AddCoordinates(client, zSetChunked, bitDepth, coordinates...)
+
+	seen := map[string]bool{}
+	err := SearchByRadiusChunked(client, zSetChunked, 39.9523, -75.1638, 1000, bitDepth, 10, func(chunk []Result) error {
+		if len(chunk) > 10 {
+			t.Logf("chunk larger than requested: %d\n", len(chunk))
+			t.Fail()
+		}
+		for _, result := range chunk {
+			seen[result.Label] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Logf("error encountered: %q\n", err)
+		t.Fail()
+	}
+	if len(seen) != len(coordinates) {
+		t.Logf("expected %d members got %d\n", len(coordinates), len(seen))
+		t.Fail()
+	}
+
+	stop := errors.New("stop")
+	err = SearchByRadiusChunked(client, zSetChunked, 39.9523, -75.1638, 1000, bitDepth, 10, func(chunk []Result) error {
+		return stop
+	})
+	if err != stop {
+		t.Logf("expected the callback error got %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestSearchByRadiusStream(t *testing.T) {
+	const zSetStream = "test:search:stream"
+
+	coordinates := make([]GeoKey, 25)
+	for idx := range coordinates {
+		coordinates[idx] = GeoKey{Lat: 39.9523 + float64(idx)*0.0001, Lon: -75.1638, Label: string(rune('a' + idx))}
+	}
+	client.Del(zSetStream)
+	AddCoordinates(client, zSetStream, bitDepth, coordinates...)
+
+	stream, err := SearchByRadiusStream(context.Background(), client, zSetStream, 39.9523, -75.1638, 1000, bitDepth)
+	if err != nil {
+		t.Logf("expected the stream to start got %v\n", err)
+		t.FailNow()
+	}
+	received := 0
+	for range stream.Results() {
+		received++
+	}
+	if stream.Err() != nil || received != len(coordinates) {
+		t.Logf("expected %d results got %d, %v\n", len(coordinates), received, stream.Err())
+		t.Fail()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, _ = SearchByRadiusStream(ctx, client, zSetStream, 39.9523, -75.1638, 1000, bitDepth)
+	<-stream.Results()
+	cancel()
+	for range stream.Results() {
+	}
+	if !errors.Is(stream.Err(), context.Canceled) {
+		t.Logf("expected the cancelled search to end with context.Canceled got %v\n", stream.Err())
+		t.Fail()
+	}
+}
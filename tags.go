@@ -0,0 +1,185 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+
+	"gopkg.in/redis.v2"
+)
+
+// setTagsLuaBody replaces the tags of a member unless it isn't part of the bucket
+//
+// KEYS are the bucket and the tags of its members, ARGV holds the prefix of the tag sets, the label and its tags.
+// The tags of every member are stored as a JSON array so they can be removed from their tag sets later. The reply
+// is 0 when the label is not a member.
+const setTagsLuaBody = `
+if not redis.call('ZSCORE', KEYS[1], ARGV[2]) then
+  return 0
+end
+
+local previous = redis.call('HGET', KEYS[2], ARGV[2])
+if previous then
+  for _, tag in ipairs(cjson.decode(previous)) do
+    redis.call('SREM', ARGV[1] .. tag, ARGV[2])
+  end
+end
+
+local tags = {}
+for i = 3, #ARGV do
+  redis.call('SADD', ARGV[1] .. ARGV[i], ARGV[2])
+  tags[#tags + 1] = ARGV[i]
+end
+
+if #tags == 0 then
+  redis.call('HDEL', KEYS[2], ARGV[2])
+else
+  redis.call('HSET', KEYS[2], ARGV[2], cjson.encode(tags))
+end
+
+return 1
+`
+
+// clearTagsLuaBody removes members from all their tag sets
+//
+// KEYS[1] holds the tags of the members, ARGV holds the prefix of the tag sets followed by the labels.
+const clearTagsLuaBody = `
+for i = 2, #ARGV do
+  local previous = redis.call('HGET', KEYS[1], ARGV[i])
+  if previous then
+    for _, tag in ipairs(cjson.decode(previous)) do
+      redis.call('SREM', ARGV[1] .. tag, ARGV[i])
+    end
+    redis.call('HDEL', KEYS[1], ARGV[i])
+  end
+end
+
+return 0
+`
+
+var (
+	setTagsScript   = newLuaScript(setTagsLuaBody)
+	clearTagsScript = newLuaScript(clearTagsLuaBody)
+)
+
+// SetTags replaces the tags of a member or returns ErrMemberNotFound, no tags remove all of them
+//
+// Every tag is a set of labels, like <bucket>:tag:restaurant. The tag sets are derived from the bucket name inside
+// a script, so buckets with tags can't be used with redis cluster. RemoveCoordinatesByKeys and the prunes remove the
+// tags together with their member.
+func SetTags(client *redis.Client, bucketName, label string, tags ...string) error {
+	args := append([]string{tagKey(bucketName, ""), label}, tags...)
+	reply, err := setTagsScript.run(client, []string{bucketName, tagsKey(bucketName)}, args)
+	if err != nil {
+		return err
+	}
+	if stored, ok := reply.(int64); !ok || stored == 0 {
+		return ErrMemberNotFound
+	}
+
+	return nil
+}
+
+// GetTags returns the tags of a member, empty if it has none
+func GetTags(client *redis.Client, bucketName, label string) ([]string, error) {
+	value, err := client.HGet(tagsKey(bucketName), label).Result()
+	if err == redis.Nil {
+		return []string{}, nil
+	}
+	if err != nil {
+		return []string{}, err
+	}
+
+	tags := []string{}
+	if err := json.Unmarshal([]byte(value), &tags); err != nil {
+		return []string{}, fmt.Errorf("invalid tags of %q: %s", label, err)
+	}
+
+	return tags, nil
+}
+
+// SearchByRadiusWithTags searches like Search for members having all tags
+//
+// The bucket is intersected with the tag sets inside redis with ZINTERSTORE into a temporary key, which is searched
+// instead of the bucket, so only members with the tags are read.
+func SearchByRadiusWithTags(client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, tags []string, options ...SearchOption) ([]Result, error) {
+	if len(tags) == 0 {
+		return Search(client, bucketName, lat, lon, radius, bitDepth, options...)
+	}
+
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, bucketName)
+	weights := make([]int64, 0, len(tags)+1)
+	weights = append(weights, 1)
+	for _, tag := range tags {
+		keys = append(keys, tagKey(bucketName, tag))
+		weights = append(weights, 0)
+	}
+
+	key := bucketName + ":tagsearch:" + strconv.FormatUint(rand.Uint64(), 36)
+	defer client.Del(key)
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	_, err := multi.Exec(func() error {
+		multi.ZInterStore(key, redis.ZStore{Weights: weights}, keys...)
+		multi.Expire(key, setOperationTTL)
+		return nil
+	})
+	if err != nil {
+		return []Result{}, err
+	}
+
+	return searchKey(client, key, bucketName, lat, lon, radius, bitDepth, newSearchOptions(options))
+}
+
+// SetTags replaces the tags of a member or returns ErrMemberNotFound
+func (c *GeoClient) SetTags(bucketName, label string, tags ...string) error {
+	return c.handle(Operation{Name: "SetTags", Bucket: bucketName, Write: true, Args: []any{label, tags}}, func() error {
+		key := c.key(bucketName)
+		defer c.wrote(key)
+		err := c.do(func() error {
+			return SetTags(c.client, key, label, tags...)
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.audit.record(c.client, AuditUpdate, key, []string{label}, "set tags")
+	})
+}
+
+// SearchByRadiusWithTags searches like Search for members having all tags
+func (c *GeoClient) SearchByRadiusWithTags(bucketName string, lat, lon, radius float64, tags []string, options ...SearchOption) ([]Result, error) {
+	return handleOp(c, Operation{Name: "SearchByRadiusWithTags", Bucket: bucketName, Args: []any{lat, lon, radius, tags, options}}, func() ([]Result, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() ([]Result, error) {
+			return SearchByRadiusWithTags(c.client, key, lat, lon, radius, c.bitDepth, tags, options...)
+		})
+	})
+}
+
+// clearTags removes the labels from all their tag sets
+func clearTags(client *redis.Client, bucketName string, labels ...string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	_, err := clearTagsScript.run(client, []string{tagsKey(bucketName)}, append([]string{tagKey(bucketName, "")}, labels...))
+	return err
+}
+
+func tagKey(bucketName, tag string) string {
+	return bucketName + ":tag:" + tag
+}
+
+func tagsKey(bucketName string) string {
+	return bucketName + ":tags"
+}
@@ -0,0 +1,83 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestSearchByRadiusWithTags(t *testing.T) {
+	const zSetTags = "test:tags"
+
+	client.Del(zSetTags, zSetTags+":tags", zSetTags+":tag:restaurant", zSetTags+":tag:vegan", zSetTags+":tag:bar")
+	AddCoordinates(client, zSetTags, bitDepth,
+		GeoKey{Label: "diner", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "salad", Lat: 52.521, Lon: 13.405},
+		GeoKey{Label: "pub", Lat: 52.522, Lon: 13.405},
+	)
+	SetTags(client, zSetTags, "diner", "restaurant")
+	SetTags(client, zSetTags, "salad", "restaurant", "vegan")
+	SetTags(client, zSetTags, "pub", "bar")
+
+	if err := SetTags(client, zSetTags, "missing", "bar"); err != ErrMemberNotFound {
+		t.Logf("expected ErrMemberNotFound got %v\n", err)
+		t.Fail()
+	}
+
+	results, err := SearchByRadiusWithTags(client, zSetTags, 52.52, 13.405, 1000, bitDepth, []string{"restaurant"})
+	if err != nil || len(results) != 2 || results[0].Label != "diner" {
+		t.Logf("expected both restaurants got %v, %v\n", results, err)
+		t.Fail()
+	}
+
+	results, err = SearchByRadiusWithTags(client, zSetTags, 52.52, 13.405, 1000, bitDepth, []string{"restaurant", "vegan"})
+	if err != nil || len(results) != 1 || results[0].Label != "salad" {
+		t.Logf("expected the vegan restaurant got %v, %v\n", results, err)
+		t.Fail()
+	}
+
+	SetTags(client, zSetTags, "salad", "bar")
+	if tags, err := GetTags(client, zSetTags, "salad"); err != nil || len(tags) != 1 || tags[0] != "bar" {
+		t.Logf("expected the tags to be replaced got %v, %v\n", tags, err)
+		t.Fail()
+	}
+
+	RemoveCoordinatesByKeys(client, zSetTags, "pub")
+	if members := client.SMembers(zSetTags + ":tag:bar").Val(); len(members) != 1 || members[0] != "salad" {
+		t.Logf("expected the removed member to leave its tag sets got %v\n", members)
+		t.Fail()
+	}
+}
+
+func TestPruneStaleClearsTags(t *testing.T) {
+	const zSetPruned = "test:tags:pruned"
+
+	client.Del(zSetPruned, zSetPruned+":seen", zSetPruned+":tags", zSetPruned+":tag:bar", zSetPruned+":name", zSetPruned+":names")
+	AddCoordinates(client, zSetPruned, bitDepth, GeoKey{Label: "pub", Lat: 52.52, Lon: 13.405})
+	SetTags(client, zSetPruned, "pub", "bar")
+	SetNames(client, zSetPruned, map[string]string{"pub": "Kneipe"})
+	TouchMembers(client, zSetPruned, time.Now().Add(-time.Hour), "pub")
+
+	if pruned, err := PruneStale(client, zSetPruned, bitDepth, time.Minute); err != nil || pruned != 1 {
+		t.Logf("expected the stale member to be pruned got %d, %v\n", pruned, err)
+		t.FailNow()
+	}
+
+	AddCoordinates(client, zSetPruned, bitDepth, GeoKey{Label: "pub", Lat: 52.52, Lon: 13.405})
+	results, err := SearchByRadiusWithTags(client, zSetPruned, 52.52, 13.405, 1000, bitDepth, []string{"bar"})
+	if err != nil || len(results) != 0 {
+		t.Logf("expected the re-added member to lose its old tags got %v, %v\n", results, err)
+		t.Fail()
+	}
+
+	if report, err := VerifyBucket(client, zSetPruned); err != nil || !report.Consistent() {
+		t.Logf("expected no orphans after pruning got %v, %v\n", report, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,161 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+// maxSearchSlices bounds the slices a single search of a SlicedBucket reads
+const maxSearchSlices = 1024
+
+var (
+	// ErrInvalidSlice is returned for slice durations which are not positive
+	ErrInvalidSlice = errors.New("slice must be positive")
+	// ErrTooManySlices is returned for searches over more than 1024 slices
+	ErrTooManySlices = errors.New("time range covers too many slices")
+)
+
+// SlicedBucket partitions a bucket by time, every slice of the given duration is stored in its own bucket which
+// expires retention after the slice ended
+//
+// Data which is only interesting for a while, like check-ins, expires with its slices instead of being removed
+// member by member. Slices are aligned to the unix epoch, an hourly slice starts at the full hour in UTC.
+type SlicedBucket struct {
+	client     *redis.Client
+	bucketName string
+	bitDepth   uint8
+	slice      time.Duration
+	retention  time.Duration
+}
+
+// NewSlicedBucket returns a SlicedBucket storing slices of the duration slice for retention after they ended
+func NewSlicedBucket(client *redis.Client, bucketName string, bitDepth uint8, slice, retention time.Duration) (*SlicedBucket, error) {
+	if err := ValidateBitDepth(bitDepth); err != nil {
+		return nil, err
+	}
+	if slice <= 0 {
+		return nil, ErrInvalidSlice
+	}
+
+	return &SlicedBucket{client: client, bucketName: bucketName, bitDepth: bitDepth, slice: slice, retention: max(retention, 0)}, nil
+}
+
+// AddCoordinates adds coordinates to the slice containing at and returns the number of new members of the slice
+func (b *SlicedBucket) AddCoordinates(at time.Time, coordinates ...GeoKey) (int64, error) {
+	if err := ValidateCoordinates(coordinates...); err != nil {
+		return 0, err
+	}
+	if len(coordinates) == 0 {
+		return 0, nil
+	}
+
+	key := b.SliceKey(at)
+	expires := b.start(at).Add(b.slice + b.retention)
+
+	encodedCoordinates := make([]redis.Z, len(coordinates))
+	payloads := []string{}
+	for idx, coordinate := range coordinates {
+		encodedCoordinates[idx] = redis.Z{
+			Score:  float64(geohashEncoder{bitDepth: b.bitDepth}.EncodeInt(coordinate.Lat, coordinate.Lon)),
+			Member: coordinate.Label,
+		}
+		if coordinate.Payload != nil {
+			payloads = append(payloads, coordinate.Label, string(coordinate.Payload))
+		}
+	}
+
+	multi := b.client.Multi()
+	defer multi.Close()
+
+	var added *redis.IntCmd
+	_, err := multi.Exec(func() error {
+		added = multi.ZAdd(key, encodedCoordinates...)
+		multi.ExpireAt(key, expires)
+		if len(payloads) > 0 {
+			multi.HMSet(payloadKey(key), payloads[0], payloads[1], payloads[2:]...)
+			multi.ExpireAt(payloadKey(key), expires)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return added.Val(), nil
+}
+
+// Search returns the members of the slices between from and to within radius of lat & lon, nearest first
+//
+// Members stored in several slices are returned once at their nearest location.
+func (b *SlicedBucket) Search(lat, lon, radius float64, from, to time.Time, options ...SearchOption) ([]Result, error) {
+	keys, err := b.SliceKeys(from, to)
+	if err != nil {
+		return []Result{}, err
+	}
+	opts := newSearchOptions(options)
+
+	results := []Result{}
+	var partial *PartialResultsError
+	for _, key := range keys {
+		found, err := Search(b.client, key, lat, lon, radius, b.bitDepth, options...)
+		if failed := (*PartialResultsError)(nil); errors.As(err, &failed) {
+			if partial == nil {
+				partial = &PartialResultsError{}
+			}
+			partial.Failed = append(partial.Failed, failed.Failed...)
+		} else if err != nil {
+			return []Result{}, err
+		}
+		results = append(results, found...)
+	}
+
+	slices.SortFunc(results, byDistance)
+	results = dedupeResults(results)
+	if opts.limit >= 0 && len(results) > opts.limit {
+		results = results[:opts.limit]
+	}
+
+	if partial != nil {
+		return results, partial
+	}
+
+	return results, nil
+}
+
+// SliceKey returns the bucket of the slice containing at
+func (b *SlicedBucket) SliceKey(at time.Time) string {
+	return b.bucketName + ":slice:" + strconv.FormatInt(b.start(at).Unix(), 10)
+}
+
+// SliceKeys returns the buckets of the slices between from and to, oldest first
+func (b *SlicedBucket) SliceKeys(from, to time.Time) ([]string, error) {
+	first, last := b.start(from), b.start(to)
+	if last.Before(first) {
+		return []string{}, nil
+	}
+	if last.Sub(first)/b.slice >= maxSearchSlices {
+		return []string{}, ErrTooManySlices
+	}
+
+	keys := []string{}
+	for start := first; !start.After(last); start = start.Add(b.slice) {
+		keys = append(keys, b.SliceKey(start))
+	}
+
+	return keys, nil
+}
+
+// start returns the start of the slice containing at, unlike time.Truncate aligned to the unix epoch
+func (b *SlicedBucket) start(at time.Time) time.Time {
+	nanos, slice := at.UnixNano(), int64(b.slice)
+	return time.Unix(0, nanos-((nanos%slice)+slice)%slice)
+}
@@ -0,0 +1,76 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+
+	"gopkg.in/redis.v2"
+)
+
+func TestSliceKeys(t *testing.T) {
+	bucket, err := NewSlicedBucket(nil, "checkins", bitDepth, time.Hour, time.Hour)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	from := time.Date(2024, 3, 1, 10, 30, 0, 0, time.FixedZone("CET", 3600))
+	keys, err := bucket.SliceKeys(from, from.Add(2*time.Hour))
+	expected := []string{"checkins:slice:1709283600", "checkins:slice:1709287200", "checkins:slice:1709290800"}
+	if err != nil || !slices.Equal(keys, expected) {
+		t.Logf("expected %v got %v error %v\n", expected, keys, err)
+		t.Fail()
+	}
+
+	if keys, err := bucket.SliceKeys(from, from.Add(-time.Hour)); err != nil || len(keys) != 0 {
+		t.Logf("expected no slices for a reversed range got %v error %v\n", keys, err)
+		t.Fail()
+	}
+	if _, err := bucket.SliceKeys(from, from.Add(2000*time.Hour)); err != ErrTooManySlices {
+		t.Logf("expected ErrTooManySlices got %v\n", err)
+		t.Fail()
+	}
+	if _, err := NewSlicedBucket(nil, "checkins", bitDepth, 0, time.Hour); err != ErrInvalidSlice {
+		t.Logf("expected ErrInvalidSlice got %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestSlicedBucketSearch(t *testing.T) {
+	bucket, _ := NewSlicedBucket(client, "test:checkins", bitDepth, time.Hour, time.Hour)
+
+	now := time.Now()
+	client.Del(bucket.SliceKey(now), bucket.SliceKey(now.Add(-time.Hour)))
+
+	bucket.AddCoordinates(now.Add(-time.Hour), GeoKey{Lat: 52.52, Lon: 13.405, Label: "a"}, GeoKey{Lat: 52.53, Lon: 13.405, Label: "b"})
+	if _, err := bucket.AddCoordinates(now, GeoKey{Lat: 52.521, Lon: 13.405, Label: "b"}); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	ttl := redis.NewCmd("TTL", bucket.SliceKey(now))
+	client.Process(ttl)
+	if seconds, err := ttl.Result(); err != nil || seconds.(int64) <= 3600 || seconds.(int64) > 7200 {
+		t.Logf("expected the slice to expire an hour after it ended got %v error %v\n", seconds, err)
+		t.Fail()
+	}
+
+	results, err := bucket.Search(52.52, 13.405, 5000, now.Add(-time.Hour), now)
+	if err != nil || !slices.Equal(labels(results), []string{"a", "b"}) || results[1].Lat > 52.525 {
+		t.Logf("expected a and the nearest location of b got %v error %v\n", results, err)
+		t.Fail()
+	}
+
+	if results, err := bucket.Search(52.52, 13.405, 5000, now, now); err != nil || !slices.Equal(labels(results), []string{"b"}) {
+		t.Logf("expected only b in the current slice got %v error %v\n", results, err)
+		t.Fail()
+	}
+}
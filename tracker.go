@@ -0,0 +1,456 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+// maxCrossSamples bounds the points checked along the path of a single update
+const maxCrossSamples = 100
+
+const (
+	// FenceEnter is emitted when a member moves into a fence
+	FenceEnter FenceEventType = "enter"
+	// FenceExit is emitted when a member moves out of a fence or is removed while inside it
+	FenceExit FenceEventType = "exit"
+	// FenceCross is emitted when a member passes through a fence between two updates, see WithCrossDetection
+	FenceCross FenceEventType = "cross"
+)
+
+type (
+	// FenceEventType is the kind of a FenceEvent
+	FenceEventType string
+
+	// FenceEvent reports a member entering, leaving or crossing a fence, Lat & Lon are the coordinates of the
+	// update which caused it
+	//
+	// Distance is only set for the proximity events of WatchProximity, Motion for trackers WithMotionTracking.
+	FenceEvent struct {
+		Type     FenceEventType
+		Fence    string
+		Label    string
+		Lat      float64
+		Lon      float64
+		Distance float64
+		Motion   *Motion
+		Time     time.Time
+	}
+
+	// FenceTracker writes location updates and emits events for the fences of a fence set the members enter and
+	// leave
+	//
+	// The fences each member is inside are stored next to the bucket, so the events survive restarts and are
+	// shared by all trackers of the bucket. Concurrent updates of the same label may emit duplicate events.
+	FenceTracker struct {
+		client    *redis.Client
+		fenceSet  string
+		bitDepth  uint8
+		handlers  []func(FenceEvent)
+		sinks     []EventSink
+		stream    string
+		maxLen    int64
+		crossStep float64
+		motion    bool
+	}
+
+	// FenceTrackerOption configures a FenceTracker
+	FenceTrackerOption func(*FenceTracker)
+)
+
+// WithEventHandler calls handler for every event, in the order the events happened
+func WithEventHandler(handler func(FenceEvent)) FenceTrackerOption {
+	return func(t *FenceTracker) {
+		t.handlers = append(t.handlers, handler)
+	}
+}
+
+// WithEventChannel sends every event to events, updates block until the events are received
+func WithEventChannel(events chan<- FenceEvent) FenceTrackerOption {
+	return WithEventHandler(func(event FenceEvent) {
+		events <- event
+	})
+}
+
+// WithEventStream appends every event to a redis stream, trimmed to about maxLen entries when maxLen is positive
+func WithEventStream(stream string, maxLen int64) FenceTrackerOption {
+	return func(t *FenceTracker) {
+		t.stream = stream
+		t.maxLen = maxLen
+	}
+}
+
+// WithCrossDetection checks the path between the previous and the new coordinates of a member every step meters
+// and emits FenceCross for fences it passes through without being inside before or after the update
+//
+// Paths are straight lines in latitude and longitude and checked at no more than 100 points.
+func WithCrossDetection(step float64) FenceTrackerOption {
+	return func(t *FenceTracker) {
+		t.crossStep = step
+	}
+}
+
+// NewFenceTracker returns a FenceTracker evaluating the fences of fenceSet
+func NewFenceTracker(client *redis.Client, fenceSet string, bitDepth uint8, options ...FenceTrackerOption) *FenceTracker {
+	t := &FenceTracker{client: client, fenceSet: fenceSet, bitDepth: bitDepth}
+	for _, option := range options {
+		option(t)
+	}
+
+	return t
+}
+
+// UpdateCoordinates adds coordinates to the set and returns the events caused by the moves, after emitting them
+func (t *FenceTracker) UpdateCoordinates(bucketName string, coordinates ...GeoKey) ([]FenceEvent, error) {
+	if err := ValidateCoordinates(coordinates...); err != nil {
+		return []FenceEvent{}, err
+	}
+	if len(coordinates) == 0 {
+		return []FenceEvent{}, nil
+	}
+
+	previous := make([]*GeoKey, len(coordinates))
+	if t.crossStep > 0 {
+		for idx := range coordinates {
+			coordinate, err := GetCoordinates(t.client, bucketName, t.bitDepth, coordinates[idx].Label)
+			if err == nil {
+				previous[idx] = &coordinate
+			} else if err != ErrMemberNotFound {
+				return []FenceEvent{}, err
+			}
+		}
+	}
+
+	now := time.Now()
+	var motions []*Motion
+	if t.motion {
+		var err error
+		if motions, err = measureMotion(t.client, bucketName, t.bitDepth, now, coordinates); err != nil {
+			return []FenceEvent{}, err
+		}
+	}
+
+	if _, err := AddCoordinates(t.client, bucketName, t.bitDepth, coordinates...); err != nil {
+		return []FenceEvent{}, err
+	}
+	if t.motion {
+		if err := storeMotion(t.client, bucketName, now, coordinates, motions); err != nil {
+			return []FenceEvent{}, err
+		}
+	}
+
+	return t.evaluate(bucketName, coordinates, previous, motions)
+}
+
+// evaluate compares the fences containing the written coordinates with the fences the members were inside before,
+// moves the roaming fences they anchor and emits the resulting events
+//
+// previous holds the coordinates before the update, nil when unknown, and is only used to detect crossed fences.
+// motions are read from the bucket when tracking motion without them.
+func (t *FenceTracker) evaluate(bucketName string, coordinates []GeoKey, previous []*GeoKey, motions []*Motion) ([]FenceEvent, error) {
+	labels := make([]string, len(coordinates))
+	for idx := range coordinates {
+		labels[idx] = coordinates[idx].Label
+	}
+	prior, err := t.memberships(bucketName, labels)
+	if err != nil {
+		return []FenceEvent{}, err
+	}
+	if t.motion && motions == nil {
+		if motions, err = GetMotion(t.client, bucketName, labels...); err != nil {
+			return []FenceEvent{}, err
+		}
+	}
+
+	moved := make([][]storedFence, len(coordinates))
+	for idx, coordinate := range coordinates {
+		if moved[idx], err = moveRoamingFences(t.client, t.fenceSet, bucketName, coordinate); err != nil {
+			return []FenceEvent{}, err
+		}
+	}
+
+	now := time.Now()
+	events := []FenceEvent{}
+	current := make([][]string, len(coordinates))
+	for idx, coordinate := range coordinates {
+		fences, err := ContainingFences(t.client, t.fenceSet, coordinate.Lat, coordinate.Lon)
+		if err != nil {
+			return events, err
+		}
+		current[idx] = fenceNames(withoutAnchored(fences, bucketName, coordinate.Label))
+
+		event := FenceEvent{Label: coordinate.Label, Lat: coordinate.Lat, Lon: coordinate.Lon, Time: now}
+		if motions != nil {
+			event.Motion = motions[idx]
+		}
+		for _, name := range prior[idx] {
+			if !slices.Contains(current[idx], name) {
+				events = append(events, event.with(FenceExit, name))
+			}
+		}
+		if t.crossStep > 0 && previous[idx] != nil {
+			crossed, err := t.crossedFences(bucketName, *previous[idx], coordinate)
+			if err != nil {
+				return events, err
+			}
+			for _, name := range crossed {
+				if !slices.Contains(prior[idx], name) && !slices.Contains(current[idx], name) {
+					events = append(events, event.with(FenceCross, name))
+				}
+			}
+		}
+		for _, name := range current[idx] {
+			if !slices.Contains(prior[idx], name) {
+				events = append(events, event.with(FenceEnter, name))
+			}
+		}
+	}
+
+	if err := t.storeMemberships(bucketName, labels, prior, current); err != nil {
+		return events, err
+	}
+
+	for idx, coordinate := range coordinates {
+		for _, fence := range moved[idx] {
+			roaming, err := t.reevaluateRoamingFence(bucketName, fence, coordinate.Lat, coordinate.Lon, labels, now)
+			events = append(events, roaming...)
+			if err != nil {
+				return events, err
+			}
+		}
+	}
+
+	proximity, err := t.evaluateProximity(bucketName, coordinates, now)
+	events = append(events, proximity...)
+	if err != nil {
+		return events, err
+	}
+
+	return events, t.emit(events)
+}
+
+// RemoveCoordinatesByKeys removes coordinates from the set and emits FenceExit for the fences they were inside
+func (t *FenceTracker) RemoveCoordinatesByKeys(bucketName string, coordinatesKeys ...string) ([]FenceEvent, error) {
+	if len(coordinatesKeys) == 0 {
+		return []FenceEvent{}, nil
+	}
+
+	coordinates := make([]GeoKey, len(coordinatesKeys))
+	for idx, label := range coordinatesKeys {
+		var err error
+		coordinates[idx], err = GetCoordinates(t.client, bucketName, t.bitDepth, label)
+		if err != nil && err != ErrMemberNotFound {
+			return []FenceEvent{}, err
+		}
+		coordinates[idx].Label = label
+	}
+
+	if _, err := RemoveCoordinatesByKeys(t.client, bucketName, coordinatesKeys...); err != nil {
+		return []FenceEvent{}, err
+	}
+
+	return t.evaluateRemoved(bucketName, coordinates)
+}
+
+// evaluateRemoved emits FenceExit for the fences removed members were inside and forgets their memberships
+func (t *FenceTracker) evaluateRemoved(bucketName string, coordinates []GeoKey) ([]FenceEvent, error) {
+	labels := make([]string, len(coordinates))
+	for idx := range coordinates {
+		labels[idx] = coordinates[idx].Label
+	}
+	prior, err := t.memberships(bucketName, labels)
+	if err != nil {
+		return []FenceEvent{}, err
+	}
+	if err := t.removeMemberships(bucketName, labels, prior); err != nil {
+		return []FenceEvent{}, err
+	}
+
+	now := time.Now()
+	events := []FenceEvent{}
+	for idx, coordinate := range coordinates {
+		event := FenceEvent{Label: coordinate.Label, Lat: coordinate.Lat, Lon: coordinate.Lon, Time: now}
+		for _, name := range prior[idx] {
+			events = append(events, event.with(FenceExit, name))
+		}
+	}
+
+	return events, t.emit(events)
+}
+
+// crossedFences returns the fences containing any point checked along the path between two coordinates
+func (t *FenceTracker) crossedFences(bucketName string, from, to GeoKey) ([]string, error) {
+	dLon := math.Remainder(to.Lon-from.Lon, 360)
+	samples := min(int(math.Ceil(Haversine(from.Lat, from.Lon, to.Lat, to.Lon)/t.crossStep)), maxCrossSamples)
+
+	crossed := []string{}
+	for i := 1; i < samples; i++ {
+		fraction := float64(i) / float64(samples)
+		lat := from.Lat + (to.Lat-from.Lat)*fraction
+		lon := math.Remainder(from.Lon+dLon*fraction, 360)
+
+		fences, err := ContainingFences(t.client, t.fenceSet, lat, lon)
+		if err != nil {
+			return crossed, err
+		}
+		for _, name := range fenceNames(withoutAnchored(fences, bucketName, to.Label)) {
+			if !slices.Contains(crossed, name) {
+				crossed = append(crossed, name)
+			}
+		}
+	}
+
+	return crossed, nil
+}
+
+// memberships returns the fences each label was inside after its last update
+func (t *FenceTracker) memberships(bucketName string, labels []string) ([][]string, error) {
+	values, err := t.client.HMGet(membershipKey(bucketName, t.fenceSet), labels...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	memberships := make([][]string, len(labels))
+	for idx := range values {
+		encoded, ok := values[idx].(string)
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal([]byte(encoded), &memberships[idx]); err != nil {
+			return nil, err
+		}
+	}
+
+	return memberships, nil
+}
+
+// storeMemberships replaces the memberships of labels and moves them between the occupants of the fences
+func (t *FenceTracker) storeMemberships(bucketName string, labels []string, prior, current [][]string) error {
+	pairs := make([]string, 0, len(labels)*2)
+	for idx, label := range labels {
+		encoded, err := json.Marshal(current[idx])
+		if err != nil {
+			return err
+		}
+		pairs = append(pairs, label, string(encoded))
+	}
+
+	multi := t.client.Multi()
+	defer multi.Close()
+
+	_, err := multi.Exec(func() error {
+		multi.HMSet(membershipKey(bucketName, t.fenceSet), pairs[0], pairs[1], pairs[2:]...)
+		for idx, label := range labels {
+			t.updateOccupants(multi, bucketName, label, prior[idx], current[idx])
+		}
+		return nil
+	})
+
+	return err
+}
+
+// removeMemberships forgets the memberships of labels and removes them from the occupants of the fences
+func (t *FenceTracker) removeMemberships(bucketName string, labels []string, prior [][]string) error {
+	multi := t.client.Multi()
+	defer multi.Close()
+
+	_, err := multi.Exec(func() error {
+		multi.HDel(membershipKey(bucketName, t.fenceSet), labels...)
+		for idx, label := range labels {
+			t.updateOccupants(multi, bucketName, label, prior[idx], nil)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// updateOccupants queues the occupancy changes of a label moving from the prior to the current fences
+func (t *FenceTracker) updateOccupants(multi *redis.Multi, bucketName, label string, prior, current []string) {
+	for _, name := range prior {
+		if !slices.Contains(current, name) {
+			multi.SRem(occupantsKey(t.fenceSet, bucketName, name), label)
+		}
+	}
+	for _, name := range current {
+		if !slices.Contains(prior, name) {
+			multi.SAdd(occupantsKey(t.fenceSet, bucketName, name), label)
+		}
+	}
+}
+
+// emit passes events to the handlers and sinks and appends them to the stream
+func (t *FenceTracker) emit(events []FenceEvent) error {
+	for _, event := range events {
+		for _, handler := range t.handlers {
+			handler(event)
+		}
+	}
+	if len(events) > 0 {
+		for _, sink := range t.sinks {
+			if err := sink.WriteFenceEvents(events...); err != nil {
+				return err
+			}
+		}
+	}
+
+	if t.stream == "" {
+		return nil
+	}
+	for _, event := range events {
+		args := []string{"XADD", t.stream}
+		if t.maxLen > 0 {
+			args = append(args, "MAXLEN", "~", strconv.FormatInt(t.maxLen, 10))
+		}
+		args = append(args, "*",
+			"type", string(event.Type),
+			"fence", event.Fence,
+			"label", event.Label,
+			"lat", strconv.FormatFloat(event.Lat, 'f', -1, 64),
+			"lon", strconv.FormatFloat(event.Lon, 'f', -1, 64),
+			"time", strconv.FormatInt(event.Time.UnixMilli(), 10),
+		)
+		if event.Distance > 0 {
+			args = append(args, "distance", strconv.FormatFloat(event.Distance, 'f', -1, 64))
+		}
+		if event.Motion != nil {
+			args = append(args, "speed", strconv.FormatFloat(event.Motion.Speed, 'f', -1, 64),
+				"heading", strconv.FormatFloat(event.Motion.Heading, 'f', -1, 64))
+		}
+
+		cmd := redis.NewCmd(args...)
+		t.client.Process(cmd)
+		if err := cmd.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e FenceEvent) with(eventType FenceEventType, fence string) FenceEvent {
+	e.Type, e.Fence = eventType, fence
+	return e
+}
+
+func fenceNames(fences []Fence) []string {
+	names := make([]string, len(fences))
+	for idx := range fences {
+		names[idx] = fences[idx].Name
+	}
+
+	return names
+}
+
+func membershipKey(bucketName, fenceSet string) string {
+	return bucketName + ":membership:" + fenceSet
+}
@@ -0,0 +1,208 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestFenceTracker(t *testing.T) {
+	const (
+		fenceSet   = "test:tracker:fences"
+		zSetDriver = "test:tracker:drivers"
+	)
+
+	client.Del(zSetDriver, zSetDriver+":membership:"+fenceSet)
+	CreateFence(client, fenceSet, "depot", 52.52, 13.405, 500)
+	CreateFence(client, fenceSet, "bridge", 52.52, 13.445, 300)
+	defer DeleteFence(client, fenceSet, "depot")
+	defer DeleteFence(client, fenceSet, "bridge")
+
+	handled := []FenceEvent{}
+	tracker := NewFenceTracker(client, fenceSet, bitDepth,
+		WithEventHandler(func(event FenceEvent) { handled = append(handled, event) }),
+		WithCrossDetection(100),
+	)
+
+	expect := func(events []FenceEvent, err error, expected ...FenceEventType) {
+		t.Helper()
+		if err != nil || len(events) != len(expected) {
+			t.Logf("expected %v got %v error %v\n", expected, events, err)
+			t.Fail()
+			return
+		}
+		for idx := range events {
+			if events[idx].Type != expected[idx] || events[idx].Label != "driver" {
+				t.Logf("expected %v got %v\n", expected, events)
+				t.Fail()
+			}
+		}
+	}
+
+	events, err := tracker.UpdateCoordinates(zSetDriver, GeoKey{Lat: 52.52, Lon: 13.405, Label: "driver"})
+	expect(events, err, FenceEnter)
+
+	if occupants, err := Occupancy(client, fenceSet, zSetDriver, "depot"); err != nil || len(occupants) != 1 || occupants[0] != "driver" {
+		t.Logf("expected the driver to occupy the depot got %v error %v\n", occupants, err)
+		t.Fail()
+	}
+
+	events, err = tracker.UpdateCoordinates(zSetDriver, GeoKey{Lat: 52.5201, Lon: 13.4051, Label: "driver"})
+	expect(events, err)
+
+	// driving east leaves the depot and passes the bridge
+	events, err = tracker.UpdateCoordinates(zSetDriver, GeoKey{Lat: 52.52, Lon: 13.48, Label: "driver"})
+	expect(events, err, FenceExit, FenceCross)
+
+	events, err = tracker.UpdateCoordinates(zSetDriver, GeoKey{Lat: 52.52, Lon: 13.445, Label: "driver"})
+	expect(events, err, FenceEnter)
+
+	events, err = tracker.RemoveCoordinatesByKeys(zSetDriver, "driver")
+	expect(events, err, FenceExit)
+
+	if count, err := OccupancyCount(client, fenceSet, zSetDriver, "bridge"); err != nil || count != 0 {
+		t.Logf("expected the bridge to be empty got %d error %v\n", count, err)
+		t.Fail()
+	}
+
+	if len(handled) != 5 {
+		t.Logf("expected the handler to receive 5 events got %v\n", handled)
+		t.Fail()
+	}
+}
+
+func TestRoamingFences(t *testing.T) {
+	const (
+		fenceSet     = "test:tracker:roaming"
+		zSetVehicles = "test:tracker:vehicles"
+	)
+
+	client.Del(zSetVehicles, zSetVehicles+":membership:"+fenceSet)
+	tracker := NewFenceTracker(client, fenceSet, bitDepth)
+
+	tracker.UpdateCoordinates(zSetVehicles,
+		GeoKey{Lat: 52.52, Lon: 13.405, Label: "vehicle"},
+		GeoKey{Lat: 52.52, Lon: 13.45, Label: "courier"},
+	)
+	if err := CreateRoamingFence(client, fenceSet, "near-vehicle", zSetVehicles, bitDepth, "vehicle", 200); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	defer DeleteFence(client, fenceSet, "near-vehicle")
+
+	// the vehicle drives up to the courier, the fence follows it
+	events, err := tracker.UpdateCoordinates(zSetVehicles, GeoKey{Lat: 52.52, Lon: 13.4495, Label: "vehicle"})
+	if err != nil || len(events) != 1 || events[0].Type != FenceEnter || events[0].Label != "courier" {
+		t.Logf("expected the courier to enter got %v error %v\n", events, err)
+		t.Fail()
+	}
+
+	events, err = tracker.UpdateCoordinates(zSetVehicles, GeoKey{Lat: 52.52, Lon: 13.405, Label: "vehicle"})
+	if err != nil || len(events) != 1 || events[0].Type != FenceExit || events[0].Label != "courier" {
+		t.Logf("expected the courier to exit got %v error %v\n", events, err)
+		t.Fail()
+	}
+
+	fences, err := ContainingFences(client, fenceSet, 52.52, 13.405)
+	if err != nil || len(fences) != 1 || fences[0].Anchor != "vehicle" {
+		t.Logf("expected the fence at the vehicle got %v error %v\n", fences, err)
+		t.Fail()
+	}
+}
+
+func TestFenceListenerEvaluate(t *testing.T) {
+	const (
+		fenceSet  = "test:listener:fences"
+		zSetUsers = "test:listener:users"
+	)
+
+	client.Del(zSetUsers, zSetUsers+":membership:"+fenceSet, zSetUsers+":snapshot:"+fenceSet)
+	CreateFence(client, fenceSet, "venue", 52.52, 13.405, 300)
+	defer DeleteFence(client, fenceSet, "venue")
+
+	listener, err := NewFenceTracker(client, fenceSet, bitDepth).Listen([]string{zSetUsers}, WithListenerDelay(time.Hour))
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	defer listener.Close()
+
+	// another process writes without a tracker
+	AddCoordinates(client, zSetUsers, bitDepth, GeoKey{Lat: 52.5201, Lon: 13.4051, Label: "visitor"})
+	events, err := listener.Evaluate(zSetUsers)
+	if err != nil || len(events) != 1 || events[0].Type != FenceEnter {
+		t.Logf("expected an enter event got %v error %v\n", events, err)
+		t.Fail()
+	}
+
+	events, err = listener.Evaluate(zSetUsers)
+	if err != nil || len(events) != 0 {
+		t.Logf("expected no events without changes got %v error %v\n", events, err)
+		t.Fail()
+	}
+
+	RemoveCoordinatesByKeys(client, zSetUsers, "visitor")
+	events, err = listener.Evaluate(zSetUsers)
+	if err != nil || len(events) != 1 || events[0].Type != FenceExit {
+		t.Logf("expected an exit event got %v error %v\n", events, err)
+		t.Fail()
+	}
+}
+
+func TestFenceListenerZeroDelay(t *testing.T) {
+	// a zero delay keeps the default instead of panicking in the ticker
+	listener, err := NewFenceTracker(client, "test:listener:fences", bitDepth).Listen([]string{"test:listener:idle"},
+		WithListenerDelay(0))
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if err := listener.Close(); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.Fail()
+	}
+}
+
+func TestWatchProximity(t *testing.T) {
+	const (
+		fenceSet    = "test:proximity:watches"
+		zSetDrivers = "test:proximity:drivers"
+		zSetRiders  = "test:proximity:riders"
+	)
+
+	client.Del(zSetDrivers, zSetRiders)
+	tracker := NewFenceTracker(client, fenceSet, bitDepth)
+	tracker.UpdateCoordinates(zSetDrivers, GeoKey{Lat: 52.52, Lon: 13.30, Label: "driver"})
+	tracker.UpdateCoordinates(zSetRiders, GeoKey{Lat: 52.52, Lon: 13.405, Label: "rider"})
+
+	if err := tracker.WatchProximity("pickup", zSetDrivers, "driver", zSetRiders, "rider", 500); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	defer tracker.UnwatchProximity("pickup")
+
+	events, err := tracker.UpdateCoordinates(zSetDrivers, GeoKey{Lat: 52.52, Lon: 13.40, Label: "driver"})
+	if err != nil || len(events) != 1 || events[0].Type != ProximityNear || events[0].Distance >= 500 {
+		t.Logf("expected the driver to come near got %v error %v\n", events, err)
+		t.Fail()
+	}
+
+	events, err = tracker.UpdateCoordinates(zSetDrivers, GeoKey{Lat: 52.52, Lon: 13.401, Label: "driver"})
+	if err != nil || len(events) != 0 {
+		t.Logf("expected no event while staying near got %v error %v\n", events, err)
+		t.Fail()
+	}
+
+	// either member moving is evaluated
+	events, err = tracker.UpdateCoordinates(zSetRiders, GeoKey{Lat: 52.53, Lon: 13.405, Label: "rider"})
+	if err != nil || len(events) != 1 || events[0].Type != ProximityFar || events[0].Label != "rider" {
+		t.Logf("expected the rider to move away got %v error %v\n", events, err)
+		t.Fail()
+	}
+}
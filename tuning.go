@@ -0,0 +1,152 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"math"
+	"slices"
+)
+
+// maxTunedCells bounds the number of cells a tuned search refines to
+const maxTunedCells = 1024
+
+type (
+	// PrecisionFunc returns the bit depth of the cells whose neighborhood covers a radius in meters
+	PrecisionFunc func(radius float64) uint8
+
+	// SearchTuning adjusts how radius searches choose the cells they read, the zero value keeps the defaults
+	//
+	// Tuning only changes how many members are read from redis, results are filtered by their exact distance
+	// either way.
+	SearchTuning struct {
+		// Precision picks the cell depth for a radius, DefaultPrecision is used when it is nil
+		Precision PrecisionFunc
+		// MinCells refines searches with finer cells until the radius is covered by at least MinCells cells
+		MinCells int
+		// MaxOverFetch refines searches with finer cells until the cells read cover at most MaxOverFetch times the
+		// area of the search circle
+		MaxOverFetch float64
+	}
+)
+
+// WithTuning sets how a radius search chooses the cells it reads
+//
+// Refined searches cover the bounding box of the circle with up to 1024 cells instead of the neighbors of the
+// center cell, searches close to the poles always scan their latitude band.
+func WithTuning(tuning SearchTuning) SearchOption {
+	return func(o *searchOptions) {
+		o.tuning = tuning
+	}
+}
+
+// DefaultPrecision is the PrecisionFunc used by default, it picks the cells which are closest in size to the radius
+func DefaultPrecision(radius float64) uint8 {
+	return rangeDepth(radius)
+}
+
+// PrecisionTable returns a PrecisionFunc picking the bit depth of the radius in meters closest to a radius
+func PrecisionTable(radii map[uint8]float64) PrecisionFunc {
+	depths := make([]uint8, 0, len(radii))
+	for depth := range radii {
+		depths = append(depths, depth)
+	}
+	slices.SortFunc(depths, func(a, b uint8) int {
+		return -int(a) + int(b)
+	})
+
+	return func(radius float64) uint8 {
+		for idx := 0; idx < len(depths)-1; idx++ {
+			if radius-radii[depths[idx]] < radii[depths[idx+1]]-radius {
+				return depths[idx]
+			}
+		}
+
+		return depths[len(depths)-1]
+	}
+}
+
+// precision returns the depth of the cells covering radius
+func (t SearchTuning) precision(radius float64) (uint8, error) {
+	if t.Precision == nil {
+		return rangeDepth(radius), nil
+	}
+
+	depth := t.Precision(radius)
+	if err := ValidateBitDepth(depth); err != nil {
+		return 0, err
+	}
+
+	return depth, nil
+}
+
+// refine returns the cells of the finest depth which satisfies the tuning, starting from the neighbors of the
+// center at depth, and their depth
+func (t SearchTuning) refine(lat, lon, radius float64, depth, bitDepth uint8) ([]uint64, uint8) {
+	cells := geohashEncoder{bitDepth: depth}
+	covering := cells.Neighbors(cells.EncodeInt(lat, lon))
+
+	circle := 2 * math.Pi * earthRadius * earthRadius * (1 - math.Cos(radius/earthRadius))
+	for !t.satisfiedBy(covering, depth, circle) && depth+2 <= bitDepth {
+		finer := circleBoxCells(lat, lon, radius, depth+2)
+		if finer == nil {
+			break
+		}
+		covering, depth = finer, depth+2
+	}
+
+	return covering, depth
+}
+
+func (t SearchTuning) satisfiedBy(cells []uint64, depth uint8, circle float64) bool {
+	if len(cells) < t.MinCells {
+		return false
+	}
+	if t.MaxOverFetch <= 0 {
+		return true
+	}
+
+	area := 0.0
+	for _, cell := range cells {
+		area += cellArea(geohashEncoder{bitDepth: depth}.bbox(cell))
+	}
+
+	return area <= t.MaxOverFetch*circle
+}
+
+// circleBoxCells returns the cells of depth intersecting the bounding box of a circle, wrapping around the antimeridian,
+// or nil if there are more than maxTunedCells
+func circleBoxCells(lat, lon, radius float64, depth uint8) []uint64 {
+	dLat := radius / metersPerDegree
+	dLon := dLat / math.Cos((math.Abs(lat)+dLat)*math.Pi/180)
+
+	cells := geohashEncoder{bitDepth: depth}
+	rows, cols := 1<<(depth/2), 1<<(depth/2)
+	height, width := 180/float64(rows), 360/float64(cols)
+
+	firstRow := max(int(math.Floor((lat-dLat+90)/height)), 0)
+	lastRow := min(int(math.Floor((lat+dLat+90)/height)), rows-1)
+	firstCol := int(math.Floor((lon - dLon + 180) / width))
+	lastCol := min(int(math.Floor((lon+dLon+180)/width)), firstCol+cols-1)
+	if (lastRow-firstRow+1)*(lastCol-firstCol+1) > maxTunedCells {
+		return nil
+	}
+
+	covering := make([]uint64, 0, (lastRow-firstRow+1)*(lastCol-firstCol+1))
+	for row := firstRow; row <= lastRow; row++ {
+		for col := firstCol; col <= lastCol; col++ {
+			wrapped := (col%cols + cols) % cols
+			covering = append(covering, cells.EncodeInt(-90+(float64(row)+0.5)*height, -180+(float64(wrapped)+0.5)*width))
+		}
+	}
+
+	return covering
+}
+
+// cellArea returns the area of a bounding box on the sphere in square meters
+func cellArea(minLat, minLon, maxLat, maxLon float64) float64 {
+	return earthRadius * earthRadius * (maxLon - minLon) * math.Pi / 180 *
+		(math.Sin(maxLat*math.Pi/180) - math.Sin(minLat*math.Pi/180))
+}
@@ -0,0 +1,74 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPrecisionTable(t *testing.T) {
+	radii := map[uint8]float64{}
+	for idx, radius := range rangeIndex {
+		radii[52-idx*2] = radius
+	}
+	precision := PrecisionTable(radii)
+
+	for _, radius := range []float64{0.1, 5, 70, 1000, 40000, 3e6} {
+		if depth := precision(radius); depth != DefaultPrecision(radius) {
+			t.Logf("expected depth %d for %f got %d\n", DefaultPrecision(radius), radius, depth)
+			t.Fail()
+		}
+	}
+}
+
+func TestTunedRanges(t *testing.T) {
+	const lat, lon, radius = 52.52, 13.405, 80.0
+
+	_, defaultDepth, _ := planRanges(lat, lon, radius, 52, SearchTuning{})
+	coarse := func(float64) uint8 { return 30 }
+
+	for _, tuning := range []SearchTuning{{MinCells: 40}, {Precision: coarse, MaxOverFetch: 4}, {Precision: coarse, MinCells: 20}} {
+		ranges, depth, err := planRanges(lat, lon, radius, 52, tuning)
+		if err != nil {
+			t.Logf("error encountered %q\n", err)
+			t.FailNow()
+		}
+		startDepth := defaultDepth
+		if tuning.Precision != nil {
+			startDepth = 30
+		}
+		if depth <= startDepth {
+			t.Logf("expected finer cells than depth %d for %+v got %d\n", startDepth, tuning, depth)
+			t.Fail()
+		}
+
+		// refined cells cover the bounding box, every point within the radius has to be in one of the ranges
+		random := rand.New(rand.NewSource(1))
+		for idx := 0; idx < 1000; idx++ {
+			pointLat := lat + (random.Float64()*2-1)*radius/metersPerDegree
+			pointLon := lon + (random.Float64()*2-1)*radius/metersPerDegree*2
+			if Haversine(lat, lon, pointLat, pointLon) > radius {
+				continue
+			}
+
+			score := float64(geohashEncoder{bitDepth: 52}.EncodeInt(pointLat, pointLon))
+			covered := false
+			for _, r := range ranges {
+				covered = covered || (r.Lower <= score && score <= r.Upper)
+			}
+			if !covered {
+				t.Logf("expected %f, %f to be covered by %+v\n", pointLat, pointLon, tuning)
+				t.FailNow()
+			}
+		}
+	}
+
+	if _, _, err := planRanges(lat, lon, radius, 52, SearchTuning{Precision: func(float64) uint8 { return 7 }}); err != ErrInvalidBitDepth {
+		t.Logf("expected ErrInvalidBitDepth got %v\n", err)
+		t.Fail()
+	}
+}
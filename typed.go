@@ -0,0 +1,141 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/redis.v2"
+)
+
+type (
+	// TypedKey is a GeoKey with a typed value, stored as the JSON payload of the member
+	TypedKey[T any] struct {
+		Lat   float64
+		Lon   float64
+		Label string
+		Value T
+	}
+
+	// TypedResult is a Result with the decoded value of the member, HasValue is false for members without payload
+	TypedResult[T any] struct {
+		Result
+		Value    T
+		HasValue bool
+	}
+
+	// TypedClient wraps a GeoClient to add and search members with values of type T
+	TypedClient[T any] struct {
+		client *GeoClient
+	}
+)
+
+// AddTyped adds members with typed values to the set like AddCoordinates
+func AddTyped[T any](client *redis.Client, bucketName string, bitDepth uint8, members ...TypedKey[T]) (int64, error) {
+	coordinates, err := typedCoordinates(members)
+	if err != nil {
+		return 0, err
+	}
+
+	return AddCoordinates(client, bucketName, bitDepth, coordinates...)
+}
+
+// GetTyped returns the decoded coordinates and value of a label or ErrMemberNotFound
+func GetTyped[T any](client *redis.Client, bucketName string, bitDepth uint8, label string) (TypedKey[T], bool, error) {
+	coordinates, err := GetCoordinates(client, bucketName, bitDepth, label)
+	if err != nil {
+		return TypedKey[T]{}, false, err
+	}
+	payloads, err := GetPayloads(client, bucketName, label)
+	if err != nil {
+		return TypedKey[T]{}, false, err
+	}
+
+	member := TypedKey[T]{Lat: coordinates.Lat, Lon: coordinates.Lon, Label: label}
+	if payloads[0] == nil {
+		return member, false, nil
+	}
+	if err := json.Unmarshal(payloads[0], &member.Value); err != nil {
+		return TypedKey[T]{}, false, fmt.Errorf("invalid value of %q: %s", label, err)
+	}
+
+	return member, true, nil
+}
+
+// SearchTyped searches like Search and decodes the values of the results
+func SearchTyped[T any](client *redis.Client, bucketName string, lat, lon, radius float64, bitDepth uint8, options ...SearchOption) ([]TypedResult[T], error) {
+	results, err := Search(client, bucketName, lat, lon, radius, bitDepth, append(options, WithPayloads())...)
+	if err != nil && len(results) == 0 {
+		return []TypedResult[T]{}, err
+	}
+
+	typed, decodeErr := typedResults[T](results)
+	if decodeErr != nil {
+		return []TypedResult[T]{}, decodeErr
+	}
+
+	return typed, err
+}
+
+// NewTypedClient returns a TypedClient using c
+func NewTypedClient[T any](c *GeoClient) *TypedClient[T] {
+	return &TypedClient[T]{client: c}
+}
+
+// Add adds members with typed values to the set
+func (c *TypedClient[T]) Add(bucketName string, members ...TypedKey[T]) (int64, error) {
+	coordinates, err := typedCoordinates(members)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.client.AddCoordinates(bucketName, coordinates...)
+}
+
+// Search searches like GeoClient.Search and decodes the values of the results
+func (c *TypedClient[T]) Search(bucketName string, lat, lon, radius float64, options ...SearchOption) ([]TypedResult[T], error) {
+	results, err := c.client.Search(bucketName, lat, lon, radius, append(options, WithPayloads())...)
+	if err != nil && len(results) == 0 {
+		return []TypedResult[T]{}, err
+	}
+
+	typed, decodeErr := typedResults[T](results)
+	if decodeErr != nil {
+		return []TypedResult[T]{}, decodeErr
+	}
+
+	return typed, err
+}
+
+func typedCoordinates[T any](members []TypedKey[T]) ([]GeoKey, error) {
+	coordinates := make([]GeoKey, len(members))
+	for idx := range members {
+		payload, err := json.Marshal(members[idx].Value)
+		if err != nil {
+			return []GeoKey{}, fmt.Errorf("invalid value of %q: %s", members[idx].Label, err)
+		}
+		coordinates[idx] = GeoKey{Lat: members[idx].Lat, Lon: members[idx].Lon, Label: members[idx].Label, Payload: payload}
+	}
+
+	return coordinates, nil
+}
+
+func typedResults[T any](results []Result) ([]TypedResult[T], error) {
+	typed := make([]TypedResult[T], len(results))
+	for idx := range results {
+		typed[idx].Result = results[idx]
+		if results[idx].Payload == nil {
+			continue
+		}
+		if err := json.Unmarshal(results[idx].Payload, &typed[idx].Value); err != nil {
+			return []TypedResult[T]{}, fmt.Errorf("invalid value of %q: %s", results[idx].Label, err)
+		}
+		typed[idx].HasValue = true
+	}
+
+	return typed, nil
+}
@@ -0,0 +1,53 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+type courier struct {
+	Name    string `json:"name"`
+	Vehicle string `json:"vehicle"`
+}
+
+func TestTypedMembers(t *testing.T) {
+	const zSetTyped = "test:typed"
+
+	client.Del(zSetTyped, zSetTyped+":payload")
+	_, err := AddTyped(client, zSetTyped, bitDepth,
+		TypedKey[courier]{Label: "c1", Lat: 52.52, Lon: 13.405, Value: courier{Name: "Anna", Vehicle: "bike"}},
+		TypedKey[courier]{Label: "c2", Lat: 52.521, Lon: 13.405, Value: courier{Name: "Ben", Vehicle: "car"}},
+	)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	AddCoordinates(client, zSetTyped, bitDepth, GeoKey{Label: "plain", Lat: 52.522, Lon: 13.405})
+
+	results, err := SearchTyped[courier](client, zSetTyped, 52.52, 13.405, 1000, bitDepth)
+	if err != nil || len(results) != 3 {
+		t.Logf("expected 3 results got %v, %v\n", results, err)
+		t.FailNow()
+	}
+	if !results[0].HasValue || results[0].Value.Name != "Anna" || results[1].Value.Vehicle != "car" || results[2].HasValue {
+		t.Logf("unexpected values %+v\n", results)
+		t.Fail()
+	}
+
+	member, ok, err := GetTyped[courier](client, zSetTyped, bitDepth, "c2")
+	if err != nil || !ok || member.Value.Name != "Ben" {
+		t.Logf("unexpected member %+v, %t, %v\n", member, ok, err)
+		t.Fail()
+	}
+
+	if _, err := SearchTyped[int](client, zSetTyped, 52.52, 13.405, 1000, bitDepth); err == nil {
+		t.Logf("expected values of another type to fail\n")
+		t.Fail()
+	}
+}
@@ -0,0 +1,65 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unit is a length unit expressed in meters
+type Unit float64
+
+const (
+	Meters        Unit = 1
+	Kilometers    Unit = 1000
+	Miles         Unit = 1609.344
+	NauticalMiles Unit = 1852
+)
+
+// WithUnit reads the radius in unit and returns distances in the same unit, meters are used by default
+func WithUnit(unit Unit) SearchOption {
+	return func(o *searchOptions) {
+		o.unit = unit
+	}
+}
+
+// ParseUnit parses the abbreviations m, km, mi and nm
+func ParseUnit(name string) (Unit, error) {
+	switch strings.ToLower(name) {
+	case "", "m":
+		return Meters, nil
+	case "km":
+		return Kilometers, nil
+	case "mi":
+		return Miles, nil
+	case "nm":
+		return NauticalMiles, nil
+	}
+
+	return 0, fmt.Errorf("unknown unit %q", name)
+}
+
+// ToMeters converts a length in unit to meters
+func (u Unit) ToMeters(length float64) float64 {
+	return length * float64(u)
+}
+
+// FromMeters converts a length in meters to unit
+func (u Unit) FromMeters(meters float64) float64 {
+	return meters / float64(u)
+}
+
+// convertDistances converts the distances of results from meters to unit
+func convertDistances(results []Result, unit Unit) {
+	if unit == Meters {
+		return
+	}
+
+	for idx := range results {
+		results[idx].Distance = unit.FromMeters(results[idx].Distance)
+	}
+}
@@ -0,0 +1,55 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+	"github.com/tapglue/georedis/memorystore"
+)
+
+func TestParseUnit(t *testing.T) {
+	for name, expected := range map[string]Unit{"": Meters, "m": Meters, "KM": Kilometers, "mi": Miles, "nm": NauticalMiles} {
+		if unit, err := ParseUnit(name); err != nil || unit != expected {
+			t.Logf("%q: expected %v got %v error %v\n", name, expected, unit, err)
+			t.Fail()
+		}
+	}
+
+	if _, err := ParseUnit("furlong"); err == nil {
+		t.Logf("expected an error for an unknown unit\n")
+		t.Fail()
+	}
+}
+
+func TestSearchWithUnit(t *testing.T) {
+	coreClient := NewCoreClient(memorystore.New(), 52)
+	coreClient.AddCoordinates("test:units",
+		GeoKey{Lat: 52.5200, Lon: 13.4050, Label: "center"},
+		GeoKey{Lat: 52.5245, Lon: 13.4050, Label: "near"},
+		GeoKey{Lat: 52.6100, Lon: 13.4050, Label: "far"},
+	)
+
+	meters, err := coreClient.Search("test:units", 52.5200, 13.4050, 1000)
+	if err != nil || len(meters) != 2 {
+		t.Logf("expected 2 results in meters got %v error %v\n", meters, err)
+		t.FailNow()
+	}
+
+	for _, unit := range []Unit{Kilometers, Miles, NauticalMiles} {
+		results, err := coreClient.Search("test:units", 52.5200, 13.4050, unit.FromMeters(1000), WithUnit(unit))
+		if err != nil || len(results) != 2 {
+			t.Logf("%v: expected 2 results got %v error %v\n", unit, results, err)
+			t.FailNow()
+		}
+		if expected := unit.FromMeters(meters[1].Distance); math.Abs(results[1].Distance-expected) > 1e-9 {
+			t.Logf("%v: expected distance %f got %f\n", unit, expected, results[1].Distance)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrInvalidLatitude is returned for latitudes outside [-90, 90], NaN or infinite
+	ErrInvalidLatitude = errors.New("invalid latitude")
+	// ErrInvalidLongitude is returned for longitudes outside [-180, 180], NaN or infinite
+	ErrInvalidLongitude = errors.New("invalid longitude")
+	// ErrInvalidBitDepth is returned for bit depths which are odd, zero or above 52
+	ErrInvalidBitDepth = errors.New("bitDepth must be an even value between 2 and 52")
+	// ErrBitDepthTooLow is returned when the radius needs finer cells than the bit depth of the bucket
+	ErrBitDepthTooLow = errors.New("bitDepth must be high enough to calculate range within radius")
+)
+
+const maxBitDepth = 52
+
+// CoordinateError identifies the GeoKey with invalid coordinates, it wraps ErrInvalidLatitude or ErrInvalidLongitude
+type CoordinateError struct {
+	Key GeoKey
+	Err error
+}
+
+func (e *CoordinateError) Error() string {
+	return fmt.Sprintf("%s for %q: %v, %v", e.Err, e.Key.Label, e.Key.Lat, e.Key.Lon)
+}
+
+func (e *CoordinateError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateCoordinates returns a CoordinateError for the first key with invalid coordinates
+func ValidateCoordinates(coordinates ...GeoKey) error {
+	for _, coordinate := range coordinates {
+		if err := validateLatLon(coordinate.Lat, coordinate.Lon); err != nil {
+			return &CoordinateError{Key: coordinate, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// ValidateBitDepth returns ErrInvalidBitDepth unless bitDepth is an even value between 2 and 52
+func ValidateBitDepth(bitDepth uint8) error {
+	if bitDepth == 0 || bitDepth > maxBitDepth || bitDepth%2 != 0 {
+		return ErrInvalidBitDepth
+	}
+
+	return nil
+}
+
+// AutoBitDepth returns the lowest bit depth locating members within accuracy meters of their coordinates
+//
+// Decoded coordinates are the centers of cells twice as wide as high at the equator, the error is at most half
+// their diagonal.
+func AutoBitDepth(accuracy float64) uint8 {
+	for bitDepth := uint8(2); bitDepth < maxBitDepth; bitDepth += 2 {
+		cellHeight := 180 / float64(uint64(1)<<(bitDepth/2)) * metersPerDegree
+		if cellHeight*math.Sqrt(5)/2 <= accuracy {
+			return bitDepth
+		}
+	}
+
+	return maxBitDepth
+}
+
+func validateLatLon(lat, lon float64) error {
+	if math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return ErrInvalidLatitude
+	}
+	if math.IsNaN(lon) || lon < -180 || lon > 180 {
+		return ErrInvalidLongitude
+	}
+
+	return nil
+}
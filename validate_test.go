@@ -0,0 +1,102 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+	"github.com/tapglue/georedis/memorystore"
+)
+
+func TestValidateCoordinates(t *testing.T) {
+	tests := []struct {
+		key GeoKey
+		err error
+	}{
+		{GeoKey{Lat: 90, Lon: -180, Label: "corner"}, nil},
+		{GeoKey{Lat: 90.1, Lon: 0, Label: "north"}, ErrInvalidLatitude},
+		{GeoKey{Lat: math.NaN(), Lon: 0, Label: "nan"}, ErrInvalidLatitude},
+		{GeoKey{Lat: 0, Lon: math.Inf(1), Label: "inf"}, ErrInvalidLongitude},
+		{GeoKey{Lat: 0, Lon: -180.5, Label: "west"}, ErrInvalidLongitude},
+	}
+
+	for _, test := range tests {
+		err := ValidateCoordinates(GeoKey{Label: "valid"}, test.key)
+		if !errors.Is(err, test.err) {
+			t.Logf("%s: expected %v got %v\n", test.key.Label, test.err, err)
+			t.Fail()
+		}
+
+		var coordinateErr *CoordinateError
+		if test.err != nil && (!errors.As(err, &coordinateErr) || coordinateErr.Key.Label != test.key.Label) {
+			t.Logf("%s: expected the offending key in %v\n", test.key.Label, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestInvalidCoordinatesAreRejected(t *testing.T) {
+	coreClient := NewCoreClient(memorystore.New(), 52)
+
+	if _, err := coreClient.AddCoordinates("test:invalid", GeoKey{Lat: 100, Lon: 0, Label: "nowhere"}); !errors.Is(err, ErrInvalidLatitude) {
+		t.Logf("expected ErrInvalidLatitude got %v\n", err)
+		t.Fail()
+	}
+	if count, _ := coreClient.CountCoordinates("test:invalid"); count != 0 {
+		t.Logf("expected nothing to be added got %d members\n", count)
+		t.Fail()
+	}
+
+	if _, err := coreClient.Search("test:invalid", 0, 200, 1000); !errors.Is(err, ErrInvalidLongitude) {
+		t.Logf("expected ErrInvalidLongitude got %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestValidateBitDepth(t *testing.T) {
+	for bitDepth, valid := range map[uint8]bool{0: false, 1: false, 2: true, 26: true, 51: false, 52: true, 54: false} {
+		if err := ValidateBitDepth(bitDepth); (err == nil) != valid {
+			t.Logf("bit depth %d: expected valid %t got %v\n", bitDepth, valid, err)
+			t.Fail()
+		}
+	}
+
+	coreClient := NewCoreClient(memorystore.New(), 26)
+	if _, err := coreClient.Search("test:bitdepth", 52.52, 13.405, 10); !errors.Is(err, ErrBitDepthTooLow) {
+		t.Logf("expected ErrBitDepthTooLow got %v\n", err)
+		t.Fail()
+	}
+
+	coreClient = NewCoreClient(memorystore.New(), 51)
+	if _, err := coreClient.AddCoordinates("test:bitdepth", GeoKey{Lat: 52.52, Lon: 13.405, Label: "odd"}); !errors.Is(err, ErrInvalidBitDepth) {
+		t.Logf("expected ErrInvalidBitDepth got %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestAutoBitDepth(t *testing.T) {
+	tests := []struct {
+		accuracy float64
+		bitDepth uint8
+	}{
+		{0, 52},
+		{0.5, 52},
+		{0.7, 50},
+		{100, 36},
+		{1e7, 4},
+		{1e8, 2},
+	}
+
+	for _, test := range tests {
+		if bitDepth := AutoBitDepth(test.accuracy); bitDepth != test.bitDepth {
+			t.Logf("accuracy %g: expected bit depth %d got %d\n", test.accuracy, test.bitDepth, bitDepth)
+			t.Fail()
+		}
+	}
+}
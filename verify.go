@@ -0,0 +1,273 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"gopkg.in/redis.v2"
+)
+
+// BucketReport lists the inconsistencies between a bucket and the keys next to it, by the suffix of the key like
+// "payload", "seen" or "tag:restaurant"
+type BucketReport struct {
+	Members int64
+	// Orphans are labels with entries in a key although they are no members or, for tag sets and the name index,
+	// although the member has another tag or name
+	Orphans map[string][]string
+	// Missing are members whose tag set or name index entry is missing although the member has the tag or name
+	Missing map[string][]string
+}
+
+// Consistent reports whether no inconsistency was found
+func (r BucketReport) Consistent() bool {
+	return len(r.Orphans) == 0 && len(r.Missing) == 0
+}
+
+// VerifyBucket compares the members of a bucket with their payloads, attributes, last seen times, motions, tags
+// and names and reports the entries which are orphaned or missing
+//
+// The keys next to the bucket are read before the members, so members added concurrently aren't reported. All
+// labels are held in memory while verifying.
+func VerifyBucket(client *redis.Client, bucketName string) (BucketReport, error) {
+	snapshot, err := readCompanions(client, bucketName)
+	if err != nil {
+		return BucketReport{}, err
+	}
+
+	members, err := bucketMembers(client, bucketName)
+	if err != nil {
+		return BucketReport{}, err
+	}
+
+	return snapshot.verify(members), nil
+}
+
+// RebuildIndexes verifies a bucket like VerifyBucket, removes the orphaned entries and restores the missing tag
+// set and name index entries from the tags and names of the members, it returns the repaired inconsistencies
+//
+// Writes to the bucket while rebuilding may be repaired wrongly, rebuild while the bucket isn't written to.
+func RebuildIndexes(client *redis.Client, bucketName string) (BucketReport, error) {
+	report, err := VerifyBucket(client, bucketName)
+	if err != nil || report.Consistent() {
+		return report, err
+	}
+
+	multi := client.Multi()
+	defer multi.Close()
+
+	_, err = multi.Exec(func() error {
+		for suffix, labels := range report.Orphans {
+			key := bucketName + ":" + suffix
+			switch {
+			case suffix == "seen", suffix == "names":
+				multi.ZRem(key, labels...)
+			case strings.HasPrefix(suffix, "tag:"):
+				multi.SRem(key, labels...)
+			default:
+				multi.HDel(key, labels...)
+			}
+		}
+		for suffix, labels := range report.Missing {
+			key := bucketName + ":" + suffix
+			if suffix == "names" {
+				entries := make([]redis.Z, len(labels))
+				for idx := range labels {
+					entries[idx] = redis.Z{Member: labels[idx]}
+				}
+				multi.ZAdd(key, entries...)
+				continue
+			}
+			multi.SAdd(key, labels...)
+		}
+		return nil
+	})
+
+	return report, err
+}
+
+// VerifyBucket reports the orphaned and missing entries next to a bucket, see VerifyBucket
+func (c *GeoClient) VerifyBucket(bucketName string) (BucketReport, error) {
+	return handleOp(c, Operation{Name: "VerifyBucket", Bucket: bucketName}, func() (BucketReport, error) {
+		key := c.key(bucketName)
+		return withRetry(c, func() (BucketReport, error) {
+			return VerifyBucket(c.client, key)
+		})
+	})
+}
+
+// RebuildIndexes repairs the orphaned and missing entries next to a bucket, see RebuildIndexes
+func (c *GeoClient) RebuildIndexes(bucketName string) (BucketReport, error) {
+	return handleOp(c, Operation{Name: "RebuildIndexes", Bucket: bucketName, Write: true}, func() (BucketReport, error) {
+		key := c.key(bucketName)
+		defer c.wrote(key)
+		return withRetry(c, func() (BucketReport, error) {
+			return RebuildIndexes(c.client, key)
+		})
+	})
+}
+
+// companions are the labels of the keys next to a bucket
+type companions struct {
+	labels    map[string][]string
+	tags      map[string][]string
+	tagSets   map[string][]string
+	names     map[string]string
+	nameIndex []string
+}
+
+func readCompanions(client *redis.Client, bucketName string) (companions, error) {
+	snapshot := companions{labels: map[string][]string{}, tags: map[string][]string{}, tagSets: map[string][]string{}}
+
+	var err error
+	for _, suffix := range []string{"payload", "attributes", "motion"} {
+		if snapshot.labels[suffix], err = client.HKeys(bucketName + ":" + suffix).Result(); err != nil {
+			return companions{}, err
+		}
+	}
+	if snapshot.labels["seen"], err = client.ZRange(lastSeenKey(bucketName), 0, -1).Result(); err != nil {
+		return companions{}, err
+	}
+
+	encodedTags, err := client.HGetAllMap(tagsKey(bucketName)).Result()
+	if err != nil {
+		return companions{}, err
+	}
+	for label, encoded := range encodedTags {
+		tags := []string{}
+		if err := json.Unmarshal([]byte(encoded), &tags); err != nil {
+			return companions{}, err
+		}
+		snapshot.tags[label] = tags
+	}
+
+	tagSetKeys, err := scanKeys(client, escapeGlob(tagKey(bucketName, ""))+"*")
+	if err != nil {
+		return companions{}, err
+	}
+	for _, key := range tagSetKeys {
+		if snapshot.tagSets[strings.TrimPrefix(key, tagKey(bucketName, ""))], err = client.SMembers(key).Result(); err != nil {
+			return companions{}, err
+		}
+	}
+
+	if snapshot.names, err = client.HGetAllMap(namesKey(bucketName)).Result(); err != nil {
+		return companions{}, err
+	}
+	if snapshot.nameIndex, err = client.ZRange(nameIndexKey(bucketName), 0, -1).Result(); err != nil {
+		return companions{}, err
+	}
+
+	return snapshot, nil
+}
+
+// verify compares the snapshot with the members of the bucket
+func (s companions) verify(members map[string]bool) BucketReport {
+	report := BucketReport{Members: int64(len(members)), Orphans: map[string][]string{}, Missing: map[string][]string{}}
+	add := func(found map[string][]string, suffix, label string) {
+		found[suffix] = append(found[suffix], label)
+	}
+
+	for suffix, labels := range s.labels {
+		for _, label := range labels {
+			if !members[label] {
+				add(report.Orphans, suffix, label)
+			}
+		}
+	}
+
+	for label, tags := range s.tags {
+		if !members[label] {
+			add(report.Orphans, "tags", label)
+			continue
+		}
+		for _, tag := range tags {
+			if !slices.Contains(s.tagSets[tag], label) {
+				add(report.Missing, "tag:"+tag, label)
+			}
+		}
+	}
+	for tag, labels := range s.tagSets {
+		for _, label := range labels {
+			if !members[label] || !slices.Contains(s.tags[label], tag) {
+				add(report.Orphans, "tag:"+tag, label)
+			}
+		}
+	}
+
+	indexed := make(map[string]bool, len(s.nameIndex))
+	for _, entry := range s.nameIndex {
+		indexed[entry] = true
+		_, label, _ := strings.Cut(entry, "\x00")
+		if name, ok := s.names[label]; !members[label] || !ok || nameEntry(name, label) != entry {
+			add(report.Orphans, "names", entry)
+		}
+	}
+	for label, name := range s.names {
+		if !members[label] {
+			add(report.Orphans, "name", label)
+		} else if !indexed[nameEntry(name, label)] {
+			add(report.Missing, "names", nameEntry(name, label))
+		}
+	}
+
+	for _, found := range []map[string][]string{report.Orphans, report.Missing} {
+		for suffix := range found {
+			slices.Sort(found[suffix])
+		}
+	}
+
+	return report
+}
+
+// bucketMembers returns the labels of a bucket, read in pages
+func bucketMembers(client *redis.Client, bucketName string) (map[string]bool, error) {
+	members := map[string]bool{}
+	for offset := int64(0); ; offset += reencodeBatchSize {
+		labels, err := client.ZRange(bucketName, offset, offset+reencodeBatchSize-1).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, label := range labels {
+			members[label] = true
+		}
+		if len(labels) < reencodeBatchSize {
+			return members, nil
+		}
+	}
+}
+
+// scanKeys returns the keys matching pattern
+func scanKeys(client *redis.Client, pattern string) ([]string, error) {
+	keys := []string{}
+	var cursor int64
+	for {
+		next, found, err := client.Scan(cursor, pattern, wipeBatchSize).Result()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, found...)
+
+		if cursor = next; cursor == 0 {
+			return keys, nil
+		}
+	}
+}
+
+// escapeGlob escapes the glob characters of s for MATCH patterns
+func escapeGlob(s string) string {
+	var escaped strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("*?[]\\", r) {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteRune(r)
+	}
+
+	return escaped.String()
+}
@@ -0,0 +1,48 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestVerifyBucketAndRebuildIndexes(t *testing.T) {
+	const zSetVerify = "test:verify"
+
+	client.Del(zSetVerify, zSetVerify+":payload", zSetVerify+":tags", zSetVerify+":tag:cafe",
+		zSetVerify+":name", zSetVerify+":names")
+	AddCoordinates(client, zSetVerify, bitDepth,
+		GeoKey{Label: "a", Lat: 52.52, Lon: 13.405},
+		GeoKey{Label: "b", Lat: 52.521, Lon: 13.405},
+	)
+	SetTags(client, zSetVerify, "a", "cafe")
+	SetNames(client, zSetVerify, map[string]string{"b": "Starbucks"})
+
+	report, err := VerifyBucket(client, zSetVerify)
+	if err != nil || !report.Consistent() || report.Members != 2 {
+		t.Logf("expected a consistent bucket with 2 members got %v, %v\n", report, err)
+		t.FailNow()
+	}
+
+	client.HSet(zSetVerify+":payload", "gone", "{}")
+	client.SRem(zSetVerify+":tag:cafe", "a")
+	client.Del(zSetVerify + ":names")
+
+	report, err = RebuildIndexes(client, zSetVerify)
+	if err != nil || report.Consistent() || len(report.Orphans["payload"]) != 1 ||
+		len(report.Missing["tag:cafe"]) != 1 || len(report.Missing["names"]) != 1 {
+		t.Logf("expected the orphaned payload and the missing tag and name entries got %v, %v\n", report, err)
+		t.FailNow()
+	}
+
+	report, err = VerifyBucket(client, zSetVerify)
+	if err != nil || !report.Consistent() {
+		t.Logf("expected a consistent bucket after rebuilding got %v, %v\n", report, err)
+		t.Fail()
+	}
+}
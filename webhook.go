@@ -0,0 +1,301 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// WebhookSignatureHeader carries the hex encoded HMAC-SHA256 of the timestamp, a dot and the body
+	WebhookSignatureHeader = "X-Georedis-Signature"
+	// WebhookTimestampHeader carries the unix time in seconds the request was signed at
+	WebhookTimestampHeader = "X-Georedis-Timestamp"
+
+	defaultWebhookTimeout   = 10 * time.Second
+	defaultWebhookQueueSize = 1024
+)
+
+var (
+	// ErrDispatcherClosed is returned when handing events to a closed WebhookDispatcher
+	ErrDispatcherClosed = errors.New("webhook dispatcher closed")
+	// ErrWebhookQueueFull is wrapped by the errors reporting events dropped for an endpoint whose queue is full
+	ErrWebhookQueueFull = errors.New("webhook queue full")
+)
+
+// DefaultWebhookRetryPolicy retries failed deliveries 4 times with backoffs from up to 500ms to up to 30s
+var DefaultWebhookRetryPolicy = RetryPolicy{MaxAttempts: 5, BaseDelay: 500 * time.Millisecond, MaxDelay: 30 * time.Second}
+
+type (
+	// WebhookDispatcher POSTs fence events as JSON to HTTPS endpoints, signed with a shared secret
+	//
+	// Every endpoint receives every event in the order they are handed over, from its own queue and background
+	// goroutine so a slow endpoint only delays its own deliveries. Handle never blocks, events arriving while the
+	// queue of an endpoint is full are dropped for it and reported as ErrWebhookQueueFull. Use Handle as a
+	// FenceTracker event handler.
+	WebhookDispatcher struct {
+		endpoints []string
+		secret    []byte
+		client    *http.Client
+		retry     RetryPolicy
+		onError   func(error)
+		queueSize int
+
+		mu      sync.RWMutex
+		closed  bool
+		queues  []chan FenceEvent
+		workers sync.WaitGroup
+	}
+
+	// WebhookOption configures a WebhookDispatcher
+	WebhookOption func(*WebhookDispatcher)
+
+	// WebhookError is returned for deliveries which an endpoint answered without a 2xx status
+	WebhookError struct {
+		Endpoint   string
+		StatusCode int
+	}
+
+	webhookPayload struct {
+		Type     FenceEventType `json:"type"`
+		Fence    string         `json:"fence"`
+		Label    string         `json:"label"`
+		Lat      float64        `json:"lat"`
+		Lon      float64        `json:"lon"`
+		Distance float64        `json:"distance,omitempty"`
+		Time     time.Time      `json:"time"`
+	}
+)
+
+// WithWebhookClient sets the HTTP client used for deliveries, the default one times out after 10 seconds
+func WithWebhookClient(client *http.Client) WebhookOption {
+	return func(d *WebhookDispatcher) {
+		d.client = client
+	}
+}
+
+// WithWebhookRetryPolicy sets how failed deliveries are retried, DefaultWebhookRetryPolicy is used by default
+//
+// A nil Retryable retries network errors, 429 and 5xx responses.
+func WithWebhookRetryPolicy(policy RetryPolicy) WebhookOption {
+	return func(d *WebhookDispatcher) {
+		d.retry = policy
+	}
+}
+
+// WithWebhookErrorHandler sets the function receiving the errors of deliveries which failed for good
+func WithWebhookErrorHandler(handler func(error)) WebhookOption {
+	return func(d *WebhookDispatcher) {
+		d.onError = handler
+	}
+}
+
+// WithWebhookQueueSize sets the number of events buffered per endpoint before Handle drops them, 1024 by default,
+// sizes which are not positive keep the default
+func WithWebhookQueueSize(size int) WebhookOption {
+	return func(d *WebhookDispatcher) {
+		if size > 0 {
+			d.queueSize = size
+		}
+	}
+}
+
+// NewWebhookDispatcher returns a WebhookDispatcher delivering to endpoints and starts its background delivery,
+// every endpoint has to be an https URL
+func NewWebhookDispatcher(endpoints []string, secret []byte, options ...WebhookOption) (*WebhookDispatcher, error) {
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "https" {
+			return nil, fmt.Errorf("webhook endpoint %q is not an https URL", endpoint)
+		}
+	}
+
+	d := &WebhookDispatcher{
+		endpoints: endpoints,
+		secret:    secret,
+		client:    &http.Client{Timeout: defaultWebhookTimeout},
+		retry:     DefaultWebhookRetryPolicy,
+		onError:   func(error) {},
+		queueSize: defaultWebhookQueueSize,
+	}
+	for _, option := range options {
+		option(d)
+	}
+	if d.retry.Retryable == nil {
+		d.retry.Retryable = isRetryableWebhook
+	}
+
+	d.queues = make([]chan FenceEvent, len(endpoints))
+	for idx, endpoint := range endpoints {
+		d.queues[idx] = make(chan FenceEvent, d.queueSize)
+		d.workers.Add(1)
+		go d.loop(endpoint, d.queues[idx])
+	}
+
+	return d, nil
+}
+
+// Handle queues an event for delivery without blocking, events handed over after Close or while the queue of an
+// endpoint is full are dropped and reported to the error handler
+func (d *WebhookDispatcher) Handle(event FenceEvent) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		d.onError(ErrDispatcherClosed)
+		return
+	}
+	for idx, queue := range d.queues {
+		select {
+		case queue <- event:
+		default:
+			d.onError(fmt.Errorf("%w, dropped %s event of %q for %s", ErrWebhookQueueFull, event.Type, event.Label, d.endpoints[idx]))
+		}
+	}
+}
+
+// Send delivers an event to all endpoints right away and returns the first failed delivery
+func (d *WebhookDispatcher) Send(event FenceEvent) error {
+	var firstErr error
+	for _, endpoint := range d.endpoints {
+		if err := d.deliver(endpoint, event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Close stops accepting events and waits for the queued ones to be delivered
+func (d *WebhookDispatcher) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return ErrDispatcherClosed
+	}
+	d.closed = true
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.mu.Unlock()
+
+	d.workers.Wait()
+	return nil
+}
+
+// SignWebhook returns the signature of a body sent at timestamp, receivers compare it to WebhookSignatureHeader
+// with hmac.Equal
+func SignWebhook(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook checks the signature headers of a delivery against its body and rejects timestamps further than
+// tolerance from now
+func VerifyWebhook(secret []byte, header http.Header, body []byte, tolerance time.Duration) bool {
+	timestamp, err := strconv.ParseInt(header.Get(WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return false
+	}
+
+	return hmac.Equal([]byte(header.Get(WebhookSignatureHeader)), []byte(SignWebhook(secret, timestamp, body)))
+}
+
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("webhook %s answered %d", e.Endpoint, e.StatusCode)
+}
+
+// loop delivers the events queued for an endpoint
+func (d *WebhookDispatcher) loop(endpoint string, queue chan FenceEvent) {
+	defer d.workers.Done()
+
+	for event := range queue {
+		if err := d.deliver(endpoint, event); err != nil {
+			d.onError(err)
+		}
+	}
+}
+
+// deliver posts an event to an endpoint according to the retry policy
+func (d *WebhookDispatcher) deliver(endpoint string, event FenceEvent) error {
+	body, err := json.Marshal(webhookPayload{
+		Type:     event.Type,
+		Fence:    event.Fence,
+		Label:    event.Label,
+		Lat:      event.Lat,
+		Lon:      event.Lon,
+		Distance: event.Distance,
+		Time:     event.Time,
+	})
+	if err != nil {
+		return err
+	}
+
+	return d.retry.Do(func() error {
+		return d.post(endpoint, body)
+	})
+}
+
+func (d *WebhookDispatcher) post(endpoint string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(d.secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &WebhookError{Endpoint: endpoint, StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// isRetryableWebhook retries transport errors, rate limiting and server errors
+func isRetryableWebhook(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var webhookErr *WebhookError
+	if errors.As(err, &webhookErr) {
+		return webhookErr.StatusCode == http.StatusTooManyRequests || webhookErr.StatusCode >= 500
+	}
+
+	return true
+}
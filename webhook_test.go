@@ -0,0 +1,149 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestWebhookDispatcher(t *testing.T) {
+	secret := []byte("secret")
+
+	var (
+		mu       sync.Mutex
+		attempts int
+		received []map[string]interface{}
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhook(secret, r.Header, body, time.Minute) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		payload := map[string]interface{}{}
+		json.Unmarshal(body, &payload)
+		received = append(received, payload)
+	}))
+	defer server.Close()
+
+	dispatcher, err := NewWebhookDispatcher([]string{server.URL}, secret,
+		WithWebhookClient(server.Client()),
+		WithWebhookRetryPolicy(RetryPolicy{MaxAttempts: 3}),
+	)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	dispatcher.Handle(FenceEvent{Type: FenceEnter, Fence: "depot", Label: "driver", Time: time.Now()})
+	dispatcher.Handle(FenceEvent{Type: FenceExit, Fence: "depot", Label: "driver", Time: time.Now()})
+	dispatcher.Close()
+
+	if attempts != 3 || len(received) != 2 || received[0]["type"] != "enter" || received[1]["type"] != "exit" {
+		t.Logf("expected a retry and 2 ordered events got %d attempts and %v\n", attempts, received)
+		t.Fail()
+	}
+}
+
+func TestWebhookDispatcherSlowEndpoint(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+
+	delivered := make(chan struct{}, 10)
+	fast := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- struct{}{}
+	}))
+	defer fast.Close()
+
+	var (
+		mu      sync.Mutex
+		dropped int
+	)
+	dispatcher, err := NewWebhookDispatcher([]string{slow.URL, fast.URL}, []byte("secret"),
+		WithWebhookClient(fast.Client()),
+		WithWebhookQueueSize(1),
+		WithWebhookErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if errors.Is(err, ErrWebhookQueueFull) {
+				dropped++
+			}
+		}),
+	)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	// the slow endpoint takes the first event, queues the second and drops the rest without blocking Handle
+	for idx := 0; idx < 4; idx++ {
+		dispatcher.Handle(FenceEvent{Type: FenceEnter, Fence: "depot", Label: "driver", Time: time.Now()})
+		select {
+		case <-delivered:
+		case <-time.After(time.Second):
+			t.Logf("expected the fast endpoint not to wait for the slow one\n")
+			t.FailNow()
+		}
+	}
+
+	mu.Lock()
+	if dropped == 0 {
+		t.Logf("expected events for the full queue of the slow endpoint to be dropped\n")
+		t.Fail()
+	}
+	mu.Unlock()
+
+	close(release)
+	dispatcher.Close()
+}
+
+func TestWebhookDispatcherRequiresHTTPS(t *testing.T) {
+	if _, err := NewWebhookDispatcher([]string{"http://example.com/hook"}, nil); err == nil {
+		t.Logf("expected an error for a plain http endpoint\n")
+		t.Fail()
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	secret, body := []byte("secret"), []byte(`{"type":"enter"}`)
+	header := http.Header{}
+	header.Set(WebhookTimestampHeader, "1700000000")
+	header.Set(WebhookSignatureHeader, SignWebhook(secret, 1700000000, body))
+
+	if VerifyWebhook(secret, header, body, time.Minute) {
+		t.Logf("expected an old timestamp to be rejected\n")
+		t.Fail()
+	}
+	if !VerifyWebhook(secret, header, body, time.Since(time.Unix(1700000000, 0))+time.Minute) {
+		t.Logf("expected a valid signature to be accepted\n")
+		t.Fail()
+	}
+	if VerifyWebhook([]byte("other"), header, body, 100*365*24*time.Hour) {
+		t.Logf("expected a different secret to be rejected\n")
+		t.Fail()
+	}
+}
@@ -0,0 +1,370 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	wkbPoint        = 1
+	wkbLineString   = 2
+	wkbPolygon      = 3
+	wkbMultiPolygon = 6
+
+	// PostGIS EWKB flags in the high bits of the geometry type
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// ParseWKT parses a WKT string, like "POINT(lon lat)", into a Geometry
+//
+// Supported types are POINT, LINESTRING, POLYGON and MULTIPOLYGON. Z and M ordinates are accepted and ignored
+// and an optional EWKT "SRID=4326;" prefix is skipped.
+func ParseWKT(wkt string) (Geometry, error) {
+	wkt = strings.TrimSpace(wkt)
+	if strings.HasPrefix(strings.ToUpper(wkt), "SRID=") {
+		idx := strings.IndexByte(wkt, ';')
+		if idx == -1 {
+			return nil, fmt.Errorf("wkt: malformed SRID prefix")
+		}
+		wkt = wkt[idx+1:]
+	}
+
+	idx := strings.IndexByte(wkt, '(')
+	if idx == -1 {
+		return nil, fmt.Errorf("wkt: missing coordinates in %q", wkt)
+	}
+
+	fields := strings.Fields(strings.ToUpper(wkt[:idx]))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("wkt: missing geometry type")
+	}
+
+	p := &wktParser{s: wkt, pos: idx}
+
+	var (
+		geometry Geometry
+		err      error
+	)
+	switch fields[0] {
+	case "POINT":
+		var points []Point
+		points, err = p.points()
+		if err == nil && len(points) != 1 {
+			err = fmt.Errorf("wkt: point must have exactly one coordinate")
+		}
+		if err == nil {
+			geometry = points[0]
+		}
+	case "LINESTRING":
+		var points []Point
+		points, err = p.points()
+		geometry = LineString(points)
+	case "POLYGON":
+		var polygon Polygon
+		polygon, err = p.polygon()
+		geometry = polygon
+	case "MULTIPOLYGON":
+		var multi MultiPolygon
+		multi, err = p.multiPolygon()
+		geometry = multi
+	default:
+		return nil, fmt.Errorf("wkt: unsupported geometry type %q", fields[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.pos != len(p.s) {
+		return nil, fmt.Errorf("wkt: unexpected trailing data at offset %d", p.pos)
+	}
+
+	return geometry, nil
+}
+
+// ParseWKB parses a WKB encoded geometry
+//
+// Both byte orders, the ISO Z, M and ZM variants and PostGIS EWKB with Z, M and SRID flags are supported for the
+// same types as ParseWKT, Z and M ordinates and the SRID are ignored.
+func ParseWKB(wkb []byte) (Geometry, error) {
+	r := bytes.NewReader(wkb)
+	geometry, err := readWKB(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("wkb: unexpected %d trailing bytes", r.Len())
+	}
+
+	return geometry, nil
+}
+
+// GeoKeyFromWKT creates a GeoKey with the provided label from a WKT point
+func GeoKeyFromWKT(label, wkt string) (GeoKey, error) {
+	geometry, err := ParseWKT(wkt)
+	if err != nil {
+		return GeoKey{}, err
+	}
+
+	return geoKeyFromGeometry(label, geometry)
+}
+
+// GeoKeyFromWKB creates a GeoKey with the provided label from a WKB point
+func GeoKeyFromWKB(label string, wkb []byte) (GeoKey, error) {
+	geometry, err := ParseWKB(wkb)
+	if err != nil {
+		return GeoKey{}, err
+	}
+
+	return geoKeyFromGeometry(label, geometry)
+}
+
+func geoKeyFromGeometry(label string, geometry Geometry) (GeoKey, error) {
+	point, ok := geometry.(Point)
+	if !ok {
+		return GeoKey{}, fmt.Errorf("expected a point got %s", geometry.geometryType())
+	}
+
+	return GeoKey{Lat: point.Lat, Lon: point.Lon, Label: label}, nil
+}
+
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n' || p.s[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func (p *wktParser) expect(c byte) error {
+	p.skipSpace()
+	if p.pos >= len(p.s) || p.s[p.pos] != c {
+		return fmt.Errorf("wkt: expected %q at offset %d", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+// list parses a parenthesized, comma separated list calling item for each element
+func (p *wktParser) list(item func() error) error {
+	if err := p.expect('('); err != nil {
+		return err
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		p.skipSpace()
+		if p.pos < len(p.s) && p.s[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		return p.expect(')')
+	}
+}
+
+func (p *wktParser) point() (Point, error) {
+	var ordinates []float64
+	for {
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.s) && strings.IndexByte(" \t\r\n,()", p.s[p.pos]) == -1 {
+			p.pos++
+		}
+		if start == p.pos {
+			break
+		}
+		value, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return Point{}, fmt.Errorf("wkt: invalid number %q", p.s[start:p.pos])
+		}
+		ordinates = append(ordinates, value)
+	}
+	if len(ordinates) < 2 || len(ordinates) > 4 {
+		return Point{}, fmt.Errorf("wkt: expected 2 to 4 ordinates got %d", len(ordinates))
+	}
+
+	return Point{Lon: ordinates[0], Lat: ordinates[1]}, nil
+}
+
+func (p *wktParser) points() ([]Point, error) {
+	points := []Point{}
+	err := p.list(func() error {
+		point, err := p.point()
+		points = append(points, point)
+		return err
+	})
+	return points, err
+}
+
+func (p *wktParser) polygon() (Polygon, error) {
+	polygon := Polygon{}
+	err := p.list(func() error {
+		ring, err := p.points()
+		polygon = append(polygon, ring)
+		return err
+	})
+	return polygon, err
+}
+
+func (p *wktParser) multiPolygon() (MultiPolygon, error) {
+	multi := MultiPolygon{}
+	err := p.list(func() error {
+		polygon, err := p.polygon()
+		multi = append(multi, polygon)
+		return err
+	})
+	return multi, err
+}
+
+type wkbReader struct {
+	r     *bytes.Reader
+	order binary.ByteOrder
+	err   error
+}
+
+func (w *wkbReader) uint32() uint32 {
+	var v uint32
+	if w.err == nil {
+		w.err = binary.Read(w.r, w.order, &v)
+	}
+	return v
+}
+
+// count reads an element count and rejects counts whose elements of at least size bytes can't fit in the rest of
+// the input, so a forged count can't make the caller allocate more than the input holds
+func (w *wkbReader) count(size int) uint32 {
+	count := w.uint32()
+	if w.err == nil && uint64(count)*uint64(size) > uint64(w.r.Len()) {
+		w.err = fmt.Errorf("%d elements exceed the remaining %d bytes", count, w.r.Len())
+	}
+	return count
+}
+
+func (w *wkbReader) point(dimensions int) Point {
+	ordinates := make([]float64, dimensions)
+	for i := range ordinates {
+		var bits uint64
+		if w.err == nil {
+			w.err = binary.Read(w.r, w.order, &bits)
+		}
+		ordinates[i] = math.Float64frombits(bits)
+	}
+	return Point{Lon: ordinates[0], Lat: ordinates[1]}
+}
+
+func (w *wkbReader) points(dimensions int) []Point {
+	count := w.count(8 * dimensions)
+	if w.err != nil {
+		return nil
+	}
+	points := make([]Point, 0, count)
+	for i := uint32(0); i < count && w.err == nil; i++ {
+		points = append(points, w.point(dimensions))
+	}
+	return points
+}
+
+func (w *wkbReader) polygon(dimensions int) Polygon {
+	// every ring holds at least its own count
+	count := w.count(4)
+	if w.err != nil {
+		return nil
+	}
+	polygon := make(Polygon, 0, count)
+	for i := uint32(0); i < count && w.err == nil; i++ {
+		polygon = append(polygon, w.points(dimensions))
+	}
+	return polygon
+}
+
+func readWKB(r *bytes.Reader) (Geometry, error) {
+	var order [1]byte
+	if _, err := io.ReadFull(r, order[:]); err != nil {
+		return nil, fmt.Errorf("wkb: %s", err)
+	}
+
+	w := &wkbReader{r: r}
+	switch order[0] {
+	case 0:
+		w.order = binary.BigEndian
+	case 1:
+		w.order = binary.LittleEndian
+	default:
+		return nil, fmt.Errorf("wkb: invalid byte order %d", order[0])
+	}
+
+	kind := w.uint32()
+	if kind&ewkbSRID != 0 {
+		w.uint32()
+	}
+	if w.err != nil {
+		return nil, fmt.Errorf("wkb: %s", w.err)
+	}
+
+	dimensions := 2
+	switch kind &^ (ewkbZ | ewkbM | ewkbSRID) / 1000 {
+	case 1, 2:
+		dimensions = 3
+	case 3:
+		dimensions = 4
+	}
+	if kind&ewkbZ != 0 {
+		dimensions++
+	}
+	if kind&ewkbM != 0 {
+		dimensions++
+	}
+	if dimensions > 4 {
+		return nil, fmt.Errorf("wkb: geometry type %#x mixes ISO and EWKB dimensions", kind)
+	}
+	kind &^= ewkbZ | ewkbM | ewkbSRID
+
+	var geometry Geometry
+	switch kind % 1000 {
+	case wkbPoint:
+		geometry = w.point(dimensions)
+	case wkbLineString:
+		geometry = LineString(w.points(dimensions))
+	case wkbPolygon:
+		geometry = w.polygon(dimensions)
+	case wkbMultiPolygon:
+		// every polygon holds at least its byte order, type and ring count
+		count := w.count(9)
+		multi := MultiPolygon{}
+		for i := uint32(0); i < count && w.err == nil; i++ {
+			member, err := readWKB(r)
+			if err != nil {
+				return nil, err
+			}
+			polygon, ok := member.(Polygon)
+			if !ok {
+				return nil, fmt.Errorf("wkb: multipolygon contains a %s", member.geometryType())
+			}
+			multi = append(multi, polygon)
+		}
+		geometry = multi
+	default:
+		return nil, fmt.Errorf("wkb: unsupported geometry type %d", kind)
+	}
+	if w.err != nil {
+		return nil, fmt.Errorf("wkb: %s", w.err)
+	}
+
+	return geometry, nil
+}
@@ -0,0 +1,117 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestGeoKeyFromWKT(t *testing.T) {
+	key, err := GeoKeyFromWKT("Philadelphia", "POINT(-75.1638 39.9523)")
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.Fail()
+	}
+	if key.Lat != 39.9523 || key.Lon != -75.1638 || key.Label != "Philadelphia" {
+		t.Logf("unexpected key %v\n", key)
+		t.Fail()
+	}
+
+	if _, err := GeoKeyFromWKT("line", "LINESTRING(1 2, 3 4)"); err == nil {
+		t.Logf("expected an error for a non point geometry\n")
+		t.Fail()
+	}
+}
+
+func TestParseWKT(t *testing.T) {
+	geometry, err := ParseWKT("SRID=4326;polygon ((0 0, 10 0, 10 10, 0 10, 0 0), (2 2, 3 2, 3 3, 2 2))")
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	polygon, ok := geometry.(Polygon)
+	if !ok {
+		t.Logf("expected a polygon got %T\n", geometry)
+		t.FailNow()
+	}
+	if len(polygon) != 2 || len(polygon[0]) != 5 || polygon[0][1] != (Point{Lat: 0, Lon: 10}) {
+		t.Logf("unexpected polygon %v\n", polygon)
+		t.Fail()
+	}
+
+	geometry, err = ParseWKT("MULTIPOLYGON Z (((0 0 1, 1 0 1, 1 1 1, 0 0 1)), ((5 5 1, 6 5 1, 6 6 1, 5 5 1)))")
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if multi, ok := geometry.(MultiPolygon); !ok || len(multi) != 2 {
+		t.Logf("unexpected multipolygon %v\n", geometry)
+		t.Fail()
+	}
+
+	for _, invalid := range []string{"POINT(1)", "POINT(1 2", "CIRCLE(1 2)", "POINT(1 2) junk", "LINESTRING(a b)"} {
+		if _, err := ParseWKT(invalid); err == nil {
+			t.Logf("expected an error for %q\n", invalid)
+			t.Fail()
+		}
+	}
+}
+
+func TestParseWKB(t *testing.T) {
+	// POINT(-75.1638 39.9523) little endian
+	wkb, _ := hex.DecodeString("01010000006dc5feb27bca52c08bfd65f7e4f94340")
+	key, err := GeoKeyFromWKB("Philadelphia", wkb)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	if key.Lat != 39.9523 || key.Lon != -75.1638 {
+		t.Logf("unexpected key %v\n", key)
+		t.Fail()
+	}
+
+	// LINESTRING(1 2, 3 4) big endian
+	wkb, _ = hex.DecodeString("0000000002000000023ff0000000000000400000000000000040080000000000004010000000000000")
+	geometry, err := ParseWKB(wkb)
+	if err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+	line, ok := geometry.(LineString)
+	if !ok || len(line) != 2 || line[1] != (Point{Lat: 4, Lon: 3}) {
+		t.Logf("unexpected linestring %v\n", geometry)
+		t.Fail()
+	}
+
+	if _, err := ParseWKB(wkb[:len(wkb)-1]); err == nil {
+		t.Logf("expected an error for truncated input\n")
+		t.Fail()
+	}
+
+	// PostGIS EWKB of SRID=4326;POINT Z(-75.1638 39.9523 12) and POINT ZM(-75.1638 39.9523 12 12)
+	for _, ewkb := range []string{
+		"01010000a0e61000006dc5feb27bca52c08bfd65f7e4f943400000000000002840",
+		"01010000c06dc5feb27bca52c08bfd65f7e4f9434000000000000028400000000000002840",
+	} {
+		wkb, _ = hex.DecodeString(ewkb)
+		if key, err := GeoKeyFromWKB("Philadelphia", wkb); err != nil || key.Lat != 39.9523 || key.Lon != -75.1638 {
+			t.Logf("unexpected key %v error %v for %s\n", key, err, ewkb)
+			t.Fail()
+		}
+	}
+
+	// counts larger than the input must fail instead of being allocated
+	for _, forged := range []string{"0102000000ffffff7f", "0103000000ffffff7f", "0106000000ffffff7f"} {
+		wkb, _ = hex.DecodeString(forged)
+		if _, err := ParseWKB(wkb); err == nil {
+			t.Logf("expected an error for the forged count in %s\n", forged)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,217 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	defaultWriterBatchSize     = 1000
+	defaultWriterFlushInterval = 100 * time.Millisecond
+)
+
+// ErrWriterClosed is returned when updating a closed Writer
+var ErrWriterClosed = errors.New("writer closed")
+
+type (
+	// Writer buffers location updates from many goroutines and writes them in batches
+	//
+	// Multiple updates of the same label in the same bucket between two flushes are coalesced, only the latest
+	// one is written. A flush happens when the batch size is reached, when the flush interval elapses or on Flush
+	// and Close.
+	Writer struct {
+		client    *redis.Client
+		bitDepth  uint8
+		batchSize int
+		interval  time.Duration
+		onError   func(error)
+
+		mu      sync.Mutex
+		pending map[string]map[string]GeoKey
+		count   int
+		closed  bool
+
+		flushing sync.Mutex
+		trigger  chan struct{}
+		done     chan struct{}
+		stopped  chan struct{}
+	}
+
+	// WriterOption configures a Writer
+	WriterOption func(*Writer)
+)
+
+// WithBatchSize sets the number of buffered updates which triggers a flush
+func WithBatchSize(size int) WriterOption {
+	return func(w *Writer) {
+		w.batchSize = size
+	}
+}
+
+// WithFlushInterval sets the maximum time updates stay buffered, intervals which are not positive keep the default
+func WithFlushInterval(interval time.Duration) WriterOption {
+	return func(w *Writer) {
+		if interval > 0 {
+			w.interval = interval
+		}
+	}
+}
+
+// WithErrorHandler sets the function receiving errors of background flushes
+func WithErrorHandler(handler func(error)) WriterOption {
+	return func(w *Writer) {
+		w.onError = handler
+	}
+}
+
+// NewWriter returns a Writer and starts its background flushing
+func NewWriter(client *redis.Client, bitDepth uint8, options ...WriterOption) *Writer {
+	w := &Writer{
+		client:    client,
+		bitDepth:  bitDepth,
+		batchSize: defaultWriterBatchSize,
+		interval:  defaultWriterFlushInterval,
+		onError:   func(error) {},
+		pending:   map[string]map[string]GeoKey{},
+		trigger:   make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	for _, option := range options {
+		option(w)
+	}
+
+	go w.loop()
+
+	return w
+}
+
+// Update buffers coordinates to be added to the set, invalid coordinates are rejected right away
+func (w *Writer) Update(bucketName string, coordinates ...GeoKey) error {
+	if err := ValidateCoordinates(coordinates...); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWriterClosed
+	}
+
+	bucket, ok := w.pending[bucketName]
+	if !ok {
+		bucket = map[string]GeoKey{}
+		w.pending[bucketName] = bucket
+	}
+	for _, coordinate := range coordinates {
+		if _, ok := bucket[coordinate.Label]; !ok {
+			w.count++
+		}
+		bucket[coordinate.Label] = coordinate
+	}
+	full := w.count >= w.batchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.trigger <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Flush writes all buffered updates and returns the first error encountered
+//
+// Updates of buckets which failed to be written are buffered again unless newer updates of their labels arrived
+// meanwhile, so they are retried by the next flush.
+func (w *Writer) Flush() error {
+	w.flushing.Lock()
+	defer w.flushing.Unlock()
+
+	w.mu.Lock()
+	pending := w.pending
+	w.pending = map[string]map[string]GeoKey{}
+	w.count = 0
+	w.mu.Unlock()
+
+	var firstErr error
+	for bucketName, bucket := range pending {
+		coordinates := make([]GeoKey, 0, len(bucket))
+		for _, coordinate := range bucket {
+			coordinates = append(coordinates, coordinate)
+		}
+
+		if _, err := AddCoordinates(w.client, bucketName, w.bitDepth, coordinates...); err != nil {
+			w.requeue(bucketName, bucket)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// requeue buffers the updates of a failed flush again, except for labels updated meanwhile
+func (w *Writer) requeue(bucketName string, failed map[string]GeoKey) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	bucket, ok := w.pending[bucketName]
+	if !ok {
+		bucket = map[string]GeoKey{}
+		w.pending[bucketName] = bucket
+	}
+	for label, coordinate := range failed {
+		if _, ok := bucket[label]; !ok {
+			bucket[label] = coordinate
+			w.count++
+		}
+	}
+}
+
+// Close stops accepting updates, waits for the background flushing to stop and writes the remaining updates
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWriterClosed
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.done)
+	<-w.stopped
+
+	return w.Flush()
+}
+
+func (w *Writer) loop() {
+	defer close(w.stopped)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		case <-w.trigger:
+		}
+
+		if err := w.Flush(); err != nil {
+			w.onError(err)
+		}
+	}
+}
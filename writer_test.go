@@ -0,0 +1,87 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package georedis_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/tapglue/georedis"
+)
+
+func TestWriter(t *testing.T) {
+	const zSetWriter = "test:writer"
+
+	client.Del(zSetWriter)
+	writer := NewWriter(client, bitDepth, WithBatchSize(10), WithFlushInterval(time.Hour))
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				writer.Update(zSetWriter, GeoKey{Lat: float64(j), Lon: 1, Label: string(rune('a' + i))})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := writer.Close(); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.Fail()
+	}
+	if err := writer.Update(zSetWriter, oneCoordinate); err != ErrWriterClosed {
+		t.Logf("expected ErrWriterClosed got %v\n", err)
+		t.Fail()
+	}
+
+	count, _ := CountCoordinates(client, zSetWriter)
+	if count != 4 {
+		t.Logf("expected 4 members got %d\n", count)
+		t.Fail()
+	}
+	coordinates, err := GetCoordinates(client, zSetWriter, bitDepth, "a")
+	if err != nil || coordinates.Lat < 48.9 || coordinates.Lat > 49.1 {
+		t.Logf("expected the latest update to win got %v error %v\n", coordinates, err)
+		t.Fail()
+	}
+}
+
+func TestWriterRetriesFailedFlushes(t *testing.T) {
+	const zSetRetried = "test:writer:retried"
+
+	// a zero interval must not panic
+	NewWriter(client, bitDepth, WithFlushInterval(0)).Close()
+
+	writer := NewWriter(client, bitDepth, WithBatchSize(100), WithFlushInterval(time.Hour))
+	defer writer.Close()
+
+	// writing to a string fails with WRONGTYPE until the key is deleted
+	client.Set(zSetRetried, "blocked")
+	writer.Update(zSetRetried, GeoKey{Lat: 1, Lon: 1, Label: "a"}, GeoKey{Lat: 1, Lon: 1, Label: "b"})
+	if err := writer.Flush(); err == nil {
+		t.Logf("expected the flush to fail\n")
+		t.FailNow()
+	}
+
+	client.Del(zSetRetried)
+	writer.Update(zSetRetried, GeoKey{Lat: 2, Lon: 2, Label: "a"})
+	if err := writer.Flush(); err != nil {
+		t.Logf("error encountered %q\n", err)
+		t.FailNow()
+	}
+
+	if count, _ := CountCoordinates(client, zSetRetried); count != 2 {
+		t.Logf("expected the failed updates to be written again got %d members\n", count)
+		t.Fail()
+	}
+	if coordinates, err := GetCoordinates(client, zSetRetried, bitDepth, "a"); err != nil || coordinates.Lat < 1.9 {
+		t.Logf("expected the newer update to win got %v error %v\n", coordinates, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,314 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+// Package wsapi provides an embeddable http.Handler pushing the members of a bucket around a moving viewport over
+// WebSockets
+//
+// Clients connect to GET /buckets/{name}/live and send viewports as JSON objects with lat, lon and radius (in
+// meters), whenever the view moves. The server answers with one message per change:
+//
+//	{"type": "enter", "label": "a", "lat": 52.52, "lon": 13.405, "distance": 120.5}
+//	{"type": "move", ...}   a member inside the viewport moved and is still inside
+//	{"type": "leave", ...}  a member moved out of the viewport or was removed
+//	{"type": "error", "error": "..."}
+//
+// A new viewport first produces enter and leave messages for the difference to the previous one. Updates are
+// received from the region channels of georedis.RegionPublisher, writers have to use it with the same region depth.
+package wsapi
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tapglue/georedis"
+
+	"gopkg.in/redis.v2"
+)
+
+const (
+	// DefaultRegionDepth is the region depth used when no other one is configured
+	DefaultRegionDepth = 20
+
+	maxMessageSize = 1 << 10
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	writeWait      = 10 * time.Second
+)
+
+// Message types sent to clients
+const (
+	MessageEnter = "enter"
+	MessageMove  = "move"
+	MessageLeave = "leave"
+	MessageError = "error"
+)
+
+type (
+	// Handler serves the live viewport API
+	Handler struct {
+		client      *redis.Client
+		bitDepth    uint8
+		regionDepth uint8
+		authorize   Authorizer
+		upgrader    websocket.Upgrader
+		handler     http.Handler
+	}
+
+	// Authorizer decides if a request may access a bucket, returning an error rejects the request
+	Authorizer func(r *http.Request, bucket string) error
+
+	// Option configures a Handler
+	Option func(*Handler)
+
+	// Viewport is the JSON representation of the area a client is interested in
+	Viewport struct {
+		Lat    float64 `json:"lat"`
+		Lon    float64 `json:"lon"`
+		Radius float64 `json:"radius"`
+	}
+
+	// Message is the JSON representation of a change sent to clients
+	Message struct {
+		Type     string  `json:"type"`
+		Label    string  `json:"label,omitempty"`
+		Lat      float64 `json:"lat,omitempty"`
+		Lon      float64 `json:"lon,omitempty"`
+		Distance float64 `json:"distance,omitempty"`
+		Error    string  `json:"error,omitempty"`
+	}
+
+	// session tracks the viewport of a connection and the members inside it
+	session struct {
+		h            *Handler
+		conn         *websocket.Conn
+		bucket       string
+		viewport     *Viewport
+		members      map[string]georedis.Point
+		subscription *georedis.RegionSubscription
+	}
+)
+
+// WithAuthorizer sets the function deciding which requests may access a bucket
+func WithAuthorizer(authorize Authorizer) Option {
+	return func(h *Handler) {
+		h.authorize = authorize
+	}
+}
+
+// WithCheckOrigin sets the function deciding which origins may connect, by default the origin has to match the host
+func WithCheckOrigin(checkOrigin func(r *http.Request) bool) Option {
+	return func(h *Handler) {
+		h.upgrader.CheckOrigin = checkOrigin
+	}
+}
+
+// WithRegionDepth sets the region depth of the channels updates are received from, DefaultRegionDepth by default
+func WithRegionDepth(depth uint8) Option {
+	return func(h *Handler) {
+		h.regionDepth = depth
+	}
+}
+
+// New returns a Handler reading coordinates with the provided bit depth
+func New(client *redis.Client, bitDepth uint8, options ...Option) *Handler {
+	h := &Handler{client: client, bitDepth: bitDepth, regionDepth: DefaultRegionDepth}
+	for _, option := range options {
+		option(h)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /buckets/{name}/live", h.live)
+	h.handler = mux
+
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler.ServeHTTP(w, r)
+}
+
+func (h *Handler) live(w http.ResponseWriter, r *http.Request) {
+	bucket := r.PathValue("name")
+	if h.authorize != nil {
+		if err := h.authorize(r, bucket); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already answered the request
+		return
+	}
+	defer conn.Close()
+
+	s := &session{h: h, conn: conn, bucket: bucket, members: map[string]georedis.Point{}}
+	defer s.close()
+	s.run()
+}
+
+// run reads viewports in the background and writes all messages from the calling goroutine
+func (s *session) run() {
+	viewports := make(chan Viewport)
+	done := make(chan struct{})
+	defer close(done)
+
+	s.conn.SetReadLimit(maxMessageSize)
+	s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			viewport := Viewport{}
+			if err := s.conn.ReadJSON(&viewport); err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case viewports <- viewport:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(pingPeriod)
+	defer ping.Stop()
+
+	for {
+		var updates <-chan georedis.RegionUpdate
+		if s.subscription != nil {
+			updates = s.subscription.Updates()
+		}
+
+		var err error
+		select {
+		case <-readErr:
+			return
+		case viewport := <-viewports:
+			err = s.move(viewport)
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			err = s.update(update)
+		case <-ping.C:
+			err = s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// move switches to a new viewport and sends the members entering and leaving it, invalid viewports are answered
+// with an error message and ignored
+func (s *session) move(viewport Viewport) error {
+	if err := validate(viewport); err != nil {
+		return s.send(Message{Type: MessageError, Error: err.Error()})
+	}
+
+	region := georedis.RegionAround(viewport.Lat, viewport.Lon, viewport.Radius)
+	var err error
+	if s.subscription == nil {
+		s.subscription, err = georedis.SubscribeRegion(s.h.client, s.bucket, s.h.bitDepth, s.h.regionDepth, region)
+	} else {
+		err = s.subscription.Move(region)
+	}
+	if err != nil {
+		return s.send(Message{Type: MessageError, Error: err.Error()})
+	}
+
+	// the region is subscribed before searching, so no update between the search and the first message is lost
+	results, err := georedis.Search(s.h.client, s.bucket, viewport.Lat, viewport.Lon, viewport.Radius, s.h.bitDepth)
+	if err != nil {
+		return s.send(Message{Type: MessageError, Error: err.Error()})
+	}
+	s.viewport = &viewport
+
+	inside := map[string]georedis.Point{}
+	for _, result := range results {
+		inside[result.Label] = georedis.Point{Lat: result.Lat, Lon: result.Lon}
+		if _, ok := s.members[result.Label]; !ok {
+			if err := s.send(s.message(MessageEnter, result.Label, result.Lat, result.Lon)); err != nil {
+				return err
+			}
+		}
+	}
+	for label, point := range s.members {
+		if _, ok := inside[label]; !ok {
+			if err := s.send(s.message(MessageLeave, label, point.Lat, point.Lon)); err != nil {
+				return err
+			}
+		}
+	}
+	s.members = inside
+
+	return nil
+}
+
+// update sends the message a change of a member causes for the current viewport, if any
+func (s *session) update(update georedis.RegionUpdate) error {
+	if s.viewport == nil {
+		return nil
+	}
+
+	_, known := s.members[update.Label]
+	inside := update.Type == georedis.RegionMove &&
+		georedis.Haversine(s.viewport.Lat, s.viewport.Lon, update.Lat, update.Lon) <= s.viewport.Radius
+
+	switch {
+	case inside && known:
+		s.members[update.Label] = georedis.Point{Lat: update.Lat, Lon: update.Lon}
+		return s.send(s.message(MessageMove, update.Label, update.Lat, update.Lon))
+	case inside:
+		s.members[update.Label] = georedis.Point{Lat: update.Lat, Lon: update.Lon}
+		return s.send(s.message(MessageEnter, update.Label, update.Lat, update.Lon))
+	case known:
+		delete(s.members, update.Label)
+		return s.send(s.message(MessageLeave, update.Label, update.Lat, update.Lon))
+	}
+
+	return nil
+}
+
+func (s *session) message(messageType, label string, lat, lon float64) Message {
+	return Message{
+		Type:     messageType,
+		Label:    label,
+		Lat:      lat,
+		Lon:      lon,
+		Distance: georedis.Haversine(s.viewport.Lat, s.viewport.Lon, lat, lon),
+	}
+}
+
+func (s *session) send(message Message) error {
+	s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return s.conn.WriteJSON(message)
+}
+
+func (s *session) close() {
+	if s.subscription != nil {
+		s.subscription.Close()
+	}
+}
+
+func validate(viewport Viewport) error {
+	if !(viewport.Radius > 0 && viewport.Radius <= georedis.MaxRadius) {
+		return georedis.ErrInvalidRadius
+	}
+	if err := georedis.ValidateCoordinates(georedis.GeoKey{Lat: viewport.Lat, Lon: viewport.Lon}); err != nil {
+		return errors.Unwrap(err)
+	}
+
+	return nil
+}
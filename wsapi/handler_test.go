@@ -0,0 +1,42 @@
+/**
+ * This code is licensed under MIT license.
+ * Please see LICENSE.md file for full license.
+ */
+
+package wsapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/tapglue/georedis/wsapi"
+
+	"gopkg.in/redis.v2"
+)
+
+var client = redis.NewTCPClient(&redis.Options{Addr: "127.0.0.1:6379"})
+
+func TestLiveAuthorization(t *testing.T) {
+	handler := New(client, 52, WithAuthorizer(func(r *http.Request, bucket string) error {
+		if bucket != "public" {
+			return errors.New("forbidden bucket")
+		}
+		return nil
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/buckets/private/live", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Logf("expected status %d got %d\n", http.StatusUnauthorized, rec.Code)
+		t.Fail()
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/buckets/public/live", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Logf("expected status %d got %d\n", http.StatusMethodNotAllowed, rec.Code)
+		t.Fail()
+	}
+}